@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	github.com/creasty/defaults v1.2.1
+	github.com/golang/protobuf v1.2.0
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/rs/xid v1.2.1
 	github.com/sirupsen/logrus v1.2.0
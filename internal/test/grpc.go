@@ -48,6 +48,9 @@ func (mock *MockCapabilitiesStreamErr) Send(capability *synse.DeviceCapability)
 type MockDevicesStream struct {
 	grpc.ServerStream
 	Results map[string]*synse.Device
+
+	// Order holds the UIDs of the devices in the order they were sent.
+	Order []string
 }
 
 // NewMockDevicesStream creates a new mock devices stream.
@@ -60,6 +63,7 @@ func NewMockDevicesStream() *MockDevicesStream {
 // Send fulfils the stream interface for the mock grpc stream.
 func (mock *MockDevicesStream) Send(device *synse.Device) error {
 	mock.Results[device.GetUid()] = device
+	mock.Order = append(mock.Order, device.GetUid())
 	return nil
 }
 
@@ -1,6 +1,12 @@
 package sdk
 
 import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 )
@@ -43,14 +49,88 @@ func execPostRun(plugin *Plugin) *errors.MultiError {
 	return multiErr
 }
 
+// execPostRunWithTimeout executes the post-run actions for the plugin, waiting at
+// most the given timeout for them to complete. A timeout of 0 waits for the actions
+// indefinitely. If the actions do not complete within the timeout, the actions which
+// are still pending are logged and an error is returned; the pending actions are
+// left running in the background.
+func execPostRunWithTimeout(plugin *Plugin, timeout time.Duration) (*errors.MultiError, error) {
+	if timeout <= 0 {
+		return execPostRun(plugin), nil
+	}
+
+	var (
+		lock    sync.Mutex
+		current int
+	)
+	actions := ctx.postRunActions
+	done := make(chan *errors.MultiError, 1)
+
+	go func() {
+		var multiErr = errors.NewMultiError("post-run actions")
+
+		log.Debugf("[sdk] executing %d post-run action(s)", len(actions))
+		for i, action := range actions {
+			lock.Lock()
+			current = i
+			lock.Unlock()
+
+			log.Debugf(" * %v", action)
+			err := action(plugin)
+			if err != nil {
+				multiErr.Add(err)
+			}
+		}
+		done <- multiErr
+	}()
+
+	select {
+	case multiErr := <-done:
+		return multiErr, nil
+	case <-time.After(timeout):
+		lock.Lock()
+		var pending []string
+		for _, action := range actions[current:] {
+			pending = append(pending, actionName(action))
+		}
+		lock.Unlock()
+
+		log.WithFields(log.Fields{
+			"timeout": timeout,
+			"pending": pending,
+		}).Error("[sdk] timed out waiting for post-run actions to complete")
+		return nil, fmt.Errorf("post-run actions did not complete within %s (%d pending)", timeout, len(pending))
+	}
+}
+
+// actionName gets the name of the function for a plugin action, for logging.
+func actionName(action pluginAction) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(action).Pointer())
+	if fn == nil {
+		return fmt.Sprintf("%v", action)
+	}
+	return fn.Name()
+}
+
 // execDeviceSetup executes the device setup actions for the plugin.
 func execDeviceSetup(plugin *Plugin) *errors.MultiError {
+	var devices []*Device
+	for _, d := range ctx.getDevices() {
+		devices = append(devices, d)
+	}
+	return execDeviceSetupFor(plugin, devices)
+}
+
+// execDeviceSetupFor executes the device setup actions for the given devices,
+// which need not be registered with the plugin yet, e.g. devices which are
+// being added to or reloaded in the running plugin.
+func execDeviceSetupFor(plugin *Plugin, list []*Device) *errors.MultiError {
 	var multiErr = errors.NewMultiError("device setup actions")
 
 	log.Debugf("[sdk] executing %d device setup action(s)", len(ctx.deviceSetupActions))
 	if len(ctx.deviceSetupActions) > 0 {
 		for filter, acts := range ctx.deviceSetupActions {
-			devices, err := filterDevices(filter)
+			devices, err := filterDeviceList(filter, list)
 			if err != nil {
 				log.Errorf("[sdk] failed to filter devices for setup actions: %v", err)
 				multiErr.Add(err)
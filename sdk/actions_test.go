@@ -3,7 +3,10 @@ package sdk
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -249,6 +252,85 @@ func Test_execPostRun5(t *testing.T) {
 	assert.Equal(t, 0, c)
 }
 
+// Test_execPostRunWithTimeout tests running post-run actions with a timeout, when
+// they complete before the timeout.
+func Test_execPostRunWithTimeout(t *testing.T) {
+	defer resetContext()
+
+	c := 0
+	action := func(_ *Plugin) error {
+		c++
+		return nil
+	}
+
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(action, action)
+
+	multiErr, err := execPostRunWithTimeout(plugin, time.Second)
+	assert.NoError(t, err)
+	assert.NoError(t, multiErr.Err())
+	assert.Equal(t, 2, c)
+}
+
+// Test_execPostRunWithTimeout2 tests running post-run actions with a timeout, when
+// an action blocks past the timeout.
+func Test_execPostRunWithTimeout2(t *testing.T) {
+	defer resetContext()
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	c := 0
+	action := func(_ *Plugin) error {
+		c++
+		return nil
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+	blocking := func(_ *Plugin) error {
+		<-block
+		return nil
+	}
+
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(action, blocking, action)
+
+	start := time.Now()
+	multiErr, err := execPostRunWithTimeout(plugin, 50*time.Millisecond)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "2 pending")
+	assert.Nil(t, multiErr)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, 1, c)
+
+	// The pending actions should be logged.
+	var entry *log.Entry
+	for _, e := range hook.AllEntries() {
+		if e.Message == "[sdk] timed out waiting for post-run actions to complete" {
+			entry = e
+		}
+	}
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, 2, len(entry.Data["pending"].([]string)))
+	}
+}
+
+// Test_execPostRunWithTimeout3 tests running post-run actions with no timeout.
+func Test_execPostRunWithTimeout3(t *testing.T) {
+	defer resetContext()
+
+	actionErr := func(_ *Plugin) error {
+		return fmt.Errorf("error")
+	}
+
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(actionErr)
+
+	multiErr, err := execPostRunWithTimeout(plugin, 0)
+	assert.NoError(t, err)
+	assert.Error(t, multiErr.Err())
+}
+
 // Test_execDeviceSetup tests running device setup actions, when none are specified.
 func Test_execDeviceSetup(t *testing.T) {
 	defer resetContext()
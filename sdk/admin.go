@@ -0,0 +1,266 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/health"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newAdminServer creates the HTTP server for the plugin's admin interface on
+// the configured address.
+func newAdminServer(plugin *Plugin, settings *AdminSettings) *http.Server {
+	return &http.Server{
+		Addr:    settings.Address,
+		Handler: newAdminHandler(plugin),
+	}
+}
+
+// runAdminServer serves the plugin's HTTP admin interface. The admin server is
+// separate from the gRPC server, so if it fails, the error is logged and the
+// plugin continues to run. This blocks until the server is stopped, so it should
+// be run in a goroutine.
+func runAdminServer(server *http.Server) {
+	log.WithField("address", server.Addr).Info("[admin] starting admin server")
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.WithField("error", err).Error("[admin] admin server stopped")
+	}
+}
+
+// stopAdminServer stops the admin server gracefully. The server stops accepting
+// new connections, and waits up to the given timeout for in-flight requests to
+// complete before it is closed immediately. A timeout of 0 closes the server
+// immediately. If the admin server is not running, this does nothing.
+func stopAdminServer(server *http.Server, timeout time.Duration) {
+	if server == nil {
+		return
+	}
+	if timeout <= 0 {
+		server.Close() // nolint: errcheck
+		return
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(c); err != nil {
+		log.WithField("error", err).Warn("[admin] timed out waiting for in-flight requests, closing server")
+		server.Close() // nolint: errcheck
+		return
+	}
+	log.Info("[admin] admin server stopped")
+}
+
+// newAdminHandler creates the http.Handler for the plugin's admin interface.
+// It exposes the following endpoints, all of which respond with JSON:
+//
+//	GET  /devices             list the plugin's devices
+//	GET  /outputs             list the plugin's output types
+//	POST /devices/{id}/read   read a device immediately
+//	POST /reads/pause         pause the read loop
+//	POST /reads/resume        resume the read loop
+//	GET  /health              get the plugin health
+func newAdminHandler(plugin *Plugin) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/devices", adminMethod(http.MethodGet, adminListDevices))
+	mux.HandleFunc("/devices/", adminMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		adminReadDevice(plugin, w, r)
+	}))
+	mux.HandleFunc("/outputs", adminMethod(http.MethodGet, adminListOutputTypes))
+	mux.HandleFunc("/reads/pause", adminMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		plugin.PauseReads()
+		writeAdminJSON(w, http.StatusOK, adminReadsState{Paused: plugin.ReadsPaused()})
+	}))
+	mux.HandleFunc("/reads/resume", adminMethod(http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+		plugin.ResumeReads()
+		writeAdminJSON(w, http.StatusOK, adminReadsState{Paused: plugin.ReadsPaused()})
+	}))
+	mux.HandleFunc("/health", adminMethod(http.MethodGet, adminHealth))
+	return mux
+}
+
+// adminDevice is the admin API representation of a device.
+type adminDevice struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Info     string `json:"info"`
+	Readable bool   `json:"readable"`
+	Writable bool   `json:"writable"`
+	Active   bool   `json:"active"`
+}
+
+// adminOutputType is the admin API representation of an output type. Its bounds
+// are the range of values expected for its readings (see OutputType.Bounds).
+type adminOutputType struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Unit      adminUnit `json:"unit"`
+	Precision int       `json:"precision"`
+	Min       *float64  `json:"min"`
+	Max       *float64  `json:"max"`
+}
+
+// adminReading is the admin API representation of a reading.
+type adminReading struct {
+	Timestamp string      `json:"timestamp"`
+	Type      string      `json:"type"`
+	Info      string      `json:"info"`
+	Unit      adminUnit   `json:"unit"`
+	Value     interface{} `json:"value"`
+}
+
+// adminUnit is the admin API representation of a reading unit.
+type adminUnit struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// adminReadsState is the admin API representation of the read loop state.
+type adminReadsState struct {
+	Paused bool `json:"paused"`
+}
+
+// adminHealthCheck is the admin API representation of a health check status.
+type adminHealthCheck struct {
+	Name      string `json:"name"`
+	Ok        bool   `json:"ok"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+}
+
+// adminPluginHealth is the admin API representation of the plugin health.
+type adminPluginHealth struct {
+	Timestamp string             `json:"timestamp"`
+	Status    string             `json:"status"`
+	Checks    []adminHealthCheck `json:"checks"`
+}
+
+// adminError is the admin API representation of an error.
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// adminListDevices responds with the plugin's devices, sorted by ID.
+func adminListDevices(w http.ResponseWriter, r *http.Request) {
+	devices := []adminDevice{}
+	for id, device := range ctx.getDevices() {
+		devices = append(devices, adminDevice{
+			ID:       id,
+			Kind:     device.Kind,
+			Info:     device.Info,
+			Readable: device.IsReadable(),
+			Writable: device.IsWritable(),
+			Active:   device.IsActive(),
+		})
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID < devices[j].ID
+	})
+	writeAdminJSON(w, http.StatusOK, devices)
+}
+
+// adminListOutputTypes responds with the plugin's output types, sorted by name.
+func adminListOutputTypes(w http.ResponseWriter, r *http.Request) {
+	outputTypes := []adminOutputType{}
+	for _, outputType := range ctx.outputTypes {
+		unit := outputType.EffectiveUnit()
+		min, max := outputType.Bounds()
+		outputTypes = append(outputTypes, adminOutputType{
+			Name:      outputType.Name,
+			Type:      outputType.Type(),
+			Unit:      adminUnit{Name: unit.Name, Symbol: unit.Symbol},
+			Precision: outputType.Precision,
+			Min:       min,
+			Max:       max,
+		})
+	}
+	sort.Slice(outputTypes, func(i, j int) bool {
+		return outputTypes[i].Name < outputTypes[j].Name
+	})
+	writeAdminJSON(w, http.StatusOK, outputTypes)
+}
+
+// adminReadDevice reads the device identified in the request path and responds
+// with its readings.
+func adminReadDevice(plugin *Plugin, w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/devices/")
+	if !strings.HasSuffix(id, "/read") {
+		http.NotFound(w, r)
+		return
+	}
+	id = strings.TrimSuffix(id, "/read")
+
+	readings, err := plugin.ReadDevice(id)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if status.Code(err) == codes.NotFound {
+			code = http.StatusNotFound
+		}
+		writeAdminJSON(w, code, adminError{Error: err.Error()})
+		return
+	}
+
+	device := ctx.getDevice(id)
+	results := []adminReading{}
+	for _, reading := range readings {
+		encoded := reading.encodeFor(device)
+		results = append(results, adminReading{
+			Timestamp: reading.Timestamp,
+			Type:      encoded.Type,
+			Info:      reading.Info,
+			Unit:      adminUnit{Name: reading.Unit.Name, Symbol: reading.Unit.Symbol},
+			Value:     reading.Value,
+		})
+	}
+	writeAdminJSON(w, http.StatusOK, results)
+}
+
+// adminHealth responds with the current plugin health.
+func adminHealth(w http.ResponseWriter, r *http.Request) {
+	statuses := health.GetStatus()
+
+	checks := []adminHealthCheck{}
+	for _, s := range statuses {
+		checks = append(checks, adminHealthCheck{
+			Name:      s.Name,
+			Ok:        s.Ok,
+			Message:   s.Message,
+			Timestamp: s.Timestamp,
+			Type:      s.Type,
+		})
+	}
+	writeAdminJSON(w, http.StatusOK, adminPluginHealth{
+		Timestamp: GetCurrentTime(),
+		Status:    pluginHealthStatus(statuses).String(),
+		Checks:    checks,
+	})
+}
+
+// adminMethod wraps an admin handler so that it only responds to requests
+// with the given HTTP method.
+func adminMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAdminJSON(w, http.StatusMethodNotAllowed, adminError{Error: "method not allowed"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// writeAdminJSON writes the given value to the response as JSON.
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithField("error", err).Error("[admin] failed to write response")
+	}
+}
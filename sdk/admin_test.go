@@ -0,0 +1,348 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"github.com/vapor-ware/synse-sdk/sdk/health"
+)
+
+// setupAdminTest is a test helper that registers a readable device and a
+// write-only device, and returns a test server for the admin interface.
+func setupAdminTest() *httptest.Server {
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{Enabled: true},
+			Cache: &CacheSettings{},
+		},
+	}
+
+	output := &Output{OutputType: OutputType{Name: "temperature", Unit: Unit{Name: "celsius", Symbol: "C"}}}
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Kind:     "temperature",
+		Info:     "temp 1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs:  []*Output{output},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				reading, err := NewReading(output, 21)
+				if err != nil {
+					return nil, err
+				}
+				return []*Reading{reading}, nil
+			},
+		},
+	}
+	ctx.devices["rack-board-2"] = &Device{
+		id:       "2",
+		Kind:     "led",
+		Info:     "led 1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Write: func(device *Device, data *WriteData) error { return nil },
+		},
+	}
+
+	return httptest.NewServer(newAdminHandler(NewPlugin()))
+}
+
+// resetAdminTest resets the state set up by setupAdminTest.
+func resetAdminTest(server *httptest.Server) {
+	server.Close()
+	DataManager = newDataManager()
+	resetContext()
+	Config.reset()
+}
+
+// TestAdmin_ListDevices tests listing devices via the admin interface.
+func TestAdmin_ListDevices(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	resp, err := http.Get(server.URL + "/devices")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var devices []adminDevice
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&devices))
+	assert.Equal(t, []adminDevice{
+		{ID: "rack-board-1", Kind: "temperature", Info: "temp 1", Readable: true, Active: true},
+		{ID: "rack-board-2", Kind: "led", Info: "led 1", Writable: true, Active: true},
+	}, devices)
+}
+
+// TestAdmin_ListOutputTypes tests listing output types, with their bounds, via the
+// admin interface.
+func TestAdmin_ListOutputTypes(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	min, max := 0.0, 100.0
+	validMax := 80.0
+	ctx.outputTypes["humidity"] = &OutputType{
+		Name:      "humidity",
+		Unit:      Unit{Name: "percent", Symbol: "%"},
+		ValidMax:  &validMax,
+		Precision: 1,
+		Transforms: []*Transform{
+			{Factor: "0.1"},
+			{Clamp: &ClampRange{Min: &min, Max: &max}},
+		},
+	}
+	ctx.outputTypes["vaporio.status"] = &OutputType{Name: "vaporio.status"}
+
+	resp, err := http.Get(server.URL + "/outputs")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var outputTypes []adminOutputType
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&outputTypes))
+	assert.Equal(t, []adminOutputType{
+		{Name: "humidity", Type: "humidity", Unit: adminUnit{Name: "percent", Symbol: "%"}, Precision: 1, Min: &min, Max: &validMax},
+		{Name: "vaporio.status", Type: "status"},
+	}, outputTypes)
+}
+
+// TestAdmin_ReadDevice tests forcing a device read via the admin interface.
+func TestAdmin_ReadDevice(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	resp, err := http.Post(server.URL+"/devices/rack-board-1/read", "", nil)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var readings []adminReading
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&readings))
+	assert.Len(t, readings, 1)
+	assert.Equal(t, "temperature", readings[0].Type)
+	assert.Equal(t, "C", readings[0].Unit.Symbol)
+	assert.Equal(t, float64(21), readings[0].Value)
+	assert.NotEmpty(t, readings[0].Timestamp)
+
+	// The forced read should update the readings state.
+	assert.Len(t, DataManager.getReadings("rack-board-1"), 1)
+}
+
+// TestAdmin_ReadDevice_Errors tests forcing a device read via the admin
+// interface when the device can not be read.
+func TestAdmin_ReadDevice_Errors(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	var testTable = []struct {
+		desc string
+		path string
+		code int
+	}{
+		{"unknown device", "/devices/rack-board-3/read", http.StatusNotFound},
+		{"device not readable", "/devices/rack-board-2/read", http.StatusInternalServerError},
+		{"unknown action", "/devices/rack-board-1/foo", http.StatusNotFound},
+	}
+
+	for _, testCase := range testTable {
+		resp, err := http.Post(server.URL+testCase.path, "", nil)
+		assert.NoError(t, err, testCase.desc)
+		resp.Body.Close()
+		assert.Equal(t, testCase.code, resp.StatusCode, testCase.desc)
+	}
+}
+
+// TestAdmin_PauseResumeReads tests pausing and resuming reads via the admin interface.
+func TestAdmin_PauseResumeReads(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	for _, testCase := range []struct {
+		path   string
+		paused bool
+	}{
+		{"/reads/pause", true},
+		{"/reads/pause", true},
+		{"/reads/resume", false},
+	} {
+		resp, err := http.Post(server.URL+testCase.path, "", nil)
+		assert.NoError(t, err, testCase.path)
+
+		var state adminReadsState
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, testCase.path)
+		assert.Equal(t, testCase.paused, state.Paused, testCase.path)
+		assert.Equal(t, testCase.paused, DataManager.readsArePaused(), testCase.path)
+	}
+}
+
+// TestAdmin_Health tests getting the plugin health via the admin interface.
+func TestAdmin_Health(t *testing.T) {
+	server := setupAdminTest()
+	defer func() {
+		health.DefaultCatalog = health.NewCatalog()
+		resetAdminTest(server)
+	}()
+
+	health.Register("foo", health.NewChecker("foo"))
+	checker := health.NewChecker("bar")
+	checker.Update(fmt.Errorf("err"))
+	health.Register("bar", checker)
+
+	resp, err := http.Get(server.URL + "/health")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var status adminPluginHealth
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.NotEmpty(t, status.Timestamp)
+	assert.Equal(t, "PARTIALLY_DEGRADED", status.Status)
+	assert.Len(t, status.Checks, 2)
+}
+
+// TestAdmin_MethodNotAllowed tests that the admin endpoints reject requests
+// with the wrong HTTP method.
+func TestAdmin_MethodNotAllowed(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	for _, path := range []string{"/devices/rack-board-1/read", "/reads/pause", "/reads/resume"} {
+		resp, err := http.Get(server.URL + path)
+		assert.NoError(t, err, path)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode, path)
+		assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"), path)
+	}
+
+	for _, path := range []string{"/devices", "/outputs"} {
+		resp, err := http.Post(server.URL+path, "", nil)
+		assert.NoError(t, err, path)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode, path)
+	}
+}
+
+// TestPlugin_ReadDevice tests reading a device on demand.
+func TestPlugin_ReadDevice(t *testing.T) {
+	server := setupAdminTest()
+	defer resetAdminTest(server)
+
+	plugin := NewPlugin()
+	plugin.PauseReads()
+	assert.True(t, plugin.ReadsPaused())
+
+	// Devices can be read while reads are paused.
+	readings, err := plugin.ReadDevice("rack-board-1")
+	assert.NoError(t, err)
+	assert.Len(t, readings, 1)
+	assert.Equal(t, 21, readings[0].Value)
+
+	_, err = plugin.ReadDevice("rack-board-2")
+	assert.Error(t, err)
+
+	plugin.ResumeReads()
+	assert.False(t, plugin.ReadsPaused())
+}
+
+// TestPlugin_shutdownAdminServer tests that shutting down the plugin stops the
+// admin server.
+func TestPlugin_shutdownAdminServer(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	Config.Plugin = &PluginConfig{
+		Network:  &NetworkSettings{DrainTimeout: "1s"},
+		Settings: &PluginSettings{ShutdownTimeout: "1s"},
+	}
+
+	plugin := NewPlugin()
+	plugin.server = newServer(networkTypeTCP, "localhost:5001")
+	plugin.admin = newAdminServer(plugin, &AdminSettings{Address: address})
+
+	stopped := make(chan struct{})
+	go func() {
+		runAdminServer(plugin.admin)
+		close(stopped)
+	}()
+
+	// Wait for the admin server to come up.
+	url := fmt.Sprintf("http://%s/health", address)
+	for i := 0; ; i++ {
+		resp, err := http.Get(url)
+		if err == nil {
+			assert.NoError(t, resp.Body.Close())
+			break
+		}
+		if i == 50 {
+			t.Fatalf("admin server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, 0, plugin.shutdown(os.Interrupt))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("admin server was not stopped")
+	}
+	_, err = http.Get(url)
+	assert.Error(t, err)
+}
+
+// Test stopping the admin server when it is not running.
+func Test_stopAdminServer_NotRunning(t *testing.T) {
+	stopAdminServer(nil, time.Second)
+}
+
+// TestAdminSettings_Validate tests validating AdminSettings.
+func TestAdminSettings_Validate(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		errCount int
+		settings AdminSettings
+	}{
+		{
+			desc:     "valid address",
+			settings: AdminSettings{Address: "localhost:5002"},
+		},
+		{
+			desc:     "valid address, all interfaces",
+			settings: AdminSettings{Address: ":5002"},
+		},
+		{
+			desc:     "no address",
+			errCount: 1,
+			settings: AdminSettings{},
+		},
+		{
+			desc:     "bad address",
+			errCount: 1,
+			settings: AdminSettings{Address: "localhost"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.settings.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
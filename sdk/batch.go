@@ -0,0 +1,271 @@
+package sdk
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// defaultBatchBuffer is the size of the buffer for readings waiting to be
+// batched, used when the read buffer size is not configured.
+const defaultBatchBuffer = 100
+
+// batchedReading is a reading of a device, as it is sent in a readingBatch.
+type batchedReading struct {
+	Rack    string         `protobuf:"bytes,1,opt,name=rack,proto3" json:"rack,omitempty"`
+	Board   string         `protobuf:"bytes,2,opt,name=board,proto3" json:"board,omitempty"`
+	Device  string         `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	Reading *synse.Reading `protobuf:"bytes,4,opt,name=reading,proto3" json:"reading,omitempty"`
+
+	// Context is the context of the reading. It is sent alongside the reading,
+	// since the synse.Reading message has no context.
+	Context map[string]string `protobuf:"bytes,5,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *batchedReading) Reset()         { *m = batchedReading{} }
+func (m *batchedReading) String() string { return proto.CompactTextString(m) }
+func (*batchedReading) ProtoMessage()    {}
+
+// readingBatch is a batch of readings, as it is sent in the response stream of
+// the synse.ReadingBatches service's `Stream` RPC method. It is described by
+// the ReadingBatch message in proto/batch.proto.
+type readingBatch struct {
+	Readings []*batchedReading `protobuf:"bytes,1,rep,name=readings,proto3" json:"readings,omitempty"`
+}
+
+func (m *readingBatch) Reset()         { *m = readingBatch{} }
+func (m *readingBatch) String() string { return proto.CompactTextString(m) }
+func (*readingBatch) ProtoMessage()    {}
+
+// readingBatcher groups the readings gathered by the plugin into batches. A
+// batch is sent once it is full, or once its first reading has waited for the
+// batch interval, whichever comes first.
+type readingBatcher struct {
+	size     int
+	interval time.Duration
+	filter   *synse.DeviceFilter
+	send     func(*readingBatch) error
+
+	pending []*batchedReading
+}
+
+// newReadingBatcher creates a readingBatcher which sends batches of the readings
+// for the devices matching the filter with the given send function, batched
+// according to the plugin's read settings. If batching is not configured, each
+// reading is sent in a batch of its own as soon as it is gathered.
+func newReadingBatcher(filter *synse.DeviceFilter, send func(*readingBatch) error) *readingBatcher {
+	batcher := &readingBatcher{size: 1, filter: filter, send: send}
+	if Config.Plugin == nil || Config.Plugin.Settings == nil || Config.Plugin.Settings.Read == nil {
+		return batcher
+	}
+
+	settings := Config.Plugin.Settings.Read
+	if settings.BatchSize > 0 {
+		batcher.size = settings.BatchSize
+	}
+	if interval, err := settings.GetBatchInterval(); err == nil {
+		batcher.interval = interval
+	}
+	return batcher
+}
+
+// run batches the readings received on the given channel until the done channel
+// is closed, or until a batch fails to send. Readings still waiting in a batch
+// when the done channel is closed are dropped.
+func (batcher *readingBatcher) run(done <-chan struct{}, readings <-chan *ReadContext) error {
+	var (
+		timer   *time.Timer
+		timeout <-chan time.Time
+	)
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		timer, timeout = nil, nil
+	}
+	defer stopTimer()
+
+	for {
+		select {
+		case <-done:
+			return nil
+
+		case <-timeout:
+			timer, timeout = nil, nil
+			if err := batcher.flush(); err != nil {
+				return err
+			}
+
+		case readCtx := <-readings:
+			if !batcher.matches(readCtx) {
+				continue
+			}
+			device := ctx.getDevice(readCtx.ID())
+			for _, reading := range readCtx.Reading {
+				if len(batcher.pending) == 0 && batcher.interval > 0 {
+					timer = time.NewTimer(batcher.interval)
+					timeout = timer.C
+				}
+				batcher.pending = append(batcher.pending, &batchedReading{
+					Rack:    readCtx.Rack,
+					Board:   readCtx.Board,
+					Device:  readCtx.Device,
+					Reading: reading.encodeFor(device),
+					Context: reading.Context,
+				})
+				if len(batcher.pending) >= batcher.size {
+					stopTimer()
+					if err := batcher.flush(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// matches checks whether the readings are for a device matched by the batcher's
+// device filter. Fields which are not set in the filter match any device.
+func (batcher *readingBatcher) matches(readCtx *ReadContext) bool {
+	if batcher.filter == nil {
+		return true
+	}
+	if batcher.filter.Rack != "" && batcher.filter.Rack != readCtx.Rack {
+		return false
+	}
+	if batcher.filter.Board != "" && batcher.filter.Board != readCtx.Board {
+		return false
+	}
+	if batcher.filter.Device != "" && batcher.filter.Device != readCtx.Device {
+		return false
+	}
+	return true
+}
+
+// flush sends the readings in the current batch, in the order they were
+// gathered, as a single readingBatch. If the batch is empty, nothing is sent.
+func (batcher *readingBatcher) flush() error {
+	if len(batcher.pending) == 0 {
+		return nil
+	}
+	batch := &readingBatch{Readings: batcher.pending}
+	batcher.pending = nil
+	return batcher.send(batch)
+}
+
+// batchBufferSize gets the size of the buffer for readings waiting to be batched.
+// This is the configured read buffer size, so that a batcher can take the readings
+// of a full read channel.
+func batchBufferSize() int {
+	if Config.Plugin != nil && Config.Plugin.Settings != nil && Config.Plugin.Settings.Read != nil {
+		if Config.Plugin.Settings.Read.Buffer > 0 {
+			return Config.Plugin.Settings.Read.Buffer
+		}
+	}
+	return defaultBatchBuffer
+}
+
+// subscribe registers a channel to which readings are published as they are
+// gathered, once they have been added to the current reading state. Readings
+// are dropped for a subscriber whose channel is full, so that a slow subscriber
+// does not hold up the plugin. The returned function unsubscribes the channel.
+func (manager *dataManager) subscribe(buffer int) (<-chan *ReadContext, func()) {
+	readings := make(chan *ReadContext, buffer)
+
+	manager.subscribersLock.Lock()
+	manager.subscribers[readings] = struct{}{}
+	manager.subscribersLock.Unlock()
+
+	return readings, func() {
+		manager.subscribersLock.Lock()
+		delete(manager.subscribers, readings)
+		manager.subscribersLock.Unlock()
+	}
+}
+
+// publishReadings publishes the readings to all subscribers.
+func (manager *dataManager) publishReadings(readCtx *ReadContext) {
+	manager.subscribersLock.Lock()
+	defer manager.subscribersLock.Unlock()
+
+	for readings := range manager.subscribers {
+		select {
+		case readings <- readCtx:
+		default:
+			repeatedLog.Warnf("[data manager] reading subscriber is full, dropping readings for %v", readCtx.ID())
+		}
+	}
+}
+
+// readingBatchesServer is the server API for the synse.ReadingBatches service.
+type readingBatchesServer interface {
+	StreamBatches(*synse.DeviceFilter, readingBatchesStream) error
+}
+
+// readingBatchesStream is the server side of the response stream for the
+// synse.ReadingBatches service's `Stream` RPC method.
+type readingBatchesStream interface {
+	Send(*readingBatch) error
+	grpc.ServerStream
+}
+
+// readingBatchesStreamServer implements readingBatchesStream for a grpc.ServerStream.
+type readingBatchesStreamServer struct {
+	grpc.ServerStream
+}
+
+// Send sends a batch of readings on the stream.
+func (stream *readingBatchesStreamServer) Send(batch *readingBatch) error {
+	return stream.ServerStream.SendMsg(batch)
+}
+
+// StreamBatches is the handler for the synse.ReadingBatches service's `Stream`
+// RPC method. It streams the readings gathered by the plugin for the devices
+// matching the filter, in batches (see ReadSettings.BatchSize), until the client
+// ends the stream. The filter fields which are not set match any device, so an
+// empty filter streams the readings for all devices. Readings are streamed as
+// they are gathered, including by a stateless plugin, which does not retain them.
+func (server *server) StreamBatches(request *synse.DeviceFilter, stream readingBatchesStream) error {
+	log.WithField("request", request).Debug("[grpc] stream batches rpc request")
+	if request.GetRack() == "" && request.GetBoard() != "" {
+		return errors.InvalidArgumentErr("filter specifies board with no rack - must specify rack as well")
+	}
+	if request.GetBoard() == "" && request.GetDevice() != "" {
+		return errors.InvalidArgumentErr("filter specifies device with no board - must specify board as well")
+	}
+
+	readings, unsubscribe := DataManager.subscribe(batchBufferSize())
+	defer unsubscribe()
+
+	return newReadingBatcher(request, stream.Send).run(stream.Context().Done(), readings)
+}
+
+// streamBatchesHandler decodes and dispatches requests for the `Stream` RPC method.
+func streamBatchesHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(synse.DeviceFilter)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(readingBatchesServer).StreamBatches(in, &readingBatchesStreamServer{stream})
+}
+
+// readingBatchesServiceDesc describes the synse.ReadingBatches gRPC service, which
+// streams the readings gathered by the plugin in batches bounded by size and time,
+// trading latency for throughput. Its messages are defined in proto/batch.proto.
+var readingBatchesServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.ReadingBatches",
+	HandlerType: (*readingBatchesServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       streamBatchesHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/batch.proto",
+}
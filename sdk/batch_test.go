@@ -0,0 +1,282 @@
+package sdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// setBatchSettings is a test helper which sets the read batch settings in the plugin config.
+func setBatchSettings(size int, interval string) {
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{BatchSize: size, BatchInterval: interval},
+		},
+	}
+}
+
+// runBatcher is a test helper which runs a readingBatcher for the given filter in
+// a goroutine. It returns the channel the batcher reads readings from, the channel
+// it sends batches to, and a function which stops the batcher.
+func runBatcher(t *testing.T, filter *synse.DeviceFilter) (chan *ReadContext, chan *readingBatch, func()) {
+	readings := make(chan *ReadContext, 10)
+	batches := make(chan *readingBatch, 10)
+	done := make(chan struct{})
+	stopped := make(chan error)
+
+	batcher := newReadingBatcher(filter, func(batch *readingBatch) error {
+		batches <- batch
+		return nil
+	})
+	go func() {
+		stopped <- batcher.run(done, readings)
+	}()
+	return readings, batches, func() {
+		close(done)
+		assert.NoError(t, <-stopped)
+	}
+}
+
+// newBatchReadCtx is a test helper which creates a ReadContext for the rack-board-device
+// device holding readings of the given types.
+func newBatchReadCtx(types ...string) *ReadContext {
+	readCtx := &ReadContext{Rack: "rack", Board: "board", Device: "device"}
+	for _, readingType := range types {
+		readCtx.Reading = append(readCtx.Reading, &Reading{Type: readingType, Value: 1})
+	}
+	return readCtx
+}
+
+// batchTypes is a test helper which gets the reading types in a batch, in order.
+func batchTypes(batch *readingBatch) []string {
+	var types []string
+	for _, reading := range batch.Readings {
+		types = append(types, reading.Reading.Type)
+	}
+	return types
+}
+
+// receiveBatch is a test helper which waits for a batch to be sent.
+func receiveBatch(t *testing.T, batches chan *readingBatch) *readingBatch {
+	select {
+	case batch := <-batches:
+		return batch
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for batch")
+		return nil
+	}
+}
+
+// Test_readingBatcher_NoBatching tests that each reading is sent in a batch of its
+// own when batching is not configured.
+func Test_readingBatcher_NoBatching(t *testing.T) {
+	readings, batches, stop := runBatcher(t, nil)
+	defer stop()
+
+	readings <- newBatchReadCtx("a", "b")
+	assert.Equal(t, []string{"a"}, batchTypes(receiveBatch(t, batches)))
+	assert.Equal(t, []string{"b"}, batchTypes(receiveBatch(t, batches)))
+}
+
+// Test_readingBatcher_FlushOnSize tests that a batch is sent once it is full.
+func Test_readingBatcher_FlushOnSize(t *testing.T) {
+	defer Config.reset()
+	setBatchSettings(3, "")
+	readings, batches, stop := runBatcher(t, nil)
+	defer stop()
+
+	readings <- newBatchReadCtx("0", "1")
+	readings <- newBatchReadCtx("2", "3", "4")
+	batch := receiveBatch(t, batches)
+	assert.Equal(t, []string{"0", "1", "2"}, batchTypes(batch))
+	assert.Equal(t, "rack", batch.Readings[0].Rack)
+	assert.Equal(t, "board", batch.Readings[0].Board)
+	assert.Equal(t, "device", batch.Readings[0].Device)
+
+	// Without a batch interval, the rest of the readings wait for the batch to fill.
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, batches)
+
+	readings <- newBatchReadCtx("5")
+	assert.Equal(t, []string{"3", "4", "5"}, batchTypes(receiveBatch(t, batches)))
+}
+
+// Test_readingBatcher_FlushOnTimeout tests that a batch is sent once its first
+// reading has waited for the batch interval, even if it is not full.
+func Test_readingBatcher_FlushOnTimeout(t *testing.T) {
+	defer Config.reset()
+	setBatchSettings(10, "100ms")
+	readings, batches, stop := runBatcher(t, nil)
+	defer stop()
+
+	start := time.Now()
+	readings <- newBatchReadCtx("a")
+	readings <- newBatchReadCtx("b")
+	assert.Equal(t, []string{"a", "b"}, batchTypes(receiveBatch(t, batches)))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+
+	// The interval starts again with the first reading of the next batch.
+	start = time.Now()
+	readings <- newBatchReadCtx("c")
+	assert.Equal(t, []string{"c"}, batchTypes(receiveBatch(t, batches)))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+// Test_readingBatcher_FlushOnSizeBeforeTimeout tests that a batch which fills
+// before the batch interval is sent when it is full.
+func Test_readingBatcher_FlushOnSizeBeforeTimeout(t *testing.T) {
+	defer Config.reset()
+	setBatchSettings(2, "1m")
+	readings, batches, stop := runBatcher(t, nil)
+	defer stop()
+
+	readings <- newBatchReadCtx("a", "b")
+	assert.Equal(t, []string{"a", "b"}, batchTypes(receiveBatch(t, batches)))
+}
+
+// Test_readingBatcher_Filter tests that only the readings for devices matching the
+// filter are batched.
+func Test_readingBatcher_Filter(t *testing.T) {
+	readings, batches, stop := runBatcher(t, &synse.DeviceFilter{Rack: "rack", Board: "other"})
+	defer stop()
+
+	readings <- newBatchReadCtx("a")
+	readings <- &ReadContext{Rack: "rack", Board: "other", Device: "device", Reading: []*Reading{{Type: "b"}}}
+	assert.Equal(t, []string{"b"}, batchTypes(receiveBatch(t, batches)))
+	assert.Empty(t, batches)
+}
+
+// Test_readingBatcher_SendError tests that the batcher stops when a batch fails to send.
+func Test_readingBatcher_SendError(t *testing.T) {
+	readings := make(chan *ReadContext, 1)
+	readings <- newBatchReadCtx("a")
+
+	batcher := newReadingBatcher(nil, func(*readingBatch) error {
+		return fmt.Errorf("grpc error")
+	})
+	assert.Error(t, batcher.run(make(chan struct{}), readings))
+}
+
+// Test_readingBatcher_Compression tests that the encoding of a compressed reading
+// is sent with the reading, so consumers can decompress it.
+func Test_readingBatcher_Compression(t *testing.T) {
+	readings, batches, stop := runBatcher(t, nil)
+	defer stop()
+
+	value := bytes.Repeat([]byte("frame data "), 100)
+	reading, err := NewReading(&Output{OutputType: OutputType{Name: "frame", Compression: "gzip"}}, value)
+	assert.NoError(t, err)
+	readings <- &ReadContext{Rack: "rack", Board: "board", Device: "device", Reading: []*Reading{reading}}
+
+	batched := receiveBatch(t, batches).Readings[0]
+	assert.Equal(t, "gzip", batched.Context[ContextKeyEncoding])
+
+	r, err := gzip.NewReader(bytes.NewReader(batched.Reading.GetBytesValue()))
+	assert.NoError(t, err)
+	decompressed, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decompressed)
+}
+
+// TestDataManager_subscribe tests that the readings added to the reading state are
+// published to the subscribers, and that readings are dropped for a full subscriber.
+func TestDataManager_subscribe(t *testing.T) {
+	defer Config.reset()
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{Cache: &CacheSettings{}},
+	}
+
+	d := newDataManager()
+	readings, unsubscribe := d.subscribe(1)
+
+	d.updateReadings(newBatchReadCtx("a"))
+	d.updateReadings(newBatchReadCtx("b"))
+	assert.Equal(t, 1, len(readings))
+	assert.Equal(t, "a", (<-readings).Reading[0].Type)
+
+	unsubscribe()
+	d.updateReadings(newBatchReadCtx("c"))
+	assert.Empty(t, readings)
+	assert.Empty(t, d.subscribers)
+}
+
+// TestServer_StreamBatches tests streaming batches of readings via the
+// synse.ReadingBatches service.
+func TestServer_StreamBatches(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+	setBatchSettings(2, "")
+	Config.Plugin.Settings.Cache = &CacheSettings{}
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+	Config.Plugin.Network = &NetworkSettings{Type: "tcp", Address: address}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := conn.NewStream(c, &readingBatchesServiceDesc.Streams[0], "/synse.ReadingBatches/Stream", grpc.FailFast(false))
+	assert.NoError(t, err)
+	assert.NoError(t, stream.SendMsg(&synse.DeviceFilter{Rack: "rack"}))
+	assert.NoError(t, stream.CloseSend())
+
+	// The stream only gets the readings gathered once it has subscribed, so keep
+	// gathering readings until a batch is received.
+	received := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-received:
+				return
+			case <-time.After(10 * time.Millisecond):
+				readCtx := newBatchReadCtx("a", "b")
+				readCtx.Reading[0].Context = map[string]string{ContextKeyEpoch: "1"}
+				DataManager.updateReadings(readCtx)
+			}
+		}
+	}()
+
+	batch := &readingBatch{}
+	err = stream.RecvMsg(batch)
+	close(received)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, batchTypes(batch))
+	assert.Equal(t, "device", batch.Readings[0].Device)
+	assert.Equal(t, int64(1), batch.Readings[0].Reading.GetInt64Value())
+
+	// The reading context is sent with the readings.
+	assert.Equal(t, map[string]string{ContextKeyEpoch: "1"}, batch.Readings[0].Context)
+	assert.Empty(t, batch.Readings[1].Context)
+}
+
+// TestServer_StreamBatches_BadFilter tests streaming batches of readings with an
+// invalid device filter.
+func TestServer_StreamBatches_BadFilter(t *testing.T) {
+	s := &server{}
+	err := s.StreamBatches(&synse.DeviceFilter{Board: "board"}, nil)
+	assert.Error(t, err)
+
+	err = s.StreamBatches(&synse.DeviceFilter{Rack: "rack", Device: "device"}, nil)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package sdk
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -11,6 +13,17 @@ import (
 // plugin, if it is enabled in the plugin configuration.
 var readingsCache *cache.Cache
 
+// deviceReadingsCaches are the caches that store the readings for devices which
+// override the plugin's cache retention settings, keyed by device ID. Readings
+// for these devices are stored here instead of in the readingsCache. This map
+// is only accessed within the cacheLock.
+var deviceReadingsCaches map[string]*cache.Cache
+
+// cacheLock is a lock around the read-modify-write of cached ReadContexts. The
+// readings cache itself is safe for concurrent use, but the cacheContexts stored
+// in it are not, so any access to those contexts must happen within this lock.
+var cacheLock sync.Mutex
+
 // cacheContexts is how ReadContexts are stored in the readings cache. Since
 // we may want to filter readings based on the timestamp they were added, we
 // want to store the ReadContexts against a timestamp key. In order to support
@@ -20,6 +33,11 @@ type cacheContexts []*ReadContext
 // setupReadingsCache sets up a cache that will be used to store readings,
 // if it is enabled in the plugin configuration.
 func setupReadingsCache() {
+	if readingsStateless() {
+		log.Debug("[cache] plugin is stateless - readings cache disabled")
+		return
+	}
+
 	cacheSettings := Config.Plugin.Settings.Cache
 	if cacheSettings.Enabled {
 		log.Debugf("[cache] readings cache is enabled")
@@ -34,18 +52,83 @@ func setupReadingsCache() {
 	}
 }
 
-// addReading adds a reading to the readings cache.
-func addReadingToCache(ctx *ReadContext) {
+// addReading adds a reading to the readings cache. If the device for the reading
+// overrides the plugin's cache retention settings, the reading is added to the
+// device's own cache instead.
+func addReadingToCache(readCtx *ReadContext) {
 	if Config.Plugin.Settings.Cache.Enabled {
+		cacheLock.Lock()
+		defer cacheLock.Unlock()
+
+		c := readingsCache
+		maxEntries := 0
+		if device := ctx.getDevice(readCtx.ID()); device != nil && device.Cache != nil {
+			c = getDeviceReadingsCache(device)
+			maxEntries = device.Cache.MaxEntries
+		}
+
 		now := GetCurrentTime()
-		item, exists := readingsCache.Get(now)
+		item, exists := c.Get(now)
 		if !exists {
-			newCtxs := cacheContexts([]*ReadContext{ctx})
-			readingsCache.Set(now, &newCtxs, cache.DefaultExpiration)
+			newCtxs := cacheContexts([]*ReadContext{readCtx})
+			c.Set(now, &newCtxs, cache.DefaultExpiration)
 		} else {
 			cached := item.(*cacheContexts)
-			*cached = append(*cached, ctx)
+			*cached = append(*cached, readCtx)
+		}
+
+		if maxEntries > 0 {
+			trimCache(c, maxEntries)
+		}
+	}
+}
+
+// getDeviceReadingsCache gets the readings cache for a device which overrides the
+// plugin's cache retention settings, creating it if it does not yet exist. If the
+// device does not override the TTL, the plugin's cache TTL is used. This should
+// only be called within the cacheLock.
+func getDeviceReadingsCache(device *Device) *cache.Cache {
+	if deviceReadingsCaches == nil {
+		deviceReadingsCaches = map[string]*cache.Cache{}
+	}
+
+	c, exists := deviceReadingsCaches[device.GUID()]
+	if !exists {
+		ttl := device.Cache.TTL
+		if ttl == 0 {
+			ttl = Config.Plugin.Settings.Cache.TTL
 		}
+		log.WithFields(log.Fields{
+			"device": device.GUID(),
+			"ttl":    ttl,
+		}).Info("[cache] creating new device readings cache")
+		c = cache.New(ttl, ttl*2)
+		deviceReadingsCaches[device.GUID()] = c
+	}
+	return c
+}
+
+// trimCache removes the oldest entries from the cache until it holds no more
+// than the given number of entries.
+func trimCache(c *cache.Cache, maxEntries int) {
+	items := c.Items()
+	if len(items) <= maxEntries {
+		return
+	}
+
+	// The cache keys are RFC3339Nano timestamps, so get the oldest entries
+	// by sorting the keys by time.
+	var keys []string
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ti, _ := ParseRFC3339Nano(keys[i])
+		tj, _ := ParseRFC3339Nano(keys[j])
+		return ti.Before(tj)
+	})
+	for _, key := range keys[:len(keys)-maxEntries] {
+		c.Delete(key)
 	}
 }
 
@@ -70,9 +153,13 @@ func getReadingsFromCache(start, end string, readings chan *ReadContext) {
 		log.Errorf("[cache] failed to parse end time: %v", err)
 	}
 
-	// If caching reads is disabled, just return all of the current
-	// tracked readings, if they fall within the specified time bound.
-	// Otherwise, collect the readings from the cache.
+	// If the plugin is stateless, there are no readings to return. If
+	// caching reads is disabled, just return all of the current tracked
+	// readings, if they fall within the specified time bound. Otherwise,
+	// collect the readings from the cache.
+	if readingsStateless() {
+		return
+	}
 	if Config.Plugin.Settings.Cache.Enabled {
 		getCachedReadings(startTime, endTime, readings)
 	} else {
@@ -80,10 +167,26 @@ func getReadingsFromCache(start, end string, readings chan *ReadContext) {
 	}
 }
 
-// getCachedReadings gets the readings from the read cache, filters them based
-// on the provided start and end bounds, and passes them to the provided channel.
+// getCachedReadings gets the readings from the read cache and any device readings
+// caches, filters them based on the provided start and end bounds, and passes them
+// to the provided channel.
 func getCachedReadings(start, end time.Time, readings chan *ReadContext) {
-	for ts, item := range readingsCache.Items() {
+	caches := []*cache.Cache{readingsCache}
+	cacheLock.Lock()
+	for _, c := range deviceReadingsCaches {
+		caches = append(caches, c)
+	}
+	cacheLock.Unlock()
+
+	for _, c := range caches {
+		getCachedReadingsFrom(c, start, end, readings)
+	}
+}
+
+// getCachedReadingsFrom gets the readings from the given cache, filters them based
+// on the provided start and end bounds, and passes them to the provided channel.
+func getCachedReadingsFrom(c *cache.Cache, start, end time.Time, readings chan *ReadContext) {
+	for ts, item := range c.Items() {
 		cachedTime, err := ParseRFC3339Nano(ts)
 		if err != nil {
 			// If we can't parse the timestamp from the cache, an error is logged
@@ -107,9 +210,15 @@ func getCachedReadings(start, end time.Time, readings chan *ReadContext) {
 			continue
 		}
 
+		// Copy the read contexts within the cache lock so they can be passed
+		// to the channel without blocking updates to the cache.
+		cacheLock.Lock()
+		ctxs := make(cacheContexts, len(*item.Object.(*cacheContexts)))
+		copy(ctxs, *item.Object.(*cacheContexts))
+		cacheLock.Unlock()
+
 		// Pass the read contexts to the channel
-		ctxs := item.Object.(*cacheContexts)
-		for _, ctx := range *ctxs {
+		for _, ctx := range ctxs {
 			readings <- ctx
 		}
 	}
@@ -121,8 +230,8 @@ func getCurrentReadings(readings chan *ReadContext) {
 	for deviceID, data := range DataManager.getAllReadings() {
 		// We have the device ID, but we will also want the provenance info
 		// (rack, board, device), so we will need to lookup the device by ID.
-		dev, ok := ctx.devices[deviceID]
-		if !ok {
+		dev := ctx.getDevice(deviceID)
+		if dev == nil {
 			log.WithField(
 				"id", deviceID,
 			).Error("[cache] found orphan reading (id does not match any known devices)")
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -410,3 +411,114 @@ func Test_getReadingsFromCache_invalidEnd(t *testing.T) {
 	}
 	assert.Equal(t, 5, len(results))
 }
+
+// Test that a device with a longer cache TTL than the plugin's cache TTL retains
+// its readings after the plugin's cache TTL has passed.
+func Test_addReadingToCache_DeviceTTL(t *testing.T) {
+	defer func() {
+		// reset plugin state
+		resetContext()
+		Config.reset()
+
+		// reset readings caches
+		readingsCache = nil
+		deviceReadingsCaches = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{
+				Enabled: true,
+				TTL:     20 * time.Millisecond,
+			},
+		},
+	}
+	setupReadingsCache()
+
+	ctx.devices["rack-board-critical"] = &Device{
+		id:       "critical",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Cache:    &DeviceCacheSettings{TTL: time.Minute},
+	}
+
+	addReadingToCache(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "critical",
+		Reading: []*Reading{{Type: "test", Value: 1}},
+	})
+	addReadingToCache(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "other",
+		Reading: []*Reading{{Type: "test", Value: 2}},
+	})
+	assert.Equal(t, 1, readingsCache.ItemCount())
+	assert.Equal(t, 1, deviceReadingsCaches["rack-board-critical"].ItemCount())
+
+	// Wait for the plugin's cache TTL to pass.
+	time.Sleep(50 * time.Millisecond)
+
+	c := make(chan *ReadContext, 5)
+	go getReadingsFromCache("", "", c)
+
+	var results []*ReadContext
+	for r := range c {
+		results = append(results, r)
+	}
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "critical", results[0].Device)
+}
+
+// Test that the oldest cached readings for a device are removed once the device's
+// max cache entries is exceeded.
+func Test_addReadingToCache_DeviceMaxEntries(t *testing.T) {
+	defer func() {
+		// reset plugin state
+		resetContext()
+		Config.reset()
+		clock = realClock{}
+
+		// reset readings caches
+		readingsCache = nil
+		deviceReadingsCaches = nil
+	}()
+	c := useFakeClock(t, time.Now())
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{
+				Enabled: true,
+				TTL:     time.Minute,
+			},
+		},
+	}
+	setupReadingsCache()
+
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Cache:    &DeviceCacheSettings{MaxEntries: 2},
+	}
+
+	for i := 1; i <= 3; i++ {
+		addReadingToCache(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Type: "test", Value: i}},
+		})
+		c.Advance(time.Second)
+	}
+	assert.Equal(t, 0, readingsCache.ItemCount())
+	assert.Equal(t, 2, deviceReadingsCaches["rack-board-device"].ItemCount())
+
+	readings := make(chan *ReadContext, 5)
+	go getReadingsFromCache("", "", readings)
+
+	var values []interface{}
+	for r := range readings {
+		values = append(values, r.Reading[0].Value)
+	}
+	assert.ElementsMatch(t, []interface{}{2, 3}, values)
+}
@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// Calibration is a piecewise-linear calibration table for a device output. It maps
+// raw values, as read from the device, to engineering values, for sensors whose
+// response is not linear enough to be calibrated by a single factor. Raw values
+// between two points of the table are linearly interpolated.
+type Calibration struct {
+	// Points are the (raw, engineering value) points of the calibration table.
+	// There must be at least two points, ordered by strictly increasing raw value.
+	Points []CalibrationPoint `yaml:"points,omitempty" addedIn:"1.3"`
+
+	// Extrapolate is the policy for raw values beyond the ends of the table. It
+	// can be one of:
+	//  - "linear": extend the first/last segment of the table
+	//  - "clamp": use the engineering value of the first/last point
+	//  - "error": fail to make the reading
+	// By default, values are extrapolated linearly.
+	Extrapolate string `yaml:"extrapolate,omitempty" addedIn:"1.3"`
+}
+
+// CalibrationPoint is a single point of a Calibration table.
+type CalibrationPoint struct {
+	// Raw is the raw value, as read from the device.
+	Raw float64 `yaml:"raw" addedIn:"1.3"`
+
+	// Value is the engineering value which the raw value is calibrated to.
+	Value float64 `yaml:"value" addedIn:"1.3"`
+}
+
+// Validate validates that the Calibration has no configuration errors.
+func (calibration Calibration) Validate(multiErr *errors.MultiError) {
+	source := multiErr.Context["source"]
+	if len(calibration.Points) < 2 {
+		multiErr.Add(errors.NewInvalidValueError(source, "calibration.points", "at least two points"))
+	}
+	for i := 1; i < len(calibration.Points); i++ {
+		if calibration.Points[i].Raw <= calibration.Points[i-1].Raw {
+			multiErr.Add(errors.NewInvalidValueError(
+				source,
+				"calibration.points",
+				"points ordered by strictly increasing (monotonic) raw values",
+			))
+			break
+		}
+	}
+
+	switch calibration.Extrapolate {
+	case "", calibrationExtrapolateLinear, calibrationExtrapolateClamp, calibrationExtrapolateError:
+	default:
+		multiErr.Add(errors.NewInvalidValueError(
+			source,
+			"calibration.extrapolate",
+			fmt.Sprintf("one of: %s, %s, %s", calibrationExtrapolateLinear, calibrationExtrapolateClamp, calibrationExtrapolateError),
+		))
+	}
+}
+
+// apply calibrates a raw value using the calibration table.
+func (calibration *Calibration) apply(value interface{}) (float64, error) {
+	raw, err := ConvertToFloat64(value)
+	if err != nil {
+		return 0, err
+	}
+	points := calibration.Points
+	if len(points) < 2 {
+		return 0, fmt.Errorf("calibration table needs at least two points, has %d", len(points))
+	}
+
+	first, last := points[0], points[len(points)-1]
+	if raw < first.Raw || raw > last.Raw {
+		switch calibration.Extrapolate {
+		case calibrationExtrapolateClamp:
+			if raw < first.Raw {
+				return first.Value, nil
+			}
+			return last.Value, nil
+		case calibrationExtrapolateError:
+			return 0, fmt.Errorf("raw value %v is outside of the calibration range [%v, %v]", raw, first.Raw, last.Raw)
+		}
+	}
+
+	// Find the segment of the table containing the raw value. Raw values beyond
+	// the ends of the table use the first or last segment.
+	i := 1
+	for i < len(points)-1 && raw > points[i].Raw {
+		i++
+	}
+	lo, hi := points[i-1], points[i]
+	return lo.Value + (raw-lo.Raw)*(hi.Value-lo.Value)/(hi.Raw-lo.Raw), nil
+}
@@ -0,0 +1,170 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// testCalibrationPoints is a nonlinear calibration table used for testing.
+var testCalibrationPoints = []CalibrationPoint{
+	{Raw: 0, Value: 0},
+	{Raw: 10, Value: 100},
+	{Raw: 20, Value: 150},
+}
+
+// TestCalibration_apply tests calibrating raw values by interpolating between
+// the points of the calibration table.
+func TestCalibration_apply(t *testing.T) {
+	calibration := &Calibration{Points: testCalibrationPoints}
+
+	var testTable = []struct {
+		raw      interface{}
+		expected float64
+	}{
+		{raw: 0, expected: 0},
+		{raw: 5, expected: 50},
+		{raw: int16(10), expected: 100},
+		{raw: 12.5, expected: 112.5},
+		{raw: "16", expected: 130},
+		{raw: uint8(20), expected: 150},
+	}
+
+	for _, testCase := range testTable {
+		value, err := calibration.apply(testCase.raw)
+		assert.NoError(t, err, testCase.raw)
+		assert.Equal(t, testCase.expected, value, testCase.raw)
+	}
+}
+
+// TestCalibration_apply_Extrapolate tests calibrating raw values beyond the ends
+// of the calibration table with each extrapolation policy.
+func TestCalibration_apply_Extrapolate(t *testing.T) {
+	var testTable = []struct {
+		policy string
+		low    float64
+		high   float64
+	}{
+		{policy: "", low: -20, high: 175},
+		{policy: "linear", low: -20, high: 175},
+		{policy: "clamp", low: 0, high: 150},
+	}
+
+	for _, testCase := range testTable {
+		calibration := &Calibration{Points: testCalibrationPoints, Extrapolate: testCase.policy}
+
+		value, err := calibration.apply(-2)
+		assert.NoError(t, err, testCase.policy)
+		assert.Equal(t, testCase.low, value, testCase.policy)
+
+		value, err = calibration.apply(25)
+		assert.NoError(t, err, testCase.policy)
+		assert.Equal(t, testCase.high, value, testCase.policy)
+	}
+
+	calibration := &Calibration{Points: testCalibrationPoints, Extrapolate: "error"}
+	_, err := calibration.apply(-2)
+	assert.Error(t, err)
+	_, err = calibration.apply(25)
+	assert.Error(t, err)
+
+	value, err := calibration.apply(20)
+	assert.NoError(t, err)
+	assert.Equal(t, 150.0, value)
+}
+
+// TestCalibration_apply_Error tests calibrating values which can not be calibrated.
+func TestCalibration_apply_Error(t *testing.T) {
+	_, err := (&Calibration{Points: testCalibrationPoints}).apply("abc")
+	assert.Error(t, err)
+
+	_, err = (&Calibration{Points: testCalibrationPoints[:1]}).apply(1)
+	assert.Error(t, err)
+}
+
+// TestCalibration_Validate_Ok tests validating a Calibration with no errors.
+func TestCalibration_Validate_Ok(t *testing.T) {
+	for _, policy := range []string{"", "linear", "clamp", "error"} {
+		merr := errors.NewMultiError("test")
+		Calibration{Points: testCalibrationPoints, Extrapolate: policy}.Validate(merr)
+		assert.NoError(t, merr.Err(), policy)
+	}
+}
+
+// TestCalibration_Validate_Error tests validating a Calibration with errors.
+func TestCalibration_Validate_Error(t *testing.T) {
+	var testTable = []struct {
+		desc        string
+		errCount    int
+		calibration Calibration
+	}{
+		{
+			desc:        "no points",
+			errCount:    1,
+			calibration: Calibration{},
+		},
+		{
+			desc:        "one point",
+			errCount:    1,
+			calibration: Calibration{Points: []CalibrationPoint{{Raw: 1, Value: 1}}},
+		},
+		{
+			desc:     "raw values decrease",
+			errCount: 1,
+			calibration: Calibration{Points: []CalibrationPoint{
+				{Raw: 0, Value: 0}, {Raw: 10, Value: 5}, {Raw: 5, Value: 10},
+			}},
+		},
+		{
+			desc:     "duplicate raw values",
+			errCount: 1,
+			calibration: Calibration{Points: []CalibrationPoint{
+				{Raw: 0, Value: 0}, {Raw: 0, Value: 5},
+			}},
+		},
+		{
+			desc:        "unknown extrapolation policy",
+			errCount:    1,
+			calibration: Calibration{Points: testCalibrationPoints, Extrapolate: "nearest"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+		testCase.calibration.Validate(merr)
+		assert.Error(t, merr.Err(), testCase.desc)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
+
+// TestNewReading_Calibration tests making a reading for an output with a
+// calibration table. The calibrated value is transformed by the output type.
+func TestNewReading_Calibration(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name:          "test",
+			ScalingFactor: "0.1",
+		},
+		Calibration: &Calibration{Points: testCalibrationPoints},
+	}
+
+	reading, err := NewReading(output, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, reading.Value)
+
+	output.Calibration.Extrapolate = "error"
+	_, err = NewReading(output, 50)
+	assert.Error(t, err)
+}
+
+// TestNewOutputFromConfig_Calibration tests creating an Output with a calibration table.
+func TestNewOutputFromConfig_Calibration(t *testing.T) {
+	defer resetContext()
+	ctx.outputTypes["test"] = &OutputType{Name: "test"}
+
+	calibration := &Calibration{Points: testCalibrationPoints}
+	output, err := NewOutputFromConfig(&DeviceOutput{Type: "test", Calibration: calibration})
+	assert.NoError(t, err)
+	assert.Equal(t, calibration, output.Calibration)
+}
@@ -0,0 +1,205 @@
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// ConfigChecksum holds checksums of the effective configurations for a plugin.
+// The checksums are deterministic, so they can be compared across plugin
+// replicas to detect configuration drift.
+type ConfigChecksum struct {
+	// Plugin is the checksum of the plugin config.
+	Plugin string
+
+	// Device is the checksum of the unified device config.
+	Device string
+
+	// OutputType is the checksum of all registered output types.
+	OutputType string
+
+	// Combined is a checksum over all of the config checksums.
+	Combined string
+}
+
+// GetConfigChecksum gets the checksums for the effective plugin, device, and
+// output type configurations. The checksums do not depend on the order in which
+// config files were found or merged.
+func GetConfigChecksum() (*ConfigChecksum, error) {
+	pluginSum, err := checksum(Config.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	deviceSum, err := deviceConfigChecksum(Config.Device)
+	if err != nil {
+		return nil, err
+	}
+	outputSum, err := outputTypesChecksum(ctx.outputTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigChecksum{
+		Plugin:     pluginSum,
+		Device:     deviceSum,
+		OutputType: outputSum,
+		Combined:   fmt.Sprintf("%x", sha256.Sum256([]byte(pluginSum+deviceSum+outputSum))),
+	}, nil
+}
+
+// checksum gets the hex-encoded SHA-256 checksum of the JSON encoding of the
+// given value. Map keys are sorted by the JSON encoder, so the encoding for a
+// given value is stable.
+func checksum(v interface{}) (string, error) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(bytes)), nil
+}
+
+// deviceConfigChecksum gets the checksum of a device config. Since a unified device
+// config is built by merging configs in the order they were found, its locations,
+// device kinds, and instances are sorted before the checksum is computed.
+func deviceConfigChecksum(config *DeviceConfig) (string, error) {
+	if config == nil {
+		return checksum(config)
+	}
+
+	canonical := &DeviceConfig{
+		SchemeVersion: config.SchemeVersion,
+		Locations:     make([]*LocationConfig, len(config.Locations)),
+		Devices:       make([]*DeviceKind, len(config.Devices)),
+	}
+
+	copy(canonical.Locations, config.Locations)
+	sort.SliceStable(canonical.Locations, func(i, j int) bool {
+		return canonical.Locations[i].Name < canonical.Locations[j].Name
+	})
+
+	for i, kind := range config.Devices {
+		k := *kind
+		instances, err := sortedInstances(kind.Instances)
+		if err != nil {
+			return "", err
+		}
+		k.Instances = instances
+		canonical.Devices[i] = &k
+	}
+	sort.SliceStable(canonical.Devices, func(i, j int) bool {
+		return canonical.Devices[i].Name < canonical.Devices[j].Name
+	})
+
+	return checksum(canonical)
+}
+
+// sortedInstances returns a copy of the given device instances, sorted by
+// their JSON encoding.
+func sortedInstances(instances []*DeviceInstance) ([]*DeviceInstance, error) {
+	keys := make(map[*DeviceInstance]string, len(instances))
+	for _, instance := range instances {
+		bytes, err := json.Marshal(instance)
+		if err != nil {
+			return nil, err
+		}
+		keys[instance] = string(bytes)
+	}
+
+	sorted := make([]*DeviceInstance, len(instances))
+	copy(sorted, instances)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return keys[sorted[i]] < keys[sorted[j]]
+	})
+	return sorted, nil
+}
+
+// outputTypesChecksum gets the checksum of the given output types, sorted by name.
+func outputTypesChecksum(outputTypes map[string]*OutputType) (string, error) {
+	var names []string
+	for name := range outputTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var types []*OutputType
+	for _, name := range names {
+		types = append(types, outputTypes[name])
+	}
+	return checksum(types)
+}
+
+// encode encodes the config checksums as a Struct, keyed by config: "plugin",
+// "device", "outputType", and "combined".
+func (sum *ConfigChecksum) encode() *structpb.Struct {
+	stringValue := func(s string) *structpb.Value {
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: s}}
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"plugin":     stringValue(sum.Plugin),
+			"device":     stringValue(sum.Device),
+			"outputType": stringValue(sum.OutputType),
+			"combined":   stringValue(sum.Combined),
+		},
+	}
+}
+
+// configChecksumServer is the server API for the synse.ConfigChecksum service.
+type configChecksumServer interface {
+	GetChecksum(context.Context, *synse.Empty) (*structpb.Struct, error)
+}
+
+// GetChecksum is the handler for the synse.ConfigChecksum service's `GetChecksum`
+// RPC method. It reports the checksums of the plugin's effective configs (see
+// GetConfigChecksum).
+func (server *server) GetChecksum(ctx context.Context, request *synse.Empty) (*structpb.Struct, error) {
+	log.Debug("[grpc] get checksum rpc request")
+	sum, err := GetConfigChecksum()
+	if err != nil {
+		return nil, err
+	}
+	return sum.encode(), nil
+}
+
+// getChecksumHandler decodes and dispatches requests for the `GetChecksum` RPC method.
+func getChecksumHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(configChecksumServer).GetChecksum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.ConfigChecksum/GetChecksum",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(configChecksumServer).GetChecksum(ctx, req.(*synse.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// configChecksumServiceDesc describes the synse.ConfigChecksum gRPC service, which
+// reports the checksums of a plugin's effective configs, so that the configs of
+// plugin replicas can be compared to detect drift.
+var configChecksumServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.ConfigChecksum",
+	HandlerType: (*configChecksumServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetChecksum",
+			Handler:    getChecksumHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/checksum.proto",
+}
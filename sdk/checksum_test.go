@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var (
+	checksumFileA = `
+version: 1.0
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: temperature
+    instances:
+      - info: temp 1
+        location: r1b1
+        data:
+          id: 1
+`
+
+	checksumFileB = `
+version: 1.0
+locations:
+  - name: r1b2
+    rack:
+      name: rack-1
+    board:
+      name: board-2
+devices:
+  - name: temperature
+    instances:
+      - info: temp 2
+        location: r1b2
+        data:
+          id: 2
+  - name: led
+    instances:
+      - info: led 1
+        location: r1b2
+        data:
+          id: 3
+`
+)
+
+// loadUnifiedDeviceConfig is a test helper that loads the device config files
+// with the given names and contents, in order, and unifies them.
+func loadUnifiedDeviceConfig(t *testing.T, files ...[2]string) *DeviceConfig {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	var ctxs []*ConfigContext
+	for _, file := range files {
+		path := test.WriteTempFile(t, file[0], file[1], os.ModePerm)
+		cfg := &DeviceConfig{}
+		err := unmarshalConfigFile(path, cfg)
+		assert.NoError(t, err)
+		ctxs = append(ctxs, NewConfigContext(path, cfg))
+	}
+
+	unified, err := unifyDeviceConfigs(ctxs)
+	assert.NoError(t, err)
+	return unified.Config.(*DeviceConfig)
+}
+
+// Test_deviceConfigChecksum tests that identical device configs from differently
+// ordered files produce the same checksum.
+func Test_deviceConfigChecksum(t *testing.T) {
+	cfg1 := loadUnifiedDeviceConfig(t, [2]string{"a.yml", checksumFileA}, [2]string{"b.yml", checksumFileB})
+	cfg2 := loadUnifiedDeviceConfig(t, [2]string{"b.yml", checksumFileB}, [2]string{"a.yml", checksumFileA})
+
+	// Make sure the unified configs are actually ordered differently.
+	assert.NotEqual(t, cfg1.Locations[0].Name, cfg2.Locations[0].Name)
+
+	sum1, err := deviceConfigChecksum(cfg1)
+	assert.NoError(t, err)
+	sum2, err := deviceConfigChecksum(cfg2)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, sum1)
+	assert.Equal(t, sum1, sum2)
+}
+
+// Test_deviceConfigChecksum2 tests that different device configs produce
+// different checksums.
+func Test_deviceConfigChecksum2(t *testing.T) {
+	cfg1 := loadUnifiedDeviceConfig(t, [2]string{"a.yml", checksumFileA}, [2]string{"b.yml", checksumFileB})
+	cfg2 := loadUnifiedDeviceConfig(t, [2]string{"a.yml", checksumFileA})
+
+	sum1, err := deviceConfigChecksum(cfg1)
+	assert.NoError(t, err)
+	sum2, err := deviceConfigChecksum(cfg2)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, sum1, sum2)
+}
+
+// Test_deviceConfigChecksum3 tests that computing the checksum does not
+// modify the device config.
+func Test_deviceConfigChecksum3(t *testing.T) {
+	cfg := loadUnifiedDeviceConfig(t, [2]string{"b.yml", checksumFileB}, [2]string{"a.yml", checksumFileA})
+
+	_, err := deviceConfigChecksum(cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "r1b2", cfg.Locations[0].Name)
+	assert.Equal(t, "temperature", cfg.Devices[0].Name)
+	assert.Equal(t, "temp 2", cfg.Devices[0].Instances[0].Info)
+}
+
+// Test_outputTypesChecksum tests that the output type checksum does not depend
+// on registration order.
+func Test_outputTypesChecksum(t *testing.T) {
+	sum1, err := outputTypesChecksum(map[string]*OutputType{
+		"foo": {Name: "foo", Precision: 2},
+		"bar": {Name: "bar", ScalingFactor: "2"},
+	})
+	assert.NoError(t, err)
+
+	sum2, err := outputTypesChecksum(map[string]*OutputType{
+		"bar": {Name: "bar", ScalingFactor: "2"},
+		"foo": {Name: "foo", Precision: 2},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, sum1, sum2)
+}
+
+// TestGetConfigChecksum tests getting the checksums for the effective configs.
+func TestGetConfigChecksum(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	pluginCfg, err := NewDefaultPluginConfig()
+	assert.NoError(t, err)
+
+	Config.Plugin = pluginCfg
+	Config.Device = loadUnifiedDeviceConfig(t, [2]string{"a.yml", checksumFileA})
+	ctx.outputTypes["foo"] = &OutputType{Name: "foo"}
+
+	sum1, err := GetConfigChecksum()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sum1.Plugin)
+	assert.NotEmpty(t, sum1.Device)
+	assert.NotEmpty(t, sum1.OutputType)
+	assert.NotEmpty(t, sum1.Combined)
+
+	sum2, err := GetConfigChecksum()
+	assert.NoError(t, err)
+	assert.Equal(t, sum1, sum2)
+
+	Config.Plugin.Debug = true
+	sum3, err := GetConfigChecksum()
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum1.Plugin, sum3.Plugin)
+	assert.NotEqual(t, sum1.Combined, sum3.Combined)
+	assert.Equal(t, sum1.Device, sum3.Device)
+}
+
+// TestServer_GetChecksum tests getting the config checksums via the
+// synse.ConfigChecksum service.
+func TestServer_GetChecksum(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	pluginCfg, err := NewDefaultPluginConfig()
+	assert.NoError(t, err)
+	pluginCfg.Network = &NetworkSettings{Type: "tcp", Address: address}
+	Config.Plugin = pluginCfg
+	Config.Device = loadUnifiedDeviceConfig(t, [2]string{"a.yml", checksumFileA})
+	ctx.outputTypes["foo"] = &OutputType{Name: "foo"}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	resp := &structpb.Struct{}
+	err = conn.Invoke(context.Background(), "/synse.ConfigChecksum/GetChecksum", &synse.Empty{}, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+
+	sum, err := GetConfigChecksum()
+	assert.NoError(t, err)
+	assert.Equal(t, sum.Plugin, resp.Fields["plugin"].GetStringValue())
+	assert.Equal(t, sum.Device, resp.Fields["device"].GetStringValue())
+	assert.Equal(t, sum.OutputType, resp.Fields["outputType"].GetStringValue())
+	assert.Equal(t, sum.Combined, resp.Fields["combined"].GetStringValue())
+}
@@ -0,0 +1,97 @@
+package sdk
+
+import (
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+)
+
+// idleCheckInterval is how often the read loop checks whether any gRPC clients
+// have connected while it is idle, if the read interval does not set it.
+const idleCheckInterval = 1 * time.Second
+
+// clientTracker is a gRPC stats handler which tracks the number of gRPC clients
+// connected to the plugin, so the data manager can degrade its reads while no
+// clients are connected.
+type clientTracker struct {
+	manager *dataManager
+}
+
+// TagRPC is a no-op; only connections are tracked.
+func (tracker *clientTracker) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC is a no-op; only connections are tracked.
+func (tracker *clientTracker) HandleRPC(ctx context.Context, s stats.RPCStats) {}
+
+// TagConn is a no-op; connections are tracked by HandleConn.
+func (tracker *clientTracker) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn updates the number of connected clients when a connection begins
+// or ends.
+func (tracker *clientTracker) HandleConn(ctx context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		tracker.manager.clientConnected(true)
+	case *stats.ConnEnd:
+		tracker.manager.clientConnected(false)
+	}
+}
+
+// clientConnected updates the number of gRPC clients connected to the plugin.
+func (manager *dataManager) clientConnected(connected bool) {
+	delta := int32(-1)
+	if connected {
+		delta = 1
+	}
+	clients := atomic.AddInt32(&manager.clients, delta)
+	log.WithField("clients", clients).Debug("[grpc] client connections changed")
+}
+
+// clientsConnected checks whether any gRPC clients are connected to the plugin.
+func (manager *dataManager) clientsConnected() bool {
+	return atomic.LoadInt32(&manager.clients) > 0
+}
+
+// waitWhileIdle extends the wait before the next read while no gRPC clients are
+// connected, if the plugin is configured to degrade its reads when idle. Reads
+// either wait for the idle interval since the given start of the previous read,
+// or are paused until a client connects. The connected clients are checked at
+// the read interval, so the full read cadence resumes shortly after a client
+// reconnects.
+func (manager *dataManager) waitWhileIdle(start time.Time, interval time.Duration, align bool) {
+	settings := Config.Plugin.Settings.Read
+	idleInterval, err := settings.GetIdleInterval()
+	if err != nil {
+		log.WithField("error", err).Warn("[data manager] misconfiguration: failed to get idle read interval")
+		return
+	}
+	if !settings.IdlePause && idleInterval <= 0 {
+		return
+	}
+
+	idle := false
+	for !manager.clientsConnected() {
+		if !settings.IdlePause && clock.Now().Sub(start) >= idleInterval {
+			break
+		}
+		if !idle {
+			log.Info("[data manager] no gRPC clients connected, degrading reads")
+			idle = true
+		}
+		delay := readDelay(clock.Now(), interval, align)
+		if delay <= 0 {
+			delay = idleCheckInterval
+		}
+		clock.Sleep(delay)
+	}
+	if idle && manager.clientsConnected() {
+		log.Info("[data manager] gRPC client connected, resuming reads")
+	}
+}
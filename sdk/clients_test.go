@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+)
+
+// sleepHookClock is a fakeClock which calls a hook after each Sleep, so tests can
+// change state (e.g. connect a client) partway through a wait.
+type sleepHookClock struct {
+	*fakeClock
+	onSleep func(sleeps int)
+}
+
+func (c *sleepHookClock) Sleep(d time.Duration) {
+	c.fakeClock.Sleep(d)
+	c.onSleep(len(c.Sleeps()))
+}
+
+// setupIdleTest sets the plugin's read settings for the idle read tests and
+// returns a fake clock which calls the given hook after each Sleep.
+func setupIdleTest(t *testing.T, settings *ReadSettings, onSleep func(int)) *sleepHookClock {
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{Read: settings},
+	}
+	c := &sleepHookClock{
+		fakeClock: newFakeClock(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)),
+		onSleep:   onSleep,
+	}
+	clock = c
+	return c
+}
+
+// Test_clientTracker tests tracking gRPC client connections.
+func Test_clientTracker(t *testing.T) {
+	manager := newDataManager()
+	tracker := &clientTracker{manager: manager}
+	assert.False(t, manager.clientsConnected())
+
+	tracker.HandleConn(context.Background(), &stats.ConnBegin{})
+	tracker.HandleConn(context.Background(), &stats.ConnBegin{})
+	assert.True(t, manager.clientsConnected())
+
+	tracker.HandleConn(context.Background(), &stats.ConnEnd{})
+	assert.True(t, manager.clientsConnected())
+
+	tracker.HandleConn(context.Background(), &stats.ConnEnd{})
+	assert.False(t, manager.clientsConnected())
+}
+
+// Test_waitWhileIdle_Disabled tests that reads are not degraded while no clients
+// are connected if it is not configured.
+func Test_waitWhileIdle_Disabled(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	c := setupIdleTest(t, &ReadSettings{}, func(int) {})
+
+	manager := newDataManager()
+	manager.waitWhileIdle(c.Now(), time.Second, false)
+	assert.Empty(t, c.Sleeps())
+}
+
+// Test_waitWhileIdle_Interval tests that the read cadence slows to the idle
+// interval when clients disconnect, and resumes when they reconnect.
+func Test_waitWhileIdle_Interval(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	manager := newDataManager()
+	c := setupIdleTest(t, &ReadSettings{IdleInterval: "5s"}, func(sleeps int) {
+		// Reconnect partway through the second idle wait.
+		if sleeps == 9 {
+			manager.clientConnected(true)
+		}
+	})
+
+	// While a client is connected, reads are made at the read interval.
+	manager.clientConnected(true)
+	start := c.Now()
+	c.Sleep(time.Second)
+	manager.waitWhileIdle(start, time.Second, false)
+	assert.Equal(t, []time.Duration{time.Second}, c.Sleeps())
+
+	// When the client disconnects, reads are made at the idle interval.
+	manager.clientConnected(false)
+	start = c.Now()
+	c.Sleep(time.Second)
+	manager.waitWhileIdle(start, time.Second, false)
+	assert.Equal(t, 5*time.Second, c.Now().Sub(start))
+	assert.Equal(t, 6, len(c.Sleeps()))
+
+	// When the client reconnects, reads resume at the read interval, even
+	// partway through an idle wait.
+	start = c.Now()
+	c.Sleep(time.Second)
+	manager.waitWhileIdle(start, time.Second, false)
+	assert.Equal(t, 3*time.Second, c.Now().Sub(start))
+
+	start = c.Now()
+	c.Sleep(time.Second)
+	manager.waitWhileIdle(start, time.Second, false)
+	assert.Equal(t, time.Second, c.Now().Sub(start))
+}
+
+// Test_waitWhileIdle_Pause tests that reads are paused while no clients are
+// connected, and resume when a client connects.
+func Test_waitWhileIdle_Pause(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	manager := newDataManager()
+	c := setupIdleTest(t, &ReadSettings{IdleInterval: "5s", IdlePause: true}, func(sleeps int) {
+		if sleeps == 20 {
+			manager.clientConnected(true)
+		}
+	})
+
+	// There are no clients connected, so reads are paused until one connects,
+	// regardless of the idle interval.
+	start := c.Now()
+	c.Sleep(2 * time.Second)
+	manager.waitWhileIdle(start, 2*time.Second, false)
+	assert.Equal(t, 20, len(c.Sleeps()))
+	assert.Equal(t, 40*time.Second, c.Now().Sub(start))
+
+	// Once connected, reads are made at the read interval.
+	start = c.Now()
+	c.Sleep(2 * time.Second)
+	manager.waitWhileIdle(start, 2*time.Second, false)
+	assert.Equal(t, 21, len(c.Sleeps()))
+}
+
+// Test_waitWhileIdle_NoInterval tests that the connected clients are checked at
+// a default interval while paused if there is no read interval.
+func Test_waitWhileIdle_NoInterval(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	manager := newDataManager()
+	c := setupIdleTest(t, &ReadSettings{IdlePause: true}, func(sleeps int) {
+		if sleeps == 3 {
+			manager.clientConnected(true)
+		}
+	})
+
+	manager.waitWhileIdle(c.Now(), 0, false)
+	assert.Equal(t, []time.Duration{idleCheckInterval, idleCheckInterval, idleCheckInterval}, c.Sleeps())
+}
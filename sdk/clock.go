@@ -0,0 +1,33 @@
+package sdk
+
+import (
+	"time"
+)
+
+// Clock provides the current time and the ability to wait for a duration.
+// The SDK uses a Clock for reading timestamps and for scheduling its read
+// and write loops, so that time-dependent behavior can be controlled in tests.
+type Clock interface {
+	// Now gets the current time.
+	Now() time.Time
+
+	// Sleep pauses the current goroutine for at least the given duration.
+	Sleep(d time.Duration)
+}
+
+// realClock is a Clock which uses the system time.
+type realClock struct{}
+
+// Now gets the current system time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses the current goroutine for the given duration.
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// clock is the Clock used by the SDK. It defaults to the system clock, and
+// can be replaced in tests to make time-dependent behavior deterministic.
+var clock Clock = realClock{}
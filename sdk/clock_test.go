@@ -0,0 +1,369 @@
+package sdk
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock for testing. Its time only moves forward when it is
+// explicitly advanced, or when Sleep is called.
+type fakeClock struct {
+	sync.Mutex
+
+	now    time.Time
+	sleeps []time.Duration
+
+	// If set, each call to Sleep blocks until a value is received on this
+	// channel, allowing tests to step through loops one interval at a time.
+	wake chan struct{}
+}
+
+// newFakeClock creates a new fakeClock set to the given time.
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+// useFakeClock sets the SDK clock to a new fakeClock for the duration of a
+// test, returning the fakeClock.
+func useFakeClock(t *testing.T, now time.Time) *fakeClock {
+	c := newFakeClock(now)
+	clock = c
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Lock()
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	wake := c.wake
+	c.Unlock()
+
+	if wake != nil {
+		<-wake
+	}
+}
+
+// Advance moves the fakeClock's time forward by the given duration.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleeps gets the durations of all calls to Sleep.
+func (c *fakeClock) Sleeps() []time.Duration {
+	c.Lock()
+	defer c.Unlock()
+	return append([]time.Duration{}, c.sleeps...)
+}
+
+// TestRealClock tests that the default clock uses the system time.
+func TestRealClock(t *testing.T) {
+	before := time.Now()
+	now := realClock{}.Now()
+	after := time.Now()
+
+	assert.False(t, now.Before(before))
+	assert.False(t, now.After(after))
+	assert.IsType(t, realClock{}, clock)
+}
+
+// TestGetCurrentTime_FakeClock tests getting the current time with a fake clock.
+func TestGetCurrentTime_FakeClock(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 6, time.UTC))
+
+	assert.Equal(t, "2019-01-02T03:04:05.000000006Z", GetCurrentTime())
+
+	c.Advance(time.Second)
+	assert.Equal(t, "2019-01-02T03:04:06.000000006Z", GetCurrentTime())
+}
+
+// TestNewReading_FakeClock tests that reading timestamps come from the clock.
+func TestNewReading_FakeClock(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("EST", -5*60*60)))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{EpochTimestamp: true},
+		},
+	}
+
+	reading, err := NewReading(&Output{OutputType: OutputType{Name: "test"}}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "2019-01-02T08:04:05Z", reading.Timestamp)
+	assert.Equal(t, "1546416245000000000", reading.Context[ContextKeyEpoch])
+}
+
+// TestDataManager_serialRead_FakeClock tests that a serial read pauses for the
+// serial read interval after each device.
+func TestDataManager_serialRead_FakeClock(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:   &ReadSettings{Buffer: 10},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+		},
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		ctx.devices[id] = &Device{
+			id:       id,
+			Location: &Location{Rack: "rack", Board: "board"},
+			Handler:  &DeviceHandler{},
+		}
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+
+	d.serialRead(5 * time.Second)
+	assert.Equal(t, []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second}, c.Sleeps())
+	assert.Equal(t, time.Unix(15, 0), c.Now())
+}
+
+// TestDataManager_serialRead_PhaseOffset tests that a serial read staggers the reads
+// of devices by their phase offsets into the read cycle.
+func TestDataManager_serialRead_PhaseOffset(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:   &ReadSettings{Buffer: 10},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+		},
+	}
+
+	readTimes := map[string]time.Time{}
+	handler := &DeviceHandler{
+		Read: func(d *Device) ([]*Reading, error) {
+			readTimes[d.id] = c.Now()
+			return []*Reading{}, nil
+		},
+	}
+	ctx.devices["rack-board-1"] = &Device{
+		id:          "1",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     handler,
+		PhaseOffset: 750 * time.Millisecond,
+	}
+	ctx.devices["rack-board-2"] = &Device{
+		id:          "2",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     handler,
+		PhaseOffset: 250 * time.Millisecond,
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+
+	// Each read cycle reads the devices at their offsets into the cycle.
+	for cycle := 0; cycle < 2; cycle++ {
+		start := c.Now()
+		d.serialRead(0)
+		<-d.readChannel
+		<-d.readChannel
+
+		assert.Equal(t, start.Add(250*time.Millisecond), readTimes["2"])
+		assert.Equal(t, start.Add(750*time.Millisecond), readTimes["1"])
+
+		// Advance to the start of the next interval.
+		c.Advance(start.Add(time.Second).Sub(c.Now()))
+	}
+}
+
+// TestDataManager_parallelRead_PhaseOffset tests that a parallel read waits for the
+// phase offset of each device before reading it.
+func TestDataManager_parallelRead_PhaseOffset(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:   &ReadSettings{Buffer: 10},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+		},
+	}
+
+	handler := &DeviceHandler{
+		Read: func(d *Device) ([]*Reading, error) {
+			return []*Reading{}, nil
+		},
+	}
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  handler,
+	}
+	ctx.devices["rack-board-2"] = &Device{
+		id:          "2",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     handler,
+		PhaseOffset: 300 * time.Millisecond,
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+
+	d.parallelRead()
+	assert.Equal(t, 2, len(d.readChannel))
+
+	// Only the device with a phase offset waits before reading.
+	assert.Equal(t, []time.Duration{300 * time.Millisecond}, c.Sleeps())
+}
+
+// TestDataManager_goRead_FakeClock tests that the read loop reads devices once
+// per read interval.
+func TestDataManager_goRead_FakeClock(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Unix(0, 0))
+	c.wake = make(chan struct{})
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Mode:   modeParallel,
+			Read:   &ReadSettings{Enabled: true, Interval: "1m", Buffer: 10, InitialRead: true},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+			Cache:  &CacheSettings{},
+		},
+	}
+
+	var reads int
+	var readsLock sync.Mutex
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Read: func(d *Device) ([]*Reading, error) {
+				readsLock.Lock()
+				defer readsLock.Unlock()
+				reads++
+				return []*Reading{}, nil
+			},
+		},
+	}
+	getReads := func() int {
+		readsLock.Lock()
+		defer readsLock.Unlock()
+		return reads
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+
+	// The initial read happens before goRead returns. The read loop then waits
+	// for the read interval before reading again.
+	d.goRead()
+	assert.Equal(t, 1, getReads())
+
+	for i := 2; i <= 3; i++ {
+		c.wake <- struct{}{}
+		<-d.readChannel
+		assert.Equal(t, i, getReads())
+	}
+
+	// Wait for the read loop to park in its next sleep, so it is not running
+	// when the test state is reset.
+	for len(c.Sleeps()) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []time.Duration{time.Minute, time.Minute, time.Minute}, c.Sleeps())
+}
+
+// TestDataManager_goRead_AlignInterval tests that reads are scheduled on boundaries
+// of the read interval when interval alignment is enabled, even when reads take time.
+func TestDataManager_goRead_AlignInterval(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Date(2018, 1, 1, 10, 0, 17, 0, time.UTC))
+	c.wake = make(chan struct{})
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Mode:   modeParallel,
+			Read:   &ReadSettings{Enabled: true, Interval: "1m", Buffer: 10, AlignInterval: true},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+			Cache:  &CacheSettings{},
+		},
+	}
+
+	var readTimes []time.Time
+	var readsLock sync.Mutex
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Read: func(d *Device) ([]*Reading, error) {
+				readsLock.Lock()
+				defer readsLock.Unlock()
+				readTimes = append(readTimes, c.Now())
+				// Simulate a read which takes some time.
+				c.Advance(3 * time.Second)
+				return []*Reading{}, nil
+			},
+		},
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+
+	d.goRead()
+	for i := 0; i < 3; i++ {
+		c.wake <- struct{}{}
+		<-d.readChannel
+	}
+
+	// Wait for the read loop to park in its next sleep, so it is not running
+	// when the test state is reset.
+	for len(c.Sleeps()) < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	readsLock.Lock()
+	defer readsLock.Unlock()
+	assert.Equal(t, []time.Time{
+		time.Date(2018, 1, 1, 10, 1, 0, 0, time.UTC),
+		time.Date(2018, 1, 1, 10, 2, 0, 0, time.UTC),
+		time.Date(2018, 1, 1, 10, 3, 0, 0, time.UTC),
+	}, readTimes)
+	assert.Equal(t, []time.Duration{43 * time.Second, 57 * time.Second, 57 * time.Second, 57 * time.Second}, c.Sleeps())
+}
@@ -2,8 +2,10 @@ package sdk
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
@@ -190,6 +192,12 @@ func (schemeVersion *SchemeVersion) parse() error {
 	return nil
 }
 
+// setVersion sets the scheme version of the configuration.
+func (schemeVersion *SchemeVersion) setVersion(version *ConfigVersion) {
+	schemeVersion.Version = version.String()
+	schemeVersion.scheme = version
+}
+
 // GetVersion gets the Version associated with the version specified
 // in the configuration.
 func (schemeVersion *SchemeVersion) GetVersion() (*ConfigVersion, error) {
@@ -205,7 +213,20 @@ func (schemeVersion *SchemeVersion) GetVersion() (*ConfigVersion, error) {
 // processDeviceConfigs searches for, reads, and validates the device configuration(s).
 // Its behavior will vary depending on the device config policies that are set. If
 // device config is processed successfully, it will be set to the global Device variable.
-func processDeviceConfigs() error { // nolint: gocyclo
+func processDeviceConfigs() error {
+	cfg, err := loadDeviceConfigs()
+	if err != nil {
+		return err
+	}
+
+	// With the config validated and unified, we can now assign it to the global Device variable.
+	Config.Device = cfg
+	return nil
+}
+
+// loadDeviceConfigs searches for, reads, validates, and unifies the device configuration(s).
+// Its behavior will vary depending on the device config policies that are set.
+func loadDeviceConfigs() (*DeviceConfig, error) { // nolint: gocyclo
 	// Get the plugin's policy for device config files.
 	deviceFilePolicy := policies.GetDeviceConfigFilePolicy()
 
@@ -221,7 +242,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 	if err != nil {
 		_, notFoundErr := err.(*errors.ConfigsNotFound)
 		if !notFoundErr {
-			return err
+			return nil, err
 		}
 	}
 
@@ -242,7 +263,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 	switch deviceFilePolicy {
 	case policies.DeviceConfigFileRequired:
 		if err != nil {
-			return errors.NewPolicyViolationError(
+			return nil, errors.NewPolicyViolationError(
 				deviceFilePolicy.String(),
 				fmt.Sprintf("device config file(s) required, but not found: %v", err),
 			)
@@ -259,7 +280,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		// if a file is found, but we will ultimately not fail. Instead, we
 		// will just pass along an empty config.
 		if err == nil && len(fileCtxs) > 0 {
-			log.Warn(
+			repeatedLog.Warnf(
 				"[sdk] device config file(s) found, but its use is prohibited via policy. " +
 					"the device config files will be ignored.",
 			)
@@ -267,7 +288,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		fileCtxs = []*ConfigContext{}
 
 	default:
-		return errors.NewPolicyViolationError(
+		return nil, errors.NewPolicyViolationError(
 			deviceFilePolicy.String(),
 			"unsupported device config file policy",
 		)
@@ -281,10 +302,22 @@ func processDeviceConfigs() error { // nolint: gocyclo
 	var dynamicCtxs []*ConfigContext
 
 	// Get device configs from dynamic registration
+	timeout, err := Config.Plugin.DynamicRegistration.GetTimeout()
+	if err != nil {
+		return nil, err
+	}
 	multiErr := errors.NewMultiError("dynamic device config registration")
+	timeoutErr := errors.NewMultiError("dynamic device config registration")
 	for _, dynamicData := range Config.Plugin.DynamicRegistration.Config {
-		dynamicCfgs, e := ctx.dynamicDeviceConfigRegistrar(dynamicData)
+		dynamicCfgs, e := registerDynamicDeviceConfigs(dynamicData, timeout)
 		if e != nil {
+			// Timeouts are handled by the dynamic config policy, so they
+			// are tracked separately from other registration errors.
+			if _, isTimeout := e.(*errors.RegistrationTimeout); isTimeout {
+				repeatedLog.WithField("error", e).Warn("[sdk] dynamic device config registration timed out")
+				timeoutErr.Add(e)
+				continue
+			}
 			multiErr.Add(e)
 			continue
 		}
@@ -298,7 +331,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		for _, err := range multiErr.Errors {
 			_, notFoundErr := err.(*errors.ConfigsNotFound)
 			if !notFoundErr {
-				return multiErr
+				return nil, multiErr
 			}
 		}
 	}
@@ -319,8 +352,14 @@ func processDeviceConfigs() error { // nolint: gocyclo
 
 	switch deviceDynamicPolicy {
 	case policies.DeviceConfigDynamicRequired:
+		if timeoutErr.HasErrors() {
+			return nil, errors.NewPolicyViolationError(
+				deviceDynamicPolicy.String(),
+				fmt.Sprintf("dynamic device config(s) required, but registration timed out: %v", timeoutErr),
+			)
+		}
 		if multiErr.Err() != nil || len(dynamicCtxs) == 0 {
-			return errors.NewPolicyViolationError(
+			return nil, errors.NewPolicyViolationError(
 				deviceDynamicPolicy.String(),
 				fmt.Sprintf("dynamic device config(s) required, but none found: %v", multiErr),
 			)
@@ -337,7 +376,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		// if any are found, but we will ultimately not fail. Instead, we
 		// will just pass along an empty config.
 		if multiErr.Err() == nil && len(dynamicCtxs) > 0 {
-			log.Warn(
+			repeatedLog.Warnf(
 				"[sdk] dynamic device config(s) found, but its use is prohibited via policy. " +
 					"the device config(s) will be ignored.",
 			)
@@ -345,13 +384,17 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		dynamicCtxs = []*ConfigContext{}
 
 	default:
-		return errors.NewPolicyViolationError(
+		return nil, errors.NewPolicyViolationError(
 			deviceDynamicPolicy.String(),
 			"unsupported dynamic device config policy",
 		)
 	}
 	log.WithField("policy", deviceDynamicPolicy.String()).Debug("[sdk] policy validation successful")
 
+	// Resolve the device kinds defined by both the config file(s) and dynamic registration
+	// according to the configured merge strategy.
+	applyDeviceConfigMergeStrategy(Config.Plugin.DynamicRegistration.Merge, fileCtxs, dynamicCtxs)
+
 	// Now, we can append whatever config contexts we got from dynamic registration to the slice
 	// of all device config contexts.
 	deviceCtxs = append(deviceCtxs, dynamicCtxs...)
@@ -361,7 +404,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 		// Validate config scheme
 		multiErr = validator.Validate(ctx)
 		if multiErr.HasErrors() {
-			return multiErr
+			return nil, multiErr
 		}
 	}
 
@@ -375,7 +418,7 @@ func processDeviceConfigs() error { // nolint: gocyclo
 	} else {
 		unifiedCtx, err = unifyDeviceConfigs(deviceCtxs)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -383,19 +426,48 @@ func processDeviceConfigs() error { // nolint: gocyclo
 	cfg := unifiedCtx.Config.(*DeviceConfig)
 	multiErr = verifyConfigs(cfg)
 	if multiErr.HasErrors() {
-		return multiErr
+		return nil, multiErr
 	}
 
 	// Validate that the `Data` fields in the config are correct using the plugin-specified
 	// validator, since `Data` is plugin-specific.
 	multiErr = cfg.ValidateDeviceConfigData(ctx.deviceDataValidator)
 	if multiErr.HasErrors() {
-		return multiErr
+		return nil, multiErr
 	}
 
-	// With the config validated and unified, we can now assign it to the global Device variable.
-	Config.Device = cfg
-	return nil
+	return cfg, nil
+}
+
+// registerDynamicDeviceConfigs runs the dynamic device config registrar for the
+// given dynamic registration data. If the timeout is greater than zero and the
+// registrar does not complete within the timeout, a RegistrationTimeout error is
+// returned. Any configs returned by the registrar after the timeout are discarded.
+func registerDynamicDeviceConfigs(data map[string]interface{}, timeout time.Duration) ([]*DeviceConfig, error) {
+	if timeout <= 0 {
+		return ctx.dynamicDeviceConfigRegistrar(data)
+	}
+
+	type result struct {
+		cfgs []*DeviceConfig
+		err  error
+	}
+
+	// The channel is buffered so the registrar goroutine does not block
+	// forever if it completes after the timeout.
+	done := make(chan result, 1)
+	registrar := ctx.dynamicDeviceConfigRegistrar
+	go func() {
+		cfgs, err := registrar(data)
+		done <- result{cfgs: cfgs, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.cfgs, r.err
+	case <-time.After(timeout):
+		return nil, errors.NewRegistrationTimeoutError(timeout)
+	}
 }
 
 // processPluginConfig searches for, reads, and validates the plugin configuration.
@@ -458,7 +530,7 @@ func processPluginConfig() error { // nolint: gocyclo
 		// It is up to the user to specify the config (whether default of not)
 		// when the plugin config is prohibited.
 		if err == nil && pluginCtx != nil {
-			log.Warn(
+			repeatedLog.Warnf(
 				"[sdk] plugin config file found, but its use is prohibited via policy. " +
 					"you must ensure that the plugin has its config set manually.",
 			)
@@ -545,7 +617,7 @@ func processOutputTypeConfig() ([]*OutputType, error) { // nolint: gocyclo
 		// if a file is found, but we will ultimately not fail. Instead, we
 		// will just pass along an empty config.
 		if err == nil && len(outputTypeCtxs) > 0 {
-			log.Warn(
+			repeatedLog.Warnf(
 				"[sdk] output type config file(s) found, but its use is prohibited via policy. " +
 					"the output type config files will be ignored.",
 			)
@@ -560,6 +632,12 @@ func processOutputTypeConfig() ([]*OutputType, error) { // nolint: gocyclo
 	}
 	log.WithField("policy", outputTypeFilePolicy.String()).Debug("[sdk] policy validation successful")
 
+	// Resolve any output types with the same name defined in multiple files.
+	outputTypeCtxs, err = dedupeOutputTypeConfigs(outputTypeCtxs, policies.GetTypeConfigDuplicatePolicy())
+	if err != nil {
+		return nil, err
+	}
+
 	var outputs []*OutputType
 
 	// Validate the plugin config
@@ -574,6 +652,160 @@ func processOutputTypeConfig() ([]*OutputType, error) { // nolint: gocyclo
 	return outputs, nil
 }
 
+// dedupeOutputTypeConfigs resolves output type configs which share the same name
+// according to the given duplicate output type policy. The deduplicated configs
+// are returned in the order their names were first found.
+func dedupeOutputTypeConfigs(ctxs []*ConfigContext, policy policies.ConfigPolicy) ([]*ConfigContext, error) {
+	var deduped []*ConfigContext
+	byName := map[string]int{}
+
+	for _, outputTypeCtx := range ctxs {
+		cfg := outputTypeCtx.Config.(*OutputType)
+		i, exists := byName[cfg.Name]
+		if !exists {
+			byName[cfg.Name] = len(deduped)
+			deduped = append(deduped, outputTypeCtx)
+			continue
+		}
+
+		// Identical definitions of an output type do not conflict, so the
+		// one already found is kept, whatever the policy.
+		existing := deduped[i]
+		if reflect.DeepEqual(existing.Config.(*OutputType), cfg) {
+			log.WithFields(log.Fields{
+				"name":    cfg.Name,
+				"sources": []string{existing.Source, outputTypeCtx.Source},
+			}).Debug("[sdk] found identical output type config")
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"name":    cfg.Name,
+			"sources": []string{existing.Source, outputTypeCtx.Source},
+			"policy":  policy.String(),
+		}).Debug("[sdk] found duplicate output type config")
+
+		switch policy {
+		case policies.TypeConfigDuplicateError:
+			return nil, errors.NewPolicyViolationError(
+				policy.String(),
+				fmt.Sprintf("output type '%s' defined differently in multiple config files: %s, %s", cfg.Name, existing.Source, outputTypeCtx.Source),
+			)
+
+		case policies.TypeConfigDuplicateLastWins:
+			deduped[i] = outputTypeCtx
+
+		case policies.TypeConfigDuplicateMerge:
+			deduped[i] = NewConfigContext(
+				existing.Source+", "+outputTypeCtx.Source,
+				mergeOutputTypes(existing.Config.(*OutputType), cfg),
+			)
+
+		default:
+			return nil, errors.NewPolicyViolationError(
+				policy.String(),
+				"unsupported duplicate output type config policy",
+			)
+		}
+	}
+	return deduped, nil
+}
+
+// mergeOutputTypes merges two output types into a new output type. Each field
+// which is set in the override is used in place of the same field in the base.
+// Fields are not merged recursively.
+func mergeOutputTypes(base, override *OutputType) *OutputType {
+	merged := *base
+	mergedValue := reflect.ValueOf(&merged).Elem()
+	overrideValue := reflect.ValueOf(override).Elem()
+	for i := 0; i < overrideValue.NumField(); i++ {
+		if field := overrideValue.Field(i); !field.IsZero() {
+			mergedValue.Field(i).Set(field)
+		}
+	}
+	return &merged
+}
+
+// MergeDeviceConfigs merges the given device configs into a single device config,
+// using the same semantics the SDK uses to unify the device configs it loads from
+// file and from dynamic registration. The Locations of all configs are appended,
+// and the instances of device kinds with the same name are merged into the kind
+// from the first config which defines it. The scheme version of the merged config
+// is that of the first config.
+//
+// The given configs are not modified. An error is returned if no configs are given,
+// or if device kinds with the same name have conflicting fields, other than their
+// instances.
+func MergeDeviceConfigs(configs ...*DeviceConfig) (*DeviceConfig, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no device configs specified for merging")
+	}
+
+	var ctxs []*ConfigContext
+	for i, cfg := range configs {
+		if cfg == nil {
+			return nil, fmt.Errorf("device config %d is nil", i)
+		}
+		ctxs = append(ctxs, NewConfigContext(fmt.Sprintf("device config %d", i), copyDeviceConfig(cfg)))
+	}
+
+	unified, err := unifyDeviceConfigs(ctxs)
+	if err != nil {
+		return nil, err
+	}
+	return unified.Config.(*DeviceConfig), nil
+}
+
+// copyDeviceConfig makes a copy of the device config which can be unified without
+// modifying the original. Only the parts of the config which unification modifies
+// are copied: its Locations and Devices, and the Instances of each device kind.
+func copyDeviceConfig(cfg *DeviceConfig) *DeviceConfig {
+	cfgCopy := *cfg
+	cfgCopy.Locations = append([]*LocationConfig{}, cfg.Locations...)
+	cfgCopy.Devices = make([]*DeviceKind, len(cfg.Devices))
+	for i, kind := range cfg.Devices {
+		kindCopy := *kind
+		kindCopy.Instances = append([]*DeviceInstance{}, kind.Instances...)
+		cfgCopy.Devices[i] = &kindCopy
+	}
+	return &cfgCopy
+}
+
+// deviceKindConflicts gets the fields, other than the name and instances, which
+// are set to different values by two device kinds. The fields are identified by
+// their config key. A field which is empty in either kind (e.g. a kind from dynamic
+// registration which only defines its instances) does not conflict, since it is
+// inherited from the other kind when they are merged (see mergeDeviceKindFields).
+func deviceKindConflicts(a, b *DeviceKind) []string {
+	var conflicts []string
+	aValue := reflect.ValueOf(a).Elem()
+	bValue := reflect.ValueOf(b).Elem()
+	for i := 0; i < aValue.NumField(); i++ {
+		field := aValue.Type().Field(i)
+		if field.Name == "Name" || field.Name == "Instances" {
+			continue
+		}
+		aField, bField := aValue.Field(i), bValue.Field(i)
+		if isEmptyValue(aField) || isEmptyValue(bField) {
+			continue
+		}
+		if !reflect.DeepEqual(aField.Interface(), bField.Interface()) {
+			conflicts = append(conflicts, strings.Split(field.Tag.Get("yaml"), ",")[0])
+		}
+	}
+	return conflicts
+}
+
+// isEmptyValue checks whether the value is the zero value for its type, or is an
+// empty map or slice.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
 // unifyDeviceConfigs will take a slice of ConfigContext which represents
 // DeviceConfigs and unify them into a single ConfigContext for a DeviceConfig.
 //
@@ -593,6 +825,7 @@ func unifyDeviceConfigs(ctxs []*ConfigContext) (*ConfigContext, error) {
 
 	log.Debugf("[sdk] unifying %d device configs", len(ctxs))
 
+	multiErr := errors.NewMultiError("device config unification")
 	var context *ConfigContext
 	for _, ctx := range ctxs {
 		if !ctx.IsDeviceConfig() {
@@ -611,19 +844,87 @@ func unifyDeviceConfigs(ctxs []*ConfigContext) (*ConfigContext, error) {
 			// Merge DeviceConfig.Devices - generally deviceKinds should not be defined in
 			// multiple files, but if doing dynamic registration, it likely will come in this
 			// way. as a result, we will need to merge instance/output data for device kinds with
-			// the same name..
-			// FIXME: without checking that the device kinds are actually the same, there
-			// there may be some dragons lurking here.
-			mergeDeviceKinds(&base.Devices, &source.Devices)
+			// the same name. Device kinds with the same name must otherwise be the same,
+			// so any conflicting fields are reported as errors.
+			mergeDeviceKinds(&base.Devices, &source.Devices, multiErr)
 		}
 	}
+	if multiErr.HasErrors() {
+		return nil, multiErr
+	}
 	return context, nil
 }
 
+// applyDeviceConfigMergeStrategy applies the merge strategy for device kinds which are
+// defined in both the device configs from file and from dynamic registration. With the
+// "dynamic-wins" strategy, those device kinds are removed from the file configs, and with
+// "file-wins", they are removed from the dynamic configs. With the "merge" strategy, the
+// configs are left as-is, so the device kinds are merged when the configs are unified.
+func applyDeviceConfigMergeStrategy(strategy string, fileCtxs, dynamicCtxs []*ConfigContext) {
+	switch strategy {
+	case mergeStrategyDynamicWins:
+		removeDeviceKinds(fileCtxs, deviceKindNames(dynamicCtxs))
+	case mergeStrategyFileWins:
+		removeDeviceKinds(dynamicCtxs, deviceKindNames(fileCtxs))
+	}
+}
+
+// deviceKindNames gets the names of all device kinds defined in the given device
+// config contexts.
+func deviceKindNames(ctxs []*ConfigContext) map[string]bool {
+	names := map[string]bool{}
+	for _, ctx := range ctxs {
+		for _, kind := range ctx.Config.(*DeviceConfig).Devices {
+			names[kind.Name] = true
+		}
+	}
+	return names
+}
+
+// removeDeviceKinds removes the device kinds with the given names from the given
+// device config contexts.
+func removeDeviceKinds(ctxs []*ConfigContext, names map[string]bool) {
+	for _, ctx := range ctxs {
+		cfg := ctx.Config.(*DeviceConfig)
+		var kinds []*DeviceKind
+		for _, kind := range cfg.Devices {
+			if names[kind.Name] {
+				log.WithFields(log.Fields{
+					"kind":   kind.Name,
+					"source": ctx.Source,
+				}).Debug("[sdk] device kind overridden by merge strategy")
+				continue
+			}
+			kinds = append(kinds, kind)
+		}
+		cfg.Devices = kinds
+	}
+}
+
+// mergeDeviceKindFields sets the fields of the base device kind, other than its
+// name and instances, which are empty in the base kind to those of the source kind.
+func mergeDeviceKindFields(base, source *DeviceKind) {
+	baseValue := reflect.ValueOf(base).Elem()
+	sourceValue := reflect.ValueOf(source).Elem()
+	for i := 0; i < baseValue.NumField(); i++ {
+		name := baseValue.Type().Field(i).Name
+		if name == "Name" || name == "Instances" {
+			continue
+		}
+		if isEmptyValue(baseValue.Field(i)) && !isEmptyValue(sourceValue.Field(i)) {
+			baseValue.Field(i).Set(sourceValue.Field(i))
+		}
+	}
+}
+
 // mergeDeviceKinds will add the device kinds from the `source` into the `base` if
 // a device kind with that name does not exist in the base, and will merge the device
-// kind fields if it does exist.
-func mergeDeviceKinds(base, source *[]*DeviceKind) {
+// kind instances if it does exist. Fields, other than the instances, which are only
+// set by one of the kinds are inherited by the merged kind. A device kind in the
+// source which sets fields to different values than the same-named kind in the base
+// is not merged; a conflict error describing the differing fields is added to the
+// given MultiError instead.
+func mergeDeviceKinds(base, source *[]*DeviceKind, multiErr *errors.MultiError) {
 	exists := map[string]*DeviceKind{}
 	for _, kind := range *base {
 		exists[kind.Name] = kind
@@ -634,8 +935,19 @@ func mergeDeviceKinds(base, source *[]*DeviceKind) {
 		if !found {
 			// If it is not found, add it to the base slice
 			*base = append(*base, kind)
+		} else if conflicts := deviceKindConflicts(k, kind); len(conflicts) > 0 {
+			// If the kinds differ, they can not be merged.
+			repeatedLog.WithFields(log.Fields{
+				"kind":   kind.Name,
+				"fields": conflicts,
+			}).Error("[sdk] conflicting device kind definitions")
+			multiErr.Add(errors.NewVerificationConflictError(
+				"device",
+				fmt.Sprintf("device kind %q is defined with conflicting fields: %s", kind.Name, strings.Join(conflicts, ", ")),
+			))
 		} else {
 			// Otherwise, just update the kind that is already in the base slice
+			mergeDeviceKindFields(k, kind)
 			k.Instances = append(k.Instances, kind.Instances...)
 		}
 	}
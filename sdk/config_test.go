@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -618,6 +619,133 @@ func TestUnifyDeviceConfigs2(t *testing.T) {
 	assert.Equal(t, 4, len(cfg.Locations))
 }
 
+// TestUnifyDeviceConfigs_MatchingKinds tests unifying configs which define device
+// kinds with the same name and the same fields, so their instances are merged.
+func TestUnifyDeviceConfigs_MatchingKinds(t *testing.T) {
+	ctx, err := unifyDeviceConfigs([]*ConfigContext{
+		NewConfigContext("first", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{{
+				Name:      "temperature",
+				Outputs:   []*DeviceOutput{{Type: "temperature"}},
+				Context:   map[string]string{"zone": "1"},
+				Instances: []*DeviceInstance{{Info: "first"}},
+			}},
+		}),
+		NewConfigContext("second", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{{
+				Name:      "temperature",
+				Outputs:   []*DeviceOutput{{Type: "temperature"}},
+				Context:   map[string]string{"zone": "1"},
+				Instances: []*DeviceInstance{{Info: "second"}},
+			}},
+		}),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"temperature": {"first", "second"},
+	}, instanceInfo(ctx.Config.(*DeviceConfig)))
+}
+
+// TestUnifyDeviceConfigs_ConflictingKinds tests unifying configs which define device
+// kinds with the same name, but different fields. Each conflict is reported.
+func TestUnifyDeviceConfigs_ConflictingKinds(t *testing.T) {
+	ctx, err := unifyDeviceConfigs([]*ConfigContext{
+		NewConfigContext("first", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{Name: "temperature", Outputs: []*DeviceOutput{{Type: "temperature"}}},
+				{Name: "humidity", Outputs: []*DeviceOutput{{Type: "humidity"}}, Access: "read-write"},
+				{Name: "led", Outputs: []*DeviceOutput{{Type: "led.state"}}},
+			},
+		}),
+		NewConfigContext("second", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{Name: "temperature", Outputs: []*DeviceOutput{{Type: "humidity"}}},
+				{Name: "humidity", Outputs: []*DeviceOutput{{Type: "humidity"}}, Access: "read-only"},
+				{Name: "led", Outputs: []*DeviceOutput{{Type: "led.state"}}},
+			},
+		}),
+	})
+
+	assert.Nil(t, ctx)
+	assert.Error(t, err)
+	merr, ok := err.(*errors.MultiError)
+	assert.True(t, ok)
+	assert.Len(t, merr.Errors, 2)
+	assert.Contains(t, merr.Errors[0].Error(), `device kind "temperature" is defined with conflicting fields: outputs`)
+	assert.Contains(t, merr.Errors[1].Error(), `device kind "humidity" is defined with conflicting fields: access`)
+}
+
+// Test_deviceKindConflicts tests getting the conflicting fields of device kinds.
+func Test_deviceKindConflicts(t *testing.T) {
+	a := &DeviceKind{
+		Name:      "foo",
+		Outputs:   []*DeviceOutput{{Type: "temperature"}},
+		Instances: []*DeviceInstance{{Info: "a"}},
+	}
+	b := &DeviceKind{
+		Name:      "foo",
+		Outputs:   []*DeviceOutput{{Type: "temperature"}},
+		Metadata:  map[string]string{},
+		Instances: []*DeviceInstance{{Info: "b"}},
+	}
+	assert.Empty(t, deviceKindConflicts(a, b))
+
+	// Fields set in only one of the kinds do not conflict.
+	b.HandlerName = "bar"
+	b.Metadata["model"] = "x"
+	assert.Empty(t, deviceKindConflicts(a, b))
+
+	a.HandlerName = "baz"
+	a.Metadata = map[string]string{"model": "y"}
+	assert.Equal(t, []string{"metadata", "handlerName"}, deviceKindConflicts(a, b))
+}
+
+// TestUnifyDeviceConfigs_PartialKind tests unifying a config with a device kind
+// which only defines its name and instances, as dynamic registration may. It
+// inherits the fields of the same-named kind, whichever config defines them.
+func TestUnifyDeviceConfigs_PartialKind(t *testing.T) {
+	ctx, err := unifyDeviceConfigs([]*ConfigContext{
+		NewConfigContext("file", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{
+					Name:      "temperature",
+					Metadata:  map[string]string{"model": "x"},
+					Outputs:   []*DeviceOutput{{Type: "temperature"}},
+					Instances: []*DeviceInstance{{Info: "file"}},
+				},
+				{Name: "humidity", Instances: []*DeviceInstance{{Info: "file"}}},
+			},
+		}),
+		NewConfigContext("dynamic", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{Name: "temperature", Instances: []*DeviceInstance{{Info: "dynamic"}}},
+				{
+					Name:      "humidity",
+					Outputs:   []*DeviceOutput{{Type: "humidity"}},
+					Instances: []*DeviceInstance{{Info: "dynamic"}},
+				},
+			},
+		}),
+	})
+
+	assert.NoError(t, err)
+	cfg := ctx.Config.(*DeviceConfig)
+	assert.Equal(t, map[string][]string{
+		"temperature": {"file", "dynamic"},
+		"humidity":    {"file", "dynamic"},
+	}, instanceInfo(cfg))
+	assert.Equal(t, map[string]string{"model": "x"}, cfg.Devices[0].Metadata)
+	assert.Equal(t, []*DeviceOutput{{Type: "temperature"}}, cfg.Devices[0].Outputs)
+	assert.Equal(t, []*DeviceOutput{{Type: "humidity"}}, cfg.Devices[1].Outputs)
+}
+
 // Test_processOutputTypeConfig_None_Optional tests getting output type config from file when
 // no files are found and the policy is optional.
 func Test_processOutputTypeConfig_None_Optional(t *testing.T) {
@@ -749,6 +877,158 @@ func Test_processOutputTypeConfig_withErrors2(t *testing.T) {
 	assert.Nil(t, outputs)
 }
 
+// setupDuplicateOutputTypeConfigs is a test helper which writes two output type
+// config files that both define the "temperature" output type, along with an
+// output type with a different name, and points the output type config env at them.
+func setupDuplicateOutputTypeConfigs(t *testing.T) {
+	test.SetupTestDir(t)
+	test.WriteTempFile(t, "a.yml", `
+version: 1.0
+name: temperature
+precision: 2
+unit:
+  name: celsius
+  symbol: C
+`, os.ModePerm)
+	test.WriteTempFile(t, "b.yml", `
+version: 1.0
+name: temperature
+scalingFactor: "0.1"
+`, os.ModePerm)
+	test.WriteTempFile(t, "c.yml", `
+version: 1.0
+name: humidity
+`, os.ModePerm)
+	test.SetEnv(t, EnvOutputTypeConfig, test.TempDir)
+}
+
+// Test_processOutputTypeConfig_Duplicate_Error tests getting output type configs from
+// file when multiple files define the same output type and the policy is to error.
+func Test_processOutputTypeConfig_Duplicate_Error(t *testing.T) {
+	setupDuplicateOutputTypeConfigs(t)
+	defer func() {
+		test.ClearTestDir(t)
+		test.RemoveEnv(t, EnvOutputTypeConfig)
+		resetContext()
+		policies.Clear()
+	}()
+
+	policies.Add(policies.TypeConfigDuplicateError)
+
+	outputs, err := processOutputTypeConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "output type 'temperature' defined differently in multiple config files")
+	assert.Nil(t, outputs)
+}
+
+// Test_processOutputTypeConfig_Duplicate_Identical tests getting output type configs
+// from file when multiple files define the same output type identically, which is
+// not a conflict.
+func Test_processOutputTypeConfig_Duplicate_Identical(t *testing.T) {
+	test.SetupTestDir(t)
+	defer func() {
+		test.ClearTestDir(t)
+		test.RemoveEnv(t, EnvOutputTypeConfig)
+		resetContext()
+		policies.Clear()
+	}()
+	for _, name := range []string{"a.yml", "b.yml"} {
+		test.WriteTempFile(t, name, `
+version: 1.0
+name: temperature
+precision: 2
+`, os.ModePerm)
+	}
+	test.SetEnv(t, EnvOutputTypeConfig, test.TempDir)
+
+	outputs, err := processOutputTypeConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(outputs))
+	assert.Equal(t, 2, outputs[0].Precision)
+}
+
+// Test_processOutputTypeConfig_Duplicate_Default tests getting output type configs from
+// file when multiple files define the same output type and no policy is set.
+func Test_processOutputTypeConfig_Duplicate_Default(t *testing.T) {
+	setupDuplicateOutputTypeConfigs(t)
+	defer func() {
+		test.ClearTestDir(t)
+		test.RemoveEnv(t, EnvOutputTypeConfig)
+		resetContext()
+		policies.Clear()
+	}()
+
+	outputs, err := processOutputTypeConfig()
+	assert.Error(t, err)
+	assert.Nil(t, outputs)
+}
+
+// Test_processOutputTypeConfig_Duplicate_LastWins tests getting output type configs from
+// file when multiple files define the same output type and the last one found is used.
+func Test_processOutputTypeConfig_Duplicate_LastWins(t *testing.T) {
+	setupDuplicateOutputTypeConfigs(t)
+	defer func() {
+		test.ClearTestDir(t)
+		test.RemoveEnv(t, EnvOutputTypeConfig)
+		resetContext()
+		policies.Clear()
+	}()
+
+	policies.Add(policies.TypeConfigDuplicateLastWins)
+
+	outputs, err := processOutputTypeConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(outputs))
+
+	assert.Equal(t, "temperature", outputs[0].Name)
+	assert.Equal(t, "0.1", outputs[0].ScalingFactor)
+	assert.Equal(t, 0, outputs[0].Precision)
+	assert.Equal(t, Unit{}, outputs[0].Unit)
+	assert.Equal(t, "humidity", outputs[1].Name)
+}
+
+// Test_processOutputTypeConfig_Duplicate_Merge tests getting output type configs from
+// file when multiple files define the same output type and they are merged.
+func Test_processOutputTypeConfig_Duplicate_Merge(t *testing.T) {
+	setupDuplicateOutputTypeConfigs(t)
+	defer func() {
+		test.ClearTestDir(t)
+		test.RemoveEnv(t, EnvOutputTypeConfig)
+		resetContext()
+		policies.Clear()
+	}()
+
+	policies.Add(policies.TypeConfigDuplicateMerge)
+
+	outputs, err := processOutputTypeConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(outputs))
+
+	assert.Equal(t, "temperature", outputs[0].Name)
+	assert.Equal(t, "0.1", outputs[0].ScalingFactor)
+	assert.Equal(t, 2, outputs[0].Precision)
+	assert.Equal(t, Unit{Name: "celsius", Symbol: "C"}, outputs[0].Unit)
+	assert.Equal(t, "humidity", outputs[1].Name)
+}
+
+// Test_mergeOutputTypes tests merging output types.
+func Test_mergeOutputTypes(t *testing.T) {
+	base := &OutputType{Name: "temperature", Precision: 2, Conversions: []string{"a"}}
+	override := &OutputType{Name: "temperature", Precision: 3, Scale: "m"}
+
+	merged := mergeOutputTypes(base, override)
+	assert.Equal(t, &OutputType{
+		Name:        "temperature",
+		Precision:   3,
+		Scale:       "m",
+		Conversions: []string{"a"},
+	}, merged)
+
+	// The merged output types are not modified.
+	assert.Equal(t, 2, base.Precision)
+	assert.Equal(t, "", base.Scale)
+}
+
 // Test_processPluginConfig_None_Optional tests getting plugin config from file when
 // no files are found and the policy is optional.
 func Test_processPluginConfig_None_Optional(t *testing.T) {
@@ -1420,3 +1700,296 @@ func Test_processDeviceConfigs_Dynamic_Multiple_Optional(t *testing.T) {
 	assert.NotNil(t, Config.Device)
 	assert.Equal(t, 2, len(Config.Device.Devices))
 }
+
+// slowDynamicDeviceConfigRegistrar is a test helper that returns a dynamic device
+// config registrar which returns a single device config, unless the registration
+// data has "slow" set, in which case it blocks until the release channel is closed.
+func slowDynamicDeviceConfigRegistrar(release chan struct{}) DynamicDeviceConfigRegistrar {
+	return func(data map[string]interface{}) ([]*DeviceConfig, error) {
+		if _, slow := data["slow"]; slow {
+			<-release
+			return nil, nil
+		}
+		return []*DeviceConfig{
+			{
+				SchemeVersion: SchemeVersion{Version: currentDeviceSchemeVersion},
+				Locations: []*LocationConfig{{
+					Name:  "foo",
+					Rack:  &LocationData{Name: "rack"},
+					Board: &LocationData{Name: "board"},
+				}},
+				Devices: []*DeviceKind{{
+					Name: "test",
+					Instances: []*DeviceInstance{{
+						Location: "foo",
+					}},
+				}},
+			},
+		}, nil
+	}
+}
+
+// Test_processDeviceConfigs_Dynamic_Timeout_Optional tests getting device config(s) from
+// dynamic registration when a registrar times out and the policy is optional.
+func Test_processDeviceConfigs_Dynamic_Timeout_Optional(t *testing.T) {
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config: []map[string]interface{}{
+				{},
+				{"slow": true},
+			},
+			Timeout: "10ms",
+		},
+	}
+
+	ctx.dynamicDeviceConfigRegistrar = slowDynamicDeviceConfigRegistrar(release)
+
+	policies.Add(policies.DeviceConfigFileOptional)
+	policies.Add(policies.DeviceConfigDynamicOptional)
+
+	assert.Nil(t, Config.Device)
+	err := processDeviceConfigs()
+	assert.NoError(t, err)
+	assert.NotNil(t, Config.Device)
+
+	// The config from the registrar which completed should still be used.
+	assert.Equal(t, 1, len(Config.Device.Devices))
+}
+
+// Test_processDeviceConfigs_Dynamic_Timeout_Required tests getting device config(s) from
+// dynamic registration when a registrar times out and the policy is required.
+func Test_processDeviceConfigs_Dynamic_Timeout_Required(t *testing.T) {
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config: []map[string]interface{}{
+				{},
+				{"slow": true},
+			},
+			Timeout: "10ms",
+		},
+	}
+
+	ctx.dynamicDeviceConfigRegistrar = slowDynamicDeviceConfigRegistrar(release)
+
+	policies.Add(policies.DeviceConfigFileOptional)
+	policies.Add(policies.DeviceConfigDynamicRequired)
+
+	assert.Nil(t, Config.Device)
+	err := processDeviceConfigs()
+	assert.Error(t, err)
+	assert.IsType(t, &errors.PolicyViolationError{}, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Nil(t, Config.Device)
+}
+
+// Test_processDeviceConfigs_Dynamic_NoTimeout tests getting device config(s) from
+// dynamic registration when the registrar completes within the timeout.
+func Test_processDeviceConfigs_Dynamic_NoTimeout(t *testing.T) {
+	defer func() {
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config: []map[string]interface{}{
+				{},
+			},
+			Timeout: "5s",
+		},
+	}
+
+	ctx.dynamicDeviceConfigRegistrar = slowDynamicDeviceConfigRegistrar(nil)
+
+	policies.Add(policies.DeviceConfigFileOptional)
+	policies.Add(policies.DeviceConfigDynamicRequired)
+
+	err := processDeviceConfigs()
+	assert.NoError(t, err)
+	assert.NotNil(t, Config.Device)
+	assert.Equal(t, 1, len(Config.Device.Devices))
+}
+
+// mergeStrategyTestConfigs creates file and dynamic device config contexts which both
+// define the "shared" device kind, for testing device config merge strategies.
+func mergeStrategyTestConfigs() (fileCtxs, dynamicCtxs []*ConfigContext) {
+	fileCtxs = []*ConfigContext{
+		NewConfigContext("file", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{Name: "shared", Instances: []*DeviceInstance{{Info: "file"}}},
+				{Name: "file-only", Instances: []*DeviceInstance{{Info: "file"}}},
+			},
+		}),
+	}
+	dynamicCtxs = []*ConfigContext{
+		NewConfigContext("dynamic registration", &DeviceConfig{
+			SchemeVersion: SchemeVersion{Version: "1.0"},
+			Devices: []*DeviceKind{
+				{Name: "shared", Instances: []*DeviceInstance{{Info: "dynamic"}}},
+				{Name: "dynamic-only", Instances: []*DeviceInstance{{Info: "dynamic"}}},
+			},
+		}),
+	}
+	return fileCtxs, dynamicCtxs
+}
+
+// instanceInfo gets the info of each instance of each device kind in a device config,
+// keyed by device kind name.
+func instanceInfo(cfg *DeviceConfig) map[string][]string {
+	info := map[string][]string{}
+	for _, kind := range cfg.Devices {
+		for _, instance := range kind.Instances {
+			info[kind.Name] = append(info[kind.Name], instance.Info)
+		}
+	}
+	return info
+}
+
+// Test_applyDeviceConfigMergeStrategy_Merge tests that with the "merge" strategy, the
+// instances of a device kind defined by both sources are merged.
+func Test_applyDeviceConfigMergeStrategy_Merge(t *testing.T) {
+	fileCtxs, dynamicCtxs := mergeStrategyTestConfigs()
+	applyDeviceConfigMergeStrategy("merge", fileCtxs, dynamicCtxs)
+
+	ctx, err := unifyDeviceConfigs(append(fileCtxs, dynamicCtxs...))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"shared":       {"file", "dynamic"},
+		"file-only":    {"file"},
+		"dynamic-only": {"dynamic"},
+	}, instanceInfo(ctx.Config.(*DeviceConfig)))
+}
+
+// Test_applyDeviceConfigMergeStrategy_DynamicWins tests that with the "dynamic-wins"
+// strategy, only the dynamic device kind is used when both sources define it.
+func Test_applyDeviceConfigMergeStrategy_DynamicWins(t *testing.T) {
+	fileCtxs, dynamicCtxs := mergeStrategyTestConfigs()
+	applyDeviceConfigMergeStrategy("dynamic-wins", fileCtxs, dynamicCtxs)
+
+	ctx, err := unifyDeviceConfigs(append(fileCtxs, dynamicCtxs...))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"shared":       {"dynamic"},
+		"file-only":    {"file"},
+		"dynamic-only": {"dynamic"},
+	}, instanceInfo(ctx.Config.(*DeviceConfig)))
+}
+
+// Test_applyDeviceConfigMergeStrategy_FileWins tests that with the "file-wins"
+// strategy, only the file device kind is used when both sources define it.
+func Test_applyDeviceConfigMergeStrategy_FileWins(t *testing.T) {
+	fileCtxs, dynamicCtxs := mergeStrategyTestConfigs()
+	applyDeviceConfigMergeStrategy("file-wins", fileCtxs, dynamicCtxs)
+
+	ctx, err := unifyDeviceConfigs(append(fileCtxs, dynamicCtxs...))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"shared":       {"file"},
+		"file-only":    {"file"},
+		"dynamic-only": {"dynamic"},
+	}, instanceInfo(ctx.Config.(*DeviceConfig)))
+}
+
+// TestMergeDeviceConfigs tests merging device configs, including the instances of
+// device kinds which are defined in more than one config.
+func TestMergeDeviceConfigs(t *testing.T) {
+	first := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{{Name: "foo"}},
+		Devices: []*DeviceKind{
+			{
+				Name:      "temperature",
+				Outputs:   []*DeviceOutput{{Type: "temperature"}},
+				Instances: []*DeviceInstance{{Info: "first"}},
+			},
+		},
+	}
+	second := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{{Name: "bar"}},
+		Devices: []*DeviceKind{
+			{
+				Name:      "temperature",
+				Outputs:   []*DeviceOutput{{Type: "temperature"}},
+				Metadata:  map[string]string{},
+				Instances: []*DeviceInstance{{Info: "second"}},
+			},
+			{
+				Name:      "humidity",
+				Instances: []*DeviceInstance{{Info: "second"}},
+			},
+		},
+	}
+
+	merged, err := MergeDeviceConfigs(first, second)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0", merged.Version)
+	assert.Len(t, merged.Locations, 2)
+	assert.Equal(t, map[string][]string{
+		"temperature": {"first", "second"},
+		"humidity":    {"second"},
+	}, instanceInfo(merged))
+
+	// The original configs are not modified.
+	assert.Len(t, first.Locations, 1)
+	assert.Len(t, first.Devices, 1)
+	assert.Len(t, first.Devices[0].Instances, 1)
+}
+
+// TestMergeDeviceConfigs_Conflict tests merging device configs which define device
+// kinds with the same name, but conflicting fields.
+func TestMergeDeviceConfigs_Conflict(t *testing.T) {
+	first := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Devices: []*DeviceKind{
+			{
+				Name:        "temperature",
+				Outputs:     []*DeviceOutput{{Type: "temperature"}},
+				HandlerName: "temp",
+			},
+		},
+	}
+	second := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Devices: []*DeviceKind{
+			{
+				Name:        "temperature",
+				Outputs:     []*DeviceOutput{{Type: "temprature"}},
+				HandlerName: "temp2",
+			},
+		},
+	}
+
+	merged, err := MergeDeviceConfigs(first, second)
+	assert.Nil(t, merged)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `device kind "temperature" is defined with conflicting fields: outputs, handlerName`)
+}
+
+// TestMergeDeviceConfigs_Error tests merging device configs when no configs, or a
+// nil config, are given.
+func TestMergeDeviceConfigs_Error(t *testing.T) {
+	_, err := MergeDeviceConfigs()
+	assert.Error(t, err)
+
+	_, err = MergeDeviceConfigs(&DeviceConfig{}, nil)
+	assert.EqualError(t, err, "device config 1 is nil")
+}
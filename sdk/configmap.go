@@ -0,0 +1,186 @@
+package sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// serviceAccountDir is the directory where Kubernetes mounts the credentials
+// for a pod's service account.
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// configMapGetter gets the data of a Kubernetes ConfigMap.
+type configMapGetter interface {
+	GetConfigMap(namespace, name string) (map[string]string, error)
+}
+
+// newConfigMapGetter creates the configMapGetter used to fetch device configs
+// from a ConfigMap. It is a variable so it can be replaced in tests.
+var newConfigMapGetter = newInClusterConfigMapGetter
+
+// inClusterConfigMapGetter gets ConfigMaps from the Kubernetes API server using
+// the pod's in-cluster credentials. It talks to the REST API directly so that
+// plugins do not need to depend on the Kubernetes client libraries.
+type inClusterConfigMapGetter struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// newInClusterConfigMapGetter creates a configMapGetter from the service account
+// credentials and API server address that Kubernetes provides to every pod.
+func newInClusterConfigMapGetter() (configMapGetter, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("unable to load in-cluster configuration: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set")
+	}
+
+	token, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse the service account CA certificate")
+	}
+
+	return &inClusterConfigMapGetter{
+		baseURL: "https://" + net.JoinHostPort(host, port),
+		token:   strings.TrimSpace(string(token)),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// GetConfigMap gets the data of the ConfigMap with the given namespace and name.
+func (getter *inClusterConfigMapGetter) GetConfigMap(namespace, name string) (map[string]string, error) {
+	endpoint := fmt.Sprintf(
+		"%s/api/v1/namespaces/%s/configmaps/%s",
+		getter.baseURL, url.PathEscape(namespace), url.PathEscape(name),
+	)
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if getter.token != "" {
+		req.Header.Set("Authorization", "Bearer "+getter.token)
+	}
+
+	resp, err := getter.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %s", namespace, name, resp.Status)
+	}
+
+	var configMap struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&configMap); err != nil {
+		return nil, err
+	}
+	return configMap.Data, nil
+}
+
+// parseConfigMapRef parses a ConfigMap reference of the form "namespace/name".
+func parseConfigMapRef(ref string) (namespace, name string, err error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid configmap reference %q: must be of the form namespace/name", ref)
+	}
+	return parts[0], parts[1], nil
+}
+
+// getConfigMapDeviceConfigs gets the device configs from the Kubernetes ConfigMap
+// with the given "namespace/name" reference. Each data key of the ConfigMap with
+// a supported config extension is read as a config stream, in key order. Only
+// device configs are loaded from the ConfigMap; any other config documents in it
+// are ignored.
+func getConfigMapDeviceConfigs(ref string) ([]*ConfigContext, error) {
+	namespace, name, err := parseConfigMapRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	getter, err := newConfigMapGetter()
+	if err != nil {
+		return nil, err
+	}
+	data, err := getter.GetConfigMap(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	source := fmt.Sprintf("configmap:%s/%s", namespace, name)
+
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cfgs []*ConfigContext
+	for _, key := range keys {
+		if !hasSupportedExt(key) {
+			log.WithFields(log.Fields{
+				"configmap": source,
+				"key":       key,
+			}).Debug("[sdk] skipping configmap key without a supported config extension")
+			continue
+		}
+
+		stream, err := readConfigStream(strings.NewReader(data[key]), source+"/"+key)
+		if err != nil {
+			return nil, err
+		}
+		if len(stream.outputTypes) > 0 || stream.plugin != nil {
+			log.WithFields(log.Fields{
+				"configmap": source,
+				"key":       key,
+			}).Warn("[sdk] ignoring non-device configs found in configmap")
+		}
+		cfgs = append(cfgs, stream.devices...)
+	}
+
+	if len(cfgs) == 0 {
+		return nil, errors.NewConfigsNotFoundError([]string{source})
+	}
+	return cfgs, nil
+}
+
+// hasSupportedExt checks whether the given name has a supported config file extension.
+func hasSupportedExt(name string) bool {
+	fileExt := filepath.Ext(name)
+	for _, ext := range supportedExts {
+		if fileExt == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+)
+
+// fakeConfigMapGetter is a configMapGetter which serves ConfigMaps from memory.
+type fakeConfigMapGetter struct {
+	configMaps map[string]map[string]string
+}
+
+func (getter *fakeConfigMapGetter) GetConfigMap(namespace, name string) (map[string]string, error) {
+	data, ok := getter.configMaps[namespace+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("configmaps %q not found", name)
+	}
+	return data, nil
+}
+
+// useFakeConfigMaps replaces the configMapGetter with a fake serving the given
+// ConfigMaps. It returns a function which restores the original.
+func useFakeConfigMaps(configMaps map[string]map[string]string) func() {
+	original := newConfigMapGetter
+	newConfigMapGetter = func() (configMapGetter, error) {
+		return &fakeConfigMapGetter{configMaps: configMaps}, nil
+	}
+	return func() { newConfigMapGetter = original }
+}
+
+// Test_parseConfigMapRef tests parsing ConfigMap references.
+func Test_parseConfigMapRef(t *testing.T) {
+	namespace, name, err := parseConfigMapRef("default/devices")
+	assert.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+	assert.Equal(t, "devices", name)
+
+	for _, ref := range []string{"devices", "/devices", "default/", "a/b/c"} {
+		_, _, err := parseConfigMapRef(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+// TestGetDeviceConfigsFromFile_ConfigMap tests getting the device configs from a ConfigMap.
+func TestGetDeviceConfigsFromFile_ConfigMap(t *testing.T) {
+	defer useFakeConfigMaps(map[string]map[string]string{
+		"synse/devices": {
+			"b.yaml": "version: 1.0\ndevices:\n- name: bar\n",
+			"a.yml":  "version: 1.0\ndevices:\n- name: foo\n---\nversion: 1.0\nname: baz\n",
+			"README": "not a config",
+		},
+	})()
+
+	test.SetEnv(t, EnvDeviceConfigMap, "synse/devices")
+	defer test.RemoveEnv(t, EnvDeviceConfigMap)
+
+	ctxs, err := getDeviceConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ctxs))
+
+	assert.Equal(t, "configmap:synse/devices/a.yml[0]", ctxs[0].Source)
+	assert.Equal(t, "foo", ctxs[0].Config.(*DeviceConfig).Devices[0].Name)
+	assert.Equal(t, "configmap:synse/devices/b.yaml[0]", ctxs[1].Source)
+	assert.Equal(t, "bar", ctxs[1].Config.(*DeviceConfig).Devices[0].Name)
+}
+
+// TestGetDeviceConfigsFromFile_ConfigMap_Error tests getting the device configs from
+// a ConfigMap when they cannot be loaded.
+func TestGetDeviceConfigsFromFile_ConfigMap_Error(t *testing.T) {
+	defer useFakeConfigMaps(map[string]map[string]string{
+		"synse/empty":   {"README": "not a config"},
+		"synse/invalid": {"devices.yml": "devices: ["},
+	})()
+	defer test.RemoveEnv(t, EnvDeviceConfigMap)
+
+	var testTable = []struct {
+		desc string
+		ref  string
+	}{
+		{"invalid reference", "devices"},
+		{"configmap not found", "synse/devices"},
+		{"no device configs", "synse/empty"},
+		{"invalid yaml", "synse/invalid"},
+	}
+
+	for _, testCase := range testTable {
+		test.SetEnv(t, EnvDeviceConfigMap, testCase.ref)
+		ctxs, err := getDeviceConfigsFromFile()
+		assert.Error(t, err, testCase.desc)
+		assert.Nil(t, ctxs, testCase.desc)
+	}
+}
+
+// TestInClusterConfigMapGetter_GetConfigMap tests getting a ConfigMap from the API server.
+func TestInClusterConfigMapGetter_GetConfigMap(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/synse/configmaps/devices" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"kind":"ConfigMap","data":{"devices.yml":"version: 1.0\n"}}`)
+	}))
+	defer server.Close()
+
+	getter := &inClusterConfigMapGetter{
+		baseURL: server.URL,
+		token:   "test-token",
+		client:  server.Client(),
+	}
+
+	data, err := getter.GetConfigMap("synse", "devices")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"devices.yml": "version: 1.0\n"}, data)
+
+	_, err = getter.GetConfigMap("synse", "other")
+	assert.Error(t, err)
+
+	getter.token = "wrong"
+	_, err = getter.GetConfigMap("synse", "devices")
+	assert.Error(t, err)
+}
+
+// Test_newInClusterConfigMapGetter_NotInCluster tests creating the in-cluster
+// configMapGetter when not running in a cluster.
+func Test_newInClusterConfigMapGetter_NotInCluster(t *testing.T) {
+	test.SetEnv(t, "KUBERNETES_SERVICE_HOST", "")
+	test.SetEnv(t, "KUBERNETES_SERVICE_PORT", "")
+	defer test.RemoveEnv(t, "KUBERNETES_SERVICE_HOST")
+	defer test.RemoveEnv(t, "KUBERNETES_SERVICE_PORT")
+
+	getter, err := newInClusterConfigMapGetter()
+	assert.Error(t, err)
+	assert.Nil(t, getter)
+}
@@ -1,5 +1,9 @@
 package sdk
 
+import (
+	"time"
+)
+
 var (
 	// sockPath is the base path for gRPC sockets.
 	// It's under /tmp rather than /var/run so that local tests pass.
@@ -12,4 +16,35 @@ const (
 
 	networkTypeTCP  = "tcp"
 	networkTypeUnix = "unix"
+
+	collisionsError  = "error"
+	collisionsSuffix = "suffix"
+
+	mergeStrategyMerge       = "merge"
+	mergeStrategyDynamicWins = "dynamic-wins"
+	mergeStrategyFileWins    = "file-wins"
+
+	calibrationExtrapolateLinear = "linear"
+	calibrationExtrapolateClamp  = "clamp"
+	calibrationExtrapolateError  = "error"
+
+	timestampTruncate = "truncate"
+	timestampRound    = "round"
+
+	deviceOrderID     = "id"
+	deviceOrderConfig = "config"
+
+	accessReadWrite = "read-write"
+	accessReadOnly  = "read-only"
+	accessWriteOnly = "write-only"
+
+	// defaultDrainTimeout is the default time to wait for in-flight RPCs to
+	// complete when the gRPC server is stopped.
+	defaultDrainTimeout = 10 * time.Second
+
+	// bindRetryInitialBackoff and bindRetryMaxBackoff are the initial and maximum
+	// times to wait between attempts to bind the gRPC server, if it is configured
+	// to retry binding. The wait doubles after each failed attempt.
+	bindRetryInitialBackoff = 100 * time.Millisecond
+	bindRetryMaxBackoff     = 5 * time.Second
 )
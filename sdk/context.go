@@ -1,6 +1,10 @@
 package sdk
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
 
 // ctx is the global context for the plugin. It stores various plugin settings,
 // data, and handler functions for customizable plugin functionality.
@@ -21,8 +25,30 @@ type PluginContext struct {
 	// and the value is the corresponding OutputType.
 	outputTypes map[string]*OutputType
 
-	// devices holds all of the known devices configured for the plugin.
-	devices map[string]*Device
+	// devices holds all of the known devices configured for the plugin. The
+	// map is accessed from many goroutines, so it should only be accessed via
+	// getDevices, getDevice, and updateDevices.
+	devices     map[string]*Device
+	devicesLock *sync.RWMutex
+
+	// conversions is a map where the key is the name of a conversion and the
+	// value is the corresponding Conversion. This includes the built-in conversions
+	// and any conversions registered by the plugin.
+	conversions map[string]Conversion
+
+	// conversionUnits maps the name of a conversion to the symbol of the unit it
+	// converts to, for conversions which declare their output unit. It is used to
+	// validate that output types are converted to the unit they declare.
+	conversionUnits map[string]string
+
+	// unitConversions is a map of the conversions used to normalize reading values
+	// from a source unit to the unit of their output type. This includes the built-in
+	// unit conversions and any unit conversions registered by the plugin.
+	unitConversions map[unitConversion]Conversion
+
+	// enumEncodings maps plugin-defined enum types to the encoding that should
+	// be used when encoding their values into readings.
+	enumEncodings map[reflect.Type]EnumEncoding
 
 	// deviceHandlers holds all of the DeviceHandlers that are registered with the plugin.
 	deviceHandlers []*DeviceHandler
@@ -35,10 +61,32 @@ type PluginContext struct {
 	// the plugin server and data manager.
 	postRunActions []pluginAction
 
+	// deviceConfigMigrations holds the migrations used to upgrade device configs
+	// from older scheme versions. The map key is the major version migrated from.
+	deviceConfigMigrations map[int]ConfigMigration
+
+	// pluginConfigMigrations holds the migrations used to upgrade plugin configs
+	// from older scheme versions. The map key is the major version migrated from.
+	pluginConfigMigrations map[int]ConfigMigration
+
+	// deviceConfigFieldAliases holds the deprecated aliases for device config
+	// fields. The first map key is the path of the mapping in the device config
+	// (e.g. "devices.instances"), and the second maps each alias to the current
+	// name of its field.
+	deviceConfigFieldAliases map[string]map[string]string
+
 	// deviceSetupActions holds all of the known device device setup actions to run
 	// prior to starting up the plugin server and data manager. The map key is the
 	// filter used to apply the deviceAction value to a Device instance.
 	deviceSetupActions map[string][]deviceAction
+
+	// instanceID is the ID of this plugin instance (replica). It is resolved when
+	// the plugin is set up and is added to the context of every reading.
+	instanceID string
+
+	// spanExporters holds the exporters which are given each trace span once it
+	// has ended.
+	spanExporters []SpanExporter
 }
 
 // checkDeviceHandlers checks that the registered device handlers do not have duplicate
@@ -62,9 +110,56 @@ func (ctx *PluginContext) checkDeviceHandlers() error {
 	return fmt.Errorf("[sdk] device handler names should be unique, but found duplicates: %v", duplicates)
 }
 
+// getDevices gets the map of all of the plugin's devices, keyed by device ID.
+// The map is never modified once it is set, since updateDevices replaces it as
+// a whole, so it can be iterated without holding a lock. It must not be modified.
+func (ctx *PluginContext) getDevices() map[string]*Device {
+	ctx.devicesLock.RLock()
+	defer ctx.devicesLock.RUnlock()
+	return ctx.devices
+}
+
+// getDevice gets the plugin's device with the given ID, or nil if there is none.
+func (ctx *PluginContext) getDevice(id string) *Device {
+	ctx.devicesLock.RLock()
+	defer ctx.devicesLock.RUnlock()
+	return ctx.devices[id]
+}
+
+// updateDevices updates the plugin's devices. The update function is given a
+// copy of the device map to modify; if it succeeds, the copy atomically replaces
+// the device map. Updates are serialized, so concurrent updates are not lost.
+func (ctx *PluginContext) updateDevices(update func(devices map[string]*Device) error) error {
+	ctx.devicesLock.Lock()
+	defer ctx.devicesLock.Unlock()
+
+	updated := make(map[string]*Device, len(ctx.devices))
+	for id, device := range ctx.devices {
+		updated[id] = device
+	}
+	if err := update(updated); err != nil {
+		return err
+	}
+	ctx.devices = updated
+	return nil
+}
+
 // newPluginContext creates a new instance of the plugin context, supplying the default
 // values for any context fields that have defaults.
 func newPluginContext() *PluginContext {
+	conversions := map[string]Conversion{}
+	for name, conversion := range builtinConversions {
+		conversions[name] = conversion
+	}
+	conversionUnits := map[string]string{}
+	for name, unit := range builtinConversionUnits {
+		conversionUnits[name] = unit
+	}
+	unitConversions := map[unitConversion]Conversion{}
+	for units, conversion := range builtinUnitConversions {
+		unitConversions[units] = conversion
+	}
+
 	return &PluginContext{
 		deviceIdentifier:             defaultDeviceIdentifier,
 		dynamicDeviceRegistrar:       defaultDynamicDeviceRegistration,
@@ -73,10 +168,20 @@ func newPluginContext() *PluginContext {
 
 		outputTypes:        map[string]*OutputType{},
 		devices:            map[string]*Device{},
+		devicesLock:        &sync.RWMutex{},
+		conversions:        conversions,
+		conversionUnits:    conversionUnits,
+		unitConversions:    unitConversions,
+		enumEncodings:      map[reflect.Type]EnumEncoding{},
 		deviceHandlers:     []*DeviceHandler{},
 		preRunActions:      []pluginAction{},
 		postRunActions:     []pluginAction{},
 		deviceSetupActions: map[string][]deviceAction{},
+
+		deviceConfigMigrations: map[int]ConfigMigration{},
+		pluginConfigMigrations: map[int]ConfigMigration{},
+
+		deviceConfigFieldAliases: map[string]map[string]string{},
 	}
 }
 
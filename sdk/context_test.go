@@ -1,6 +1,8 @@
 package sdk
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -71,3 +73,51 @@ func Test_checkDeviceHandlers4(t *testing.T) {
 	err := context.checkDeviceHandlers()
 	assert.Error(t, err)
 }
+
+// TestPluginContext_updateDevices tests that concurrent updates to the device map
+// are serialized, so no update is lost, and that readers are not affected.
+func TestPluginContext_updateDevices(t *testing.T) {
+	defer resetContext()
+
+	before := ctx.getDevices()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = ctx.updateDevices(func(devices map[string]*Device) error {
+				id := fmt.Sprintf("device-%d", i)
+				devices[id] = &Device{id: id}
+				return nil
+			})
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ctx.getDevices() {
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, ctx.getDevices(), 50)
+	assert.NotNil(t, ctx.getDevice("device-7"))
+	assert.Empty(t, before)
+}
+
+// TestPluginContext_updateDevices_Error tests that the device map is not changed
+// when an update fails.
+func TestPluginContext_updateDevices_Error(t *testing.T) {
+	defer resetContext()
+	ctx.devices["a"] = &Device{id: "a"}
+
+	err := ctx.updateDevices(func(devices map[string]*Device) error {
+		delete(devices, "a")
+		devices["b"] = &Device{id: "b"}
+		return fmt.Errorf("update failed")
+	})
+	assert.Error(t, err)
+	assert.NotNil(t, ctx.getDevice("a"))
+	assert.Nil(t, ctx.getDevice("b"))
+}
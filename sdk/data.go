@@ -0,0 +1,133 @@
+package sdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// secretRefPrefix is the prefix of a device config Data value which references
+// a secret, e.g. "secret:file:/run/secrets/password" or "secret:env:PASSWORD".
+const secretRefPrefix = "secret:"
+
+// isSecretRef checks whether the given device config Data value is a reference
+// to a secret.
+func isSecretRef(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, secretRefPrefix)
+}
+
+// resolveSecretRef resolves a secret reference to the value of the secret. A
+// "file" secret is the contents of the file, with any trailing newline removed.
+// An "env" secret is the value of the environment variable, which must be set.
+func resolveSecretRef(ref string) (string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(ref, secretRefPrefix), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", fmt.Errorf("invalid secret reference: must be of the form secret:file:<path> or secret:env:<name>")
+	}
+
+	source, location := parts[0], parts[1]
+	switch source {
+	case "file":
+		contents, err := ioutil.ReadFile(location)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %v", err)
+		}
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	case "env":
+		value, ok := os.LookupEnv(location)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %s is not set", location)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported secret source '%s': must be one of: file, env", source)
+	}
+}
+
+// GetData gets the value of a key in the device's config Data. If the value is
+// a secret reference, e.g. "secret:file:/run/secrets/password" or
+// "secret:env:PASSWORD", the secret is resolved and its value is returned.
+// Secrets are resolved each time they are accessed, and are never stored in the
+// device's Data, so they are not included in its JSON encoding.
+func (device *Device) GetData(key string) (interface{}, error) {
+	value, ok := device.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("no data found for key: %s", key)
+	}
+	if isSecretRef(value) {
+		secret, err := resolveSecretRef(value.(string))
+		if err != nil {
+			return nil, fmt.Errorf("data key %s: %v", key, err)
+		}
+		return secret, nil
+	}
+	return value, nil
+}
+
+// GetDataString gets the value of a key in the device's config Data as a string.
+// See GetData.
+func (device *Device) GetDataString(key string) (string, error) {
+	value, err := device.GetData(key)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("data key %s: value %v is not a string", key, value)
+	}
+	return s, nil
+}
+
+// GetDataInt gets the value of a key in the device's config Data as an int. The
+// value may be an integer or a string which parses as one. See GetData.
+func (device *Device) GetDataInt(key string) (int, error) {
+	value, err := device.GetData(key)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := value.(int); ok {
+		return i, nil
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(value)))
+	if err != nil {
+		return 0, fmt.Errorf("data key %s: value %v is not an integer", key, value)
+	}
+	return i, nil
+}
+
+// GetDataFloat64 gets the value of a key in the device's config Data as a float64.
+// The value may be a number or a string which parses as one. See GetData.
+func (device *Device) GetDataFloat64(key string) (float64, error) {
+	value, err := device.GetData(key)
+	if err != nil {
+		return 0, err
+	}
+	if s, ok := value.(string); ok {
+		value = strings.TrimSpace(s)
+	}
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		return 0, fmt.Errorf("data key %s: value %v is not a number", key, value)
+	}
+	return f, nil
+}
+
+// GetDataBool gets the value of a key in the device's config Data as a bool. The
+// value may be a bool or a string which parses as one. See GetData.
+func (device *Device) GetDataBool(key string) (bool, error) {
+	value, err := device.GetData(key)
+	if err != nil {
+		return false, err
+	}
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(fmt.Sprint(value)))
+	if err != nil {
+		return false, fmt.Errorf("data key %s: value %v is not a bool", key, value)
+	}
+	return b, nil
+}
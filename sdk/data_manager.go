@@ -3,7 +3,12 @@ package sdk
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -69,6 +74,11 @@ type dataManager struct {
 	// attempt to re-run the listener.
 	listenerRetry chan *ListenerCtx
 
+	// listening is set (to 1) once the listeners for the registered devices are
+	// started, so that listeners are started for any devices added afterwards.
+	// It is accessed atomically, while holding the device map lock.
+	listening int32
+
 	// readings is a map of readings, where the key is the GUID of a
 	// device, and the values are the readings associated with that device.
 	readings map[string][]*Reading
@@ -79,19 +89,135 @@ type dataManager struct {
 	// Lock around async reads and writes.
 	rwLock *sync.Mutex
 
+	// handlerLocks holds the locks used to serialize reads for DeviceHandlers
+	// which require it. The lock for a handler is created on first use.
+	handlerLocks map[*DeviceHandler]*sync.Mutex
+
+	// Lock around access/update of the `handlerLocks` map.
+	handlerLocksLock *sync.Mutex
+
+	// readingTypes tracks the (device, reading type) combinations that have
+	// been seen. In debug mode, the Go type of a reading value is logged the
+	// first time its combination is seen, to help with output type configuration.
+	readingTypes map[string]struct{}
+
+	// Lock around access/update of the `readingTypes` map.
+	readingTypesLock *sync.Mutex
+
+	// debounceStates tracks the stable value, and any pending change to it, of
+	// each boolean reading of the devices which debounce them. A device's state
+	// is keyed by "<device GUID>/<reading type>".
+	debounceStates map[string]*debounceState
+
+	// Lock around access/update of the `debounceStates` map.
+	debounceLock *sync.Mutex
+
+	// averages holds the current moving average of each numeric reading whose
+	// output type smooths it. The same reading type is averaged separately for
+	// each device, so the key includes both (see debounceStates).
+	averages map[string]float64
+
+	// Lock around access/update of the `averages` map.
+	averagesLock *sync.Mutex
+
+	// counters holds the last value, and its timestamp, of each counter reading
+	// which a rate is computed from. It is the baseline for the counter's next
+	// rate, and is keyed like the debounce states and moving averages.
+	counters map[string]counterState
+
+	// Lock around access/update of the `counters` map.
+	countersLock *sync.Mutex
+
+	// writeQueues holds the per-device write queues, keyed by device ID. These
+	// are only used when the write queue size is configured.
+	writeQueues map[string]*deviceWriteQueue
+
+	// Lock around access/update of the `writeQueues` map.
+	writeQueuesLock *sync.Mutex
+
+	// ranges holds the running minimum and maximum values of the numeric
+	// readings for each device, keyed by the device ID and then the reading type.
+	ranges map[string]map[string]*ReadingRange
+
+	// Lock around access/update of the `ranges` map.
+	rangesLock *sync.Mutex
+
+	// subscribers are the channels to which readings are published as they
+	// are gathered, e.g. for the synse.ReadingBatches service.
+	subscribers map[chan *ReadContext]struct{}
+
+	// Lock around access/update of the `subscribers` map.
+	subscribersLock *sync.Mutex
+
+	// writeWorkers limits the number of per-device write queues which fulfill
+	// writes at the same time. It is created along with the first write queue,
+	// and is only used when the number of write workers is configured.
+	writeWorkers chan struct{}
+
+	// readsPaused is set (to 1) while the read loop is paused. It is accessed
+	// atomically.
+	readsPaused int32
+
+	// clients is the number of gRPC clients connected to the plugin. It is
+	// accessed atomically.
+	clients int32
+
 	// limiter is a rate limiter for making requests. This is configured
 	// via the plugin config.
 	limiter *rate.Limiter
+
+	// recorder records the readings made by the plugin, when recording is
+	// enabled via the --record flag.
+	recorder *readingRecorder
+
+	// recording holds the recorded reads to replay in place of reading from
+	// devices, when replay is enabled via the --replay flag.
+	recording []*recordedRead
 }
 
 func newDataManager() *dataManager {
 	return &dataManager{
 		// Do not make the read/write channel. Those channels will be set up
 		// when the DataManger is initialized via `dataManager.init()`
-		readings: make(map[string][]*Reading),
-		dataLock: &sync.RWMutex{},
-		rwLock:   &sync.Mutex{},
+		readings:         make(map[string][]*Reading),
+		dataLock:         &sync.RWMutex{},
+		rwLock:           &sync.Mutex{},
+		handlerLocks:     make(map[*DeviceHandler]*sync.Mutex),
+		handlerLocksLock: &sync.Mutex{},
+		readingTypes:     make(map[string]struct{}),
+		readingTypesLock: &sync.Mutex{},
+		debounceStates:   make(map[string]*debounceState),
+		debounceLock:     &sync.Mutex{},
+		averages:         make(map[string]float64),
+		averagesLock:     &sync.Mutex{},
+		counters:         make(map[string]counterState),
+		countersLock:     &sync.Mutex{},
+		writeQueues:      make(map[string]*deviceWriteQueue),
+		writeQueuesLock:  &sync.Mutex{},
+		ranges:           make(map[string]map[string]*ReadingRange),
+		rangesLock:       &sync.Mutex{},
+		subscribers:      make(map[chan *ReadContext]struct{}),
+		subscribersLock:  &sync.Mutex{},
+	}
+}
+
+// lockHandlerReads acquires the read lock for the given DeviceHandler if the
+// handler serializes its reads. The returned function releases the lock.
+func (manager *dataManager) lockHandlerReads(handler *DeviceHandler) func() {
+	if handler == nil || !handler.SerializeReads {
+		return func() {}
+	}
+
+	manager.handlerLocksLock.Lock()
+	lock, exists := manager.handlerLocks[handler]
+	if !exists {
+		lock = &sync.Mutex{}
+		manager.handlerLocks[handler] = lock
 	}
+	manager.handlerLocksLock.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
 }
 
 // run sets up the dataManager and starts the read, write, and updater goroutines
@@ -103,9 +229,16 @@ func (manager *dataManager) run() error {
 		return err
 	}
 
-	// Start the listeners/reader/writer
-	manager.goListen()
-	manager.goRead()
+	// Start the listeners/reader/writer. When replaying recorded readings,
+	// the recording is replayed instead of listening to and reading from
+	// devices.
+	if manager.recording != nil {
+		log.WithField("speed", flagReplaySpeed).Info("[data manager] replaying recorded readings")
+		go manager.replay(manager.recording, flagReplaySpeed)
+	} else {
+		manager.goListen()
+		manager.goRead()
+	}
 	manager.goWrite()
 
 	// Update the manager readings state
@@ -114,6 +247,9 @@ func (manager *dataManager) run() error {
 	// Watch for failed listeners to retry them
 	go manager.watchForListenerRetry()
 
+	// Watch for devices which stop producing readings
+	manager.goWatchdog()
+
 	log.Info("[data manager] running")
 	return nil
 }
@@ -140,9 +276,41 @@ func (manager *dataManager) setup() error {
 			Config.Plugin.Limiter.Burst,
 		)
 	}
+
+	// Load the recording to replay, if set.
+	if flagReplay != "" {
+		if flagReplaySpeed <= 0 {
+			return fmt.Errorf("replay speed must be greater than 0, got %v", flagReplaySpeed)
+		}
+		recording, err := loadRecording(flagReplay)
+		if err != nil {
+			return fmt.Errorf("failed to load recording: %v", err)
+		}
+		manager.recording = recording
+	}
+
+	// Start recording readings, if set.
+	if flagRecord != "" {
+		recorder, err := newReadingRecorder(flagRecord)
+		if err != nil {
+			return fmt.Errorf("failed to create recording: %v", err)
+		}
+		manager.recorder = recorder
+	}
 	return nil
 }
 
+// stopRecording stops recording readings, if the plugin is recording them, and
+// closes the recording file.
+func (manager *dataManager) stopRecording() {
+	if manager.recorder == nil {
+		return
+	}
+	if err := manager.recorder.close(); err != nil {
+		log.WithField("error", err).Error("[data manager] failed to close recording")
+	}
+}
+
 // writesEnabled checks to see whether writing is enabled for the plugin based on
 // the configuration.
 func (manager *dataManager) writesEnabled() bool {
@@ -162,26 +330,40 @@ func (manager *dataManager) goListen() {
 		return
 	}
 
-	// For each handler that has a listener function defined, get the devices for
-	// that handler and start the listener for the devices.
+	// Start the listeners for the registered devices. This holds the device map
+	// lock while marking the listeners as started, so that any device which is
+	// added concurrently either is in the devices here or has its listener started
+	// when it is added (see startListener).
+	ctx.devicesLock.RLock()
+	atomic.StoreInt32(&manager.listening, 1)
+	devices := ctx.devices
+	ctx.devicesLock.RUnlock()
+
 	for _, handler := range ctx.deviceHandlers {
-		hlog := log.WithField("handler", handler.Name)
 		if handler.Listen != nil {
-			hlog.Info("[data manager] setting up listeners")
+			log.WithField("handler", handler.Name).Info("[data manager] setting up listeners")
+		}
+	}
+	for _, device := range devices {
+		manager.startListener(device)
+	}
+}
 
-			// Get all of the devices that have registered with the handler
-			devices := handler.getDevicesForHandler()
-			if len(devices) == 0 {
-				hlog.Debugf("[data manager] found no devices for handler")
-				continue
-			}
+// startListener starts the listener goroutine for a device, if its handler has
+// a listener function. Write-only devices are not listened to.
+func (manager *dataManager) startListener(device *Device) {
+	if device.Handler == nil || device.Handler.Listen == nil || !device.IsReadable() {
+		return
+	}
+	go manager.runListener(NewListenerCtx(device.Handler, device))
+}
 
-			// For each device, run the listener goroutine
-			for _, device := range devices {
-				ctx := NewListenerCtx(handler, device)
-				go manager.runListener(ctx)
-			}
-		}
+// startAddedListener starts the listener for a device which is added to the
+// plugin while it is running, if the listeners for the registered devices have
+// already been started. It must be called while holding the device map lock.
+func (manager *dataManager) startAddedListener(device *Device) {
+	if atomic.LoadInt32(&manager.listening) == 1 {
+		manager.startListener(device)
 	}
 }
 
@@ -232,6 +414,21 @@ func (manager *dataManager) goRead() {
 		return
 	}
 
+	// If configured, perform an initial read of all devices before starting the
+	// read loop, so readings are available as soon as the plugin is running.
+	initialRead := Config.Plugin.Settings.Read.InitialRead
+	if initialRead {
+		readLog.Info("[data manager] performing initial read")
+		if err := manager.initialRead(mode); err != nil {
+			readLog.WithField("error", err).Error("[data manager] failed initial read")
+			return
+		}
+	}
+
+	// The devices are polled even if the plugin is stateless. Their readings
+	// are not retained, but they are still published to the subscribers (see
+	// the synse.ReadingBatches service), and they drive the staleness watchdog,
+	// device quarantine, and reading recording.
 	readLog.Info("[data manager] starting read goroutine (reads enabled)")
 	go func() {
 		interval, err := Config.Plugin.Settings.Read.GetInterval()
@@ -239,43 +436,137 @@ func (manager *dataManager) goRead() {
 			readLog.WithField("error", err).
 				Warn("[data manager] misconfiguration: failed to get read interval")
 		}
+
+		// If the initial read was done, wait for the interval before
+		// reading again. If reads are aligned to the interval, the first
+		// read should also wait for the next interval boundary.
+		align := Config.Plugin.Settings.Read.AlignInterval
+		if initialRead || align {
+			clock.Sleep(readDelay(clock.Now(), interval, align))
+		}
 		for {
+			start := clock.Now()
+
 			// Perform the reads. This is done in a separate function
 			// to allow for cleaner lock/unlock semantics.
-			log.Infof("Starting reads in mode %v", mode)
-			switch mode {
-			case "serial":
-				// Get device readings in serial
-				serialReadInterval, err := Config.Plugin.Settings.Read.GetSerialReadInterval()
-				if err != nil {
-					readLog.WithField("error", err).
-						Warn("[data manager] misconfiguration: failed to get serial read interval")
+			if manager.readsArePaused() {
+				log.Debug("[data manager] reads paused, skipping reads")
+			} else {
+				log.Infof("Starting reads in mode %v", mode)
+				if err := manager.readAll(mode); err != nil {
+					readLog.WithField("error", err).Error("[data manager] exiting read loop")
+					return
 				}
-				manager.serialRead(serialReadInterval)
-			case "parallel":
-				// Get device readings in parallel
-				manager.parallelRead()
-			default:
-				readLog.Error("[data manager] exiting read loop: unsupported plugin run mode")
-				return
+				log.Infof("Completed reads in mode %v", mode)
 			}
 
-			log.Infof("Completed reads in mode %v", mode)
 			log.Infof("Sleeping for interval %v", interval)
-			time.Sleep(interval)
+			clock.Sleep(readDelay(clock.Now(), interval, align))
+			manager.waitWhileIdle(start, interval, align)
 			log.Infof("Slept for interval %v", interval)
 		}
 	}()
 }
 
+// pauseReads sets whether the read loop is paused.
+func (manager *dataManager) pauseReads(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&manager.readsPaused, v)
+}
+
+// readsArePaused checks whether the read loop is paused.
+func (manager *dataManager) readsArePaused() bool {
+	return atomic.LoadInt32(&manager.readsPaused) == 1
+}
+
+// readDelay gets the duration to wait before the next read. If the reads are
+// aligned, this is the time until the next wall-clock boundary of the interval,
+// otherwise it is the interval itself.
+func readDelay(now time.Time, interval time.Duration, align bool) time.Duration {
+	if !align || interval <= 0 {
+		return interval
+	}
+	return now.Truncate(interval).Add(interval).Sub(now)
+}
+
+// waitForPhase waits until the given phase offset from the start of a read
+// cycle, if that time has not already passed.
+func waitForPhase(start time.Time, offset time.Duration) {
+	if offset <= 0 {
+		return
+	}
+	if delay := start.Add(offset).Sub(clock.Now()); delay > 0 {
+		clock.Sleep(delay)
+	}
+}
+
+// readAll reads all devices configured with the Plugin using the given run mode.
+func (manager *dataManager) readAll(mode string) error {
+	switch mode {
+	case "serial":
+		// Get device readings in serial
+		serialReadInterval, err := Config.Plugin.Settings.Read.GetSerialReadInterval()
+		if err != nil {
+			log.WithField("error", err).
+				Warn("[data manager] misconfiguration: failed to get serial read interval")
+		}
+		manager.serialRead(serialReadInterval)
+	case "parallel":
+		// Get device readings in parallel
+		manager.parallelRead()
+	default:
+		return fmt.Errorf("unsupported plugin run mode: %s", mode)
+	}
+	return nil
+}
+
+// initialRead reads all devices configured with the Plugin and updates the
+// readings state with the results before returning. Unlike the read loop,
+// which passes readings to the updater goroutine, this consumes the readings
+// directly so that they are available as soon as it returns.
+func (manager *dataManager) initialRead(mode string) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- manager.readAll(mode)
+		close(errs)
+	}()
+
+	for {
+		select {
+		case reading := <-manager.readChannel:
+			manager.updateReadings(reading)
+		case err := <-errs:
+			// All reads have completed, so any remaining readings are
+			// already buffered in the channel.
+			for {
+				select {
+				case reading := <-manager.readChannel:
+					manager.updateReadings(reading)
+				default:
+					return err
+				}
+			}
+		}
+	}
+}
+
 // readOne implements the logic for reading from an individual device that is
 // configured with the Plugin.
-func (manager *dataManager) readOne(device *Device) {
+func (manager *dataManager) readOne(cycleCtx context.Context, device *Device) {
+	// Devices are only polled within their active hours.
+	if !device.IsActive() {
+		log.WithField("device", device.GUID()).Debug("[data manager] skipping read of inactive device")
+		return
+	}
+
 	// Rate limiting, if configured
 	if manager.limiter != nil {
 		err := manager.limiter.Wait(context.Background())
 		if err != nil {
-			log.Errorf("[data manager] error from limiter when reading %v: %v", device.GUID(), err)
+			repeatedLog.Errorf("[data manager] error from limiter when reading %v: %v", device.GUID(), err)
 		}
 	}
 
@@ -283,16 +574,27 @@ func (manager *dataManager) readOne(device *Device) {
 	// then it is read individually. If a device is read in bulk, it will
 	// not be read here; it will be read via the readBulk function.
 	if !device.bulkRead {
-		resp, err := device.Read()
+		readCtx, span := StartSpan(cycleCtx, "device read")
+		span.SetAttribute("device", device.GUID())
+		defer span.Finish()
+
+		unlock := manager.lockHandlerReads(device.Handler)
+		resp, err := device.ReadWithContext(readCtx)
+		unlock()
 		if err != nil {
 			// Check to see if the error is that of unsupported error. If it is, we
 			// do not want to log out here (low-interval read polling would cause this
 			// to pollute the logs for something that we should already know).
 			_, unsupported := err.(*errors.UnsupportedCommandError)
 			if !unsupported {
-				log.Errorf("[data manager] failed to read from device %v: %v", device.GUID(), err)
+				metrics.recordRead(err)
+				repeatedLog.Errorf("[data manager] failed to read from device %v: %v", device.GUID(), err)
+				if errorReadingsEnabled() {
+					manager.readChannel <- newErrorReadContext(device, err)
+				}
 			}
 		} else {
+			metrics.recordRead(nil)
 			manager.readChannel <- resp
 		}
 	}
@@ -301,33 +603,93 @@ func (manager *dataManager) readOne(device *Device) {
 // readBulk will execute bulk reads on all device handlers that support
 // bulk reading. If a handler does not support bulk reading, it's devices
 // will be read individually via readOne instead.
-func (manager *dataManager) readBulk(handler *DeviceHandler) {
+func (manager *dataManager) readBulk(cycleCtx context.Context, handler *DeviceHandler) {
 	// Rate limiting, if configured
 	if manager.limiter != nil {
 		err := manager.limiter.Wait(context.Background())
 		if err != nil {
-			log.Errorf("[data manager] error from limiter when bulk reading with handler for %v: %v", handler.Name, err)
+			repeatedLog.Errorf("[data manager] error from limiter when bulk reading with handler for %v: %v", handler.Name, err)
 		}
 	}
 
 	// If the handler supports bulk read, execute bulk read. Otherwise,
 	// do nothing. Individual reads are done via the readOne function.
 	if handler.supportsBulkRead() {
-		devices := handler.getDevicesForHandler()
+		// Devices are only polled within their active hours.
+		var devices []*Device
+		for _, device := range handler.getDevicesForHandler() {
+			if device.IsActive() && device.IsReadable() {
+				devices = append(devices, device)
+			}
+		}
 		if len(devices) == 0 {
 			return
 		}
+		_, span := StartSpan(cycleCtx, "bulk read")
+		span.SetAttribute("handler", handler.Name)
+		defer span.Finish()
+
+		unlock := manager.lockHandlerReads(handler)
 		resp, err := handler.BulkRead(devices)
+		unlock()
+		if err == nil {
+			resp, err = filterReadContexts(handler, devices, resp)
+		}
+		metrics.recordRead(err)
 		if err != nil {
-			log.Errorf("[data manager] failed to bulk read from device handler for: %v: %v", handler.Name, err)
+			repeatedLog.Errorf("[data manager] failed to bulk read from device handler for: %v: %v", handler.Name, err)
+			if errorReadingsEnabled() {
+				for _, device := range devices {
+					manager.readChannel <- newErrorReadContext(device, err)
+				}
+			}
 		} else {
-			for _, readCtx := range resp {
+			for _, readCtx := range manager.checkBulkEmptyReadings(handler, devices, resp) {
 				manager.readChannel <- readCtx
 			}
 		}
 	}
 }
 
+// checkBulkEmptyReadings applies the handler's EmptyReadings policy to the devices
+// of a bulk read which got no readings, whether the handler returned an empty
+// ReadContext for them or none at all. The devices for which the policy treats
+// this as an error are handled as failed reads, and their ReadContexts are removed
+// from the response, which is returned.
+func (manager *dataManager) checkBulkEmptyReadings(handler *DeviceHandler, devices []*Device, resp []*ReadContext) []*ReadContext {
+	read := make(map[string]struct{}, len(resp))
+	for _, readCtx := range resp {
+		if len(readCtx.Reading) > 0 {
+			read[readCtx.ID()] = struct{}{}
+		}
+	}
+
+	failed := map[string]struct{}{}
+	for _, device := range devices {
+		if _, ok := read[device.GUID()]; ok {
+			continue
+		}
+		if err := handler.checkEmptyReadings(device); err != nil {
+			failed[device.GUID()] = struct{}{}
+			repeatedLog.Errorf("[data manager] failed to bulk read from device %v: %v", device.GUID(), err)
+			if errorReadingsEnabled() {
+				manager.readChannel <- newErrorReadContext(device, err)
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return resp
+	}
+
+	var checked []*ReadContext
+	for _, readCtx := range resp {
+		if _, ok := failed[readCtx.ID()]; !ok {
+			checked = append(checked, readCtx)
+		}
+	}
+	return checked
+}
+
 // serialRead reads all devices configured with the Plugin in serial.
 func (manager *dataManager) serialRead(serialReadInterval time.Duration) {
 	// If the plugin is a serial plugin, we want to lock around reads
@@ -335,30 +697,55 @@ func (manager *dataManager) serialRead(serialReadInterval time.Duration) {
 	manager.rwLock.Lock()
 	defer manager.rwLock.Unlock()
 
-	log.Infof("Starting serial read of %v devices", len(ctx.devices))
-	for _, dev := range ctx.devices {
-		manager.readOne(dev)
+	// Each read cycle is traced, with a child span for each device read.
+	cycleCtx, span := StartSpan(context.Background(), "read cycle")
+	span.SetAttribute("mode", modeSerial)
+	defer span.Finish()
+
+	// Devices are read in order of their phase offsets, each waiting until its
+	// offset into the read cycle has elapsed.
+	start := clock.Now()
+	devices := make([]*Device, 0, len(ctx.getDevices()))
+	for _, dev := range ctx.getDevices() {
+		devices = append(devices, dev)
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].PhaseOffset < devices[j].PhaseOffset
+	})
+
+	log.Infof("Starting serial read of %v devices", len(ctx.getDevices()))
+	for _, dev := range devices {
+		waitForPhase(start, dev.PhaseOffset)
+		manager.readOne(cycleCtx, dev)
 		log.Infof("Sleeping after read %v", serialReadInterval)
-		time.Sleep(serialReadInterval)
+		clock.Sleep(serialReadInterval)
 	}
-	log.Infof("Completed serial read of %v devices", len(ctx.devices))
+	log.Infof("Completed serial read of %v devices", len(ctx.getDevices()))
 
 	for _, handler := range ctx.deviceHandlers {
-		manager.readBulk(handler)
+		manager.readBulk(cycleCtx, handler)
 	}
 }
 
 // parallelRead reads all devices configured with the Plugin in parallel.
 func (manager *dataManager) parallelRead() {
 	var waitGroup sync.WaitGroup
+	start := clock.Now()
+
+	// Each read cycle is traced, with a child span for each device read.
+	cycleCtx, span := StartSpan(context.Background(), "read cycle")
+	span.SetAttribute("mode", modeParallel)
+	defer span.Finish()
 
-	for _, dev := range ctx.devices {
+	for _, dev := range ctx.getDevices() {
 		// Increment the WaitGroup counter.
 		waitGroup.Add(1)
 
-		// Launch a goroutine to read from the device
+		// Launch a goroutine to read from the device once its phase
+		// offset into the read cycle has elapsed
 		go func(wg *sync.WaitGroup, device *Device) {
-			manager.readOne(device)
+			waitForPhase(start, device.PhaseOffset)
+			manager.readOne(cycleCtx, device)
 			wg.Done()
 		}(&waitGroup, dev)
 	}
@@ -369,7 +756,7 @@ func (manager *dataManager) parallelRead() {
 
 		// Launch a goroutine to bulk read from the handler
 		go func(wg *sync.WaitGroup, handler *DeviceHandler) {
-			manager.readBulk(handler)
+			manager.readBulk(cycleCtx, handler)
 			wg.Done()
 		}(&waitGroup, handler)
 	}
@@ -411,7 +798,7 @@ func (manager *dataManager) goWrite() {
 				return
 			}
 
-			time.Sleep(interval)
+			clock.Sleep(interval)
 		}
 	}()
 }
@@ -481,15 +868,29 @@ func (manager *dataManager) write(w *WriteContext) {
 	}).Debug("[data manager] fulfilling write transaction")
 	w.transaction.setStatusWriting()
 
-	device := ctx.devices[w.ID()]
+	device := ctx.getDevice(w.ID())
 	if device == nil {
-		w.transaction.setStateError()
-		msg := "no device found with ID " + w.ID()
-		w.transaction.message = msg
-		log.Error(msg)
+		// If there is no device with the ID, the write may be for a
+		// transaction group.
+		if members := getGroupMembers(w.rack, w.board, w.device); len(members) > 0 {
+			manager.writeGroup(w, members)
+		} else {
+			w.transaction.setStateError()
+			msg := "no device found with ID " + w.ID()
+			w.transaction.message = msg
+			log.Error(msg)
+		}
 	} else {
+		// Trace the write. If the write request carried a trace, the span
+		// is part of that trace.
+		writeCtx, span := StartSpan(w.traceCtx, "device write")
+		span.SetAttribute("device", w.ID())
+		span.SetAttribute("transaction", w.transaction.id)
+
 		data := decodeWriteData(w.data)
-		err := device.Write(data)
+		err := device.WriteWithContext(writeCtx, data)
+		span.Finish()
+		metrics.recordWrite(err)
 		if err != nil {
 			w.transaction.setStateError()
 			w.transaction.message = err.Error()
@@ -504,31 +905,496 @@ func (manager *dataManager) write(w *WriteContext) {
 func (manager *dataManager) goUpdateData() {
 	go func() {
 		for {
-			var (
-				id       string
-				readings []*Reading
-			)
-
 			// Read from the listen and read channel for incoming readings
 			var reading *ReadContext
 			select {
 			case reading = <-manager.readChannel:
-				id = reading.ID()
-				readings = reading.Reading
 			case reading = <-manager.listenChannel:
-				id = reading.ID()
-				readings = reading.Reading
+				reading = manager.checkListenedReadings(reading)
+				if reading == nil {
+					continue
+				}
 			}
+			manager.updateReadings(reading)
+		}
+	}()
+}
 
-			// Update the internal map of current reading state
-			manager.dataLock.Lock()
-			manager.readings[id] = readings
-			manager.dataLock.Unlock()
+// checkListenedReadings applies the EmptyReadings policy of the device's handler
+// to readings pushed by a listener with no readings. If the policy treats this as
+// an error, it is handled as a failed read: an error ReadContext is returned if
+// error readings are enabled, otherwise nil is returned and the readings should
+// be dropped.
+func (manager *dataManager) checkListenedReadings(reading *ReadContext) *ReadContext {
+	if len(reading.Reading) > 0 {
+		return reading
+	}
+	device := ctx.getDevice(reading.ID())
+	if device == nil || device.Handler == nil {
+		return reading
+	}
+	if err := device.Handler.checkEmptyReadings(device); err != nil {
+		repeatedLog.Errorf("[data manager] failed to listen to device %v: %v", device.GUID(), err)
+		if errorReadingsEnabled() {
+			return newErrorReadContext(device, err)
+		}
+		return nil
+	}
+	return reading
+}
 
-			// update the readings cache
-			addReadingToCache(reading)
+// updateReadings updates the current reading state and the readings cache with
+// the readings from the given ReadContext. This is safe to call from multiple
+// goroutines.
+func (manager *dataManager) updateReadings(reading *ReadContext) {
+	// Record the readings as they were made, before any are dropped below.
+	if manager.recorder != nil {
+		if err := manager.recorder.record(reading); err != nil {
+			repeatedLog.Errorf("[data manager] failed to record readings for %v: %v", reading.ID(), err)
 		}
-	}()
+	}
+
+	// Drop the readings if their device is quarantined, clearing its
+	// current reading state so stale readings are not emitted
+	if quarantineReadings(reading) {
+		manager.dataLock.Lock()
+		delete(manager.readings, reading.ID())
+		manager.dataLock.Unlock()
+		return
+	}
+
+	// Record that the device produced readings, for the staleness watchdog
+	watchReadings(reading)
+
+	// If the device debounces its boolean readings, report the previous state
+	// of a reading until a change to it has been stable for long enough.
+	if device := ctx.getDevice(reading.ID()); device != nil && device.Debounce > 0 {
+		reading = &ReadContext{
+			Rack:    reading.Rack,
+			Board:   reading.Board,
+			Device:  reading.Device,
+			Reading: manager.debounceReadings(reading.ID(), device.Debounce, reading.Reading),
+		}
+	}
+
+	// Compute rates from the counter readings for output types configured to
+	// do so, then smooth the numeric readings for output types configured to.
+	// If none of the readings give a rate yet, the current reading state is kept.
+	if device := ctx.getDevice(reading.ID()); device != nil {
+		rated := manager.rateReadings(device, reading.Reading)
+		if len(rated) == 0 && len(reading.Reading) > 0 {
+			return
+		}
+		reading = &ReadContext{
+			Rack:    reading.Rack,
+			Board:   reading.Board,
+			Device:  reading.Device,
+			Reading: rated,
+		}
+		manager.smoothReadings(device, reading.Reading)
+	}
+
+	// If configured, suppress readings which duplicate the device's most
+	// recent readings. The current reading state is updated with the full set
+	// of readings, but only new readings are added to the cache and history.
+	newReadings := reading
+	if readingsDeduplicated() {
+		unique := manager.uniqueReadings(reading)
+		if len(unique) == 0 {
+			return
+		}
+		if len(unique) < len(reading.Reading) {
+			newReadings = &ReadContext{
+				Rack:    reading.Rack,
+				Board:   reading.Board,
+				Device:  reading.Device,
+				Reading: unique,
+			}
+		}
+	}
+
+	// Track the running minimum and maximum of the numeric readings
+	manager.trackReadingRanges(reading.ID(), reading.Reading)
+
+	// Add the device's static context to the readings
+	if device := ctx.getDevice(reading.ID()); device != nil {
+		device.mergeContext(reading.Reading)
+	}
+
+	// Tag the readings with the plugin instance ID
+	if ctx.instanceID != "" {
+		addInstanceContext(reading.Reading)
+	}
+
+	// In debug mode, log the value types of new readings
+	if Config.Plugin != nil && Config.Plugin.Debug {
+		manager.logReadingTypes(reading)
+	}
+
+	// A stateless plugin does not retain any readings, but they are still
+	// published, so they flow to the subscribers without retention.
+	if readingsStateless() {
+		manager.publishReadings(newReadings)
+		return
+	}
+
+	// Update the internal map of current reading state
+	manager.dataLock.Lock()
+	manager.readings[reading.ID()] = reading.Reading
+	manager.dataLock.Unlock()
+
+	// update the readings cache
+	addReadingToCache(newReadings)
+
+	// update the readings history
+	addReadingToHistory(newReadings)
+
+	// publish the new readings to any subscribers
+	manager.publishReadings(newReadings)
+}
+
+// debounceState is the debounce state of a boolean reading for a device.
+type debounceState struct {
+	// stable is the state which is reported for the reading.
+	stable bool
+
+	// pending is whether a change from the stable state has been seen and
+	// is waiting to become stable.
+	pending bool
+
+	// since is the time at which the pending change was first seen.
+	since time.Time
+}
+
+// debounceReadings debounces the boolean readings for a device. A change to the
+// state of a boolean reading is only reported once every reading of that type has
+// had the new state for the given duration; until then, the reading is reported
+// with its previous stable state. Readings with non-boolean values are unchanged.
+func (manager *dataManager) debounceReadings(device string, window time.Duration, readings []*Reading) []*Reading {
+	manager.debounceLock.Lock()
+	defer manager.debounceLock.Unlock()
+
+	now := clock.Now()
+	debounced := make([]*Reading, len(readings))
+	for i, reading := range readings {
+		debounced[i] = reading
+
+		value, ok := reading.Value.(bool)
+		if !ok {
+			continue
+		}
+
+		key := device + "/" + reading.Type
+		state, exists := manager.debounceStates[key]
+		if !exists {
+			manager.debounceStates[key] = &debounceState{stable: value}
+			continue
+		}
+
+		if value == state.stable {
+			state.pending = false
+			continue
+		}
+		if !state.pending {
+			state.pending = true
+			state.since = now
+		}
+		if now.Sub(state.since) >= window {
+			state.stable = value
+			state.pending = false
+			continue
+		}
+
+		held := *reading
+		held.Value = state.stable
+		debounced[i] = &held
+	}
+	return debounced
+}
+
+// smoothReadings applies the moving average filter of the device's output types,
+// if configured, to the device's numeric readings. The reading values are replaced
+// with the moving average of the values read for the device so far.
+func (manager *dataManager) smoothReadings(device *Device, readings []*Reading) {
+	manager.averagesLock.Lock()
+	defer manager.averagesLock.Unlock()
+
+	for _, reading := range readings {
+		smoothing := device.getSmoothing(reading.Type)
+		if smoothing == nil {
+			continue
+		}
+
+		// Only numeric values are smoothed. Strings are not converted, since
+		// they are not otherwise treated as numeric reading values.
+		if _, isString := reading.Value.(string); isString || reading.Value == nil {
+			continue
+		}
+		value, err := ConvertToFloat64(reading.Value)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+
+		key := device.GUID() + "/" + reading.Type
+		average, exists := manager.averages[key]
+		if exists {
+			average = smoothing.smooth(average, value)
+		} else {
+			average = value
+		}
+		manager.averages[key] = average
+
+		if smoothing.KeepRaw {
+			if reading.Context == nil {
+				reading.Context = map[string]string{}
+			}
+			reading.Context[ContextKeyRawValue] = fmt.Sprint(reading.Value)
+		}
+		reading.Value = average
+	}
+}
+
+// resetDeviceState clears the debounce states, moving averages, and counter
+// baselines of the device with the given ID (GUID). This is done when a device
+// is swapped in, e.g. when it is reloaded, so that the new device does not
+// inherit the state built up from the readings of the device it replaces.
+func (manager *dataManager) resetDeviceState(id string) {
+	prefix := id + "/"
+
+	manager.debounceLock.Lock()
+	for key := range manager.debounceStates {
+		if strings.HasPrefix(key, prefix) {
+			delete(manager.debounceStates, key)
+		}
+	}
+	manager.debounceLock.Unlock()
+
+	manager.averagesLock.Lock()
+	for key := range manager.averages {
+		if strings.HasPrefix(key, prefix) {
+			delete(manager.averages, key)
+		}
+	}
+	manager.averagesLock.Unlock()
+
+	manager.countersLock.Lock()
+	for key := range manager.counters {
+		if strings.HasPrefix(key, prefix) {
+			delete(manager.counters, key)
+		}
+	}
+	manager.countersLock.Unlock()
+}
+
+// counterState is the previous reading of a counter, used to compute its rate.
+type counterState struct {
+	value float64
+	time  time.Time
+}
+
+// perSecondUnit gets the unit of the rate of change per second of a value with
+// the given unit.
+func perSecondUnit(unit Unit) Unit {
+	rate := Unit{Name: "per second", Symbol: "/s"}
+	if unit.Name != "" {
+		rate.Name = unit.Name + " per second"
+	}
+	if unit.Symbol != "" {
+		rate.Symbol = unit.Symbol + "/s"
+	}
+	return rate
+}
+
+// rateReadings computes the rates per second of the counter readings for a
+// device, for output types which compute rates from counters. The rate is
+// emitted in place of the counter value. Counter readings which can not give a
+// rate, e.g. the first reading of a counter, or the reading after a counter is
+// reset, only set the baseline for the next rate, and are removed from the
+// returned readings.
+func (manager *dataManager) rateReadings(device *Device, readings []*Reading) []*Reading {
+	manager.countersLock.Lock()
+	defer manager.countersLock.Unlock()
+
+	rated := make([]*Reading, 0, len(readings))
+	for _, reading := range readings {
+		counter := device.getCounter(reading.Type)
+		if counter == nil {
+			rated = append(rated, reading)
+			continue
+		}
+
+		// Only numeric values are counted. Other values are passed through.
+		if _, isString := reading.Value.(string); isString || reading.Value == nil {
+			rated = append(rated, reading)
+			continue
+		}
+		value, err := ConvertToFloat64(reading.Value)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			rated = append(rated, reading)
+			continue
+		}
+		timestamp, err := ParseRFC3339Nano(reading.Timestamp)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"device": device.GUID(),
+				"type":   reading.Type,
+			}).Warn("[data manager] unable to compute counter rate for reading with no valid timestamp")
+			continue
+		}
+
+		key := device.GUID() + "/" + reading.Type
+		previous, exists := manager.counters[key]
+		if exists && !timestamp.After(previous.time) {
+			// The reading is not newer than the previous one, so there
+			// is no elapsed time to compute a rate over.
+			continue
+		}
+		manager.counters[key] = counterState{value: value, time: timestamp}
+		if !exists {
+			continue
+		}
+		delta, ok := counter.delta(previous.value, value)
+		if !ok {
+			log.WithFields(log.Fields{
+				"device":   device.GUID(),
+				"type":     reading.Type,
+				"previous": previous.value,
+				"value":    value,
+			}).Info("[data manager] counter reset, resetting rate baseline")
+			continue
+		}
+
+		if counter.KeepRaw {
+			if reading.Context == nil {
+				reading.Context = map[string]string{}
+			}
+			reading.Context[ContextKeyRawValue] = fmt.Sprint(reading.Value)
+		}
+		reading.Value = delta / timestamp.Sub(previous.time).Seconds()
+		reading.Unit = perSecondUnit(reading.Unit)
+		rated = append(rated, reading)
+	}
+	return rated
+}
+
+// readsStrict checks whether reads should fail when a device handler returns
+// readings for a device which was not read.
+func readsStrict() bool {
+	return Config.Plugin != nil && Config.Plugin.Settings != nil &&
+		Config.Plugin.Settings.Read != nil && Config.Plugin.Settings.Read.Strict
+}
+
+// filterReadContexts checks that the ReadContexts returned by a bulk read are
+// for the devices that were read, so that a buggy handler can not attribute
+// readings to the wrong device. In strict mode, any mis-associated readings
+// cause an error. Otherwise, they are logged and dropped.
+func filterReadContexts(handler *DeviceHandler, devices []*Device, resp []*ReadContext) ([]*ReadContext, error) {
+	read := make(map[string]struct{}, len(devices))
+	for _, device := range devices {
+		read[device.GUID()] = struct{}{}
+	}
+
+	var filtered []*ReadContext
+	for _, readCtx := range resp {
+		if readCtx == nil {
+			continue
+		}
+		if _, ok := read[readCtx.ID()]; ok {
+			filtered = append(filtered, readCtx)
+			continue
+		}
+		if readsStrict() {
+			return nil, fmt.Errorf(
+				"device handler %s returned readings for device %s, which was not read",
+				handler.Name, readCtx.ID(),
+			)
+		}
+		log.WithFields(log.Fields{
+			"handler":  handler.Name,
+			"device":   readCtx.ID(),
+			"readings": len(readCtx.Reading),
+		}).Warn("[data manager] dropping readings for device which was not read")
+	}
+	return filtered, nil
+}
+
+// errorReadingsEnabled checks whether error readings should be emitted for
+// failed device reads.
+func errorReadingsEnabled() bool {
+	return Config.Plugin != nil && Config.Plugin.Settings != nil &&
+		Config.Plugin.Settings.Read != nil && Config.Plugin.Settings.Read.ErrorReadings
+}
+
+// readingsStateless checks whether the plugin is configured to not retain readings.
+func readingsStateless() bool {
+	return Config.Plugin != nil && Config.Plugin.Settings != nil && Config.Plugin.Settings.Stateless
+}
+
+// readingsDeduplicated checks whether duplicate readings should be suppressed.
+func readingsDeduplicated() bool {
+	return Config.Plugin != nil && Config.Plugin.Settings != nil &&
+		Config.Plugin.Settings.Read != nil && Config.Plugin.Settings.Read.Deduplicate
+}
+
+// uniqueReadings gets the readings from a ReadContext which are not identical in
+// timestamp, type, and value to the most recent reading of the same type for the
+// device.
+func (manager *dataManager) uniqueReadings(readCtx *ReadContext) []*Reading {
+	manager.dataLock.RLock()
+	previous := manager.readings[readCtx.ID()]
+	manager.dataLock.RUnlock()
+
+	var unique []*Reading
+	for _, reading := range readCtx.Reading {
+		duplicate := false
+		for _, prev := range previous {
+			if reading.Timestamp == prev.Timestamp && reading.Type == prev.Type && reflect.DeepEqual(reading.Value, prev.Value) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, reading)
+		}
+	}
+	return unique
+}
+
+// addInstanceContext adds the plugin instance ID to the context of each of the
+// given readings. If a reading already specifies the plugin instance in its
+// context, it is not overridden.
+func addInstanceContext(readings []*Reading) {
+	for _, reading := range readings {
+		if reading.Context == nil {
+			reading.Context = map[string]string{}
+		}
+		if _, exists := reading.Context[ContextKeyPluginInstance]; !exists {
+			reading.Context[ContextKeyPluginInstance] = ctx.instanceID
+		}
+	}
+}
+
+// logReadingTypes logs the Go type and a sample value of each reading in the
+// ReadContext the first time a reading of its type is seen for its device. This
+// info can be used by plugin authors to configure output types correctly.
+func (manager *dataManager) logReadingTypes(reading *ReadContext) {
+	manager.readingTypesLock.Lock()
+	defer manager.readingTypesLock.Unlock()
+
+	for _, r := range reading.Reading {
+		key := fmt.Sprintf("%s:%s", reading.ID(), r.Type)
+		if _, seen := manager.readingTypes[key]; seen {
+			continue
+		}
+		manager.readingTypes[key] = struct{}{}
+
+		log.WithFields(log.Fields{
+			"device":    reading.ID(),
+			"type":      r.Type,
+			"valueType": fmt.Sprintf("%T", r.Value),
+			"value":     r.Value,
+		}).Debug("[data manager] new reading type for device")
+	}
 }
 
 // getReadings safely gets a reading value from the dataManager readings field by
@@ -576,8 +1442,28 @@ func (manager *dataManager) Read(req *synse.DeviceFilter) ([]*synse.Reading, err
 		return nil, err
 	}
 
-	// Get the readings for the device.
-	readings := manager.getReadings(deviceID)
+	// Devices outside of their active hours are not polled, so any readings
+	// for them would be stale.
+	if device := ctx.getDevice(deviceID); !device.IsActive() {
+		log.WithField("id", deviceID).Debug("[data manager] device inactive")
+		return nil, errors.FailedPreconditionErr(
+			"device %s is inactive outside of its active hours (%s to %s)",
+			deviceID, device.ActiveHours.Start, device.ActiveHours.End,
+		)
+	}
+
+	// Get the readings for the device. If the plugin is stateless, there is
+	// no reading state, so the device is read on demand.
+	var readings []*Reading
+	if readingsStateless() {
+		readings, err = manager.readDevice(ctx.getDevice(deviceID))
+		if err != nil {
+			log.WithField("id", deviceID).Error("[data manager] failed to read device")
+			return nil, err
+		}
+	} else {
+		readings = manager.getReadings(deviceID)
+	}
 	if readings == nil {
 		log.WithField("id", deviceID).Error("[data manager] no readings found")
 		return nil, errors.NotFoundErr("no readings found for device: %s", deviceID)
@@ -586,14 +1472,89 @@ func (manager *dataManager) Read(req *synse.DeviceFilter) ([]*synse.Reading, err
 	// Create the response containing the device readings.
 	var resp []*synse.Reading
 	for _, r := range readings {
-		resp = append(resp, r.encode())
+		resp = append(resp, r.encodeFor(ctx.getDevice(deviceID)))
+	}
+	return resp, nil
+}
+
+// readDevice reads a device on demand, applying the same rate limiting and reading
+// context as the read loop. If the device is read in bulk, its handler's bulk read
+// is executed for the device alone.
+func (manager *dataManager) readDevice(device *Device) ([]*Reading, error) {
+	// Rate limiting, if configured
+	if manager.limiter != nil {
+		err := manager.limiter.Wait(context.Background())
+		if err != nil {
+			repeatedLog.Errorf("[data manager] error from limiter when reading %v: %v", device.GUID(), err)
+		}
+	}
+
+	unlock := manager.lockHandlerReads(device.Handler)
+	var readings []*Reading
+	var err error
+	if device.bulkRead {
+		var resp []*ReadContext
+		resp, err = device.Handler.BulkRead([]*Device{device})
+		if err == nil {
+			resp, err = filterReadContexts(device.Handler, []*Device{device}, resp)
+		}
+		for _, readCtx := range resp {
+			readings = append(readings, readCtx.Reading...)
+		}
+	} else {
+		var readCtx *ReadContext
+		readCtx, err = device.Read()
+		if readCtx != nil {
+			readings = readCtx.Reading
+		}
+	}
+	unlock()
+	metrics.recordRead(err)
+	if err != nil {
+		return nil, err
+	}
+
+	device.mergeContext(readings)
+	if ctx.instanceID != "" {
+		addInstanceContext(readings)
+	}
+	return readings, nil
+}
+
+// ReadHistory fulfills a request for the recent readings of a device by getting
+// up to the last k readings from the readings history. If k is not positive, all
+// of the retained readings for the device are returned.
+func (manager *dataManager) ReadHistory(req *synse.DeviceFilter, k int) ([]*synse.Reading, error) {
+	// Validate that the incoming request has the requisite fields populated.
+	err := validateDeviceFilter(req)
+	if err != nil {
+		log.WithField("request", req).Error("[data manager] request failed validation")
+		return nil, err
+	}
+
+	if readingsHistory == nil {
+		return nil, fmt.Errorf("readings history is not enabled")
+	}
+
+	// Create the id for the device.
+	deviceID := makeIDString(req.Rack, req.Board, req.Device)
+	err = validateForRead(deviceID)
+	if err != nil {
+		log.WithField("id", deviceID).Error("[data manager] unable to read device history")
+		return nil, err
+	}
+
+	var resp []*synse.Reading
+	device := ctx.getDevice(deviceID)
+	for _, r := range getReadingsFromHistory(deviceID, k) {
+		resp = append(resp, r.encodeFor(device))
 	}
 	return resp, nil
 }
 
 // Write fulfills a Write request by queuing up the write context and framing
 // up the corresponding gRPC response.
-func (manager *dataManager) Write(req *synse.WriteInfo) (map[string]*synse.WriteData, error) {
+func (manager *dataManager) Write(traceCtx context.Context, req *synse.WriteInfo) (map[string]*synse.WriteData, error) {
 	// Validate that the incoming request has the requisite fields populated.
 	err := validateWriteInfo(req)
 	if err != nil {
@@ -605,7 +1566,18 @@ func (manager *dataManager) Write(req *synse.WriteInfo) (map[string]*synse.Write
 
 	// Create the id for the device.
 	deviceID := makeIDString(filter.Rack, filter.Board, filter.Device)
-	err = validateForWrite(deviceID)
+	// The write may be for a transaction group, rather than for a single
+	// device, in which case it is validated for each device in the group.
+	var targets []*Device
+	if device := ctx.getDevice(deviceID); device != nil {
+		targets = []*Device{device}
+		err = validateForWrite(deviceID)
+	} else if members := getGroupMembers(filter.Rack, filter.Board, filter.Device); len(members) > 0 {
+		targets = members
+		err = validateForGroupWrite(deviceID, members)
+	} else {
+		err = validateForWrite(deviceID)
+	}
 	if err != nil {
 		log.WithField("id", deviceID).Error("[data manager] unable to write to device")
 		return nil, err
@@ -616,6 +1588,36 @@ func (manager *dataManager) Write(req *synse.WriteInfo) (map[string]*synse.Write
 		return nil, fmt.Errorf("writing is not enabled")
 	}
 
+	// Ensure the write data is allowed by the device's write constraints.
+	for _, target := range targets {
+		for _, data := range req.Data {
+			err = validateWriteData(target, data)
+			if err != nil {
+				log.WithField("id", target.GUID()).Error("[data manager] write data failed validation")
+				return nil, err
+			}
+		}
+	}
+
+	// If writes are queued per-device, make sure the queue of each device
+	// being written to has room for all of the writes before any transactions
+	// are created. Writes to a transaction group are queued for each of its
+	// members, so they are ordered with the writes to the members directly.
+	var queues []*deviceWriteQueue
+	if writeQueuesEnabled() {
+		ids := make([]string, len(targets))
+		for i, target := range targets {
+			ids[i] = target.GUID()
+		}
+		var unlock func()
+		queues, unlock, err = manager.reserveWriteQueues(ids, len(req.Data))
+		if err != nil {
+			log.WithField("id", deviceID).Error("[data manager] unable to queue write")
+			return nil, err
+		}
+		defer unlock()
+	}
+
 	// Perform the write and build the response.
 	var resp = make(map[string]*synse.WriteData)
 	for _, data := range req.Data {
@@ -625,14 +1627,26 @@ func (manager *dataManager) Write(req *synse.WriteInfo) (map[string]*synse.Write
 		// Map the transaction ID to the write context for the response
 		resp[t.id] = data
 
-		// Pass the write context to the write channel to be queued for writing.
-		manager.writeChannel <- &WriteContext{
+		w := &WriteContext{
+			traceCtx:    traceCtx,
 			transaction: t,
 			device:      filter.Device,
 			board:       filter.Board,
 			rack:        filter.Rack,
 			data:        data,
 		}
+
+		// Pass the write context to the write queues of the devices, if there
+		// are any, otherwise to the write channel to be queued for writing.
+		if len(queues) > 1 {
+			w.barrier = newGroupBarrier(len(queues))
+		}
+		for _, queue := range queues {
+			queue.writes <- w
+		}
+		if queues == nil {
+			manager.writeChannel <- w
+		}
 	}
 	log.Debugf("[data manager] write response data: %#v", resp)
 	return resp, nil
@@ -1,10 +1,15 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-server-grpc/go"
 )
@@ -17,7 +22,9 @@ func TestNewDataManager(t *testing.T) {
 	assert.Nil(t, d.limiter)
 	assert.NotNil(t, d.dataLock)
 	assert.NotNil(t, d.rwLock)
+	assert.NotNil(t, d.handlerLocksLock)
 	assert.Empty(t, d.readings)
+	assert.Empty(t, d.handlerLocks)
 }
 
 // TestDeviceManager_setupError tests calling setup on the DataManager when there
@@ -106,7 +113,7 @@ func TestDataManager_readOneOkNoLimiter(t *testing.T) {
 
 	// Pass a reading in
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readOne(device)
+	d.readOne(context.Background(), device)
 	assert.Equal(t, 1, len(d.readChannel))
 
 	// Get the reading out
@@ -165,7 +172,7 @@ func TestDataManager_readOneOkWithLimiter(t *testing.T) {
 
 	// Pass a reading in
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readOne(device)
+	d.readOne(context.Background(), device)
 	assert.Equal(t, 1, len(d.readChannel))
 
 	// Get the reading out
@@ -216,10 +223,518 @@ func TestDataManager_readOneErr(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readOne(device)
+	d.readOne(context.Background(), device)
 	assert.Equal(t, 0, len(d.readChannel))
 }
 
+// TestDataManager_readOneErrorReadings tests reading a device that results in error
+// when error readings are enabled.
+func TestDataManager_readOneErrorReadings(t *testing.T) {
+	defer Config.reset()
+
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		Network: &NetworkSettings{
+			Type:    "tcp",
+			Address: "test",
+		},
+		Settings: &PluginSettings{
+			Read:        &ReadSettings{Buffer: 200, ErrorReadings: true},
+			Write:       &WriteSettings{Buffer: 200},
+			Listen:      &ListenSettings{Buffer: 100},
+			Transaction: &TransactionSettings{TTL: "2s"},
+		},
+	}
+
+	// Create the device to read
+	device := &Device{
+		id:       "device",
+		Kind:     "test.state",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "foo.temperature", Unit: Unit{Name: "celsius", Symbol: "C"}}},
+			{OutputType: OutputType{Name: "foo.humidity"}, Info: "humidity"},
+		},
+		Handler: &DeviceHandler{
+			Read: func(d *Device) ([]*Reading, error) {
+				return nil, fmt.Errorf("test read error")
+			},
+		},
+	}
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, len(d.readChannel))
+	d.readOne(context.Background(), device)
+	assert.Equal(t, 1, len(d.readChannel))
+
+	readCtx := <-d.readChannel
+	assert.Equal(t, "rack-board-device", readCtx.ID())
+	assert.Len(t, readCtx.Reading, 2)
+
+	assert.Equal(t, "temperature", readCtx.Reading[0].Type)
+	assert.Equal(t, "C", readCtx.Reading[0].Unit.Symbol)
+	assert.Nil(t, readCtx.Reading[0].Value)
+	assert.Equal(t, "test read error", readCtx.Reading[0].Context[ContextKeyError])
+	assert.Equal(t, "error: test read error", readCtx.Reading[0].Info)
+	assert.NotEmpty(t, readCtx.Reading[0].Timestamp)
+
+	// The error is set as the info of the readings, in place of the output info.
+	assert.Equal(t, "humidity", readCtx.Reading[1].Type)
+	assert.Equal(t, "error: test read error", readCtx.Reading[1].Info)
+	assert.Nil(t, readCtx.Reading[1].Value)
+	assert.Equal(t, "test read error", readCtx.Reading[1].Context[ContextKeyError])
+
+	// The error readings replace the device's readings in the reading state.
+	Config.Plugin.Settings.Cache = &CacheSettings{}
+	d.updateReadings(readCtx)
+	readings := d.getReadings("rack-board-device")
+	assert.Len(t, readings, 2)
+	assert.Nil(t, readings[0].Value)
+}
+
+// TestDataManager_readBulkErrorReadings tests bulk reading devices that results in
+// error when error readings are enabled.
+func TestDataManager_readBulkErrorReadings(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	for _, enabled := range []bool{false, true} {
+		Config.Plugin = &PluginConfig{
+			SchemeVersion: SchemeVersion{Version: "test"},
+			Network: &NetworkSettings{
+				Type:    "tcp",
+				Address: "test",
+			},
+			Settings: &PluginSettings{
+				Read:        &ReadSettings{Buffer: 200, ErrorReadings: enabled},
+				Write:       &WriteSettings{Buffer: 200},
+				Listen:      &ListenSettings{Buffer: 100},
+				Transaction: &TransactionSettings{TTL: "2s"},
+			},
+		}
+
+		handler := &DeviceHandler{
+			BulkRead: func(devices []*Device) ([]*ReadContext, error) {
+				return nil, fmt.Errorf("test bulk read error")
+			},
+		}
+		ctx.deviceHandlers = []*DeviceHandler{handler}
+		for _, id := range []string{"1", "2"} {
+			ctx.devices["rack-board-"+id] = &Device{
+				id:       id,
+				Kind:     "test.state",
+				Location: &Location{Rack: "rack", Board: "board"},
+				Handler:  handler,
+				bulkRead: true,
+			}
+		}
+
+		d := newDataManager()
+		err := d.setup()
+		assert.NoError(t, err)
+
+		d.readBulk(context.Background(), handler)
+		if !enabled {
+			assert.Equal(t, 0, len(d.readChannel))
+			continue
+		}
+
+		assert.Equal(t, 2, len(d.readChannel))
+		var ids []string
+		for i := 0; i < 2; i++ {
+			readCtx := <-d.readChannel
+			ids = append(ids, readCtx.ID())
+
+			// Devices with no outputs get a single untyped error reading.
+			assert.Len(t, readCtx.Reading, 1)
+			assert.Equal(t, "", readCtx.Reading[0].Type)
+			assert.Nil(t, readCtx.Reading[0].Value)
+			assert.Equal(t, "test bulk read error", readCtx.Reading[0].Context[ContextKeyError])
+		}
+		assert.ElementsMatch(t, []string{"rack-board-1", "rack-board-2"}, ids)
+	}
+}
+
+// TestDataManager_readOneActiveHours tests that a device is only read within its
+// active hours.
+func TestDataManager_readOneActiveHours(t *testing.T) {
+	defer func() {
+		Config.reset()
+		clock = realClock{}
+	}()
+	c := useFakeClock(t, time.Date(2018, 1, 1, 7, 0, 0, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		Network: &NetworkSettings{
+			Type:    "tcp",
+			Address: "test",
+		},
+		Settings: &PluginSettings{
+			Read:        &ReadSettings{Buffer: 200},
+			Write:       &WriteSettings{Buffer: 200},
+			Listen:      &ListenSettings{Buffer: 100},
+			Transaction: &TransactionSettings{TTL: "2s"},
+		},
+	}
+
+	reads := 0
+	device := &Device{
+		Kind:        "test.state",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		ActiveHours: &ActiveHours{Start: "08:00", End: "18:00"},
+		Outputs: []*Output{
+			{
+				OutputType: OutputType{
+					Name: "foo",
+				},
+			},
+		},
+		Handler: &DeviceHandler{
+			Read: func(d *Device) ([]*Reading, error) {
+				reads++
+				reading, err := d.GetOutput("foo").MakeReading("ok")
+				if err != nil {
+					return nil, err
+				}
+				return []*Reading{reading}, nil
+			},
+		},
+	}
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	// Outside of the window, the device is not read.
+	d.readOne(context.Background(), device)
+	assert.Equal(t, 0, reads)
+	assert.Equal(t, 0, len(d.readChannel))
+
+	// Inside of the window, the device is read.
+	c.Advance(2 * time.Hour)
+	d.readOne(context.Background(), device)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, 1, len(d.readChannel))
+
+	// Once the window ends, the device is no longer read.
+	c.Advance(9 * time.Hour)
+	d.readOne(context.Background(), device)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, 1, len(d.readChannel))
+}
+
+// TestDataManager_readBulkEmptyReadings tests that the handler's EmptyReadings
+// policy is applied to the devices of a bulk read which got no readings.
+func TestDataManager_readBulkEmptyReadings(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	for _, policy := range []EmptyReadingsPolicy{EmptyReadingsSilent, EmptyReadingsLog, EmptyReadingsError} {
+		Config.Plugin = &PluginConfig{
+			SchemeVersion: SchemeVersion{Version: "test"},
+			Network: &NetworkSettings{
+				Type:    "tcp",
+				Address: "test",
+			},
+			Settings: &PluginSettings{
+				Read:        &ReadSettings{Buffer: 200, ErrorReadings: true},
+				Write:       &WriteSettings{Buffer: 200},
+				Listen:      &ListenSettings{Buffer: 100},
+				Transaction: &TransactionSettings{TTL: "2s"},
+			},
+		}
+
+		// Device 1 gets readings, device 2 gets an empty ReadContext, and
+		// device 3 gets no ReadContext at all.
+		handler := &DeviceHandler{
+			Name:          "test",
+			EmptyReadings: policy,
+			BulkRead: func(devices []*Device) ([]*ReadContext, error) {
+				return []*ReadContext{
+					{Rack: "rack", Board: "board", Device: "1", Reading: []*Reading{{Type: "state", Value: 1}}},
+					{Rack: "rack", Board: "board", Device: "2"},
+				}, nil
+			},
+		}
+		ctx.deviceHandlers = []*DeviceHandler{handler}
+		for _, id := range []string{"1", "2", "3"} {
+			ctx.devices["rack-board-"+id] = &Device{
+				id:       id,
+				Kind:     "test.state",
+				Location: &Location{Rack: "rack", Board: "board"},
+				Handler:  handler,
+				bulkRead: true,
+			}
+		}
+
+		d := newDataManager()
+		assert.NoError(t, d.setup())
+		d.readBulk(context.Background(), handler)
+
+		read := map[string]*ReadContext{}
+		for len(d.readChannel) > 0 {
+			readCtx := <-d.readChannel
+			read[readCtx.Device] = readCtx
+		}
+		assert.Equal(t, 1, len(read["1"].Reading))
+		if policy != EmptyReadingsError {
+			assert.Equal(t, 2, len(read), policy)
+			assert.Empty(t, read["2"].Reading)
+			continue
+		}
+
+		// With the error policy, the devices with no readings are failed reads.
+		assert.Equal(t, 3, len(read))
+		for _, id := range []string{"2", "3"} {
+			assert.Equal(t, 1, len(read[id].Reading), id)
+			assert.Equal(t, "handler test returned no readings for device rack-board-"+id, read[id].Reading[0].Context[ContextKeyError], id)
+		}
+	}
+}
+
+// TestDataManager_checkListenedReadings tests that the handler's EmptyReadings
+// policy is applied to readings pushed by a listener.
+func TestDataManager_checkListenedReadings(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	device := emptyReadingsTestDevice(EmptyReadingsError)
+	ctx.devices[device.GUID()] = device
+	d := newDataManager()
+
+	// Listened readings are passed through.
+	readCtx := &ReadContext{Rack: "rack", Board: "board", Device: "1", Reading: []*Reading{{Type: "state"}}}
+	assert.Equal(t, readCtx, d.checkListenedReadings(readCtx))
+
+	// Empty listened readings are dropped, unless error readings are enabled.
+	empty := &ReadContext{Rack: "rack", Board: "board", Device: "1"}
+	assert.Nil(t, d.checkListenedReadings(empty))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{Read: &ReadSettings{ErrorReadings: true}},
+	}
+	errCtx := d.checkListenedReadings(empty)
+	assert.Equal(t, 1, len(errCtx.Reading))
+	assert.Equal(t, "handler test returned no readings for device rack-board-1", errCtx.Reading[0].Context[ContextKeyError])
+
+	// Empty listened readings are passed through with other policies.
+	device.Handler.EmptyReadings = EmptyReadingsSilent
+	assert.Equal(t, empty, d.checkListenedReadings(empty))
+}
+
+// TestDataManager_readBulkActiveHours tests that only the devices within their
+// active hours are bulk read.
+func TestDataManager_readBulkActiveHours(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		clock = realClock{}
+	}()
+	useFakeClock(t, time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		Network: &NetworkSettings{
+			Type:    "tcp",
+			Address: "test",
+		},
+		Settings: &PluginSettings{
+			Read:        &ReadSettings{Buffer: 200},
+			Write:       &WriteSettings{Buffer: 200},
+			Listen:      &ListenSettings{Buffer: 100},
+			Transaction: &TransactionSettings{TTL: "2s"},
+		},
+	}
+
+	var read []string
+	handler := &DeviceHandler{
+		BulkRead: func(devices []*Device) ([]*ReadContext, error) {
+			var ctxs []*ReadContext
+			for _, d := range devices {
+				read = append(read, d.id)
+				ctxs = append(ctxs, NewReadContext(d, nil))
+			}
+			return ctxs, nil
+		},
+	}
+	ctx.deviceHandlers = []*DeviceHandler{handler}
+
+	ctx.devices["rack-board-day"] = &Device{
+		id:          "day",
+		Kind:        "test.state",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     handler,
+		ActiveHours: &ActiveHours{Start: "08:00", End: "18:00"},
+	}
+	ctx.devices["rack-board-night"] = &Device{
+		id:          "night",
+		Kind:        "test.state",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     handler,
+		ActiveHours: &ActiveHours{Start: "22:00", End: "06:00"},
+	}
+	ctx.devices["rack-board-always"] = &Device{
+		id:       "always",
+		Kind:     "test.state",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  handler,
+	}
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	d.readBulk(context.Background(), handler)
+	assert.ElementsMatch(t, []string{"day", "always"}, read)
+	assert.Equal(t, 2, len(d.readChannel))
+}
+
+// setupMisassociatedBulkRead is a test helper that registers a device whose bulk
+// read handler also returns readings for a device which was not read.
+func setupMisassociatedBulkRead(strict bool) (*DeviceHandler, *Device) {
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		Network: &NetworkSettings{
+			Type:    "tcp",
+			Address: "test",
+		},
+		Settings: &PluginSettings{
+			Read:        &ReadSettings{Buffer: 200, Strict: strict},
+			Write:       &WriteSettings{Buffer: 200},
+			Listen:      &ListenSettings{Buffer: 100},
+			Transaction: &TransactionSettings{TTL: "2s"},
+		},
+	}
+
+	handler := &DeviceHandler{
+		Name: "buggy",
+		BulkRead: func(devices []*Device) ([]*ReadContext, error) {
+			var ctxs []*ReadContext
+			for _, d := range devices {
+				ctxs = append(ctxs, NewReadContext(d, []*Reading{{Type: "state", Value: "ok"}}))
+			}
+			// A reading for a device which was not read.
+			ctxs = append(ctxs, &ReadContext{
+				Rack:    "rack",
+				Board:   "board",
+				Device:  "other",
+				Reading: []*Reading{{Type: "state", Value: "wrong"}},
+			})
+			return ctxs, nil
+		},
+	}
+	ctx.deviceHandlers = []*DeviceHandler{handler}
+
+	device := &Device{
+		id:       "device",
+		Kind:     "test.state",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  handler,
+		bulkRead: true,
+	}
+	ctx.devices["rack-board-device"] = device
+	return handler, device
+}
+
+// TestDataManager_readBulkMisassociated tests that readings which a bulk read
+// returns for a device that was not read are dropped.
+func TestDataManager_readBulkMisassociated(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+	handler, _ := setupMisassociatedBulkRead(false)
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	d.readBulk(context.Background(), handler)
+	assert.Equal(t, 1, len(d.readChannel))
+
+	reading := <-d.readChannel
+	assert.Equal(t, "rack-board-device", reading.ID())
+	assert.Equal(t, "ok", reading.Reading[0].Value)
+}
+
+// TestDataManager_readBulkMisassociatedStrict tests that a bulk read which returns
+// readings for a device that was not read fails in strict mode.
+func TestDataManager_readBulkMisassociatedStrict(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+	handler, _ := setupMisassociatedBulkRead(true)
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	d.readBulk(context.Background(), handler)
+	assert.Equal(t, 0, len(d.readChannel))
+}
+
+// TestDataManager_readDeviceMisassociated tests reading a bulk read device on
+// demand when its handler returns readings for a device that was not read.
+func TestDataManager_readDeviceMisassociated(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	_, device := setupMisassociatedBulkRead(false)
+	readings, err := newDataManager().readDevice(device)
+	assert.NoError(t, err)
+	assert.Len(t, readings, 1)
+	assert.Equal(t, "ok", readings[0].Value)
+
+	Config.Plugin.Settings.Read.Strict = true
+	readings, err = newDataManager().readDevice(device)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "rack-board-other")
+	assert.Nil(t, readings)
+}
+
+// Test_filterReadContexts tests filtering the ReadContexts returned by a bulk read.
+func Test_filterReadContexts(t *testing.T) {
+	defer Config.reset()
+
+	handler := &DeviceHandler{Name: "test"}
+	devices := []*Device{
+		{id: "1", Location: &Location{Rack: "rack", Board: "board"}},
+		{id: "2", Location: &Location{Rack: "rack", Board: "board"}},
+	}
+	resp := []*ReadContext{
+		{Rack: "rack", Board: "board", Device: "1"},
+		{Rack: "rack", Board: "board", Device: "3"},
+		nil,
+		{Rack: "rack", Board: "board", Device: "2"},
+	}
+
+	filtered, err := filterReadContexts(handler, devices, resp)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ReadContext{resp[0], resp[3]}, filtered)
+
+	Config.Plugin = &PluginConfig{Settings: &PluginSettings{Read: &ReadSettings{Strict: true}}}
+	filtered, err = filterReadContexts(handler, devices, resp)
+	assert.Error(t, err)
+	assert.Nil(t, filtered)
+
+	filtered, err = filterReadContexts(handler, devices, []*ReadContext{resp[3], resp[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, []*ReadContext{resp[3], resp[0]}, filtered)
+}
+
 // TestDataManager_readBulkOkNoLimiter tests bulk reading a device when a limiter is
 // not configured.
 func TestDataManager_readBulkOkNoLimiter(t *testing.T) {
@@ -287,7 +802,7 @@ func TestDataManager_readBulkOkNoLimiter(t *testing.T) {
 
 	// Pass a reading in
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readBulk(handler)
+	d.readBulk(context.Background(), handler)
 	assert.Equal(t, 1, len(d.readChannel))
 
 	// Get the reading out
@@ -365,7 +880,7 @@ func TestDataManager_readBulkOkWithLimiter(t *testing.T) {
 
 	// Pass a reading in
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readBulk(handler)
+	d.readBulk(context.Background(), handler)
 	assert.Equal(t, 1, len(d.readChannel))
 
 	// Get the reading out
@@ -426,7 +941,7 @@ func TestDataManager_readBulkError(t *testing.T) {
 
 	// Pass a reading in
 	assert.Equal(t, 0, len(d.readChannel))
-	d.readBulk(handler)
+	d.readBulk(context.Background(), handler)
 	assert.Equal(t, 0, len(d.readChannel))
 }
 
@@ -537,6 +1052,7 @@ func TestDataManager_serialReadSingleBulk(t *testing.T) {
 
 	// Create the device to read
 	device := &Device{
+		id:       "device",
 		Kind:     "test.state",
 		Location: &Location{Rack: "rack", Board: "board"},
 		Outputs: []*Output{
@@ -675,6 +1191,7 @@ func TestDataManager_parallelReadSingleBulk(t *testing.T) {
 
 	// Create the device to read
 	device := &Device{
+		id:       "device",
 		Kind:     "test.state",
 		Location: &Location{Rack: "rack", Board: "board"},
 		Outputs: []*Output{
@@ -1457,3 +1974,1009 @@ func TestNewListenerCtx(t *testing.T) {
 	assert.Equal(t, device, ctx.device)
 	assert.Equal(t, 0, ctx.restarts)
 }
+
+// TestDataManager_updateReadingsConcurrent tests updating the reading state and
+// readings cache from multiple simulated listeners concurrently. This should be
+// run with the race detector enabled.
+func TestDataManager_updateReadingsConcurrent(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		readingsCache = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{
+				Enabled: true,
+				TTL:     time.Minute,
+			},
+		},
+	}
+	setupReadingsCache()
+
+	manager := newDataManager()
+
+	listeners := 10
+	readingsPerListener := 100
+
+	var waitGroup sync.WaitGroup
+	for i := 0; i < listeners; i++ {
+		waitGroup.Add(1)
+		go func(wg *sync.WaitGroup, listener int) {
+			defer wg.Done()
+			for j := 0; j < readingsPerListener; j++ {
+				manager.updateReadings(&ReadContext{
+					Rack:   "rack",
+					Board:  "board",
+					Device: fmt.Sprintf("device-%d", listener),
+					Reading: []*Reading{
+						{Type: "test", Value: j},
+					},
+				})
+			}
+		}(&waitGroup, i)
+	}
+
+	// Read from the state while it is being updated.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			manager.getAllReadings()
+			c := make(chan *ReadContext, listeners*readingsPerListener)
+			getCachedReadings(time.Time{}, time.Time{}, c)
+		}
+	}()
+
+	waitGroup.Wait()
+	<-done
+
+	// The last reading for every listener should be tracked.
+	readings := manager.getAllReadings()
+	assert.Equal(t, listeners, len(readings))
+	for i := 0; i < listeners; i++ {
+		r := manager.getReadings(fmt.Sprintf("rack-board-device-%d", i))
+		assert.Equal(t, 1, len(r))
+		assert.Equal(t, readingsPerListener-1, r[0].Value)
+	}
+
+	// No cached readings should have been lost.
+	c := make(chan *ReadContext, listeners*readingsPerListener)
+	getCachedReadings(time.Time{}, time.Time{}, c)
+	close(c)
+	assert.Equal(t, listeners*readingsPerListener, len(c))
+}
+
+// concurrencyTracker is a test helper which tracks the maximum number of
+// concurrent calls made to a read handler.
+type concurrencyTracker struct {
+	lock    sync.Mutex
+	current int
+	max     int
+}
+
+// read returns a read handler function which records its concurrency.
+func (tracker *concurrencyTracker) read(d *Device) ([]*Reading, error) {
+	tracker.lock.Lock()
+	tracker.current++
+	if tracker.current > tracker.max {
+		tracker.max = tracker.current
+	}
+	tracker.lock.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	tracker.lock.Lock()
+	tracker.current--
+	tracker.lock.Unlock()
+
+	reading, err := d.GetOutput("foo").MakeReading("ok")
+	if err != nil {
+		return nil, err
+	}
+	return []*Reading{reading}, nil
+}
+
+// TestDataManager_parallelReadSerializedHandler tests reading devices in parallel
+// when one of the handlers serializes its reads.
+func TestDataManager_parallelReadSerializedHandler(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		Network: &NetworkSettings{
+			Type:    "tcp",
+			Address: "test",
+		},
+		Settings: &PluginSettings{
+			Read:        &ReadSettings{Buffer: 200},
+			Write:       &WriteSettings{Buffer: 200},
+			Listen:      &ListenSettings{Buffer: 100},
+			Transaction: &TransactionSettings{TTL: "2s"},
+		},
+	}
+
+	serialTracker := &concurrencyTracker{}
+	serialHandler := &DeviceHandler{
+		Name:           "serial",
+		Read:           serialTracker.read,
+		SerializeReads: true,
+	}
+
+	// All reads across the parallel handlers are tracked together, so the max
+	// concurrency reflects reads overlapping across different handlers.
+	parallelTracker := &concurrencyTracker{}
+	parallelHandler1 := &DeviceHandler{Name: "parallel-1", Read: parallelTracker.read}
+	parallelHandler2 := &DeviceHandler{Name: "parallel-2", Read: parallelTracker.read}
+
+	newDevice := func(kind string, handler *DeviceHandler) *Device {
+		return &Device{
+			Kind:     kind,
+			Location: &Location{Rack: "rack", Board: "board"},
+			Outputs:  []*Output{{OutputType: OutputType{Name: "foo"}}},
+			Handler:  handler,
+		}
+	}
+
+	ctx.devices["test-id-1"] = newDevice("test.1.state", serialHandler)
+	ctx.devices["test-id-2"] = newDevice("test.2.state", serialHandler)
+	ctx.devices["test-id-3"] = newDevice("test.3.state", parallelHandler1)
+	ctx.devices["test-id-4"] = newDevice("test.4.state", parallelHandler2)
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	d.parallelRead()
+	assert.Equal(t, 4, len(d.readChannel))
+
+	// Devices on the serialized handler should never overlap.
+	assert.Equal(t, 1, serialTracker.max)
+
+	// Devices on different handlers should be read in parallel.
+	assert.Equal(t, 2, parallelTracker.max)
+}
+
+// TestDataManager_initialRead tests that the initial read updates the readings
+// state for all devices before it returns.
+func TestDataManager_initialRead(t *testing.T) {
+	for _, mode := range []string{modeSerial, modeParallel} {
+		t.Run(mode, func(t *testing.T) {
+			defer func() {
+				Config.reset()
+				resetContext()
+			}()
+
+			Config.Plugin = &PluginConfig{
+				SchemeVersion: SchemeVersion{Version: "test"},
+				Network: &NetworkSettings{
+					Type:    "tcp",
+					Address: "test",
+				},
+				Settings: &PluginSettings{
+					Mode:        mode,
+					Read:        &ReadSettings{Buffer: 1, InitialRead: true},
+					Write:       &WriteSettings{Buffer: 200},
+					Listen:      &ListenSettings{Buffer: 100},
+					Transaction: &TransactionSettings{TTL: "2s"},
+					Cache:       &CacheSettings{Enabled: false},
+				},
+			}
+
+			handler := &DeviceHandler{
+				Read: func(d *Device) ([]*Reading, error) {
+					reading, err := d.GetOutput("foo").MakeReading("ok")
+					if err != nil {
+						return nil, err
+					}
+					return []*Reading{reading}, nil
+				},
+			}
+
+			// Use more devices than the read buffer can hold, so the initial read
+			// must consume readings while devices are still being read.
+			for i := 0; i < 5; i++ {
+				id := fmt.Sprintf("%d", i)
+				ctx.devices["rack-board-"+id] = &Device{
+					id:       id,
+					Kind:     "test.state",
+					Location: &Location{Rack: "rack", Board: "board"},
+					Outputs:  []*Output{{OutputType: OutputType{Name: "foo"}}},
+					Handler:  handler,
+				}
+			}
+
+			d := newDataManager()
+			err := d.setup()
+			assert.NoError(t, err)
+
+			err = d.initialRead(mode)
+			assert.NoError(t, err)
+
+			readings := d.getAllReadings()
+			assert.Equal(t, 5, len(readings))
+			for i := 0; i < 5; i++ {
+				r := readings[fmt.Sprintf("rack-board-%d", i)]
+				assert.Equal(t, 1, len(r))
+				assert.Equal(t, "ok", r[0].Value)
+			}
+			assert.Equal(t, 0, len(d.readChannel))
+		})
+	}
+}
+
+// TestDataManager_initialReadError tests the initial read when the plugin run
+// mode is not supported.
+func TestDataManager_initialReadError(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:   &ReadSettings{Buffer: 1},
+			Write:  &WriteSettings{Buffer: 1},
+			Listen: &ListenSettings{Buffer: 1},
+		},
+	}
+
+	d := newDataManager()
+	err := d.setup()
+	assert.NoError(t, err)
+
+	err = d.initialRead("unsupported")
+	assert.Error(t, err)
+}
+
+// TestDataManager_updateReadingsContext tests that a device's static context is
+// added to its readings when the readings state is updated.
+func TestDataManager_updateReadingsContext(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Context:  map[string]string{"asset_id": "123", "epoch": "static"},
+	}
+
+	d := newDataManager()
+	d.updateReadings(&ReadContext{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+		Reading: []*Reading{
+			{Value: 1, Context: map[string]string{"epoch": "1000"}},
+		},
+	})
+
+	readings := d.getReadings("rack-board-device")
+	assert.Equal(t, 1, len(readings))
+	assert.Equal(t, map[string]string{"asset_id": "123", "epoch": "1000"}, readings[0].Context)
+}
+
+// Test_readDelay tests getting the delay before the next read.
+func Test_readDelay(t *testing.T) {
+	now := time.Date(2018, 1, 1, 10, 0, 17, 500000000, time.UTC)
+
+	var tests = []struct {
+		interval time.Duration
+		align    bool
+		expected time.Duration
+	}{
+		{time.Minute, false, time.Minute},
+		{time.Minute, true, 42500 * time.Millisecond},
+		{10 * time.Second, true, 2500 * time.Millisecond},
+		{time.Hour, true, 59*time.Minute + 42500*time.Millisecond},
+		{0, true, 0},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, readDelay(now, tc.interval, tc.align))
+	}
+}
+
+// TestDataManager_updateReadingsLogTypes tests that in debug mode, the reading value types
+// are logged once for each new (device, type) combination.
+func TestDataManager_updateReadingsLogTypes(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	defer log.SetLevel(log.GetLevel())
+	log.SetLevel(log.DebugLevel)
+
+	Config.Plugin = &PluginConfig{
+		Debug: true,
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+
+	typeEntries := func() []log.Entry {
+		var entries []log.Entry
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == "[data manager] new reading type for device" {
+				entries = append(entries, *entry)
+			}
+		}
+		return entries
+	}
+
+	d := newDataManager()
+	update := func(device string, readings ...*Reading) {
+		d.updateReadings(&ReadContext{Rack: "rack", Board: "board", Device: device, Reading: readings})
+	}
+
+	update("1", &Reading{Type: "temperature", Value: 20.5}, &Reading{Type: "humidity", Value: 40})
+	entries := typeEntries()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "rack-board-1", entries[0].Data["device"])
+	assert.Equal(t, "temperature", entries[0].Data["type"])
+	assert.Equal(t, "float64", entries[0].Data["valueType"])
+	assert.Equal(t, 20.5, entries[0].Data["value"])
+	assert.Equal(t, "humidity", entries[1].Data["type"])
+	assert.Equal(t, "int", entries[1].Data["valueType"])
+
+	// Already seen combinations are not logged again.
+	update("1", &Reading{Type: "temperature", Value: 21.0}, &Reading{Type: "humidity", Value: 41})
+	assert.Equal(t, 2, len(typeEntries()))
+
+	// A seen type for a new device is logged.
+	update("2", &Reading{Type: "temperature", Value: "21"})
+	entries = typeEntries()
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "rack-board-2", entries[2].Data["device"])
+	assert.Equal(t, "string", entries[2].Data["valueType"])
+}
+
+// TestDataManager_updateReadingsLogTypesDisabled tests that reading value types are not
+// logged when not in debug mode.
+func TestDataManager_updateReadingsLogTypesDisabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+
+	d := newDataManager()
+	d.updateReadings(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "1",
+		Reading: []*Reading{{Type: "temperature", Value: 20.5}},
+	})
+
+	for _, entry := range hook.AllEntries() {
+		assert.NotEqual(t, "[data manager] new reading type for device", entry.Message)
+	}
+	assert.Empty(t, d.readingTypes)
+}
+
+// TestDataManager_updateReadingsInstanceID tests that the plugin instance ID is added
+// to the context of readings when they are updated.
+func TestDataManager_updateReadingsInstanceID(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.instanceID = "replica-1"
+
+	d := newDataManager()
+	d.updateReadings(&ReadContext{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+		Reading: []*Reading{
+			{Value: 1},
+			{Value: 2, Context: map[string]string{"epoch": "1000"}},
+		},
+	})
+
+	readings := d.getReadings("rack-board-device")
+	assert.Equal(t, 2, len(readings))
+	assert.Equal(t, map[string]string{ContextKeyPluginInstance: "replica-1"}, readings[0].Context)
+	assert.Equal(t, map[string]string{ContextKeyPluginInstance: "replica-1", "epoch": "1000"}, readings[1].Context)
+}
+
+// TestDataManager_updateReadingsNoInstanceID tests that no plugin instance ID is added
+// to the context of readings when it is not set.
+func TestDataManager_updateReadingsNoInstanceID(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+
+	d := newDataManager()
+	d.updateReadings(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "device",
+		Reading: []*Reading{{Value: 1}},
+	})
+
+	readings := d.getReadings("rack-board-device")
+	assert.Equal(t, 1, len(readings))
+	assert.Nil(t, readings[0].Context)
+}
+
+// TestDataManager_updateReadingsDeduplicate tests that readings identical in timestamp,
+// type, and value to a device's most recent readings are suppressed when deduplication
+// is enabled, while differing readings are kept.
+func TestDataManager_updateReadingsDeduplicate(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		readingsHistory = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{Deduplicate: true},
+			Cache: &CacheSettings{},
+		},
+	}
+	readingsHistory = newReadingHistory(10)
+
+	d := newDataManager()
+	update := func(readings ...*Reading) {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: readings,
+		})
+	}
+
+	update(
+		&Reading{Timestamp: "ts1", Type: "temperature", Value: 20.5},
+		&Reading{Timestamp: "ts1", Type: "humidity", Value: 40},
+	)
+	assert.Equal(t, []interface{}{20.5, 40}, readingValues(getReadingsFromHistory("rack-board-device", 0)))
+
+	// Identical readings are suppressed.
+	update(
+		&Reading{Timestamp: "ts1", Type: "temperature", Value: 20.5},
+		&Reading{Timestamp: "ts1", Type: "humidity", Value: 40},
+	)
+	assert.Equal(t, []interface{}{20.5, 40}, readingValues(getReadingsFromHistory("rack-board-device", 0)))
+
+	// Only the readings which differ are kept, but the current readings are
+	// the full set of new readings.
+	update(
+		&Reading{Timestamp: "ts1", Type: "temperature", Value: 20.5},
+		&Reading{Timestamp: "ts1", Type: "humidity", Value: 41},
+	)
+	assert.Equal(t, []interface{}{20.5, 40, 41}, readingValues(getReadingsFromHistory("rack-board-device", 0)))
+	assert.Equal(t, []interface{}{20.5, 41}, readingValues(d.getReadings("rack-board-device")))
+
+	// Readings with a different timestamp are kept.
+	update(&Reading{Timestamp: "ts2", Type: "temperature", Value: 20.5})
+	assert.Equal(t, []interface{}{20.5, 40, 41, 20.5}, readingValues(getReadingsFromHistory("rack-board-device", 0)))
+}
+
+// TestDataManager_updateReadingsNoDeduplicate tests that identical readings are kept
+// when deduplication is not enabled.
+func TestDataManager_updateReadingsNoDeduplicate(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		readingsHistory = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	readingsHistory = newReadingHistory(10)
+
+	d := newDataManager()
+	for i := 0; i < 2; i++ {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Timestamp: "ts1", Type: "temperature", Value: 20.5}},
+		})
+	}
+	assert.Equal(t, []interface{}{20.5, 20.5}, readingValues(getReadingsFromHistory("rack-board-device", 0)))
+}
+
+// TestDataManager_updateReadingsStateless tests that no readings are retained
+// when the plugin is stateless.
+func TestDataManager_updateReadingsStateless(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		readingsCache = nil
+		readingsHistory = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Stateless: true,
+			Cache:     &CacheSettings{Enabled: true, TTL: time.Minute},
+			History:   &HistorySettings{Enabled: true, Size: 10},
+		},
+	}
+	setupReadingsCache()
+	setupReadingsHistory()
+	assert.Nil(t, readingsCache)
+	assert.Nil(t, readingsHistory)
+
+	d := newDataManager()
+	published, unsubscribe := d.subscribe(10)
+	defer unsubscribe()
+	for i := 0; i < 10; i++ {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Type: "test", Value: i}},
+		})
+	}
+	assert.Equal(t, 0, len(d.getAllReadings()))
+	assert.Nil(t, getReadingsFromHistory("rack-board-device", 0))
+
+	// The readings are still published to subscribers.
+	assert.Equal(t, 10, len(published))
+	assert.Equal(t, 0, (<-published).Reading[0].Value)
+}
+
+// TestDataManager_readDeviceBulk tests reading a bulk read device on demand.
+func TestDataManager_readDeviceBulk(t *testing.T) {
+	defer resetContext()
+
+	ctx.instanceID = "instance-1"
+	device := &Device{
+		id:       "1",
+		bulkRead: true,
+		Location: &Location{Rack: "rack", Board: "board"},
+		Context:  map[string]string{"zone": "a"},
+	}
+	device.Handler = &DeviceHandler{
+		BulkRead: func(devices []*Device) ([]*ReadContext, error) {
+			assert.Equal(t, []*Device{device}, devices)
+			return []*ReadContext{
+				{Rack: "rack", Board: "board", Device: "1", Reading: []*Reading{{Type: "test", Value: 1}}},
+				{Rack: "rack", Board: "board", Device: "2", Reading: []*Reading{{Type: "test", Value: 2}}},
+			}, nil
+		},
+	}
+
+	d := newDataManager()
+	readings, err := d.readDevice(device)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{1}, readingValues(readings))
+	assert.Equal(t, "a", readings[0].Context["zone"])
+	assert.Equal(t, "instance-1", readings[0].Context[ContextKeyPluginInstance])
+}
+
+// TestDataManager_updateReadingsDebounce tests that a change to a boolean reading
+// is only emitted once it has been stable for the device's debounce duration.
+func TestDataManager_updateReadingsDebounce(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		readingsHistory = nil
+		clock = realClock{}
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	readingsHistory = newReadingHistory(100)
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Debounce: 2 * time.Second,
+	}
+
+	d := newDataManager()
+	update := func(value bool) {
+		d.updateReadings(&ReadContext{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+			Reading: []*Reading{
+				{Type: "state", Value: value},
+				{Type: "count", Value: 1},
+			},
+		})
+		c.Advance(time.Second)
+	}
+
+	// The input flaps, then settles on true, then flaps and settles on false.
+	for _, value := range []bool{false, true, false, true, false, true, true, true, true, false, true, false, false, false} {
+		update(value)
+	}
+
+	var states []interface{}
+	for _, reading := range getReadingsFromHistory("rack-board-device", 0) {
+		if reading.Type == "state" {
+			states = append(states, reading.Value)
+		}
+	}
+	assert.Equal(t, []interface{}{
+		false, false, false, false, false, // flapping: held at false
+		false, false, true, true, // true for 2s: changed to true
+		true, true, // flapping: held at true
+		true, true, false, // false for 2s: changed to false
+	}, states)
+
+	// The non-boolean readings are not debounced.
+	current := d.getReadings("rack-board-device")
+	assert.Equal(t, []interface{}{false, 1}, readingValues(current))
+}
+
+// TestDataManager_updateReadingsNoDebounce tests that boolean readings are emitted
+// as-is for devices which do not debounce them.
+func TestDataManager_updateReadingsNoDebounce(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+	}
+
+	d := newDataManager()
+	for _, value := range []bool{false, true, false} {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Type: "state", Value: value}},
+		})
+		assert.Equal(t, []interface{}{value}, readingValues(d.getReadings("rack-board-device")))
+	}
+	assert.Equal(t, 0, len(d.debounceStates))
+}
+
+// TestDataManager_updateReadingsSmoothing tests that the moving average of readings
+// approaches a step input for output types which smooth their readings.
+func TestDataManager_updateReadingsSmoothing(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "foo.temperature", Smoothing: &SmoothingSettings{Alpha: 0.5, KeepRaw: true}}},
+			{OutputType: OutputType{Name: "foo.humidity"}},
+		},
+	}
+
+	d := newDataManager()
+	update := func(temperature, humidity interface{}) []*Reading {
+		d.updateReadings(&ReadContext{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+			Reading: []*Reading{
+				{Type: "temperature", Value: temperature},
+				{Type: "humidity", Value: humidity},
+			},
+		})
+		return d.getReadings("rack-board-device")
+	}
+
+	// The first reading sets the average.
+	readings := update(0, 0)
+	assert.Equal(t, 0.0, readings[0].Value)
+	assert.Equal(t, "0", readings[0].Context[ContextKeyRawValue])
+
+	// After a step from 0 to 100, the average approaches the step with each
+	// sample, halving the difference each time with an alpha of 0.5.
+	expected := []float64{50, 75, 87.5, 93.75, 96.875, 98.4375}
+	var previous float64
+	for i, e := range expected {
+		readings = update(100, 100)
+		value := readings[0].Value.(float64)
+		assert.Equal(t, e, value, i)
+		assert.True(t, value > previous)
+		assert.Equal(t, "100", readings[0].Context[ContextKeyRawValue])
+
+		// Readings for output types without smoothing are unchanged.
+		assert.Equal(t, 100, readings[1].Value)
+		assert.NotContains(t, readings[1].Context, ContextKeyRawValue)
+		previous = value
+	}
+	assert.InDelta(t, 100, previous, 2)
+}
+
+// TestDataManager_updateReadingsSmoothingNonNumeric tests that non-numeric readings
+// are not smoothed, and that the raw value is only kept if configured.
+func TestDataManager_updateReadingsSmoothingNonNumeric(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "status", Smoothing: &SmoothingSettings{Alpha: 0.5}}},
+			{OutputType: OutputType{Name: "level", Smoothing: &SmoothingSettings{Alpha: 0.5}}},
+		},
+	}
+
+	d := newDataManager()
+	for _, level := range []float64{10, 20} {
+		d.updateReadings(&ReadContext{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+			Reading: []*Reading{
+				{Type: "status", Value: "ok"},
+				{Type: "level", Value: level},
+			},
+		})
+	}
+
+	readings := d.getReadings("rack-board-device")
+	assert.Equal(t, []interface{}{"ok", 15.0}, readingValues(readings))
+	assert.Nil(t, readings[1].Context)
+}
+
+// TestDataManager_resetDeviceState tests that resetting the state of a device
+// clears its debounce states, moving averages, and counter baselines, so that a
+// device which replaces it starts smoothing its readings afresh.
+func TestDataManager_resetDeviceState(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "level", Smoothing: &SmoothingSettings{Alpha: 0.5}}},
+		},
+	}
+
+	d := newDataManager()
+	update := func(level float64) []*Reading {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Type: "level", Value: level}},
+		})
+		return d.getReadings("rack-board-device")
+	}
+	update(10)
+	assert.Equal(t, []interface{}{15.0}, readingValues(update(20)))
+
+	d.debounceStates["rack-board-device/state"] = &debounceState{}
+	d.counters["rack-board-device/bytes"] = counterState{}
+	d.averages["rack-board-device-2/level"] = 1
+	d.resetDeviceState("rack-board-device")
+
+	assert.Empty(t, d.debounceStates)
+	assert.Empty(t, d.counters)
+	assert.Equal(t, map[string]float64{"rack-board-device-2/level": 1}, d.averages)
+
+	// The first reading after the reset is not smoothed with the old average.
+	assert.Equal(t, []interface{}{30.0}, readingValues(update(30)))
+}
+
+// TestDataManager_updateReadingsCounter tests that the rates of increasing counter
+// readings are computed for output types which compute rates from counters.
+func TestDataManager_updateReadingsCounter(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "foo.bytes", Counter: &CounterSettings{KeepRaw: true}}},
+			{OutputType: OutputType{Name: "foo.status"}},
+		},
+	}
+
+	start := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := newDataManager()
+	update := func(offset time.Duration, counter interface{}) []*Reading {
+		timestamp := start.Add(offset).Format(time.RFC3339Nano)
+		d.updateReadings(&ReadContext{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+			Reading: []*Reading{
+				{Timestamp: timestamp, Type: "bytes", Value: counter, Unit: Unit{Name: "bytes", Symbol: "B"}},
+				{Timestamp: timestamp, Type: "status", Value: "ok"},
+			},
+		})
+		return d.getReadings("rack-board-device")
+	}
+
+	// The first counter reading only sets the baseline, so it is not emitted.
+	readings := update(0, uint32(1000))
+	assert.Equal(t, []interface{}{"ok"}, readingValues(readings))
+
+	var testTable = []struct {
+		offset  time.Duration
+		counter interface{}
+		rate    float64
+	}{
+		{offset: 10 * time.Second, counter: uint32(2000), rate: 100},
+		{offset: 20 * time.Second, counter: uint32(2000), rate: 0},
+		{offset: 22 * time.Second, counter: uint32(2500), rate: 250},
+		{offset: 22500 * time.Millisecond, counter: 2600, rate: 200},
+	}
+	for _, testCase := range testTable {
+		readings = update(testCase.offset, testCase.counter)
+		assert.Equal(t, []interface{}{testCase.rate, "ok"}, readingValues(readings), testCase.offset)
+		assert.Equal(t, fmt.Sprint(testCase.counter), readings[0].Context[ContextKeyRawValue], testCase.offset)
+		assert.Equal(t, Unit{Name: "bytes per second", Symbol: "B/s"}, readings[0].Unit, testCase.offset)
+	}
+
+	// A counter which decreases with no max value is reset, so the reading
+	// only sets a new baseline.
+	readings = update(30*time.Second, 100)
+	assert.Equal(t, []interface{}{"ok"}, readingValues(readings))
+
+	readings = update(40*time.Second, 600)
+	assert.Equal(t, []interface{}{50.0, "ok"}, readingValues(readings))
+}
+
+// TestDataManager_updateReadingsCounterWraparound tests computing the rate of
+// a counter which wraps around its max value.
+func TestDataManager_updateReadingsCounterWraparound(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "packets", Counter: &CounterSettings{Max: math.MaxUint16}}},
+		},
+	}
+
+	start := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := newDataManager()
+	update := func(offset time.Duration, counter uint16) []*Reading {
+		d.updateReadings(&ReadContext{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+			Reading: []*Reading{
+				{Timestamp: start.Add(offset).Format(time.RFC3339Nano), Type: "packets", Value: counter},
+			},
+		})
+		return d.getReadings("rack-board-device")
+	}
+
+	update(0, 65000)
+	readings := update(10*time.Second, 65500)
+	assert.Equal(t, []interface{}{50.0}, readingValues(readings))
+
+	// The counter wraps from 65535 to 0: 35 counts to the max, 1 to wrap to
+	// 0, and 464 after that.
+	readings = update(20*time.Second, 464)
+	assert.Equal(t, []interface{}{50.0}, readingValues(readings))
+	assert.Nil(t, readings[0].Context)
+
+	// A reading which is not newer than the previous one gives no rate, so the
+	// current readings are kept.
+	readings = update(20*time.Second, 500)
+	assert.Equal(t, []interface{}{50.0}, readingValues(readings))
+}
+
+// TestCounterSettings_delta tests getting the change between counter values.
+func TestCounterSettings_delta(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		max      float64
+		previous float64
+		value    float64
+		delta    float64
+		ok       bool
+	}{
+		{desc: "increase", previous: 10, value: 15, delta: 5, ok: true},
+		{desc: "no change", previous: 10, value: 10, delta: 0, ok: true},
+		{desc: "reset", previous: 10, value: 5, ok: false},
+		{desc: "wraparound", max: 255, previous: 250, value: 4, delta: 10, ok: true},
+		{desc: "wraparound to 0", max: 255, previous: 255, value: 0, delta: 1, ok: true},
+		{desc: "previous above max", max: 255, previous: 300, value: 4, ok: false},
+	}
+
+	for _, testCase := range testTable {
+		counter := &CounterSettings{Max: testCase.max}
+		delta, ok := counter.delta(testCase.previous, testCase.value)
+		assert.Equal(t, testCase.ok, ok, testCase.desc)
+		assert.Equal(t, testCase.delta, delta, testCase.desc)
+	}
+}
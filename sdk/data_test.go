@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+)
+
+// TestDevice_GetData tests getting device config Data values.
+func TestDevice_GetData(t *testing.T) {
+	device := &Device{
+		Data: map[string]interface{}{
+			"address": "10.1.2.3",
+			"port":    "502",
+			"id":      3,
+			"scale":   "0.5",
+			"enabled": "true",
+			"debug":   false,
+		},
+	}
+
+	value, err := device.GetData("id")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+
+	s, err := device.GetDataString("address")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.1.2.3", s)
+
+	i, err := device.GetDataInt("port")
+	assert.NoError(t, err)
+	assert.Equal(t, 502, i)
+
+	i, err = device.GetDataInt("id")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, i)
+
+	f, err := device.GetDataFloat64("scale")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, f)
+
+	b, err := device.GetDataBool("enabled")
+	assert.NoError(t, err)
+	assert.True(t, b)
+
+	b, err = device.GetDataBool("debug")
+	assert.NoError(t, err)
+	assert.False(t, b)
+}
+
+// TestDevice_GetData_Error tests getting device config Data values when they
+// are missing or of the wrong type.
+func TestDevice_GetData_Error(t *testing.T) {
+	device := &Device{
+		Data: map[string]interface{}{
+			"address": "10.1.2.3",
+			"id":      3,
+		},
+	}
+
+	_, err := device.GetData("missing")
+	assert.Error(t, err)
+
+	_, err = device.GetDataString("id")
+	assert.Error(t, err)
+
+	_, err = device.GetDataInt("address")
+	assert.Error(t, err)
+
+	_, err = device.GetDataFloat64("address")
+	assert.Error(t, err)
+
+	_, err = device.GetDataBool("id")
+	assert.Error(t, err)
+}
+
+// TestDevice_GetData_Secret tests resolving secret references in device config Data.
+func TestDevice_GetData_Secret(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := test.WriteTempFile(t, "pw", "hunter2\n", os.ModePerm)
+
+	test.SetEnv(t, "TEST_DEVICE_PORT", "8080")
+	defer test.RemoveEnv(t, "TEST_DEVICE_PORT")
+
+	device := &Device{
+		Data: map[string]interface{}{
+			"password": "secret:file:" + path,
+			"port":     "secret:env:TEST_DEVICE_PORT",
+		},
+	}
+
+	password, err := device.GetDataString("password")
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", password)
+
+	port, err := device.GetDataInt("port")
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	// The secrets are not stored in the device's Data.
+	assert.Equal(t, "secret:file:"+path, device.Data["password"])
+	assert.Equal(t, "secret:env:TEST_DEVICE_PORT", device.Data["port"])
+}
+
+// TestDevice_GetData_SecretError tests resolving secret references which can
+// not be resolved.
+func TestDevice_GetData_SecretError(t *testing.T) {
+	device := &Device{
+		Data: map[string]interface{}{
+			"no-file":     "secret:file:/nonexistent/secret",
+			"no-env":      "secret:env:TEST_NONEXISTENT_SECRET",
+			"bad-source":  "secret:vault:pw",
+			"bad-reffmt":  "secret:file",
+			"empty-param": "secret:env:",
+		},
+	}
+
+	for key := range device.Data {
+		_, err := device.GetData(key)
+		assert.Error(t, err, key)
+	}
+}
+
+// TestDevice_JSON_SecretRedacted tests that secret references are redacted when
+// the device is encoded as JSON.
+func TestDevice_JSON_SecretRedacted(t *testing.T) {
+	test.SetEnv(t, "TEST_DEVICE_TOKEN", "s3cr3t")
+	defer test.RemoveEnv(t, "TEST_DEVICE_TOKEN")
+
+	device := &Device{
+		Data: map[string]interface{}{
+			"address": "10.1.2.3",
+			"token":   "secret:env:TEST_DEVICE_TOKEN",
+			"hosts":   []interface{}{"secret:file:/run/secrets/host"},
+		},
+	}
+
+	token, err := device.GetDataString("token")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", token)
+
+	out, err := device.JSON()
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"address":"10.1.2.3"`)
+	assert.Contains(t, out, `"token":"REDACTED"`)
+	assert.Contains(t, out, `"hosts":["REDACTED"]`)
+	assert.NotContains(t, out, "s3cr3t")
+	assert.NotContains(t, out, "secret:")
+}
@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is a fixed-point decimal reading value, for readings which can not
+// tolerate the imprecision of floating point values, e.g. billing-grade metering.
+// A Decimal holds its value exactly, as an arbitrary precision integer coefficient
+// and a base 10 exponent.
+//
+// The scaling factor, scale, and significant figures of an output type, and the
+// "factor" and "significantFigures" transforms, are applied to Decimal values with
+// exact decimal arithmetic. Other transforms, such as conversions, operate on
+// float64 values, so a Decimal value is converted to a float64 for them.
+//
+// Decimal readings are sent to Synse Server as string values, with the reading
+// context "decimal" key set.
+type Decimal struct {
+	coefficient *big.Int
+	exponent    int
+}
+
+// NewDecimal creates a new Decimal from its string representation, e.g. "12.345",
+// "-0.5", or "1.5e-3".
+func NewDecimal(value string) (Decimal, error) {
+	digits := value
+	exponent := 0
+
+	if i := strings.IndexAny(digits, "eE"); i != -1 {
+		exp, err := strconv.Atoi(digits[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal value %q: bad exponent", value)
+		}
+		exponent = exp
+		digits = digits[:i]
+	}
+
+	if i := strings.IndexByte(digits, '.'); i != -1 {
+		fraction := digits[i+1:]
+		exponent -= len(fraction)
+		digits = digits[:i] + fraction
+	}
+
+	// Only signs and decimal digits are allowed in the coefficient, since big.Int
+	// would otherwise accept some non-decimal forms, e.g. underscores.
+	unsigned := strings.TrimLeft(digits, "+-")
+	if len(digits)-len(unsigned) > 1 || unsigned == "" || strings.Trim(unsigned, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal value %q", value)
+	}
+
+	coefficient, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal value %q", value)
+	}
+	return Decimal{coefficient: coefficient, exponent: exponent}, nil
+}
+
+// NewDecimalFromInt creates a new Decimal with the given integer value.
+func NewDecimalFromInt(value int64) Decimal {
+	return Decimal{coefficient: big.NewInt(value)}
+}
+
+// decimalPow10 creates a new Decimal with the value 10^exponent.
+func decimalPow10(exponent int) Decimal {
+	return Decimal{coefficient: big.NewInt(1), exponent: exponent}
+}
+
+// coeff gets the coefficient of the Decimal. The zero value Decimal is 0.
+func (d Decimal) coeff() *big.Int {
+	if d.coefficient == nil {
+		return new(big.Int)
+	}
+	return d.coefficient
+}
+
+// Mul multiplies the Decimal by another Decimal. The product is exact.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		coefficient: new(big.Int).Mul(d.coeff(), other.coeff()),
+		exponent:    d.exponent + other.exponent,
+	}
+}
+
+// RoundSignificant rounds the Decimal to the given number of significant figures,
+// rounding halves away from zero, e.g. 12350 to 12400 for 3 significant figures.
+// The rounding is exact.
+func (d Decimal) RoundSignificant(figures int) Decimal {
+	coefficient := d.coeff()
+	drop := len(new(big.Int).Abs(coefficient).String()) - figures
+	if figures <= 0 || coefficient.Sign() == 0 || drop <= 0 {
+		return d
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(drop)), nil)
+	quotient, remainder := new(big.Int).QuoRem(coefficient, pow, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(pow) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(coefficient.Sign())))
+	}
+	return Decimal{coefficient: quotient, exponent: d.exponent + drop}
+}
+
+// Equal checks whether the Decimal has the same value as another Decimal,
+// regardless of their number of decimal places, e.g. 1.5 and 1.50 are equal.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.rat().Cmp(other.rat()) == 0
+}
+
+// rat gets the value of the Decimal as a big.Rat.
+func (d Decimal) rat() *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(d.exponent))), nil)
+	if d.exponent < 0 {
+		return new(big.Rat).SetFrac(d.coeff(), pow)
+	}
+	return new(big.Rat).SetInt(new(big.Int).Mul(d.coeff(), pow))
+}
+
+// Float64 gets the nearest float64 value to the Decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// String gets the string representation of the Decimal. The value is formatted
+// without an exponent, keeping the number of decimal places of the Decimal,
+// e.g. "3.00" for the product of 1.50 and 2.
+func (d Decimal) String() string {
+	coefficient := d.coeff()
+	digits := new(big.Int).Abs(coefficient).String()
+	sign := ""
+	if coefficient.Sign() < 0 {
+		sign = "-"
+	}
+
+	if d.exponent >= 0 {
+		return sign + digits + strings.Repeat("0", d.exponent)
+	}
+
+	places := -d.exponent
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+}
+
+// abs gets the absolute value of an integer.
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
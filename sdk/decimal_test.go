@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewDecimal tests creating Decimals from their string representation.
+func TestNewDecimal(t *testing.T) {
+	var tests = []struct {
+		value    string
+		expected string
+	}{
+		{"0", "0"},
+		{"12", "12"},
+		{"12.345", "12.345"},
+		{"-0.5", "-0.5"},
+		{"+0.5", "0.5"},
+		{".25", "0.25"},
+		{"1.50", "1.50"},
+		{"1.5e-3", "0.0015"},
+		{"1.5E3", "1500"},
+		{"-12e2", "-1200"},
+	}
+
+	for _, test := range tests {
+		d, err := NewDecimal(test.value)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, d.String(), test.value)
+	}
+}
+
+// TestNewDecimal_Error tests creating Decimals from invalid string representations.
+func TestNewDecimal_Error(t *testing.T) {
+	for _, value := range []string{"", "abc", "1.2.3", "1e", "1e1.5", "--1", "1_000", "0x10", "."} {
+		_, err := NewDecimal(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// TestDecimal_Mul tests that multiplying Decimals is exact.
+func TestDecimal_Mul(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		expected string
+	}{
+		{"0.1", "0.1", "0.01"},
+		{"1.50", "2", "3.00"},
+		{"-0.3", "3", "-0.9"},
+		{"123456789.123456789", "1000", "123456789123.456789000"},
+	}
+
+	for _, test := range tests {
+		a, err := NewDecimal(test.a)
+		assert.NoError(t, err)
+		b, err := NewDecimal(test.b)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, a.Mul(b).String(), test.a+" * "+test.b)
+	}
+}
+
+// TestDecimal_Zero tests that the zero value Decimal is 0.
+func TestDecimal_Zero(t *testing.T) {
+	var d Decimal
+	assert.Equal(t, "0", d.String())
+	assert.Equal(t, float64(0), d.Float64())
+	assert.Equal(t, "0", d.Mul(NewDecimalFromInt(5)).String())
+}
+
+// TestDecimal_RoundSignificant tests rounding Decimals to a number of significant figures.
+func TestDecimal_RoundSignificant(t *testing.T) {
+	var tests = []struct {
+		value    string
+		figures  int
+		expected string
+	}{
+		{"12345", 3, "12300"},
+		{"12350", 3, "12400"},
+		{"-12350", 3, "-12400"},
+		{"0.012345", 2, "0.012"},
+		{"9.99", 2, "10.0"},
+		{"1.5", 3, "1.5"},
+		{"0", 3, "0"},
+		{"12345", 0, "12345"},
+	}
+
+	for _, test := range tests {
+		value, err := NewDecimal(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, value.RoundSignificant(test.figures).String(), test.value)
+	}
+}
+
+// TestDecimal_Equal tests comparing Decimals with different numbers of decimal places.
+func TestDecimal_Equal(t *testing.T) {
+	a, _ := NewDecimal("1.5")
+	b, _ := NewDecimal("1.50")
+	c, _ := NewDecimal("1.51")
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.Equal(c))
+	assert.True(t, NewDecimalFromInt(1500).Equal(decimalPow10(3).Mul(a)))
+}
+
+// TestDecimal_Float64 tests converting Decimals to float64.
+func TestDecimal_Float64(t *testing.T) {
+	d, _ := NewDecimal("-12.5e-1")
+	assert.Equal(t, -1.25, d.Float64())
+
+	f, err := ConvertToFloat64(d)
+	assert.NoError(t, err)
+	assert.Equal(t, -1.25, f)
+}
+
+// TestOutputType_Apply_Decimal tests that the scaling factor and scale of an
+// output type are applied to Decimal values with exact decimal arithmetic.
+func TestOutputType_Apply_Decimal(t *testing.T) {
+	var tests = []struct {
+		outputType OutputType
+		value      string
+		expected   string
+	}{
+		{OutputType{ScalingFactor: "0.1"}, "0.1", "0.01"},
+		{OutputType{ScalingFactor: "0.1"}, "0.2", "0.02"},
+		{OutputType{ScalingFactor: "3"}, "0.1", "0.3"},
+		{OutputType{ScalingFactor: "1e-3"}, "123456789", "123456.789"},
+		{OutputType{Scale: "k"}, "1234", "1.234"},
+		{OutputType{Scale: "m"}, "1.234", "1234"},
+		{OutputType{ScalingFactor: "0.1", Scale: "k"}, "3", "0.0003"},
+		{OutputType{}, "0.1", "0.1"},
+		{OutputType{ScalingFactor: "0.1", SignificantFigures: 2}, "1234", "120"},
+		{OutputType{Transforms: []*Transform{{Factor: "0.1"}}}, "0.2", "0.02"},
+		{OutputType{Transforms: []*Transform{{Factor: "0.1"}, {SignificantFigures: 1}}}, "0.26", "0.03"},
+	}
+
+	for _, test := range tests {
+		value, err := NewDecimal(test.value)
+		assert.NoError(t, err)
+
+		result := test.outputType.Apply(value)
+		assert.IsType(t, Decimal{}, result)
+		assert.Equal(t, test.expected, result.(Decimal).String(), test.value)
+	}
+
+	// The same scaling with float values is not exact.
+	outputType := OutputType{ScalingFactor: "0.1"}
+	assert.NotEqual(t, 0.01, outputType.Apply(0.1))
+}
+
+// TestNewReading_Decimal tests that the Decimal value of a new reading is encoded as a
+// string, and that the reading is marked as decimal in its context.
+func TestNewReading_Decimal(t *testing.T) {
+	value, err := NewDecimal("0.1")
+	assert.NoError(t, err)
+
+	output := &Output{OutputType: OutputType{Name: "energy", ScalingFactor: "0.1"}}
+	reading, err := NewReading(output, value)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.01", reading.Value)
+	assert.Equal(t, "true", reading.Context[ContextKeyDecimal])
+	assert.Equal(t, "0.01", reading.encode().GetStringValue())
+}
@@ -1,10 +1,15 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
@@ -42,18 +47,179 @@ type DeviceHandler struct {
 	// does not support reading, this can be left as nil.
 	Read func(*Device) ([]*Reading, error)
 
+	// WriteWithContext is a context-aware alternative to Write. The context
+	// carries the trace span for the write (see SpanFromContext), so the trace
+	// ID can be propagated into the handler's own calls and logs. If both are
+	// set, WriteWithContext is used.
+	WriteWithContext func(context.Context, *Device, *WriteData) error
+
+	// ReadWithContext is a context-aware alternative to Read. The context
+	// carries the trace span for the read (see SpanFromContext). If both are
+	// set, ReadWithContext is used.
+	ReadWithContext func(context.Context, *Device) ([]*Reading, error)
+
 	// BulkRead is a function that handles bulk reading for the device. A bulk read
 	// is where all devices of a given kind are read at once, instead of individually.
 	// If a device does not support bulk read, this can be left as nil. Additionally,
 	// a device can only be bulk read if there is no Read handler set.
 	BulkRead func([]*Device) ([]*ReadContext, error)
 
+	// Rollback is a function that reverts a write which was applied to the device.
+	// It is used when a write to a transaction group fails for one of the group's
+	// devices, to undo the write for the devices it was already applied to (see
+	// DeviceInstance.Group). It is passed the write data which was applied. If
+	// this is nil, writes to the device can not be rolled back.
+	Rollback func(*Device, *WriteData) error
+
 	// Listen is a function that will listen for push-based data from the device.
 	// This function is called one per device using the handler, even if there are
 	// other handler functions (e.g. read, write) defined. The listener function
 	// will run in a separate goroutine for each device. The goroutines are started
 	// before the read/write loops.
 	Listen func(*Device, chan *ReadContext) error
+
+	// SerializeReads specifies whether reads for the handler must be serialized.
+	// Some protocols are not safe for concurrent access to the same physical
+	// interface, even across different devices. If this is set, only one read
+	// (or bulk read) will be performed with the handler at a time, even when the
+	// plugin runs in parallel mode. Reads using other handlers are unaffected.
+	SerializeReads bool
+
+	// MinVersion is the minimum device firmware/protocol version supported by
+	// the handler, e.g. "2.1.0". If set, devices using the handler must report
+	// their version in their config Data (see VersionKey). Devices which report a
+	// version below the minimum, or which do not report a valid version, will be
+	// skipped with a warning rather than being read.
+	MinVersion string
+
+	// VersionKey is the key in the device's config Data which holds the version
+	// reported by the device. If not set, this defaults to "firmware". This is
+	// only used if MinVersion is set.
+	VersionKey string
+
+	// RequiredDataKeys are the keys which must be present in the config Data of
+	// every device instance using the handler. These are checked when the device
+	// config is loaded, and when devices are registered by dynamic registration,
+	// so missing keys are reported up front rather than failing at runtime in the
+	// handler.
+	RequiredDataKeys []string
+
+	// ExclusiveDataKeys are groups of mutually-exclusive keys for the config Data
+	// of device instances using the handler. Exactly one key from each group must
+	// be present, e.g. a group of "tcp_host" and "serial_port" requires a device
+	// to specify one of the two, but not both. These are checked when the device
+	// config is loaded.
+	ExclusiveDataKeys [][]string
+
+	// NumericDataKeys are constraints on keys in the config Data of device
+	// instances using the handler whose values are numeric, e.g. a "port" or
+	// "channel". Data values are often specified as strings, so a value is
+	// checked if it is a number or a string which parses as one. These are
+	// checked when the device config is loaded. A constraint does not require
+	// its key to be present; use RequiredDataKeys for that.
+	NumericDataKeys map[string]NumericDataConstraint
+
+	// EmptyReadings is the policy for reads with the handler which return no
+	// readings and no error. This is ambiguous, since it can mean either that the
+	// device has no data, or that it has nothing to report. By default, empty
+	// reads are silently accepted (EmptyReadingsSilent). The policy applies to
+	// devices read individually, to the devices of a bulk read which get no
+	// readings, and to listeners which push a ReadContext with no readings.
+	EmptyReadings EmptyReadingsPolicy
+}
+
+// EmptyReadingsPolicy specifies how a device read which returns no readings and
+// no error is treated. See DeviceHandler.EmptyReadings.
+type EmptyReadingsPolicy uint8
+
+const (
+	// EmptyReadingsSilent accepts empty reads without logging them.
+	EmptyReadingsSilent EmptyReadingsPolicy = iota
+
+	// EmptyReadingsLog accepts empty reads, logging them at debug level.
+	EmptyReadingsLog
+
+	// EmptyReadingsError treats empty reads as failed reads.
+	EmptyReadingsError
+)
+
+// checkEmptyReadings applies the handler's EmptyReadings policy to a read of the
+// given device which returned no readings. An error is returned if the policy
+// treats empty reads as errors.
+func (deviceHandler *DeviceHandler) checkEmptyReadings(device *Device) error {
+	switch deviceHandler.EmptyReadings {
+	case EmptyReadingsLog:
+		log.WithFields(log.Fields{
+			"device":  device.GUID(),
+			"handler": deviceHandler.Name,
+		}).Debug("[sdk] device read returned no readings")
+	case EmptyReadingsError:
+		return fmt.Errorf("handler %s returned no readings for device %s", deviceHandler.Name, device.GUID())
+	}
+	return nil
+}
+
+// NumericDataConstraint is a constraint on the numeric value of a device config
+// Data key. See DeviceHandler.NumericDataKeys.
+type NumericDataConstraint struct {
+	// Min is the minimum allowed value, inclusive. If nil, there is no minimum.
+	Min *float64
+
+	// Max is the maximum allowed value, inclusive. If nil, there is no maximum.
+	Max *float64
+
+	// Integer specifies whether the value must be an integer.
+	Integer bool
+}
+
+// check checks whether the given config Data value satisfies the constraint.
+func (constraint NumericDataConstraint) check(value interface{}) error {
+	number, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return fmt.Errorf("value %v is not a number", value)
+	}
+	if constraint.Integer && number != math.Trunc(number) {
+		return fmt.Errorf("value %v is not an integer", value)
+	}
+	if constraint.Min != nil && number < *constraint.Min {
+		return fmt.Errorf("value %v is below the minimum %v", value, *constraint.Min)
+	}
+	if constraint.Max != nil && number > *constraint.Max {
+		return fmt.Errorf("value %v is above the maximum %v", value, *constraint.Max)
+	}
+	return nil
+}
+
+// defaultVersionKey is the device Data key used to look up a device's reported
+// version if its handler does not specify one.
+const defaultVersionKey = "firmware"
+
+// supportsVersion checks whether the handler supports the version reported in
+// the given device config Data. If the handler does not specify a minimum
+// version, all devices are supported.
+func (deviceHandler *DeviceHandler) supportsVersion(data map[string]interface{}) error {
+	if deviceHandler.MinVersion == "" {
+		return nil
+	}
+
+	key := deviceHandler.VersionKey
+	if key == "" {
+		key = defaultVersionKey
+	}
+
+	version, ok := data[key]
+	if !ok {
+		return fmt.Errorf("device does not report a version (data key %q)", key)
+	}
+
+	result, err := compareVersions(fmt.Sprint(version), deviceHandler.MinVersion)
+	if err != nil {
+		return err
+	}
+	if result < 0 {
+		return fmt.Errorf("device version %v is below the minimum supported version %s", version, deviceHandler.MinVersion)
+	}
+	return nil
 }
 
 // supportsBulkRead checks if the handler supports bulk reading for its Devices.
@@ -63,14 +229,26 @@ type DeviceHandler struct {
 // will not be considered supported and the handler will default to individual
 // reads.
 func (deviceHandler *DeviceHandler) supportsBulkRead() bool {
-	return deviceHandler.Read == nil && deviceHandler.BulkRead != nil
+	return !deviceHandler.supportsRead() && deviceHandler.BulkRead != nil
+}
+
+// supportsRead checks if the handler supports individual reads for its Devices,
+// via either Read or ReadWithContext.
+func (deviceHandler *DeviceHandler) supportsRead() bool {
+	return deviceHandler.Read != nil || deviceHandler.ReadWithContext != nil
+}
+
+// supportsWrite checks if the handler supports writes for its Devices, via
+// either Write or WriteWithContext.
+func (deviceHandler *DeviceHandler) supportsWrite() bool {
+	return deviceHandler.Write != nil || deviceHandler.WriteWithContext != nil
 }
 
 // getDevicesForHandler gets a list of all the devices which use the DeviceHandler.
 func (deviceHandler *DeviceHandler) getDevicesForHandler() []*Device {
 	var devices []*Device
 
-	for _, v := range ctx.devices {
+	for _, v := range ctx.getDevices() {
 		if v.Handler == deviceHandler {
 			devices = append(devices, v)
 		}
@@ -110,6 +288,47 @@ type Device struct {
 	// Any plugin-specific configuration data associated with the Device.
 	Data map[string]interface{}
 
+	// Static context which is added to the context of every reading for the
+	// Device. Context set by the device handler takes precedence.
+	Context map[string]string
+
+	// Constraints on the values which can be written to the Device, by
+	// write action. Writes which violate a constraint are rejected before
+	// they are passed to the device handler.
+	WriteConstraints map[string]*WriteConstraint
+
+	// Overrides of the plugin's readings cache retention settings for the
+	// Device. If this is nil, the plugin's cache settings are used.
+	Cache *DeviceCacheSettings
+
+	// PhaseOffset is the offset into each read interval at which the Device
+	// is read, so that reads of devices sharing an interval can be staggered.
+	PhaseOffset time.Duration
+
+	// Debounce is the duration for which a change in the state of a boolean
+	// reading for the Device must be stable before the change is emitted. If
+	// this is 0, boolean readings are not debounced.
+	Debounce time.Duration
+
+	// ActiveHours is the daily window during which the Device is polled. If
+	// this is nil, the Device is always polled.
+	ActiveHours *ActiveHours
+
+	// Group is the name of the transaction group which the Device belongs to,
+	// if any. See DeviceInstance.Group.
+	Group string
+
+	// Access restricts the operations supported by the Device to reads
+	// ("read-only") or writes ("write-only"), regardless of the functions its
+	// DeviceHandler defines. If this is empty, the Device supports whichever
+	// operations its DeviceHandler does.
+	Access string
+
+	// TypePrefix is the namespace prepended to the Type of the Device's readings
+	// when they are encoded. If this is empty, the plugin's reading type prefix
+	// is used, if any.
+	TypePrefix string
+
 	// The outputs supported by the device. A device output may supply more
 	// info, such as Data, Info, Type, etc. It is up to the user to extract
 	// and use that output info when they perform reads for the Device outputs.
@@ -121,6 +340,11 @@ type Device struct {
 	// id is the deterministic id of the device
 	id string
 
+	// order is the position in which the device was registered with the plugin,
+	// i.e. the order of the devices in their config, followed by dynamically
+	// registered devices.
+	order int
+
 	// bulkRead is a flag that determines whether or not the device should be
 	// read in bulk, i.e. in a batch with other devices of the same kind.
 	bulkRead bool
@@ -189,30 +413,46 @@ func makeDevices(config *DeviceConfig) ([]*Device, error) { // nolint: gocyclo
 				return nil, err
 			}
 
-			// Get the DeviceHandler. If a specific handlerName is set in the config,
-			// we will use that as the definitive handler. Otherwise, use the kind.
-			handlerName := kind.Name
-			if kind.HandlerName != "" {
-				handlerName = kind.HandlerName
-			}
-			if instance.HandlerName != "" {
-				handlerName = instance.HandlerName
-			}
-			handler, err := getHandlerForDevice(handlerName)
+			// Get the DeviceHandler.
+			handler, err := getHandlerForDevice(getHandlerName(kind, instance))
 			if err != nil {
 				return nil, err
 			}
 
+			// If the handler requires a minimum device version, skip any devices
+			// which it does not support.
+			if err := handler.supportsVersion(instance.Data); err != nil {
+				log.WithFields(log.Fields{
+					"kind":    kind.Name,
+					"info":    instance.Info,
+					"handler": handler.Name,
+					"error":   err,
+				}).Warn("[sdk] skipping device with unsupported version")
+				continue
+			}
+
 			device := &Device{
-				Kind:        kind.Name,
-				Metadata:    kind.Metadata,
-				Plugin:      metainfo.Name,
-				Info:        instance.Info,
-				Location:    location,
-				Data:        instance.Data,
-				Outputs:     instanceOutputs,
-				Handler:     handler,
-				SortOrdinal: instance.SortOrdinal,
+				Kind:             kind.Name,
+				Metadata:         kind.Metadata,
+				Plugin:           metainfo.Name,
+				Info:             instance.Info,
+				Location:         location,
+				Data:             instance.Data,
+				Context:          getInstanceContext(kind, instance),
+				WriteConstraints: getInstanceWriteConstraints(kind, instance),
+				Cache:            getInstanceCacheSettings(kind, instance),
+				PhaseOffset:      getInstancePhaseOffset(kind, instance),
+				Debounce:         getInstanceDebounce(kind, instance),
+				ActiveHours:      getInstanceActiveHours(kind, instance),
+				Outputs:          instanceOutputs,
+				Handler:          handler,
+				SortOrdinal:      instance.SortOrdinal,
+				Group:            instance.Group,
+				TypePrefix:       getInstanceTypePrefix(kind, instance),
+				Access:           getInstanceAccess(kind, instance),
+			}
+			if err := device.checkAccess(); err != nil {
+				return nil, err
 			}
 			devices = append(devices, device)
 		}
@@ -220,6 +460,122 @@ func makeDevices(config *DeviceConfig) ([]*Device, error) { // nolint: gocyclo
 	return devices, nil
 }
 
+// getHandlerName gets the name of the DeviceHandler for a device instance. If a
+// specific handlerName is set in the config, we will use that as the definitive
+// handler. Otherwise, use the kind.
+func getHandlerName(kind *DeviceKind, instance *DeviceInstance) string {
+	handlerName := kind.Name
+	if kind.HandlerName != "" {
+		handlerName = kind.HandlerName
+	}
+	if instance.HandlerName != "" {
+		handlerName = instance.HandlerName
+	}
+	return handlerName
+}
+
+// getInstanceContext gets the static reading context for a device instance. The
+// context defined by the instance is merged over the context defined by its kind.
+func getInstanceContext(kind *DeviceKind, instance *DeviceInstance) map[string]string {
+	if len(kind.Context) == 0 && len(instance.Context) == 0 {
+		return nil
+	}
+
+	context := map[string]string{}
+	for k, v := range kind.Context {
+		context[k] = v
+	}
+	for k, v := range instance.Context {
+		context[k] = v
+	}
+	return context
+}
+
+// getInstanceWriteConstraints gets the write constraints for a device instance, by
+// write action. A constraint defined by the instance overrides a constraint for the
+// same action defined by its kind.
+func getInstanceWriteConstraints(kind *DeviceKind, instance *DeviceInstance) map[string]*WriteConstraint {
+	if len(kind.WriteConstraints) == 0 && len(instance.WriteConstraints) == 0 {
+		return nil
+	}
+
+	constraints := map[string]*WriteConstraint{}
+	for _, c := range kind.WriteConstraints {
+		constraints[c.Action] = c
+	}
+	for _, c := range instance.WriteConstraints {
+		constraints[c.Action] = c
+	}
+	return constraints
+}
+
+// getInstancePhaseOffset gets the read phase offset for a device instance. An offset
+// defined by the instance overrides the offset defined by its kind.
+func getInstancePhaseOffset(kind *DeviceKind, instance *DeviceInstance) time.Duration {
+	if instance.PhaseOffset != 0 {
+		return instance.PhaseOffset
+	}
+	return kind.PhaseOffset
+}
+
+// getInstanceDebounce gets the boolean reading debounce duration for a device instance.
+// A duration defined by the instance overrides the duration defined by its kind.
+func getInstanceDebounce(kind *DeviceKind, instance *DeviceInstance) time.Duration {
+	if instance.Debounce != 0 {
+		return instance.Debounce
+	}
+	return kind.Debounce
+}
+
+// getInstanceAccess gets the access restriction for a device instance. The access
+// defined by the instance overrides the access defined by its kind.
+func getInstanceAccess(kind *DeviceKind, instance *DeviceInstance) string {
+	if instance.Access != "" {
+		return instance.Access
+	}
+	return kind.Access
+}
+
+// getInstanceActiveHours gets the active hours for a device instance. Active hours
+// defined by the instance override the active hours defined by its kind.
+func getInstanceActiveHours(kind *DeviceKind, instance *DeviceInstance) *ActiveHours {
+	if instance.ActiveHours != nil {
+		return instance.ActiveHours
+	}
+	return kind.ActiveHours
+}
+
+// getInstanceTypePrefix gets the reading type prefix for a device instance. A prefix
+// defined by the instance overrides the prefix defined by its kind.
+func getInstanceTypePrefix(kind *DeviceKind, instance *DeviceInstance) string {
+	if instance.TypePrefix != "" {
+		return instance.TypePrefix
+	}
+	return kind.TypePrefix
+}
+
+// getInstanceCacheSettings gets the cache retention overrides for a device instance.
+// Settings defined by the instance are layered over the settings defined by its kind.
+func getInstanceCacheSettings(kind *DeviceKind, instance *DeviceInstance) *DeviceCacheSettings {
+	if kind.Cache == nil && instance.Cache == nil {
+		return nil
+	}
+
+	settings := &DeviceCacheSettings{}
+	for _, s := range []*DeviceCacheSettings{kind.Cache, instance.Cache} {
+		if s == nil {
+			continue
+		}
+		if s.TTL != 0 {
+			settings.TTL = s.TTL
+		}
+		if s.MaxEntries != 0 {
+			settings.MaxEntries = s.MaxEntries
+		}
+	}
+	return settings
+}
+
 // getInstanceOutputs get the Outputs for a single device instance. It converts
 // the instance's DeviceOutput to an Output type, and by doing so unifies that
 // output with its corresponding OutputType information.
@@ -285,6 +641,10 @@ type Output struct {
 
 	Info string
 	Data map[string]interface{}
+
+	// Calibration is the optional calibration table applied to the raw reading
+	// values for the output.
+	Calibration *Calibration
 }
 
 // MakeReading makes a reading for the Output. This is a wrapper around `NewReading`.
@@ -292,6 +652,12 @@ func (output *Output) MakeReading(value interface{}) (reading *Reading, err erro
 	return NewReading(output, value)
 }
 
+// MakeReadingWithUnit makes a reading for the Output from a value measured in the
+// given source unit. This is a wrapper around `NewReadingWithUnit`.
+func (output *Output) MakeReadingWithUnit(value interface{}, sourceUnit string) (reading *Reading, err error) {
+	return NewReadingWithUnit(output, value, sourceUnit)
+}
+
 // encode translates the Output to the corresponding gRPC Output message.
 func (output *Output) encode() *synse.Output {
 	sf, err := output.GetScalingFactor()
@@ -299,12 +665,13 @@ func (output *Output) encode() *synse.Output {
 		log.Errorf("[sdk] error getting scaling factor: %v", err)
 	}
 
+	unit := output.EffectiveUnit()
 	return &synse.Output{
 		Name:          output.Name,
 		Type:          output.Type(),
 		Precision:     int32(output.Precision),
 		ScalingFactor: sf,
-		Unit:          output.Unit.encode(),
+		Unit:          unit.encode(),
 	}
 }
 
@@ -316,9 +683,10 @@ func NewOutputFromConfig(config *DeviceOutput) (*Output, error) {
 	}
 
 	return &Output{
-		OutputType: *t,
-		Info:       config.Info,
-		Data:       config.Data,
+		OutputType:  *t,
+		Info:        config.Info,
+		Data:        config.Data,
+		Calibration: config.Calibration,
 	}, nil
 }
 
@@ -328,6 +696,15 @@ func NewOutputFromConfig(config *DeviceOutput) (*Output, error) {
 // returned.
 // FIXME: should we update the unsupported command error to be more descriptive?
 func (device *Device) Read() (*ReadContext, error) {
+	return device.ReadWithContext(context.Background())
+}
+
+// ReadWithContext performs the read action for the device, as set by its
+// DeviceHandler, passing the given context to the handler if it is context-aware.
+//
+// If reading is not supported on the device, an UnsupportedCommandError is
+// returned.
+func (device *Device) ReadWithContext(readCtx context.Context) (*ReadContext, error) {
 	// Bulk read is handled elsewhere.
 	// Device may only support bulk read.
 	if device == nil {
@@ -336,39 +713,164 @@ func (device *Device) Read() (*ReadContext, error) {
 	if device.Handler == nil {
 		return nil, fmt.Errorf("device.Handler is nil")
 	}
-	if device.Handler.Read != nil {
-		readings, err := device.Handler.Read(device)
+	if device.Access == accessWriteOnly {
+		return nil, &errors.UnsupportedCommandError{}
+	}
+	if device.Handler.supportsRead() {
+		var readings []*Reading
+		var err error
+		if device.Handler.ReadWithContext != nil {
+			readings, err = device.Handler.ReadWithContext(readCtx, device)
+		} else {
+			readings, err = device.Handler.Read(device)
+		}
 		if err != nil {
 			return nil, err
 		}
+		if len(readings) == 0 {
+			if err := device.Handler.checkEmptyReadings(device); err != nil {
+				return nil, err
+			}
+		}
 
 		return NewReadContext(device, readings), nil
 	}
 	return nil, &errors.UnsupportedCommandError{}
 }
 
+// getSmoothing gets the smoothing settings of the Device's output for the given
+// reading type. If there is no such output, or it does not smooth its readings,
+// nil is returned.
+func (device *Device) getSmoothing(readingType string) *SmoothingSettings {
+	for _, output := range device.Outputs {
+		if output.Type() == readingType {
+			return output.Smoothing
+		}
+	}
+	return nil
+}
+
+// getCounter gets the counter settings of the Device's output for the given
+// reading type. If there is no such output, or it does not compute rates from
+// counters, nil is returned.
+func (device *Device) getCounter(readingType string) *CounterSettings {
+	for _, output := range device.Outputs {
+		if output.Type() == readingType {
+			return output.Counter
+		}
+	}
+	return nil
+}
+
+// mergeContext merges the Device's static context into the context of each of
+// the given readings. If a reading already has a value for a context key, it is
+// kept, so handler-set context takes precedence over the static context.
+func (device *Device) mergeContext(readings []*Reading) {
+	if len(device.Context) == 0 {
+		return
+	}
+	for _, reading := range readings {
+		if reading == nil {
+			continue
+		}
+		if reading.Context == nil {
+			reading.Context = map[string]string{}
+		}
+		for k, v := range device.Context {
+			if _, exists := reading.Context[k]; !exists {
+				reading.Context[k] = v
+			}
+		}
+	}
+}
+
 // Write performs the write action for the device, as set by its DeviceHandler.
 //
 // If writing is not supported on the device, an UnsupportedCommandError is
 // returned.
 // FIXME: should we update the unsupported command error to be more descriptive?
 func (device *Device) Write(data *WriteData) error {
+	return device.WriteWithContext(context.Background(), data)
+}
+
+// WriteWithContext performs the write action for the device, as set by its
+// DeviceHandler, passing the given context to the handler if it is context-aware.
+//
+// If writing is not supported on the device, an UnsupportedCommandError is
+// returned.
+func (device *Device) WriteWithContext(writeCtx context.Context, data *WriteData) error {
 	if device.IsWritable() {
+		if device.Handler.WriteWithContext != nil {
+			return device.Handler.WriteWithContext(writeCtx, device, data)
+		}
 		return device.Handler.Write(device, data)
 	}
 	return &errors.UnsupportedCommandError{}
 }
 
 // IsReadable checks if the Device is readable based on the presence/absence
-// of a Read/BulkRead action defined in its DeviceHandler.
+// of a Read/BulkRead action defined in its DeviceHandler. A write-only Device
+// is not readable.
 func (device *Device) IsReadable() bool {
-	return device.Handler.Read != nil || device.Handler.BulkRead != nil || device.Handler.Listen != nil
+	return device.Access != accessWriteOnly && device.handlerCanRead()
+}
+
+// handlerCanRead checks if the Device's DeviceHandler defines a Read, BulkRead,
+// or Listen action.
+func (device *Device) handlerCanRead() bool {
+	return device.Handler.supportsRead() || device.Handler.BulkRead != nil || device.Handler.Listen != nil
+}
+
+// checkAccess checks that the Device's DeviceHandler supports the operations
+// allowed by the Device's access restriction, e.g. that a read-only Device has
+// a handler which can read it.
+func (device *Device) checkAccess() error {
+	canRead := device.handlerCanRead()
+	canWrite := device.Handler.supportsWrite()
+
+	var missing string
+	switch device.Access {
+	case accessReadOnly:
+		if !canRead {
+			missing = "reading"
+		}
+	case accessWriteOnly:
+		if !canWrite {
+			missing = "writing"
+		}
+	case accessReadWrite:
+		if !canRead {
+			missing = "reading"
+		} else if !canWrite {
+			missing = "writing"
+		}
+	}
+	if missing != "" {
+		log.WithFields(log.Fields{
+			"kind":    device.Kind,
+			"info":    device.Info,
+			"access":  device.Access,
+			"handler": device.Handler.Name,
+		}).Error("[sdk] device access not supported by its handler")
+		return fmt.Errorf(
+			"device %q (kind %s) is %s, but its handler %s does not support %s",
+			device.Info, device.Kind, device.Access, device.Handler.Name, missing,
+		)
+	}
+	return nil
+}
+
+// IsActive checks if the Device is within its active hours, and so should be
+// polled. A Device with no active hours is always active.
+func (device *Device) IsActive() bool {
+	return device.ActiveHours == nil || device.ActiveHours.contains(clock.Now())
 }
 
 // IsWritable checks if the Device is writable based on the presence/absence
-// of a Write action defined in its DeviceHandler.
+// of a Write action defined in its DeviceHandler. A read-only Device is not
+// writable.
 func (device *Device) IsWritable() bool {
-	return device.Handler.Write != nil
+	return device.Access != accessReadOnly && device.Handler.supportsWrite()
 }
 
 // ID generates the deterministic ID for the Device using its config values.
@@ -390,6 +892,18 @@ func (device *Device) GUID() string {
 	)
 }
 
+// readingTypePrefix gets the namespace to prepend to the Type of the Device's
+// readings. The Device's own prefix takes precedence over the plugin's.
+func (device *Device) readingTypePrefix() string {
+	if device != nil && device.TypePrefix != "" {
+		return device.TypePrefix
+	}
+	if Config.Plugin != nil && Config.Plugin.Settings != nil && Config.Plugin.Settings.Read != nil {
+		return Config.Plugin.Settings.Read.TypePrefix
+	}
+	return ""
+}
+
 // encode translates the Device to the corresponding gRPC Device message.
 func (device *Device) encode() *synse.Device {
 	var output []*synse.Output
@@ -413,37 +927,52 @@ func (device *Device) encode() *synse.Device {
 // If duplicate IDs are detected, the plugin will terminate.
 func updateDeviceMap(devices []*Device) {
 	var foundDuplicates bool
-	for _, d := range devices {
-		if existing, hasDevice := ctx.devices[d.GUID()]; hasDevice {
-			// If we have devices with the same ID, there is something very wrong
-			// happening and we will not want to proceed, since we won't be able
-			// to route to devices correctly.
-			log.WithField("id", d.ID()).Error("[sdk] duplicate device found")
-			foundDuplicates = true
-
-			// Get a dump of the device data, including all nested structs
-			existingJSON, err := existing.JSON()
-			if err != nil {
-				log.Errorf("[sdk] failed to dump device to JSON: %v", err)
-				log.Errorf("[sdk] existing device: %v", existing)
-			} else {
-				log.Errorf("[sdk] existing device: %v", existingJSON)
-			}
-			duplicateJSON, err := d.JSON()
-			if err != nil {
-				log.Errorf("[sdk] failed to dump device to JSON: %v", err)
-				log.Errorf("[sdk] duplicate device: %v", d)
-			} else {
-				log.Errorf("[sdk] duplicate device: %v", duplicateJSON)
+	ctx.updateDevices(func(deviceMap map[string]*Device) error { // nolint: errcheck
+		for _, d := range devices {
+			if existing, hasDevice := deviceMap[d.GUID()]; hasDevice {
+				// If we have devices with the same ID, there is something very wrong
+				// happening and we will not want to proceed, since we won't be able
+				// to route to devices correctly.
+				log.WithField("id", d.ID()).Error("[sdk] duplicate device found")
+				foundDuplicates = true
+
+				// Get a dump of the device data, including all nested structs
+				existingJSON, err := existing.JSON()
+				if err != nil {
+					log.Errorf("[sdk] failed to dump device to JSON: %v", err)
+					log.Errorf("[sdk] existing device: %v", existing)
+				} else {
+					log.Errorf("[sdk] existing device: %v", existingJSON)
+				}
+				duplicateJSON, err := d.JSON()
+				if err != nil {
+					log.Errorf("[sdk] failed to dump device to JSON: %v", err)
+					log.Errorf("[sdk] duplicate device: %v", d)
+				} else {
+					log.Errorf("[sdk] duplicate device: %v", duplicateJSON)
+				}
 			}
+			d.order = len(deviceMap)
+			deviceMap[d.GUID()] = d
 		}
-		ctx.devices[d.GUID()] = d
-	}
+		return nil
+	})
 	if foundDuplicates {
 		log.Panic("[sdk] unable to run plugin with duplicate device configurations")
 	}
 }
 
+// sortDevices sorts devices in the given order: "config" sorts them in the order
+// they were registered, and any other order sorts them by their ID.
+func sortDevices(devices []*Device, order string) {
+	sort.SliceStable(devices, func(i, j int) bool {
+		if order == deviceOrderConfig && devices[i].order != devices[j].order {
+			return devices[i].order < devices[j].order
+		}
+		return devices[i].GUID() < devices[j].GUID()
+	})
+}
+
 // DeviceConfig holds the configuration for the kinds of devices and the
 // instances of those kinds which a plugin will manage.
 type DeviceConfig struct {
@@ -476,22 +1005,28 @@ func NewDeviceConfig() *DeviceConfig {
 // ValidateDeviceConfigData validates the `Data` field(s) of a Device Config to
 // ensure that they are correct. The `Data` fields are plugin-specific, so its
 // up to the user to provide us with a validation function.
+//
+// The validator is run for the data of each device instance and device output
+// separately. Each error it returns is wrapped with the device kind, instance
+// index and location, and output type that the data belongs to, so the offending
+// config can be found.
 func (config *DeviceConfig) ValidateDeviceConfigData(validator func(map[string]interface{}) error) *errors.MultiError {
 	multiErr := errors.NewMultiError("device config 'data' field validation")
 
 	for _, device := range config.Devices {
 		// Verify that the DeviceKind Instances' `Data` field is correct
-		for _, instance := range device.Instances {
+		for i, instance := range device.Instances {
+			where := fmt.Sprintf("device kind %q instance %d (location %q)", device.Name, i, instance.Location)
 			err := validator(instance.Data)
 			if err != nil {
-				multiErr.Add(err)
+				multiErr.Add(fmt.Errorf("%s: %v", where, err))
 			}
 			// Instance Outputs can have their own data too. Verify instance
 			// output data.
 			for _, output := range instance.Outputs {
 				err := validator(output.Data)
 				if err != nil {
-					multiErr.Add(err)
+					multiErr.Add(fmt.Errorf("%s output %q: %v", where, output.Type, err))
 				}
 			}
 		}
@@ -501,7 +1036,7 @@ func (config *DeviceConfig) ValidateDeviceConfigData(validator func(map[string]i
 		for _, output := range device.Outputs {
 			err := validator(output.Data)
 			if err != nil {
-				multiErr.Add(err)
+				multiErr.Add(fmt.Errorf("device kind %q output %q: %v", device.Name, output.Type, err))
 			}
 		}
 	}
@@ -550,6 +1085,15 @@ type LocationConfig struct {
 	Board *LocationData `yaml:"board,omitempty" addedIn:"1.0"`
 }
 
+// UnmarshalYAML unmarshals the LocationConfig, honoring any registered field aliases.
+func (location *LocationConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain LocationConfig
+	if err := unmarshal((*plain)(location)); err != nil {
+		return err
+	}
+	return applyFieldAliases(unmarshal, "locations", location)
+}
+
 // Validate validates that the Location has no configuration errors.
 func (location LocationConfig) Validate(multiErr *errors.MultiError) {
 	// All locations must have a name.
@@ -706,6 +1250,52 @@ type DeviceKind struct {
 	// with. By default, a DeviceKind will match with a DeviceHandler using its
 	// `Name` field. This field can be set to override that behavior.
 	HandlerName string `yaml:"handlerName,omitempty" addedIn:"1.0"`
+
+	// Context is static context which is added to the context of every reading
+	// for all instances of this DeviceKind. Instances can override individual
+	// keys with their own Context.
+	Context map[string]string `yaml:"context,omitempty" addedIn:"1.3"`
+
+	// WriteConstraints specifies constraints on the values which can be written
+	// to instances of this DeviceKind. Instances can override the constraint
+	// for an action with their own WriteConstraints.
+	WriteConstraints []*WriteConstraint `yaml:"writeConstraints,omitempty" addedIn:"1.3"`
+
+	// Cache specifies overrides of the plugin's readings cache retention
+	// settings for instances of this DeviceKind. Instances can override
+	// individual settings with their own Cache.
+	Cache *DeviceCacheSettings `yaml:"cache,omitempty" addedIn:"1.3"`
+
+	// PhaseOffset is the offset into each read interval at which instances of
+	// this DeviceKind are read, e.g. "250ms". This allows the reads of devices
+	// sharing an interval to be staggered. It should be less than the plugin's
+	// read interval. Instances can override this with their own PhaseOffset.
+	PhaseOffset time.Duration `yaml:"phaseOffset,omitempty" addedIn:"1.3"`
+
+	// Debounce is the duration for which a change in the state of a boolean
+	// reading for instances of this DeviceKind must be stable before it is
+	// emitted, e.g. "2s". Until then, the previous state continues to be
+	// reported. This is useful for noisy digital inputs which flap rapidly.
+	// Instances can override this with their own Debounce.
+	Debounce time.Duration `yaml:"debounce,omitempty" addedIn:"1.3"`
+
+	// ActiveHours specifies the daily window during which instances of this
+	// DeviceKind are polled, e.g. to only read devices during business hours.
+	// Instances can override this with their own ActiveHours.
+	ActiveHours *ActiveHours `yaml:"activeHours,omitempty" addedIn:"1.3"`
+
+	// TypePrefix is a namespace which is prepended to the Type of the readings
+	// for instances of this DeviceKind, e.g. "siteA". This overrides the reading
+	// type prefix defined in the plugin's read settings. Instances can override
+	// this with their own TypePrefix.
+	TypePrefix string `yaml:"typePrefix,omitempty" addedIn:"1.3"`
+
+	// Access restricts the operations supported by instances of this DeviceKind.
+	// It can be one of "read-only", "write-only", or "read-write". The device
+	// handler must support the allowed operations. If this is not set, the
+	// operations supported by the handler are allowed. Instances can override
+	// this with their own Access.
+	Access string `yaml:"access,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the DeviceKind has no configuration errors.
@@ -714,6 +1304,31 @@ func (deviceKind DeviceKind) Validate(multiErr *errors.MultiError) {
 		log.WithField("config", deviceKind).Error("[validation] empty name")
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "deviceKind.name"))
 	}
+	if deviceKind.PhaseOffset < 0 {
+		log.WithField("config", deviceKind).Error("[validation] negative phase offset")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceKind.phaseOffset", "non-negative duration"))
+	}
+	if deviceKind.Debounce < 0 {
+		log.WithField("config", deviceKind).Error("[validation] negative debounce")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceKind.debounce", "non-negative duration"))
+	}
+	if deviceKind.TypePrefix != "" && !validNamespacedName(deviceKind.TypePrefix) {
+		log.WithField("config", deviceKind).Error("[validation] bad reading type prefix")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceKind.typePrefix", "a name or dot-separated namespace with no empty segments (e.g. siteA)"))
+	}
+	if !validAccess(deviceKind.Access) {
+		log.WithField("config", deviceKind).Error("[validation] bad access")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceKind.access", accessValues))
+	}
+}
+
+// UnmarshalYAML unmarshals the DeviceKind, honoring any registered field aliases.
+func (deviceKind *DeviceKind) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain DeviceKind
+	if err := unmarshal((*plain)(deviceKind)); err != nil {
+		return err
+	}
+	return applyFieldAliases(unmarshal, "devices", deviceKind)
 }
 
 // DeviceInstance describes an individual instance of a given DeviceKind.
@@ -755,11 +1370,66 @@ type DeviceInstance struct {
 	// don't care.
 	SortOrdinal int32 `yaml:"sortOrdinal,omitempty" addedIn:"1.0"`
 
+	// Group is the name of a transaction group for the device instance. The
+	// instances in a location which share a group can be written to together,
+	// atomically, by writing to the group name as though it were a device ID
+	// in that location. The write is applied to each device in the group, and
+	// if it fails for any of them, it is rolled back for the rest.
+	Group string `yaml:"group,omitempty" addedIn:"1.3"`
+
 	// HandlerName specifies the name of the DeviceHandler to match this DeviceInstance
 	// with. By default, a DeviceInstance will match with a DeviceHandler using
 	// the `Name` field of its DeviceKind. This field can be set to override
 	// that behavior.
 	HandlerName string `yaml:"handlerName,omitempty" addedIn:"1.0"`
+
+	// Context is static context (e.g. an asset ID) which is added to the context
+	// of every reading for this DeviceInstance. If a device handler sets a context
+	// key on a reading, the handler's value is used instead.
+	Context map[string]string `yaml:"context,omitempty" addedIn:"1.3"`
+
+	// WriteConstraints specifies constraints on the values which can be written
+	// to this DeviceInstance. These override the constraints for the same write
+	// action defined by its DeviceKind.
+	WriteConstraints []*WriteConstraint `yaml:"writeConstraints,omitempty" addedIn:"1.3"`
+
+	// Cache specifies overrides of the plugin's readings cache retention
+	// settings for this DeviceInstance. These are layered over the Cache
+	// settings defined by its DeviceKind.
+	Cache *DeviceCacheSettings `yaml:"cache,omitempty" addedIn:"1.3"`
+
+	// PhaseOffset is the offset into each read interval at which this
+	// DeviceInstance is read. This overrides the PhaseOffset defined by its
+	// DeviceKind.
+	PhaseOffset time.Duration `yaml:"phaseOffset,omitempty" addedIn:"1.3"`
+
+	// Debounce is the duration for which a change in the state of a boolean
+	// reading for this DeviceInstance must be stable before it is emitted.
+	// This overrides the Debounce defined by its DeviceKind.
+	Debounce time.Duration `yaml:"debounce,omitempty" addedIn:"1.3"`
+
+	// ActiveHours specifies the daily window during which this DeviceInstance
+	// is polled. This overrides the ActiveHours defined by its DeviceKind.
+	ActiveHours *ActiveHours `yaml:"activeHours,omitempty" addedIn:"1.3"`
+
+	// TypePrefix is a namespace which is prepended to the Type of the readings
+	// for this DeviceInstance. This overrides the TypePrefix defined by its
+	// DeviceKind.
+	TypePrefix string `yaml:"typePrefix,omitempty" addedIn:"1.3"`
+
+	// Access restricts the operations supported by this DeviceInstance. It can
+	// be one of "read-only", "write-only", or "read-write". This overrides the
+	// Access defined by its DeviceKind.
+	Access string `yaml:"access,omitempty" addedIn:"1.3"`
+}
+
+// UnmarshalYAML unmarshals the DeviceInstance, honoring any registered field aliases.
+func (deviceInstance *DeviceInstance) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain DeviceInstance
+	if err := unmarshal((*plain)(deviceInstance)); err != nil {
+		return err
+	}
+	return applyFieldAliases(unmarshal, "devices.instances", deviceInstance)
 }
 
 // Validate validates that the DeviceInstance has no configuration errors.
@@ -769,6 +1439,36 @@ func (deviceInstance DeviceInstance) Validate(multiErr *errors.MultiError) {
 		log.WithField("config", deviceInstance).Error("[validation] empty location")
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "deviceInstance.location"))
 	}
+	if deviceInstance.PhaseOffset < 0 {
+		log.WithField("config", deviceInstance).Error("[validation] negative phase offset")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceInstance.phaseOffset", "non-negative duration"))
+	}
+	if deviceInstance.Debounce < 0 {
+		log.WithField("config", deviceInstance).Error("[validation] negative debounce")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceInstance.debounce", "non-negative duration"))
+	}
+	if deviceInstance.TypePrefix != "" && !validNamespacedName(deviceInstance.TypePrefix) {
+		log.WithField("config", deviceInstance).Error("[validation] bad reading type prefix")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceInstance.typePrefix", "a name or dot-separated namespace with no empty segments (e.g. siteA)"))
+	}
+	if !validAccess(deviceInstance.Access) {
+		log.WithField("config", deviceInstance).Error("[validation] bad access")
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "deviceInstance.access", accessValues))
+	}
+}
+
+// accessValues describes the valid values of a device access restriction, for
+// validation errors.
+var accessValues = fmt.Sprintf("one of: %s, %s, %s", accessReadOnly, accessWriteOnly, accessReadWrite)
+
+// validAccess checks whether a device access restriction is valid. An empty
+// access is valid, and does not restrict the device.
+func validAccess(access string) bool {
+	switch access {
+	case "", accessReadOnly, accessWriteOnly, accessReadWrite:
+		return true
+	}
+	return false
 }
 
 // DeviceOutput describes a valid output for the DeviceInstance.
@@ -792,6 +1492,11 @@ type DeviceOutput struct {
 	//
 	// It is the responsibility of the plugin to handle these values correctly.
 	Data map[string]interface{} `yaml:"data,omitempty" addedIn:"1.0"`
+
+	// Calibration is an optional piecewise-linear calibration table for the
+	// device output. Reading values are calibrated from their raw value before
+	// they are transformed by the output type.
+	Calibration *Calibration `yaml:"calibration,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the DeviceOutput has no configuration errors.
@@ -802,3 +1507,150 @@ func (deviceOutput DeviceOutput) Validate(multiErr *errors.MultiError) {
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "deviceOutput.type"))
 	}
 }
+
+// DeviceCacheSettings specifies overrides of the plugin's readings cache retention
+// settings for a device. These only take effect if the readings cache is enabled.
+type DeviceCacheSettings struct {
+	// TTL is the time-to-live for the device's readings in the readings cache.
+	// If this is not set, the TTL from the plugin's cache settings is used.
+	TTL time.Duration `yaml:"ttl,omitempty" addedIn:"1.3"`
+
+	// MaxEntries is the maximum number of entries to keep in the readings cache
+	// for the device. Once exceeded, the oldest entries are removed. If this is
+	// not set, the number of entries is only bound by the TTL.
+	MaxEntries int `yaml:"maxEntries,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the DeviceCacheSettings has no configuration errors.
+func (settings DeviceCacheSettings) Validate(multiErr *errors.MultiError) {
+	if settings.TTL < 0 {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "cache.ttl", "a non-negative duration"))
+	}
+	if settings.MaxEntries < 0 {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "cache.maxEntries", "a non-negative integer"))
+	}
+}
+
+// ActiveHours specifies a daily window during which a device is polled. Outside
+// of the window, the device is not read and is reported as inactive. The start
+// and end of the window are times of day in the plugin's local time, formatted
+// as "15:04". The window includes its start but not its end, and wraps past
+// midnight if it ends before it starts (e.g. 22:00 to 06:00).
+type ActiveHours struct {
+	// Start is the time of day at which the window starts, e.g. "08:00".
+	Start string `yaml:"start,omitempty" addedIn:"1.3"`
+
+	// End is the time of day at which the window ends, e.g. "18:00".
+	End string `yaml:"end,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the ActiveHours has no configuration errors.
+func (hours ActiveHours) Validate(multiErr *errors.MultiError) {
+	start, err := parseTimeOfDay(hours.Start)
+	if err != nil {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "activeHours.start", "a time of day (e.g. 08:00)"))
+	}
+	end, e := parseTimeOfDay(hours.End)
+	if e != nil {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "activeHours.end", "a time of day (e.g. 18:00)"))
+	}
+	if err == nil && e == nil && start == end {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "activeHours.end", "a time of day different from the start"))
+	}
+}
+
+// contains checks whether the given time falls within the active hours. If the
+// active hours can not be parsed, they are considered to contain all times, so
+// a misconfigured device is still polled.
+func (hours *ActiveHours) contains(t time.Time) bool {
+	start, err := parseTimeOfDay(hours.Start)
+	if err != nil {
+		return true
+	}
+	end, err := parseTimeOfDay(hours.End)
+	if err != nil {
+		return true
+	}
+
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseTimeOfDay parses a time of day, formatted as "15:04", into the duration
+// since midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// WriteConstraint specifies the values which are allowed to be written to
+// a device for a given write action. The allowed values can be specified as
+// a numeric range, as a set of enumerated values, or both.
+type WriteConstraint struct {
+	// Action is the write action which the constraint applies to.
+	Action string `yaml:"action,omitempty" addedIn:"1.3"`
+
+	// Min is the minimum numeric value (inclusive) allowed for the write.
+	Min *float64 `yaml:"min,omitempty" addedIn:"1.3"`
+
+	// Max is the maximum numeric value (inclusive) allowed for the write.
+	Max *float64 `yaml:"max,omitempty" addedIn:"1.3"`
+
+	// Values are the enumerated values allowed for the write.
+	Values []string `yaml:"values,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the WriteConstraint has no configuration errors.
+func (constraint WriteConstraint) Validate(multiErr *errors.MultiError) {
+	// All write constraints must be associated with a write action.
+	if constraint.Action == "" {
+		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "writeConstraint.action"))
+	}
+
+	// If a range is specified, the minimum can not exceed the maximum.
+	if constraint.Min != nil && constraint.Max != nil && *constraint.Min > *constraint.Max {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"writeConstraint.min",
+			"a value less than or equal to writeConstraint.max",
+		))
+	}
+}
+
+// check checks that the given write value satisfies the WriteConstraint.
+func (constraint *WriteConstraint) check(value string) error {
+	if len(constraint.Values) > 0 {
+		allowed := false
+		for _, v := range constraint.Values {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("value %q is not one of the allowed values %v", value, constraint.Values)
+		}
+	}
+
+	if constraint.Min == nil && constraint.Max == nil {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("value %q is not numeric", value)
+	}
+	if constraint.Min != nil && f < *constraint.Min {
+		return fmt.Errorf("value %v is less than the minimum allowed value %v", f, *constraint.Min)
+	}
+	if constraint.Max != nil && f > *constraint.Max {
+		return fmt.Errorf("value %v is greater than the maximum allowed value %v", f, *constraint.Max)
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 )
@@ -183,7 +186,7 @@ func TestDevice_JSON_1(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		"{\"Data\":null,\"Info\":\"\",\"Kind\":\"\",\"Location\":null,\"Metadata\":null,\"Outputs\":null,\"Plugin\":\"\",\"SortOrdinal\":0}",
+		"{\"Access\":\"\",\"ActiveHours\":null,\"Cache\":null,\"Context\":null,\"Data\":null,\"Debounce\":0,\"Group\":\"\",\"Info\":\"\",\"Kind\":\"\",\"Location\":null,\"Metadata\":null,\"Outputs\":null,\"PhaseOffset\":0,\"Plugin\":\"\",\"SortOrdinal\":0,\"TypePrefix\":\"\",\"WriteConstraints\":null}",
 		out,
 	)
 }
@@ -206,7 +209,7 @@ func TestDevice_JSON_2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		"{\"Data\":null,\"Info\":\"info\",\"Kind\":\"foo\",\"Location\":{\"Board\":\"board\",\"Rack\":\"rack\"},\"Metadata\":{\"test\":\"data\"},\"Outputs\":null,\"Plugin\":\"\",\"SortOrdinal\":1}",
+		"{\"Access\":\"\",\"ActiveHours\":null,\"Cache\":null,\"Context\":null,\"Data\":null,\"Debounce\":0,\"Group\":\"\",\"Info\":\"info\",\"Kind\":\"foo\",\"Location\":{\"Board\":\"board\",\"Rack\":\"rack\"},\"Metadata\":{\"test\":\"data\"},\"Outputs\":null,\"PhaseOffset\":0,\"Plugin\":\"\",\"SortOrdinal\":1,\"TypePrefix\":\"\",\"WriteConstraints\":null}",
 		out,
 	)
 }
@@ -257,6 +260,549 @@ func TestMakeDevices(t *testing.T) {
 	assert.Equal(t, 1, len(devices))
 }
 
+// TestMakeDevices_MinVersion tests making devices when the handler specifies a
+// minimum supported version. Devices below the minimum should be skipped.
+func TestMakeDevices_MinVersion(t *testing.T) {
+	defer resetContext()
+
+	ctx.outputTypes["something"] = &OutputType{
+		Name: "something",
+	}
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", MinVersion: "2.1"},
+	}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Outputs: []*DeviceOutput{
+					{
+						Type: "something",
+					},
+				},
+				Instances: []*DeviceInstance{
+					{
+						Info:     "above",
+						Location: "foo",
+						Data:     map[string]interface{}{"firmware": "2.10.0"},
+					},
+					{
+						Info:     "below",
+						Location: "foo",
+						Data:     map[string]interface{}{"firmware": "2.0.9"},
+					},
+					{
+						Info:     "equal",
+						Location: "foo",
+						Data:     map[string]interface{}{"firmware": 2.1},
+					},
+					{
+						Info:     "missing",
+						Location: "foo",
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(devices))
+	assert.Equal(t, "above", devices[0].Info)
+	assert.Equal(t, "equal", devices[1].Info)
+}
+
+// TestMakeDevices_MinVersionKey tests making devices when the handler specifies a
+// minimum supported version with a custom version key.
+func TestMakeDevices_MinVersionKey(t *testing.T) {
+	defer resetContext()
+
+	ctx.outputTypes["something"] = &OutputType{
+		Name: "something",
+	}
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", MinVersion: "3", VersionKey: "protocol"},
+	}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Outputs: []*DeviceOutput{
+					{
+						Type: "something",
+					},
+				},
+				Instances: []*DeviceInstance{
+					{
+						Info:     "below",
+						Location: "foo",
+						Data:     map[string]interface{}{"protocol": 2, "firmware": "5.0"},
+					},
+					{
+						Info:     "above",
+						Location: "foo",
+						Data:     map[string]interface{}{"protocol": "v3.1"},
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(devices))
+	assert.Equal(t, "above", devices[0].Info)
+}
+
+// TestMakeDevices_Context tests making devices with static reading context
+// defined at the kind and instance level.
+func TestMakeDevices_Context(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test"},
+	}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name:    "test",
+				Context: map[string]string{"site": "dc-1", "room": "a"},
+				Instances: []*DeviceInstance{
+					{
+						Info:     "override",
+						Location: "foo",
+						Context:  map[string]string{"room": "b", "asset_id": "123"},
+					},
+					{
+						Info:     "inherit",
+						Location: "foo",
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(devices))
+	assert.Equal(t, map[string]string{"site": "dc-1", "room": "b", "asset_id": "123"}, devices[0].Context)
+	assert.Equal(t, map[string]string{"site": "dc-1", "room": "a"}, devices[1].Context)
+}
+
+// TestMakeDevices_Access tests making devices when the device kind and instance
+// specify an access restriction.
+func TestMakeDevices_Access(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{
+			Name:  "test",
+			Read:  func(device *Device) ([]*Reading, error) { return nil, nil },
+			Write: func(device *Device, data *WriteData) error { return nil },
+		},
+	}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name:   "test",
+				Access: "read-only",
+				Instances: []*DeviceInstance{
+					{
+						Info:     "inherit",
+						Location: "foo",
+					},
+					{
+						Info:     "override",
+						Location: "foo",
+						Access:   "write-only",
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(devices))
+
+	assert.Equal(t, "read-only", devices[0].Access)
+	assert.True(t, devices[0].IsReadable())
+	assert.False(t, devices[0].IsWritable())
+	assert.IsType(t, &errors.UnsupportedCommandError{}, devices[0].Write(&WriteData{Action: "test"}))
+
+	assert.Equal(t, "write-only", devices[1].Access)
+	assert.False(t, devices[1].IsReadable())
+	assert.True(t, devices[1].IsWritable())
+	_, err = devices[1].Read()
+	assert.IsType(t, &errors.UnsupportedCommandError{}, err)
+}
+
+// TestMakeDevices_AccessUnsupported tests making devices when a device's access
+// restriction allows an operation which its handler does not support.
+func TestMakeDevices_AccessUnsupported(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{
+			Name: "test",
+			Read: func(device *Device) ([]*Reading, error) { return nil, nil },
+		},
+	}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Info:     "fan speed",
+						Location: "foo",
+						Access:   "write-only",
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.EqualError(t, err, `device "fan speed" (kind test) is write-only, but its handler test does not support writing`)
+	assert.Nil(t, devices)
+}
+
+// TestMakeDevices_WriteConstraints tests making devices when the device kind and
+// instance specify write constraints.
+func TestMakeDevices_WriteConstraints(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test"},
+	}
+
+	min, max := 10.0, 30.0
+	kindSetpoint := &WriteConstraint{Action: "setpoint", Min: &min, Max: &max}
+	kindMode := &WriteConstraint{Action: "mode", Values: []string{"auto", "manual"}}
+	instanceMode := &WriteConstraint{Action: "mode", Values: []string{"auto"}}
+
+	cfg := &DeviceConfig{
+		Locations: []*LocationConfig{
+			{
+				Name:  "foo",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name:             "test",
+				WriteConstraints: []*WriteConstraint{kindSetpoint, kindMode},
+				Instances: []*DeviceInstance{
+					{
+						Info:             "override",
+						Location:         "foo",
+						WriteConstraints: []*WriteConstraint{instanceMode},
+					},
+					{
+						Info:     "inherit",
+						Location: "foo",
+					},
+				},
+			},
+		},
+	}
+
+	devices, err := makeDevices(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(devices))
+	assert.Equal(t, map[string]*WriteConstraint{"setpoint": kindSetpoint, "mode": instanceMode}, devices[0].WriteConstraints)
+	assert.Equal(t, map[string]*WriteConstraint{"setpoint": kindSetpoint, "mode": kindMode}, devices[1].WriteConstraints)
+}
+
+// Test_getInstanceCacheSettings tests getting the cache settings for a device
+// instance, layered over the cache settings of its kind.
+func Test_getInstanceCacheSettings(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		kind     *DeviceKind
+		instance *DeviceInstance
+		expected *DeviceCacheSettings
+	}{
+		{
+			desc:     "no cache settings",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{},
+			expected: nil,
+		},
+		{
+			desc:     "kind cache settings only",
+			kind:     &DeviceKind{Cache: &DeviceCacheSettings{TTL: time.Hour, MaxEntries: 10}},
+			instance: &DeviceInstance{},
+			expected: &DeviceCacheSettings{TTL: time.Hour, MaxEntries: 10},
+		},
+		{
+			desc:     "instance cache settings only",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{Cache: &DeviceCacheSettings{TTL: time.Hour}},
+			expected: &DeviceCacheSettings{TTL: time.Hour},
+		},
+		{
+			desc:     "instance cache settings layered over kind",
+			kind:     &DeviceKind{Cache: &DeviceCacheSettings{TTL: time.Hour, MaxEntries: 10}},
+			instance: &DeviceInstance{Cache: &DeviceCacheSettings{TTL: time.Minute}},
+			expected: &DeviceCacheSettings{TTL: time.Minute, MaxEntries: 10},
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := getInstanceCacheSettings(testCase.kind, testCase.instance)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// Test_getInstancePhaseOffset tests getting the read phase offset for a device instance.
+func Test_getInstancePhaseOffset(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		kind     *DeviceKind
+		instance *DeviceInstance
+		expected time.Duration
+	}{
+		{
+			desc:     "no phase offset",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{},
+			expected: 0,
+		},
+		{
+			desc:     "kind phase offset only",
+			kind:     &DeviceKind{PhaseOffset: time.Second},
+			instance: &DeviceInstance{},
+			expected: time.Second,
+		},
+		{
+			desc:     "instance phase offset overrides kind",
+			kind:     &DeviceKind{PhaseOffset: time.Second},
+			instance: &DeviceInstance{PhaseOffset: 250 * time.Millisecond},
+			expected: 250 * time.Millisecond,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := getInstancePhaseOffset(testCase.kind, testCase.instance)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// Test_getInstanceDebounce tests getting the boolean reading debounce duration for
+// a device instance.
+func Test_getInstanceDebounce(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		kind     *DeviceKind
+		instance *DeviceInstance
+		expected time.Duration
+	}{
+		{
+			desc:     "no debounce",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{},
+			expected: 0,
+		},
+		{
+			desc:     "kind debounce only",
+			kind:     &DeviceKind{Debounce: time.Second},
+			instance: &DeviceInstance{},
+			expected: time.Second,
+		},
+		{
+			desc:     "instance debounce overrides kind",
+			kind:     &DeviceKind{Debounce: time.Second},
+			instance: &DeviceInstance{Debounce: 2 * time.Second},
+			expected: 2 * time.Second,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := getInstanceDebounce(testCase.kind, testCase.instance)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// Test_getInstanceActiveHours tests getting the active hours for a device instance.
+func Test_getInstanceActiveHours(t *testing.T) {
+	kindHours := &ActiveHours{Start: "08:00", End: "18:00"}
+	instanceHours := &ActiveHours{Start: "22:00", End: "06:00"}
+
+	var testTable = []struct {
+		desc     string
+		kind     *DeviceKind
+		instance *DeviceInstance
+		expected *ActiveHours
+	}{
+		{
+			desc:     "no active hours",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{},
+			expected: nil,
+		},
+		{
+			desc:     "kind active hours only",
+			kind:     &DeviceKind{ActiveHours: kindHours},
+			instance: &DeviceInstance{},
+			expected: kindHours,
+		},
+		{
+			desc:     "instance active hours override kind",
+			kind:     &DeviceKind{ActiveHours: kindHours},
+			instance: &DeviceInstance{ActiveHours: instanceHours},
+			expected: instanceHours,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := getInstanceActiveHours(testCase.kind, testCase.instance)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// Test_getInstanceTypePrefix tests getting the reading type prefix for a device instance.
+func Test_getInstanceTypePrefix(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		kind     *DeviceKind
+		instance *DeviceInstance
+		expected string
+	}{
+		{
+			desc:     "no prefix",
+			kind:     &DeviceKind{},
+			instance: &DeviceInstance{},
+			expected: "",
+		},
+		{
+			desc:     "kind prefix only",
+			kind:     &DeviceKind{TypePrefix: "siteA"},
+			instance: &DeviceInstance{},
+			expected: "siteA",
+		},
+		{
+			desc:     "instance prefix overrides kind",
+			kind:     &DeviceKind{TypePrefix: "siteA"},
+			instance: &DeviceInstance{TypePrefix: "siteB"},
+			expected: "siteB",
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := getInstanceTypePrefix(testCase.kind, testCase.instance)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestActiveHours_contains tests checking whether times fall within active hours.
+func TestActiveHours_contains(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		hours    ActiveHours
+		time     string
+		expected bool
+	}{
+		{"before window", ActiveHours{Start: "08:00", End: "18:00"}, "07:59", false},
+		{"start of window", ActiveHours{Start: "08:00", End: "18:00"}, "08:00", true},
+		{"within window", ActiveHours{Start: "08:00", End: "18:00"}, "12:30", true},
+		{"end of window", ActiveHours{Start: "08:00", End: "18:00"}, "18:00", false},
+		{"after window", ActiveHours{Start: "08:00", End: "18:00"}, "23:00", false},
+		{"overnight, before midnight", ActiveHours{Start: "22:00", End: "06:00"}, "23:15", true},
+		{"overnight, after midnight", ActiveHours{Start: "22:00", End: "06:00"}, "02:00", true},
+		{"overnight, outside window", ActiveHours{Start: "22:00", End: "06:00"}, "12:00", false},
+		{"invalid window", ActiveHours{Start: "foo", End: "06:00"}, "12:00", true},
+	}
+
+	for _, testCase := range testTable {
+		now, err := time.Parse("15:04", testCase.time)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expected, testCase.hours.contains(now), testCase.desc)
+	}
+}
+
+// TestActiveHours_Validate tests validating ActiveHours.
+func TestActiveHours_Validate(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		hours    ActiveHours
+		errCount int
+	}{
+		{"valid window", ActiveHours{Start: "08:00", End: "18:00"}, 0},
+		{"valid overnight window", ActiveHours{Start: "22:00", End: "06:00"}, 0},
+		{"invalid start", ActiveHours{Start: "8am", End: "18:00"}, 1},
+		{"invalid end", ActiveHours{Start: "08:00", End: "25:00"}, 1},
+		{"empty window", ActiveHours{}, 2},
+		{"start same as end", ActiveHours{Start: "08:00", End: "08:00"}, 1},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+		testCase.hours.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
+
+// TestDevice_IsActive tests checking whether a device is within its active hours.
+func TestDevice_IsActive(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	c := useFakeClock(t, time.Date(2018, 1, 1, 7, 30, 0, 0, time.UTC))
+
+	device := &Device{}
+	assert.True(t, device.IsActive())
+
+	device.ActiveHours = &ActiveHours{Start: "08:00", End: "18:00"}
+	assert.False(t, device.IsActive())
+
+	c.Advance(time.Hour)
+	assert.True(t, device.IsActive())
+
+	c.Advance(10 * time.Hour)
+	assert.False(t, device.IsActive())
+}
+
 // TestMakeDevices2 tests making devices when no device kinds are specified
 func TestMakeDevices2(t *testing.T) {
 	cfg := &DeviceConfig{
@@ -598,6 +1144,89 @@ func TestDeviceReadErr(t *testing.T) {
 	assert.Equal(t, "test error", err.Error())
 }
 
+// emptyReadingsTestDevice creates a device whose handler returns no readings
+// and no error, with the given EmptyReadings policy.
+func emptyReadingsTestDevice(policy EmptyReadingsPolicy) *Device {
+	return &Device{
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Name: "test",
+			Read: func(device *Device) ([]*Reading, error) {
+				return []*Reading{}, nil
+			},
+			EmptyReadings: policy,
+		},
+		id: "1",
+	}
+}
+
+// emptyReadingsLogEntries gets the log entries recorded by the hook for empty reads.
+func emptyReadingsLogEntries(hook *logtest.Hook) []*log.Entry {
+	var entries []*log.Entry
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "[sdk] device read returned no readings" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// TestDeviceRead_EmptyReadingsSilent tests that with the silent policy, an empty read
+// succeeds without being logged.
+func TestDeviceRead_EmptyReadingsSilent(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	defer log.SetLevel(log.GetLevel())
+	log.SetLevel(log.DebugLevel)
+
+	ctx, err := emptyReadingsTestDevice(EmptyReadingsSilent).Read()
+	assert.NoError(t, err)
+	assert.NotNil(t, ctx)
+	assert.Empty(t, ctx.Reading)
+	assert.Empty(t, emptyReadingsLogEntries(hook))
+}
+
+// TestDeviceRead_EmptyReadingsLog tests that with the log policy, an empty read
+// succeeds and is logged at debug level.
+func TestDeviceRead_EmptyReadingsLog(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+	defer log.SetLevel(log.GetLevel())
+	log.SetLevel(log.DebugLevel)
+
+	ctx, err := emptyReadingsTestDevice(EmptyReadingsLog).Read()
+	assert.NoError(t, err)
+	assert.NotNil(t, ctx)
+	assert.Empty(t, ctx.Reading)
+
+	entries := emptyReadingsLogEntries(hook)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, log.DebugLevel, entries[0].Level)
+	assert.Equal(t, "rack-board-1", entries[0].Data["device"])
+	assert.Equal(t, "test", entries[0].Data["handler"])
+}
+
+// TestDeviceRead_EmptyReadingsError tests that with the error policy, an empty read
+// fails.
+func TestDeviceRead_EmptyReadingsError(t *testing.T) {
+	ctx, err := emptyReadingsTestDevice(EmptyReadingsError).Read()
+	assert.Nil(t, ctx)
+	assert.EqualError(t, err, "handler test returned no readings for device rack-board-1")
+}
+
+// TestDeviceRead_EmptyReadingsErrorWithReadings tests that the error policy does not
+// affect reads which return readings.
+func TestDeviceRead_EmptyReadingsErrorWithReadings(t *testing.T) {
+	device := emptyReadingsTestDevice(EmptyReadingsError)
+	device.Handler.Read = func(device *Device) ([]*Reading, error) {
+		return []*Reading{{Type: "foo", Value: 1}}, nil
+	}
+
+	ctx, err := device.Read()
+	assert.NoError(t, err)
+	assert.Len(t, ctx.Reading, 1)
+}
+
 // TestDeviceReadOk tests reading from a device when the device is readable,
 // and the device config is correct, so we get back a good reading.
 func TestDeviceReadOk(t *testing.T) {
@@ -1038,7 +1667,7 @@ func TestDeviceConfig_JSON_2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		`{"Version":"1.0","Locations":[{"Name":"test","Rack":{"Name":"test","FromEnv":""},"Board":{"Name":"test","FromEnv":""}}],"Devices":[{"Name":"test","Metadata":null,"Instances":null,"Outputs":null,"HandlerName":""}]}`,
+		`{"Version":"1.0","Locations":[{"Name":"test","Rack":{"Name":"test","FromEnv":""},"Board":{"Name":"test","FromEnv":""}}],"Devices":[{"Name":"test","Metadata":null,"Instances":null,"Outputs":null,"HandlerName":"","Context":null,"WriteConstraints":null,"Cache":null,"PhaseOffset":0,"Debounce":0,"ActiveHours":null,"TypePrefix":"","Access":""}]}`,
 		out,
 	)
 }
@@ -1658,6 +2287,38 @@ func TestDeviceKind_Validate_Error(t *testing.T) {
 			errCount: 1,
 			kind:     DeviceKind{},
 		},
+		{
+			desc:     "DeviceKind has a negative phase offset",
+			errCount: 1,
+			kind: DeviceKind{
+				Name:        "test",
+				PhaseOffset: -time.Second,
+			},
+		},
+		{
+			desc:     "DeviceKind has a negative debounce",
+			errCount: 1,
+			kind: DeviceKind{
+				Name:     "test",
+				Debounce: -time.Second,
+			},
+		},
+		{
+			desc:     "DeviceKind has an invalid type prefix",
+			errCount: 1,
+			kind: DeviceKind{
+				Name:       "test",
+				TypePrefix: "siteA.",
+			},
+		},
+		{
+			desc:     "DeviceKind has an invalid access",
+			errCount: 1,
+			kind: DeviceKind{
+				Name:   "test",
+				Access: "readonly",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -1719,6 +2380,38 @@ func TestDeviceInstance_Validate_Error(t *testing.T) {
 				Location: "",
 			},
 		},
+		{
+			desc:     "DeviceInstance has a negative phase offset",
+			errCount: 1,
+			instance: DeviceInstance{
+				Location:    "test",
+				PhaseOffset: -time.Second,
+			},
+		},
+		{
+			desc:     "DeviceInstance has a negative debounce",
+			errCount: 1,
+			instance: DeviceInstance{
+				Location: "test",
+				Debounce: -time.Second,
+			},
+		},
+		{
+			desc:     "DeviceInstance has an invalid type prefix",
+			errCount: 1,
+			instance: DeviceInstance{
+				Location:   "test",
+				TypePrefix: "site A",
+			},
+		},
+		{
+			desc:     "DeviceInstance has an invalid access",
+			errCount: 1,
+			instance: DeviceInstance{
+				Location: "test",
+				Access:   "write",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -1777,6 +2470,124 @@ func TestDeviceOutput_Validate_Error(t *testing.T) {
 	}
 }
 
+// TestDeviceCacheSettings_Validate tests validating a DeviceCacheSettings.
+func TestDeviceCacheSettings_Validate(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		errCount int
+		settings DeviceCacheSettings
+	}{
+		{
+			desc:     "DeviceCacheSettings is empty",
+			errCount: 0,
+			settings: DeviceCacheSettings{},
+		},
+		{
+			desc:     "DeviceCacheSettings is valid",
+			errCount: 0,
+			settings: DeviceCacheSettings{TTL: time.Hour, MaxEntries: 10},
+		},
+		{
+			desc:     "DeviceCacheSettings has negative values",
+			errCount: 2,
+			settings: DeviceCacheSettings{TTL: -time.Hour, MaxEntries: -10},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.settings.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
+
+// TestWriteConstraint_Validate_Ok tests validating a WriteConstraint with no errors.
+func TestWriteConstraint_Validate_Ok(t *testing.T) {
+	min, max := 10.0, 30.0
+	var testTable = []struct {
+		desc       string
+		constraint WriteConstraint
+	}{
+		{
+			desc:       "WriteConstraint has a range",
+			constraint: WriteConstraint{Action: "setpoint", Min: &min, Max: &max},
+		},
+		{
+			desc:       "WriteConstraint has values",
+			constraint: WriteConstraint{Action: "mode", Values: []string{"auto", "manual"}},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.constraint.Validate(merr)
+		assert.NoError(t, merr.Err(), testCase.desc)
+	}
+}
+
+// TestWriteConstraint_Validate_Error tests validating a WriteConstraint with errors.
+func TestWriteConstraint_Validate_Error(t *testing.T) {
+	min, max := 10.0, 30.0
+	var testTable = []struct {
+		desc       string
+		errCount   int
+		constraint WriteConstraint
+	}{
+		{
+			desc:       "WriteConstraint has no action",
+			errCount:   1,
+			constraint: WriteConstraint{Values: []string{"auto"}},
+		},
+		{
+			desc:       "WriteConstraint min is greater than max",
+			errCount:   1,
+			constraint: WriteConstraint{Action: "setpoint", Min: &max, Max: &min},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.constraint.Validate(merr)
+		assert.Error(t, merr.Err(), testCase.desc)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), merr.Error())
+	}
+}
+
+// TestWriteConstraint_check tests checking write values against a WriteConstraint.
+func TestWriteConstraint_check(t *testing.T) {
+	min, max := 10.0, 30.0
+	var testTable = []struct {
+		desc       string
+		constraint WriteConstraint
+		value      string
+		ok         bool
+	}{
+		{"in range", WriteConstraint{Min: &min, Max: &max}, "20", true},
+		{"at minimum", WriteConstraint{Min: &min, Max: &max}, "10", true},
+		{"at maximum", WriteConstraint{Min: &min, Max: &max}, "30", true},
+		{"below minimum", WriteConstraint{Min: &min, Max: &max}, "9.5", false},
+		{"above maximum", WriteConstraint{Min: &min, Max: &max}, "31", false},
+		{"minimum only", WriteConstraint{Min: &min}, "100", true},
+		{"maximum only", WriteConstraint{Max: &max}, "-100", true},
+		{"not numeric", WriteConstraint{Min: &min, Max: &max}, "warm", false},
+		{"allowed value", WriteConstraint{Values: []string{"auto", "manual"}}, "manual", true},
+		{"not allowed value", WriteConstraint{Values: []string{"auto", "manual"}}, "off", false},
+		{"no constraints", WriteConstraint{}, "anything", true},
+	}
+
+	for _, testCase := range testTable {
+		err := testCase.constraint.check(testCase.value)
+		if testCase.ok {
+			assert.NoError(t, err, testCase.desc)
+		} else {
+			assert.Error(t, err, testCase.desc)
+		}
+	}
+}
+
 // TestDeviceConfig_ValidateDeviceConfigDataOk tests validating config data when there
 // are no errors.
 func TestDeviceConfig_ValidateDeviceConfigDataOk(t *testing.T) {
@@ -1943,6 +2754,43 @@ func TestDeviceConfig_ValidateDeviceConfigDataError(t *testing.T) {
 	}
 }
 
+// TestDeviceConfig_ValidateDeviceConfigDataError2 tests that the errors from validating
+// config data identify the device instance or output whose data is invalid.
+func TestDeviceConfig_ValidateDeviceConfigDataError2(t *testing.T) {
+	config := DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Devices: []*DeviceKind{
+			{
+				Name:    "temperature",
+				Outputs: []*DeviceOutput{{Type: "temperature", Data: map[string]interface{}{"bad": true}}},
+				Instances: []*DeviceInstance{
+					{Location: "r1b1", Data: map[string]interface{}{"id": 1}},
+					{Location: "r1b2", Data: map[string]interface{}{"id": 2, "bad": true}},
+					{
+						Location: "r1b3",
+						Data:     map[string]interface{}{"id": 3},
+						Outputs:  []*DeviceOutput{{Type: "humidity", Data: map[string]interface{}{"bad": true}}},
+					},
+				},
+			},
+		},
+	}
+
+	var validator = func(data map[string]interface{}) error {
+		if _, bad := data["bad"]; bad {
+			return fmt.Errorf("bad data")
+		}
+		return nil
+	}
+
+	merr := config.ValidateDeviceConfigData(validator)
+	assert.Error(t, merr.Err())
+	assert.Len(t, merr.Errors, 3)
+	assert.EqualError(t, merr.Errors[0], `device kind "temperature" instance 1 (location "r1b2"): bad data`)
+	assert.EqualError(t, merr.Errors[1], `device kind "temperature" instance 2 (location "r1b3") output "humidity": bad data`)
+	assert.EqualError(t, merr.Errors[2], `device kind "temperature" output "temperature": bad data`)
+}
+
 // ----------
 // Examples
 // ----------
@@ -2017,3 +2865,30 @@ func ExampleDevice_GUID() {
 	fmt.Println(guid)
 	// Output: foo-bar-baz
 }
+
+// TestDevice_mergeContext tests merging a device's static context into its readings.
+func TestDevice_mergeContext(t *testing.T) {
+	device := &Device{
+		Context: map[string]string{"asset_id": "123", "site": "dc-1"},
+	}
+
+	readings := []*Reading{
+		{Value: 1, Context: map[string]string{"site": "handler"}},
+		{Value: 2},
+		nil,
+	}
+	device.mergeContext(readings)
+
+	// handler-set keys win on conflict
+	assert.Equal(t, map[string]string{"asset_id": "123", "site": "handler"}, readings[0].Context)
+	assert.Equal(t, map[string]string{"asset_id": "123", "site": "dc-1"}, readings[1].Context)
+}
+
+// TestDevice_mergeContext2 tests merging when the device has no static context.
+func TestDevice_mergeContext2(t *testing.T) {
+	device := &Device{}
+
+	readings := []*Reading{{Value: 1}}
+	device.mergeContext(readings)
+	assert.Nil(t, readings[0].Context)
+}
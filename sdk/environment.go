@@ -6,6 +6,13 @@ const (
 	// file that specifies the device configs.
 	EnvDeviceConfig = "PLUGIN_DEVICE_CONFIG"
 
+	// EnvDeviceConfigMap is the environment variable that can be used to
+	// specify a Kubernetes ConfigMap, as "namespace/name", to load the device
+	// configs from. When set, the ConfigMap is fetched from the Kubernetes API
+	// using the plugin's in-cluster credentials instead of searching for
+	// device config files.
+	EnvDeviceConfigMap = "PLUGIN_DEVICE_CONFIGMAP"
+
 	// EnvOutputTypeConfig is the environment variable that can be used to
 	// specify the directory which holds the output type configs, or a single
 	// file that specifies the output type configs.
@@ -17,4 +24,9 @@ const (
 	// the plugin config, or it can specify the plugin config file
 	// itself.
 	EnvPluginConfig = "PLUGIN_CONFIG"
+
+	// EnvPluginInstanceID is the environment variable that can be used to
+	// specify the ID of the plugin instance, if it is not set in the plugin
+	// config. If neither is set, the hostname is used.
+	EnvPluginInstanceID = "PLUGIN_INSTANCE_ID"
 )
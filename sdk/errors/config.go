@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ConfigsNotFound is an error used when the search for a config file
 // results in that file not being found.
@@ -20,3 +23,22 @@ func NewConfigsNotFoundError(searchPaths []string) *ConfigsNotFound {
 func (e *ConfigsNotFound) Error() string {
 	return fmt.Sprintf("no configuration file(s) found in: %s", e.searchPaths)
 }
+
+// RegistrationTimeout is an error used when dynamic registration does not
+// complete within its configured timeout.
+type RegistrationTimeout struct {
+	// timeout is the duration that registration was allowed to run for.
+	timeout time.Duration
+}
+
+// NewRegistrationTimeoutError returns a new instance of a RegistrationTimeout error.
+func NewRegistrationTimeoutError(timeout time.Duration) *RegistrationTimeout {
+	return &RegistrationTimeout{
+		timeout: timeout,
+	}
+}
+
+// Error returns the error string and fulfils the error interface.
+func (e *RegistrationTimeout) Error() string {
+	return fmt.Sprintf("dynamic registration timed out after %v", e.timeout)
+}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,3 +22,17 @@ func TestConfigsNotFound_Error(t *testing.T) {
 
 	assert.Equal(t, "no configuration file(s) found in: [foo bar]", out)
 }
+
+func TestNewRegistrationTimeout(t *testing.T) {
+	err := NewRegistrationTimeoutError(5 * time.Second)
+
+	assert.IsType(t, &RegistrationTimeout{}, err)
+	assert.Equal(t, 5*time.Second, err.timeout)
+}
+
+func TestRegistrationTimeout_Error(t *testing.T) {
+	err := NewRegistrationTimeoutError(5 * time.Second)
+	out := err.Error()
+
+	assert.Equal(t, "dynamic registration timed out after 5s", out)
+}
@@ -1,6 +1,8 @@
 package errors
 
 import (
+	"fmt"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -24,3 +26,45 @@ func InvalidArgumentErr(format string, a ...interface{}) error {
 func NotFoundErr(format string, a ...interface{}) error {
 	return status.Errorf(codes.NotFound, format, a...)
 }
+
+// ResourceExhaustedErr creates a gRPC ResourceExhausted error with the given description.
+func ResourceExhaustedErr(format string, a ...interface{}) error {
+	return status.Errorf(codes.ResourceExhausted, format, a...)
+}
+
+// FailedPreconditionErr creates a gRPC FailedPrecondition error with the given description.
+func FailedPreconditionErr(format string, a ...interface{}) error {
+	return status.Errorf(codes.FailedPrecondition, format, a...)
+}
+
+// ServerBindError is used to designate that the plugin's gRPC server failed
+// to bind to its configured address, e.g. because the address is already in use.
+type ServerBindError struct {
+	// Network is the network type the server failed to bind with.
+	Network string
+
+	// Address is the address the server failed to bind to.
+	Address string
+
+	// Err is the underlying error from the bind attempt.
+	Err error
+}
+
+// NewServerBindError returns a new instance of a ServerBindError.
+func NewServerBindError(network, address string, err error) *ServerBindError {
+	return &ServerBindError{
+		Network: network,
+		Address: address,
+		Err:     err,
+	}
+}
+
+// Error returns the error string and fulfils the error interface.
+func (e *ServerBindError) Error() string {
+	return fmt.Sprintf("failed to bind grpc server (%s:%s): %v", e.Network, e.Address, e.Err)
+}
+
+// Unwrap returns the underlying error from the bind attempt.
+func (e *ServerBindError) Unwrap() error {
+	return e.Err
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -25,6 +26,24 @@ func TestNotFoundErr(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), errString))
 }
 
+// TestResourceExhaustedErr tests constructing a new ResourceExhausted error.
+func TestResourceExhaustedErr(t *testing.T) {
+	errString := "test error"
+	err := ResourceExhaustedErr(errString)
+
+	assert.True(t, strings.Contains(err.Error(), "ResourceExhausted"))
+	assert.True(t, strings.Contains(err.Error(), errString))
+}
+
+// TestFailedPreconditionErr tests constructing a new FailedPrecondition error.
+func TestFailedPreconditionErr(t *testing.T) {
+	errString := "test error"
+	err := FailedPreconditionErr(errString)
+
+	assert.True(t, strings.Contains(err.Error(), "FailedPrecondition"))
+	assert.True(t, strings.Contains(err.Error(), errString))
+}
+
 // TestUnsupportedCommandErrorErr tests constructing and stringify-ing
 // an UnsupportedCommandError error.
 func TestUnsupportedCommandErrorErr(t *testing.T) {
@@ -37,3 +56,17 @@ func TestUnsupportedCommandErrorErr(t *testing.T) {
 		err.Error(),
 	)
 }
+
+// TestServerBindError tests constructing and stringify-ing a ServerBindError.
+func TestServerBindError(t *testing.T) {
+	cause := fmt.Errorf("address already in use")
+	err := NewServerBindError("tcp", "localhost:5001", cause)
+
+	assert.Error(t, err)
+	assert.Equal(t, cause, err.Unwrap())
+	assert.Equal(
+		t,
+		"failed to bind grpc server (tcp:localhost:5001): address already in use",
+		err.Error(),
+	)
+}
@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// PluginFeatures describes the features supported by a plugin. Since different
+// versions of Synse Server support different plugin features, Synse Server can
+// query the plugin's features via the `synse.PluginFeatures/Negotiate` RPC and
+// adapt to what the plugin supports.
+type PluginFeatures struct {
+	// BulkRead is whether any of the plugin's device handlers read in bulk.
+	BulkRead bool
+
+	// Listen is whether any of the plugin's device handlers listen for
+	// push-based readings.
+	Listen bool
+
+	// Write is whether any of the plugin's device handlers support writes.
+	Write bool
+
+	// TLS is whether the plugin's gRPC server is secured with TLS.
+	TLS bool
+}
+
+// GetPluginFeatures gets the features supported by the plugin, based on its
+// registered device handlers and its configuration.
+func GetPluginFeatures() *PluginFeatures {
+	features := &PluginFeatures{}
+
+	var settings *PluginSettings
+	if Config.Plugin != nil {
+		settings = Config.Plugin.Settings
+		features.TLS = Config.Plugin.Network != nil && Config.Plugin.Network.TLS != nil
+	}
+	readsEnabled := settings != nil && settings.Read != nil && settings.Read.Enabled
+	listenEnabled := settings != nil && settings.Listen != nil && settings.Listen.Enabled
+	writesEnabled := settings != nil && settings.Write != nil && settings.Write.Enabled
+
+	for _, handler := range ctx.deviceHandlers {
+		if readsEnabled && handler.supportsBulkRead() {
+			features.BulkRead = true
+		}
+		if listenEnabled && handler.Listen != nil {
+			features.Listen = true
+		}
+		if writesEnabled && handler.supportsWrite() {
+			features.Write = true
+		}
+	}
+	return features
+}
+
+// encode translates the PluginFeatures to a protobuf Struct, keyed by feature.
+// A Struct is used so that features can be added without breaking clients,
+// which can ignore any features they do not know about.
+func (features *PluginFeatures) encode() *structpb.Struct {
+	boolValue := func(b bool) *structpb.Value {
+		return &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: b}}
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"sdk_version": {Kind: &structpb.Value_StringValue{StringValue: version.SDKVersion}},
+			"bulk_read":   boolValue(features.BulkRead),
+			"listen":      boolValue(features.Listen),
+			"write":       boolValue(features.Write),
+			"tls":         boolValue(features.TLS),
+		},
+	}
+}
+
+// pluginFeaturesServer is the server API for the synse.PluginFeatures service.
+type pluginFeaturesServer interface {
+	Negotiate(context.Context, *synse.Empty) (*structpb.Struct, error)
+}
+
+// Negotiate is the handler for the synse.PluginFeatures service's `Negotiate` RPC
+// method. It reports the features supported by the plugin.
+func (server *server) Negotiate(ctx context.Context, request *synse.Empty) (*structpb.Struct, error) {
+	log.WithField("request", request).Debug("[grpc] negotiate rpc request")
+	return GetPluginFeatures().encode(), nil
+}
+
+// negotiateHandler decodes and dispatches requests for the `Negotiate` RPC method.
+func negotiateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(pluginFeaturesServer).Negotiate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.PluginFeatures/Negotiate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(pluginFeaturesServer).Negotiate(ctx, req.(*synse.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// pluginFeaturesServiceDesc describes the synse.PluginFeatures gRPC service. It is
+// registered alongside the synse.Plugin service, so that Synse Server versions
+// which do not know about it are unaffected. It is defined in proto/features.proto.
+var pluginFeaturesServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.PluginFeatures",
+	HandlerType: (*pluginFeaturesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Negotiate",
+			Handler:    negotiateHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/features.proto",
+}
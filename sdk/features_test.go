@@ -0,0 +1,148 @@
+package sdk
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// TestGetPluginFeatures tests getting the features of a plugin with no handlers
+// or configuration.
+func TestGetPluginFeatures(t *testing.T) {
+	defer resetContext()
+
+	assert.Equal(t, &PluginFeatures{}, GetPluginFeatures())
+}
+
+// TestGetPluginFeatures2 tests getting the features of a plugin from its registered
+// handlers and configuration.
+func TestGetPluginFeatures2(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	readings := func(_ *Device) ([]*Reading, error) { return nil, nil }
+	bulk := func(_ []*Device) ([]*ReadContext, error) { return nil, nil }
+	write := func(_ *Device, _ *WriteData) error { return nil }
+	listen := func(_ *Device, _ chan *ReadContext) error { return nil }
+
+	var testTable = []struct {
+		desc     string
+		handlers []*DeviceHandler
+		settings *PluginSettings
+		tls      *TLSNetworkSettings
+		expected *PluginFeatures
+	}{
+		{
+			desc:     "read only handler",
+			handlers: []*DeviceHandler{{Name: "foo", Read: readings}},
+			settings: &PluginSettings{Read: &ReadSettings{Enabled: true}},
+			expected: &PluginFeatures{},
+		},
+		{
+			desc: "all features",
+			handlers: []*DeviceHandler{
+				{Name: "foo", Read: readings, Write: write},
+				{Name: "bar", BulkRead: bulk, Listen: listen},
+			},
+			settings: &PluginSettings{
+				Read:   &ReadSettings{Enabled: true},
+				Write:  &WriteSettings{Enabled: true},
+				Listen: &ListenSettings{Enabled: true},
+			},
+			tls:      &TLSNetworkSettings{Cert: "cert", Key: "key"},
+			expected: &PluginFeatures{BulkRead: true, Listen: true, Write: true, TLS: true},
+		},
+		{
+			desc: "handlers support features which are disabled",
+			handlers: []*DeviceHandler{
+				{Name: "foo", Read: readings, Write: write},
+				{Name: "bar", BulkRead: bulk, Listen: listen},
+			},
+			settings: &PluginSettings{
+				Read:   &ReadSettings{},
+				Write:  &WriteSettings{},
+				Listen: &ListenSettings{},
+			},
+			expected: &PluginFeatures{},
+		},
+		{
+			desc:     "bulk read handler which also reads individually",
+			handlers: []*DeviceHandler{{Name: "foo", Read: readings, BulkRead: bulk}},
+			settings: &PluginSettings{Read: &ReadSettings{Enabled: true}},
+			expected: &PluginFeatures{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		ctx.deviceHandlers = testCase.handlers
+		Config.Plugin = &PluginConfig{
+			Settings: testCase.settings,
+			Network:  &NetworkSettings{TLS: testCase.tls},
+		}
+		assert.Equal(t, testCase.expected, GetPluginFeatures(), testCase.desc)
+	}
+}
+
+// TestPluginFeatures_encode tests encoding the plugin features.
+func TestPluginFeatures_encode(t *testing.T) {
+	encoded := (&PluginFeatures{BulkRead: true, TLS: true}).encode()
+
+	assert.Equal(t, version.SDKVersion, encoded.Fields["sdk_version"].GetStringValue())
+	assert.True(t, encoded.Fields["bulk_read"].GetBoolValue())
+	assert.False(t, encoded.Fields["listen"].GetBoolValue())
+	assert.False(t, encoded.Fields["write"].GetBoolValue())
+	assert.True(t, encoded.Fields["tls"].GetBoolValue())
+}
+
+// TestServer_Negotiate tests the Negotiate RPC of the synse.PluginFeatures service
+// over a running gRPC server.
+func TestServer_Negotiate(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{Enabled: true},
+			Write: &WriteSettings{Enabled: true},
+		},
+		Network: &NetworkSettings{Type: "tcp", Address: address},
+	}
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "foo", Write: func(_ *Device, _ *WriteData) error { return nil }},
+	}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	resp := &structpb.Struct{}
+	err = conn.Invoke(context.Background(), "/synse.PluginFeatures/Negotiate", &synse.Empty{}, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	assert.True(t, resp.Fields["write"].GetBoolValue())
+	assert.False(t, resp.Fields["bulk_read"].GetBoolValue())
+	assert.False(t, resp.Fields["listen"].GetBoolValue())
+	assert.False(t, resp.Fields["tls"].GetBoolValue())
+
+	// The synse.Plugin service is still served.
+	status, err := synse.NewPluginClient(conn).Test(context.Background(), &synse.Empty{})
+	assert.NoError(t, err)
+	assert.True(t, status.Ok)
+}
@@ -12,12 +12,27 @@ var (
 	flagDebug   bool
 	flagVersion bool
 	flagDryRun  bool
+
+	flagPersistMigrations bool
+	flagConfigStdin       bool
+
+	flagRecord      string
+	flagReplay      string
+	flagReplaySpeed float64
+
+	flagSnapshot string
 )
 
 func init() {
 	flag.BoolVar(&flagDebug, "debug", false, "run the plugin with debug logging")
 	flag.BoolVar(&flagVersion, "version", false, "print plugin version information")
 	flag.BoolVar(&flagDryRun, "dry-run", false, "perform a dry run to verify the plugin is functional")
+	flag.BoolVar(&flagPersistMigrations, "persist-migrations", false, "write migrated configs back to their source files")
+	flag.BoolVar(&flagConfigStdin, "config-stdin", false, "read plugin, device, and output type configs from stdin as multi-document YAML")
+	flag.StringVar(&flagRecord, "record", "", "record the plugin's readings to the given file, so they can be replayed")
+	flag.StringVar(&flagReplay, "replay", "", "replay the readings recorded in the given file instead of reading from devices")
+	flag.Float64Var(&flagReplaySpeed, "replay-speed", 1, "the speed at which to replay recorded readings, relative to the recorded cadence")
+	flag.StringVar(&flagSnapshot, "snapshot", "", "save a snapshot of the latest readings to the given file when the plugin receives SIGUSR1")
 }
 
 // parseFlags parses any command line flags passed to the plugin and executes
@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getGroupMembers gets the devices in the given rack and board which belong to
+// the transaction group with the given name, sorted by ID. Writes to the group
+// are applied to its members in this order.
+func getGroupMembers(rack, board, group string) []*Device {
+	if group == "" {
+		return nil
+	}
+
+	var members []*Device
+	for _, device := range ctx.getDevices() {
+		if device.Group == group && device.Location != nil &&
+			device.Location.Rack == rack && device.Location.Board == board {
+			members = append(members, device)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].GUID() < members[j].GUID()
+	})
+	return members
+}
+
+// validateForGroupWrite validates that all of the devices in a transaction group
+// are writable.
+func validateForGroupWrite(groupID string, members []*Device) error {
+	for _, device := range members {
+		if !device.IsWritable() {
+			return fmt.Errorf("writing not enabled for device %s in group %s (no write handler)", device.GUID(), groupID)
+		}
+	}
+	return nil
+}
+
+// writeGroup implements the logic for writing to the devices in a transaction
+// group. The write is applied to each device in turn. If it fails for any of
+// them, the write is rolled back for the devices it was already applied to, in
+// reverse order, and the transaction is put into the error state.
+func (manager *dataManager) writeGroup(w *WriteContext, members []*Device) {
+	// Trace the write. If the write request carried a trace, the span
+	// is part of that trace.
+	writeCtx, span := StartSpan(w.traceCtx, "group write")
+	span.SetAttribute("group", w.ID())
+	span.SetAttribute("transaction", w.transaction.id)
+	defer span.Finish()
+
+	data := decodeWriteData(w.data)
+
+	var applied []*Device
+	for _, device := range members {
+		err := device.WriteWithContext(writeCtx, data)
+		metrics.recordWrite(err)
+		if err == nil {
+			applied = append(applied, device)
+			continue
+		}
+
+		log.Errorf("[data manager] failed to write to device %v in group %v: %v", device.GUID(), w.ID(), err)
+		msg := fmt.Sprintf("failed to write to device %s: %v", device.GUID(), err)
+		if failed := rollbackGroupWrite(applied, data); len(failed) > 0 {
+			msg += fmt.Sprintf("; failed to roll back write for: %s", strings.Join(failed, ", "))
+		}
+		w.transaction.setStateError()
+		w.transaction.message = msg
+		return
+	}
+}
+
+// rollbackGroupWrite rolls back a write for the given devices, in reverse order.
+// It returns the IDs of any devices for which the write could not be rolled back,
+// either because their handler does not support rollback or because the rollback
+// failed.
+func rollbackGroupWrite(applied []*Device, data *WriteData) []string {
+	var failed []string
+	for i := len(applied) - 1; i >= 0; i-- {
+		device := applied[i]
+		if device.Handler.Rollback == nil {
+			log.Warnf("[data manager] unable to roll back write for device %v (no rollback handler)", device.GUID())
+			failed = append(failed, device.GUID())
+			continue
+		}
+		if err := device.Handler.Rollback(device, data); err != nil {
+			log.Errorf("[data manager] failed to roll back write for device %v: %v", device.GUID(), err)
+			failed = append(failed, device.GUID())
+		}
+	}
+	return failed
+}
@@ -0,0 +1,251 @@
+package sdk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+)
+
+// groupTestHandler is a test helper which records the writes and rollbacks for
+// the devices using it. Writes fail for any devices in the failing set.
+type groupTestHandler struct {
+	written    []string
+	rolledBack []string
+	failing    map[string]bool
+}
+
+func (h *groupTestHandler) handler(rollback bool) *DeviceHandler {
+	handler := &DeviceHandler{
+		Write: func(device *Device, data *WriteData) error {
+			if h.failing[device.id] {
+				return fmt.Errorf("test write error")
+			}
+			h.written = append(h.written, device.id)
+			return nil
+		},
+	}
+	if rollback {
+		handler.Rollback = func(device *Device, data *WriteData) error {
+			h.rolledBack = append(h.rolledBack, device.id)
+			return nil
+		}
+	}
+	return handler
+}
+
+// addGroupTestDevice is a test helper which adds a device with the given ID and
+// transaction group to the "rack-board" location.
+func addGroupTestDevice(id, group string, handler *DeviceHandler) {
+	ctx.devices["rack-board-"+id] = &Device{
+		id:       id,
+		Kind:     "foo",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Group:    group,
+		Handler:  handler,
+	}
+}
+
+// newGroupWriteContext is a test helper which creates a write context for the
+// transaction group with the given name.
+func newGroupWriteContext(group string) *WriteContext {
+	t := newTransaction()
+	t.setStatusPending()
+	return &WriteContext{
+		traceCtx:    context.Background(),
+		transaction: t,
+		rack:        "rack",
+		board:       "board",
+		device:      group,
+		data:        &synse.WriteData{Action: "position", Data: []byte("50")},
+	}
+}
+
+// Test_getGroupMembers tests getting the devices in a transaction group.
+func Test_getGroupMembers(t *testing.T) {
+	defer resetContext()
+
+	h := &groupTestHandler{}
+	addGroupTestDevice("c", "dampers", h.handler(true))
+	addGroupTestDevice("a", "dampers", h.handler(true))
+	addGroupTestDevice("b", "fans", h.handler(true))
+	addGroupTestDevice("d", "", h.handler(true))
+	ctx.devices["rack-other-e"] = &Device{
+		id:       "e",
+		Location: &Location{Rack: "rack", Board: "other"},
+		Group:    "dampers",
+	}
+
+	members := getGroupMembers("rack", "board", "dampers")
+	assert.Equal(t, 2, len(members))
+	assert.Equal(t, "a", members[0].id)
+	assert.Equal(t, "c", members[1].id)
+
+	assert.Empty(t, getGroupMembers("rack", "board", "unknown"))
+	assert.Empty(t, getGroupMembers("rack", "board", ""))
+}
+
+// TestDataManager_writeGroup tests a successful write to a transaction group.
+func TestDataManager_writeGroup(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer resetContext()
+
+	h := &groupTestHandler{}
+	addGroupTestDevice("a", "dampers", h.handler(true))
+	addGroupTestDevice("b", "dampers", h.handler(true))
+	addGroupTestDevice("c", "dampers", h.handler(true))
+
+	w := newGroupWriteContext("dampers")
+	DataManager.write(w)
+
+	assert.Equal(t, []string{"a", "b", "c"}, h.written)
+	assert.Empty(t, h.rolledBack)
+	assert.Equal(t, statusDone, w.transaction.status)
+	assert.Equal(t, stateOk, w.transaction.state)
+	assert.Equal(t, "", w.transaction.message)
+}
+
+// TestDataManager_writeGroupRollback tests that a partially failed write to a
+// transaction group is rolled back for the devices it was applied to.
+func TestDataManager_writeGroupRollback(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer resetContext()
+
+	h := &groupTestHandler{failing: map[string]bool{"c": true}}
+	addGroupTestDevice("a", "dampers", h.handler(true))
+	addGroupTestDevice("b", "dampers", h.handler(true))
+	addGroupTestDevice("c", "dampers", h.handler(true))
+	addGroupTestDevice("d", "dampers", h.handler(true))
+
+	w := newGroupWriteContext("dampers")
+	DataManager.write(w)
+
+	// The write is not applied to devices after the failure, and is rolled
+	// back, in reverse order, for the devices before it.
+	assert.Equal(t, []string{"a", "b"}, h.written)
+	assert.Equal(t, []string{"b", "a"}, h.rolledBack)
+	assert.Equal(t, statusDone, w.transaction.status)
+	assert.Equal(t, stateError, w.transaction.state)
+	assert.Equal(t, "failed to write to device rack-board-c: test write error", w.transaction.message)
+}
+
+// TestDataManager_writeGroupNoRollback tests a partially failed write to a
+// transaction group when a device's handler does not support rollback.
+func TestDataManager_writeGroupNoRollback(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer resetContext()
+
+	h := &groupTestHandler{failing: map[string]bool{"c": true}}
+	addGroupTestDevice("a", "dampers", h.handler(false))
+	addGroupTestDevice("b", "dampers", h.handler(true))
+	addGroupTestDevice("c", "dampers", h.handler(true))
+
+	w := newGroupWriteContext("dampers")
+	DataManager.write(w)
+
+	assert.Equal(t, []string{"a", "b"}, h.written)
+	assert.Equal(t, []string{"b"}, h.rolledBack)
+	assert.Equal(t, stateError, w.transaction.state)
+	assert.Equal(
+		t,
+		"failed to write to device rack-board-c: test write error; failed to roll back write for: rack-board-a",
+		w.transaction.message,
+	)
+}
+
+// TestDataManager_WriteGroup tests fulfilling a Write request for a transaction group.
+func TestDataManager_WriteGroup(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	DataManager.writeChannel = make(chan *WriteContext, 20)
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Write: &WriteSettings{
+				Enabled: true,
+			},
+		},
+	}
+
+	h := &groupTestHandler{}
+	addGroupTestDevice("a", "dampers", h.handler(true))
+	addGroupTestDevice("b", "dampers", h.handler(true))
+
+	req := &synse.WriteInfo{
+		DeviceFilter: &synse.DeviceFilter{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "dampers",
+		},
+		Data: []*synse.WriteData{{Action: "position", Data: []byte("50")}},
+	}
+	resp, err := DataManager.Write(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp))
+
+	// A single write is queued for the group.
+	assert.Equal(t, 1, len(DataManager.writeChannel))
+	w := <-DataManager.writeChannel
+	assert.Equal(t, "rack-board-dampers", w.ID())
+}
+
+// TestDataManager_WriteGroupError tests Write requests for a transaction group
+// which are rejected because of one of the devices in the group.
+func TestDataManager_WriteGroupError(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	DataManager.writeChannel = make(chan *WriteContext, 20)
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Write: &WriteSettings{
+				Enabled: true,
+			},
+		},
+	}
+
+	h := &groupTestHandler{}
+	max := 40.0
+	addGroupTestDevice("a", "dampers", h.handler(true))
+	addGroupTestDevice("b", "dampers", h.handler(true))
+	ctx.devices["rack-board-b"].WriteConstraints = map[string]*WriteConstraint{
+		"position": {Action: "position", Max: &max},
+	}
+	addGroupTestDevice("c", "sensors", &DeviceHandler{})
+	addGroupTestDevice("d", "sensors", h.handler(true))
+
+	var testTable = []struct {
+		desc  string
+		group string
+	}{
+		{"write violates a device's constraints", "dampers"},
+		{"device is not writable", "sensors"},
+		{"group does not exist", "unknown"},
+	}
+
+	for _, testCase := range testTable {
+		req := &synse.WriteInfo{
+			DeviceFilter: &synse.DeviceFilter{
+				Rack:   "rack",
+				Board:  "board",
+				Device: testCase.group,
+			},
+			Data: []*synse.WriteData{{Action: "position", Data: []byte("50")}},
+		}
+		resp, err := DataManager.Write(context.Background(), req)
+		assert.Error(t, err, testCase.desc)
+		assert.Nil(t, resp, testCase.desc)
+	}
+	assert.Equal(t, 0, len(DataManager.writeChannel))
+}
@@ -19,3 +19,9 @@ type DynamicDeviceConfigRegistrar func(map[string]interface{}) ([]*DeviceConfig,
 // and performs some validation on it. This allows users to provide validation on the
 // plugin-specific config fields.
 type DeviceDataValidator func(map[string]interface{}) error
+
+// AtmosphericPressureSource is a handler function that gets the current local
+// atmospheric pressure, e.g. from a barometric sensor or a weather service. It is
+// used as the baseline for converting between absolute and gauge pressure. The
+// pressure should be in the same unit as the pressure readings being converted.
+type AtmosphericPressureSource func() (float64, error)
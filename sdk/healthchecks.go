@@ -37,3 +37,25 @@ func writeBufferHealthCheck() error {
 	}
 	return nil
 }
+
+// quarantineHealthCheck is a plugin health check that looks at the device quarantine.
+// If any devices are quarantined for returning bad readings, it will cause the health
+// check to fail.
+func quarantineHealthCheck() error {
+	devices := getQuarantinedDevices()
+	if len(devices) > 0 {
+		return fmt.Errorf("%d device(s) quarantined for bad readings: %v", len(devices), devices)
+	}
+	return nil
+}
+
+// watchdogHealthCheck is a plugin health check that looks at the device staleness
+// watchdog. If any devices have produced no readings within the watchdog timeout,
+// it will cause the health check to fail.
+func watchdogHealthCheck() error {
+	devices := getStaleDevices()
+	if len(devices) > 0 {
+		return fmt.Errorf("%d device(s) stale, with no readings within %v: %v", len(devices), deviceWatchdog.timeout, devices)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package sdk
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// readingsHistory holds a short rolling window of the most recent readings for
+// each device, if it is enabled in the plugin configuration. Unlike the readings
+// cache, the history is not time-based; it retains a fixed number of readings
+// per device, making it useful for live troubleshooting.
+var readingsHistory *readingHistory
+
+// readingHistory stores a bounded ring buffer of readings for each device,
+// keyed by the device ID.
+type readingHistory struct {
+	sync.RWMutex
+
+	size    int
+	buffers map[string]*readingRing
+}
+
+// newReadingHistory creates a new readingHistory which retains the given number
+// of readings per device.
+func newReadingHistory(size int) *readingHistory {
+	return &readingHistory{
+		size:    size,
+		buffers: map[string]*readingRing{},
+	}
+}
+
+// add adds the readings for a device to the device's ring buffer.
+func (history *readingHistory) add(device string, readings []*Reading) {
+	history.Lock()
+	defer history.Unlock()
+
+	buffer, ok := history.buffers[device]
+	if !ok {
+		buffer = newReadingRing(history.size)
+		history.buffers[device] = buffer
+	}
+	for _, reading := range readings {
+		buffer.add(reading)
+	}
+}
+
+// get gets up to the last k readings for a device, ordered from oldest to newest.
+// If k is not positive, all of the retained readings for the device are returned.
+func (history *readingHistory) get(device string, k int) []*Reading {
+	history.RLock()
+	defer history.RUnlock()
+
+	buffer, ok := history.buffers[device]
+	if !ok {
+		return nil
+	}
+	return buffer.last(k)
+}
+
+// readingRing is a fixed-size ring buffer of readings. Once the buffer is full,
+// adding a reading evicts the oldest reading.
+type readingRing struct {
+	readings []*Reading
+	next     int
+	full     bool
+}
+
+// newReadingRing creates a new readingRing with the given capacity.
+func newReadingRing(size int) *readingRing {
+	return &readingRing{
+		readings: make([]*Reading, size),
+	}
+}
+
+// add adds a reading to the ring buffer, evicting the oldest reading if the
+// buffer is full.
+func (ring *readingRing) add(reading *Reading) {
+	if len(ring.readings) == 0 {
+		return
+	}
+	ring.readings[ring.next] = reading
+	ring.next = (ring.next + 1) % len(ring.readings)
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// len gets the number of readings currently held in the ring buffer.
+func (ring *readingRing) len() int {
+	if ring.full {
+		return len(ring.readings)
+	}
+	return ring.next
+}
+
+// last gets up to the last k readings in the ring buffer, ordered from oldest to
+// newest. If k is not positive, or is larger than the number of readings held,
+// all readings in the buffer are returned.
+func (ring *readingRing) last(k int) []*Reading {
+	n := ring.len()
+	if k <= 0 || k > n {
+		k = n
+	}
+
+	readings := make([]*Reading, k)
+	start := ring.next - k
+	if start < 0 {
+		start += len(ring.readings)
+	}
+	for i := 0; i < k; i++ {
+		readings[i] = ring.readings[(start+i)%len(ring.readings)]
+	}
+	return readings
+}
+
+// setupReadingsHistory sets up the readings history, if it is enabled in the
+// plugin configuration.
+func setupReadingsHistory() {
+	if readingsStateless() {
+		log.Debug("[history] plugin is stateless - readings history disabled")
+		return
+	}
+
+	historySettings := Config.Plugin.Settings.History
+	if historySettings != nil && historySettings.Enabled {
+		log.WithField(
+			"size", historySettings.Size,
+		).Info("[history] creating new readings history")
+		readingsHistory = newReadingHistory(historySettings.Size)
+	} else {
+		log.Debug("[history] readings history disabled")
+	}
+}
+
+// addReadingToHistory adds the readings from a ReadContext to the readings
+// history, if it is enabled.
+func addReadingToHistory(ctx *ReadContext) {
+	if readingsHistory != nil {
+		readingsHistory.add(ctx.ID(), ctx.Reading)
+	}
+}
+
+// getReadingsFromHistory gets up to the last k readings for the specified device
+// from the readings history. If the history is not enabled, nil is returned.
+func getReadingsFromHistory(device string, k int) []*Reading {
+	if readingsHistory == nil {
+		return nil
+	}
+	return readingsHistory.get(device, k)
+}
+
+// historyRequest is the request of the synse.ReadingHistory service's `Read` RPC
+// method. It is described by the HistoryRequest message in proto/history.proto.
+type historyRequest struct {
+	Filter *synse.DeviceFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	K      int32               `protobuf:"varint,2,opt,name=k,proto3" json:"k,omitempty"`
+}
+
+func (m *historyRequest) Reset()         { *m = historyRequest{} }
+func (m *historyRequest) String() string { return proto.CompactTextString(m) }
+func (*historyRequest) ProtoMessage()    {}
+
+// readingHistoryServer is the server API for the synse.ReadingHistory service.
+type readingHistoryServer interface {
+	ReadHistory(*historyRequest, readingHistoryStream) error
+}
+
+// readingHistoryStream is the server side of the response stream for the
+// synse.ReadingHistory service's `Read` RPC method.
+type readingHistoryStream interface {
+	Send(*synse.Reading) error
+	grpc.ServerStream
+}
+
+// readingHistoryStreamServer implements readingHistoryStream for a grpc.ServerStream.
+type readingHistoryStreamServer struct {
+	grpc.ServerStream
+}
+
+// Send sends a reading on the stream.
+func (stream *readingHistoryStreamServer) Send(reading *synse.Reading) error {
+	return stream.ServerStream.SendMsg(reading)
+}
+
+// ReadHistory is the handler for the synse.ReadingHistory service's `Read` RPC
+// method. It streams up to the last k readings retained in the readings history
+// for the device, from oldest to newest. If k is not positive, all of the retained
+// readings for the device are streamed.
+func (server *server) ReadHistory(request *historyRequest, stream readingHistoryStream) error {
+	log.WithField("request", request).Debug("[grpc] read history rpc request")
+	filter := request.Filter
+	if filter == nil {
+		filter = &synse.DeviceFilter{}
+	}
+	responses, err := DataManager.ReadHistory(filter, int(request.K))
+	if err != nil {
+		return err
+	}
+	for _, response := range responses {
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readHistoryHandler decodes and dispatches requests for the `Read` RPC method.
+func readHistoryHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(historyRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(readingHistoryServer).ReadHistory(in, &readingHistoryStreamServer{stream})
+}
+
+// readingHistoryServiceDesc describes the synse.ReadingHistory gRPC service, which
+// streams the recent readings of a device from the readings history, for live
+// troubleshooting. Its messages are defined in proto/history.proto.
+var readingHistoryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.ReadingHistory",
+	HandlerType: (*readingHistoryServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Read",
+			Handler:       readHistoryHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/history.proto",
+}
@@ -0,0 +1,218 @@
+package sdk
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Test setting up the readings history when it is enabled in the config.
+func Test_setupReadingsHistory_Enabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		readingsHistory = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			History: &HistorySettings{
+				Enabled: true,
+				Size:    5,
+			},
+		},
+	}
+
+	assert.Nil(t, readingsHistory)
+	setupReadingsHistory()
+	assert.NotNil(t, readingsHistory)
+	assert.Equal(t, 5, readingsHistory.size)
+}
+
+// Test setting up the readings history when it is disabled in the config.
+func Test_setupReadingsHistory_Disabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		readingsHistory = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			History: &HistorySettings{
+				Enabled: false,
+			},
+		},
+	}
+
+	assert.Nil(t, readingsHistory)
+	setupReadingsHistory()
+	assert.Nil(t, readingsHistory)
+}
+
+// Test that the ring buffer retains the last K readings and evicts older ones.
+func Test_readingRing(t *testing.T) {
+	ring := newReadingRing(3)
+	assert.Equal(t, 0, ring.len())
+	assert.Empty(t, ring.last(0))
+
+	ring.add(&Reading{Value: 1})
+	ring.add(&Reading{Value: 2})
+	assert.Equal(t, 2, ring.len())
+	assert.Equal(t, []interface{}{1, 2}, readingValues(ring.last(0)))
+
+	ring.add(&Reading{Value: 3})
+	ring.add(&Reading{Value: 4})
+	ring.add(&Reading{Value: 5})
+	assert.Equal(t, 3, ring.len())
+	assert.Equal(t, []interface{}{3, 4, 5}, readingValues(ring.last(0)))
+	assert.Equal(t, []interface{}{4, 5}, readingValues(ring.last(2)))
+	assert.Equal(t, []interface{}{3, 4, 5}, readingValues(ring.last(10)))
+}
+
+// Test that a zero-capacity ring buffer does not retain readings.
+func Test_readingRing_Empty(t *testing.T) {
+	ring := newReadingRing(0)
+	ring.add(&Reading{Value: 1})
+	assert.Equal(t, 0, ring.len())
+	assert.Empty(t, ring.last(0))
+}
+
+// Test adding readings to the history for multiple devices.
+func Test_addReadingToHistory(t *testing.T) {
+	defer func() {
+		readingsHistory = nil
+	}()
+
+	readingsHistory = newReadingHistory(2)
+
+	addReadingToHistory(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "foo",
+		Reading: []*Reading{{Value: 1}, {Value: 2}},
+	})
+	addReadingToHistory(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "foo",
+		Reading: []*Reading{{Value: 3}},
+	})
+	addReadingToHistory(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "bar",
+		Reading: []*Reading{{Value: 4}},
+	})
+
+	assert.Equal(t, []interface{}{2, 3}, readingValues(getReadingsFromHistory("rack-board-foo", 0)))
+	assert.Equal(t, []interface{}{3}, readingValues(getReadingsFromHistory("rack-board-foo", 1)))
+	assert.Equal(t, []interface{}{4}, readingValues(getReadingsFromHistory("rack-board-bar", 0)))
+	assert.Nil(t, getReadingsFromHistory("rack-board-baz", 0))
+}
+
+// Test getting readings from the history when it is not enabled.
+func Test_getReadingsFromHistory_Disabled(t *testing.T) {
+	addReadingToHistory(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "foo",
+		Reading: []*Reading{{Value: 1}},
+	})
+	assert.Nil(t, getReadingsFromHistory("rack-board-foo", 0))
+}
+
+// readingValues is a test helper to get the values of the given readings.
+func readingValues(readings []*Reading) []interface{} {
+	var values []interface{}
+	for _, r := range readings {
+		values = append(values, r.Value)
+	}
+	return values
+}
+
+// Test reading the history of a device via the synse.ReadingHistory service.
+func TestServer_ReadHistory(t *testing.T) {
+	defer func() {
+		readingsHistory = nil
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{Type: "tcp", Address: address},
+	}
+
+	ctx.devices["rack-board-foo"] = &Device{
+		id:       "foo",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  &DeviceHandler{Read: func(*Device) ([]*Reading, error) { return nil, nil }},
+	}
+	readingsHistory = newReadingHistory(5)
+	addReadingToHistory(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "foo",
+		Reading: []*Reading{{Value: 1}, {Value: 2}, {Value: 3}},
+	})
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	readHistory := func(k int32) ([]int64, error) {
+		stream, err := conn.NewStream(context.Background(), &readingHistoryServiceDesc.Streams[0], "/synse.ReadingHistory/Read", grpc.FailFast(false))
+		if err != nil {
+			return nil, err
+		}
+		request := &historyRequest{
+			Filter: &synse.DeviceFilter{Rack: "rack", Board: "board", Device: "foo"},
+			K:      k,
+		}
+		if err := stream.SendMsg(request); err != nil {
+			return nil, err
+		}
+		if err := stream.CloseSend(); err != nil {
+			return nil, err
+		}
+
+		var values []int64
+		for {
+			reading := &synse.Reading{}
+			err := stream.RecvMsg(reading)
+			if err == io.EOF {
+				return values, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, reading.GetInt64Value())
+		}
+	}
+
+	// The last k readings are streamed, from oldest to newest.
+	values, err := readHistory(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, values)
+
+	// If k is not set, all of the readings are streamed.
+	values, err = readHistory(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, values)
+
+	// The history must be enabled.
+	readingsHistory = nil
+	_, err = readHistory(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"golang.org/x/net/context"
+	"gopkg.in/yaml.v2"
+)
+
+// hotAddSource is the config source for device configs which are added to a
+// running plugin, rather than loaded from file or dynamic registration.
+const hotAddSource = "hot-add"
+
+// AddDevice adds the devices defined by the given device config document (YAML)
+// to the running plugin. The document is migrated, validated, and verified by the
+// same pipeline as the device configs loaded at startup, so it must be a complete
+// device config which defines the locations that its devices reference. If the
+// config is invalid, or any of its devices has the same ID as an existing device,
+// no devices are added and an error is returned.
+//
+// The devices are swapped in by atomically replacing the device map as a whole, so
+// anything iterating the existing map is unaffected, and concurrent additions are
+// serialized. They are read and written like any other device from the next read
+// cycle; if their handler has a listener function and the plugin's listeners are
+// running, a listener is started for each of them. Before they are added, the
+// device setup actions whose filters match the devices are run for them; if any
+// action fails, no devices are added and the errors are returned. The IDs (GUIDs)
+// of the added devices are returned, sorted.
+//
+// Added devices are not persisted: they are not part of the plugin's device config
+// sources, so they can not be reloaded (see Plugin.ReloadDevice), and they do not
+// survive a plugin restart.
+func (plugin *Plugin) AddDevice(document []byte) ([]string, error) {
+	return addDevices(plugin, document)
+}
+
+// addDevices adds the devices defined by the given device config document. See
+// Plugin.AddDevice.
+func addDevices(plugin *Plugin, document []byte) ([]string, error) {
+	cfg := &DeviceConfig{}
+	if err := yaml.Unmarshal(document, cfg); err != nil {
+		return nil, fmt.Errorf("%s -> %s", hotAddSource, err)
+	}
+	if _, err := migrateConfig(cfg, ctx.deviceConfigMigrations, currentDeviceSchemeVersion); err != nil {
+		return nil, fmt.Errorf("%s -> %s", hotAddSource, err)
+	}
+
+	// Validate the config scheme, then verify the config and its plugin-specific data,
+	// just as for the device configs loaded at startup.
+	multiErr := validator.Validate(NewConfigContext(hotAddSource, cfg))
+	if multiErr.HasErrors() {
+		return nil, multiErr
+	}
+	multiErr = verifyConfigs(cfg)
+	if multiErr.HasErrors() {
+		return nil, multiErr
+	}
+	multiErr = cfg.ValidateDeviceConfigData(ctx.deviceDataValidator)
+	if multiErr.HasErrors() {
+		return nil, multiErr
+	}
+
+	devices, err := makeDevices(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("%s -> device config does not define any devices", hotAddSource)
+	}
+
+	// Run the device setup actions for the devices before they are added, just
+	// as they are run for the devices registered at startup.
+	multiErr = execDeviceSetupFor(plugin, devices)
+	if multiErr.HasErrors() {
+		return nil, multiErr
+	}
+
+	// The collision check and the swap are done as a single update, so concurrent
+	// additions are serialized and can not collide with each other undetected.
+	var ids []string
+	err = ctx.updateDevices(func(updated map[string]*Device) error {
+		for _, device := range devices {
+			guid := device.GUID()
+			if _, exists := updated[guid]; exists {
+				return errors.NewVerificationConflictError(
+					"device",
+					fmt.Sprintf("device id collides with an existing device: %s", guid),
+				)
+			}
+			device.order = len(updated)
+			updated[guid] = device
+			ids = append(ids, guid)
+		}
+		for _, id := range ids {
+			DataManager.resetDeviceState(id)
+		}
+
+		// Listeners are started once the devices can no longer be rejected.
+		for _, device := range devices {
+			DataManager.startAddedListener(device)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(ids)
+	log.WithField("devices", ids).Info("[sdk] added devices to running plugin")
+	return ids, nil
+}
+
+// AddDevice is the handler for the synse.DeviceInventory service's `AddDevice` RPC
+// method. The request holds a device config document (YAML), whose devices are
+// added to the running plugin (see Plugin.AddDevice). The response holds the IDs
+// of the added devices under the "devices" key.
+func (server *server) AddDevice(ctx context.Context, request *wrappers.StringValue) (*structpb.Struct, error) {
+	log.Debug("[grpc] add device rpc request")
+	ids, err := addDevices(server.plugin, []byte(request.GetValue()))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*structpb.Value, len(ids))
+	for i, id := range ids {
+		values[i] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: id}}
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"devices": {Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: values}}},
+	}}, nil
+}
@@ -0,0 +1,281 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"google.golang.org/grpc"
+)
+
+// hotAddDeviceConfig is a device config document used for testing hot-adding devices.
+const hotAddDeviceConfig = `
+version: 1.0
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: test
+    instances:
+      - info: hot-added device
+        location: r1b1
+        data:
+          id: 1
+`
+
+// setupHotAddTest is a test helper which sets up the plugin context with a device
+// handler for the devices in hotAddDeviceConfig.
+func setupHotAddTest() {
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:  &ReadSettings{Enabled: true},
+			Cache: &CacheSettings{},
+		},
+	}
+	ctx.deviceHandlers = []*DeviceHandler{{
+		Name: "test",
+		Read: func(device *Device) ([]*Reading, error) {
+			return []*Reading{{Type: "test", Value: 1}}, nil
+		},
+	}}
+}
+
+// TestPlugin_AddDevice tests adding a valid device config to the running plugin.
+// The device should be added alongside the existing devices and be readable.
+func TestPlugin_AddDevice(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+	existing := &Device{id: "existing", Location: &Location{Rack: "rack", Board: "board"}}
+	ctx.devices[existing.GUID()] = existing
+
+	plugin := NewPlugin()
+	ids, err := plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+	assert.Len(t, ctx.devices, 2)
+	assert.Equal(t, existing, ctx.devices[existing.GUID()])
+
+	device := ctx.devices[ids[0]]
+	assert.NotNil(t, device)
+	assert.Equal(t, "hot-added device", device.Info)
+	assert.Equal(t, 1, device.order)
+	assert.True(t, device.IsReadable())
+
+	readings, err := plugin.ReadDevice(ids[0])
+	assert.NoError(t, err)
+	assert.Len(t, readings, 1)
+	assert.Equal(t, 1, readings[0].Value)
+}
+
+// TestPlugin_AddDevice_Invalid tests adding an invalid device config to the running
+// plugin. The config should be rejected with its validation errors, and no devices
+// should be added.
+func TestPlugin_AddDevice_Invalid(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	var tests = []struct {
+		desc     string
+		document string
+	}{
+		{"invalid yaml", "devices: ["},
+		{"no scheme version", "devices: [{name: test, instances: [{location: r1b1}]}]"},
+		{"unknown location", "version: 1.0\ndevices: [{name: test, instances: [{info: no location, location: r1b1}]}]"},
+		{"no devices", "version: 1.0\ndevices: []"},
+	}
+
+	plugin := NewPlugin()
+	for _, test := range tests {
+		ids, err := plugin.AddDevice([]byte(test.document))
+		assert.Error(t, err, test.desc)
+		assert.Nil(t, ids, test.desc)
+		assert.Empty(t, ctx.devices, test.desc)
+	}
+
+	// Validation errors are reported together.
+	_, err := plugin.AddDevice([]byte("version: 1.0\ndevices: [{name: test, instances: [{location: r1b1}]}]"))
+	merr, ok := err.(*errors.MultiError)
+	assert.True(t, ok)
+	assert.NotEmpty(t, merr.Errors)
+}
+
+// TestPlugin_AddDevice_Collision tests adding a device config whose device has the
+// same ID as an existing device. No devices should be added.
+func TestPlugin_AddDevice_Collision(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	plugin := NewPlugin()
+	ids, err := plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.NoError(t, err)
+	device := ctx.devices[ids[0]]
+
+	_, err = plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "collides with an existing device")
+	assert.Len(t, ctx.devices, 1)
+	assert.Equal(t, device, ctx.devices[ids[0]])
+}
+
+// TestPlugin_AddDevice_SetupActions tests that the device setup actions are run
+// for added devices. If an action fails, no devices should be added.
+func TestPlugin_AddDevice_SetupActions(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	plugin := NewPlugin()
+	var setup []string
+	var setupErr error
+	plugin.RegisterDeviceSetupActions("kind=test", func(p *Plugin, d *Device) error {
+		assert.Equal(t, plugin, p)
+		setup = append(setup, d.Info)
+		return setupErr
+	})
+
+	setupErr = fmt.Errorf("setup failed")
+	ids, err := plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.Error(t, err)
+	assert.Nil(t, ids)
+	assert.Empty(t, ctx.devices)
+	assert.Equal(t, []string{"hot-added device"}, setup)
+
+	setupErr = nil
+	ids, err = plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+	assert.Equal(t, []string{"hot-added device", "hot-added device"}, setup)
+}
+
+// TestServer_AddDevice tests the AddDevice RPC of the synse.DeviceInventory service
+// over a running gRPC server.
+func TestServer_AddDevice(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+	Config.Plugin.Network = &NetworkSettings{Type: "tcp", Address: address}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	resp := &structpb.Struct{}
+	err = conn.Invoke(context.Background(), "/synse.DeviceInventory/AddDevice", &wrappers.StringValue{Value: hotAddDeviceConfig}, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	devices := resp.Fields["devices"].GetListValue().GetValues()
+	assert.Len(t, devices, 1)
+	_, exists := ctx.devices[devices[0].GetStringValue()]
+	assert.True(t, exists)
+
+	// Invalid device configs are rejected.
+	invalid := &wrappers.StringValue{Value: "version: 1.0\ndevices: [{name: test, instances: [{location: unknown}]}]"}
+	err = conn.Invoke(context.Background(), "/synse.DeviceInventory/AddDevice", invalid, &structpb.Struct{}, grpc.FailFast(false))
+	assert.Error(t, err)
+	assert.Len(t, ctx.devices, 1)
+}
+
+// TestPlugin_AddDevice_Concurrent tests adding different devices concurrently. All
+// of the devices should be added.
+func TestPlugin_AddDevice_Concurrent(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	plugin := NewPlugin()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			document := strings.Replace(hotAddDeviceConfig, "id: 1", fmt.Sprintf("id: %d", i), 1)
+			_, err := plugin.AddDevice([]byte(document))
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, ctx.getDevices(), 20)
+}
+
+// TestPlugin_AddDevice_Listener tests that a listener is started for an added device
+// whose handler has a listener function, once the plugin's listeners are running.
+func TestPlugin_AddDevice_Listener(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+	setupHotAddTest()
+
+	listened := make(chan *Device, 1)
+	ctx.deviceHandlers[0].Listen = func(device *Device, data chan *ReadContext) error {
+		listened <- device
+		return nil
+	}
+
+	// No listener is started before the plugin's listeners are running, since
+	// the device is listened to along with the others when they start.
+	plugin := NewPlugin()
+	ids, err := plugin.AddDevice([]byte(hotAddDeviceConfig))
+	assert.NoError(t, err)
+	select {
+	case <-listened:
+		t.Fatal("listener started before listeners are running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	Config.Plugin.Settings.Listen = &ListenSettings{Enabled: true}
+	DataManager.goListen()
+	select {
+	case device := <-listened:
+		assert.Equal(t, ids[0], device.GUID())
+	case <-time.After(time.Second):
+		t.Fatal("listener not started for registered device")
+	}
+
+	// Devices added while the listeners are running get a listener.
+	document := strings.Replace(hotAddDeviceConfig, "id: 1", "id: 2", 1)
+	ids, err = plugin.AddDevice([]byte(document))
+	assert.NoError(t, err)
+	select {
+	case device := <-listened:
+		assert.Equal(t, ids[0], device.GUID())
+	case <-time.After(time.Second):
+		t.Fatal("listener not started for added device")
+	}
+}
@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// deviceInventoryServer is the server API for the synse.DeviceInventory service.
+type deviceInventoryServer interface {
+	AddDevice(context.Context, *wrappers.StringValue) (*structpb.Struct, error)
+	ReloadDevice(context.Context, *synse.DeviceFilter) (*synse.Status, error)
+}
+
+// addDeviceHandler decodes and dispatches requests for the `AddDevice` RPC method.
+func addDeviceHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(wrappers.StringValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(deviceInventoryServer).AddDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.DeviceInventory/AddDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(deviceInventoryServer).AddDevice(ctx, req.(*wrappers.StringValue))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// reloadDeviceHandler decodes and dispatches requests for the `ReloadDevice` RPC method.
+func reloadDeviceHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.DeviceFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(deviceInventoryServer).ReloadDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.DeviceInventory/ReloadDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(deviceInventoryServer).ReloadDevice(ctx, req.(*synse.DeviceFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// deviceInventoryServiceDesc describes the synse.DeviceInventory gRPC service, which
+// changes the set of devices of a running plugin: devices can be hot-added from a
+// device config document, and a single device can be reloaded from its config. It
+// is defined in proto/inventory.proto.
+var deviceInventoryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.DeviceInventory",
+	HandlerType: (*deviceInventoryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddDevice",
+			Handler:    addDeviceHandler,
+		},
+		{
+			MethodName: "ReloadDevice",
+			Handler:    reloadDeviceHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/inventory.proto",
+}
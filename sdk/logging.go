@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// repeatedLogWindow is the window within which identical messages logged via
+// the repeatedLog deduplicator are coalesced.
+const repeatedLogWindow = time.Minute
+
+// repeatedLog is used to log messages which may be repeated often, e.g. errors
+// from a device which fails on every read. Identical messages are only logged
+// once per window; the next time it is logged, the message notes how many times
+// it was suppressed.
+var repeatedLog = newLogDeduplicator(repeatedLogWindow)
+
+// logDeduplicator rate-limits identical log messages so that a message that is
+// logged repeatedly does not flood the logs.
+type logDeduplicator struct {
+	sync.Mutex
+
+	window   time.Duration
+	messages map[string]*logOccurrence
+}
+
+// logOccurrence tracks when a message was last logged, and how many times it
+// has been suppressed since.
+type logOccurrence struct {
+	logged     time.Time
+	suppressed int
+}
+
+// newLogDeduplicator creates a new logDeduplicator which coalesces identical
+// messages logged within the given window.
+func newLogDeduplicator(window time.Duration) *logDeduplicator {
+	return &logDeduplicator{
+		window:   window,
+		messages: map[string]*logOccurrence{},
+	}
+}
+
+// check checks whether the message with the given key should be logged. If so,
+// it returns the number of times the message was suppressed since it was last
+// logged.
+func (dedup *logDeduplicator) check(key string) (int, bool) {
+	dedup.Lock()
+	defer dedup.Unlock()
+
+	now := clock.Now()
+	occurrence, exists := dedup.messages[key]
+	if exists && now.Sub(occurrence.logged) < dedup.window {
+		occurrence.suppressed++
+		return 0, false
+	}
+
+	if !exists {
+		// Drop any messages which have not been seen within the window, so
+		// the tracked messages do not grow unbounded.
+		for k, o := range dedup.messages {
+			if now.Sub(o.logged) >= dedup.window {
+				delete(dedup.messages, k)
+			}
+		}
+	}
+
+	var suppressed int
+	if exists {
+		suppressed = occurrence.suppressed
+	}
+	dedup.messages[key] = &logOccurrence{logged: now}
+	return suppressed, true
+}
+
+// entry gets the log entry to use for a message which was suppressed the given
+// number of times, along with the message to log.
+func (dedup *logDeduplicator) entry(msg string, suppressed int) (*log.Entry, string) {
+	entry := log.NewEntry(log.StandardLogger())
+	if suppressed > 0 {
+		entry = entry.WithField("suppressed", suppressed)
+		msg = fmt.Sprintf("%s (logged %d more times in the last %v)", msg, suppressed, dedup.window)
+	}
+	return entry, msg
+}
+
+// Errorf logs a message at the error level, unless an identical message was
+// already logged within the deduplication window.
+func (dedup *logDeduplicator) Errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if suppressed, ok := dedup.check(log.ErrorLevel.String() + msg); ok {
+		entry, msg := dedup.entry(msg, suppressed)
+		entry.Error(msg)
+	}
+}
+
+// Warnf logs a message at the warning level, unless an identical message was
+// already logged within the deduplication window.
+func (dedup *logDeduplicator) Warnf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if suppressed, ok := dedup.check(log.WarnLevel.String() + msg); ok {
+		entry, msg := dedup.entry(msg, suppressed)
+		entry.Warn(msg)
+	}
+}
+
+// WithField gets a logger for a message with the given field, which is logged
+// unless an identical message with identical fields was already logged within
+// the deduplication window.
+func (dedup *logDeduplicator) WithField(key string, value interface{}) *repeatedEntry {
+	return dedup.WithFields(log.Fields{key: value})
+}
+
+// WithFields gets a logger for a message with the given fields, which is logged
+// unless an identical message with identical fields was already logged within
+// the deduplication window.
+func (dedup *logDeduplicator) WithFields(fields log.Fields) *repeatedEntry {
+	return &repeatedEntry{dedup: dedup, fields: fields}
+}
+
+// repeatedEntry is a message with fields to be logged via a logDeduplicator.
+type repeatedEntry struct {
+	dedup  *logDeduplicator
+	fields log.Fields
+}
+
+// Error logs the message at the error level.
+func (e *repeatedEntry) Error(msg string) {
+	if suppressed, ok := e.dedup.check(e.key(log.ErrorLevel, msg)); ok {
+		entry, msg := e.dedup.entry(msg, suppressed)
+		entry.WithFields(e.fields).Error(msg)
+	}
+}
+
+// Warn logs the message at the warning level.
+func (e *repeatedEntry) Warn(msg string) {
+	if suppressed, ok := e.dedup.check(e.key(log.WarnLevel, msg)); ok {
+		entry, msg := e.dedup.entry(msg, suppressed)
+		entry.WithFields(e.fields).Warn(msg)
+	}
+}
+
+// key gets the deduplication key for the message. Fields are part of the key,
+// so that e.g. the same error for different devices is not coalesced.
+func (e *repeatedEntry) key(level log.Level, msg string) string {
+	// Maps are printed with sorted keys, so the key is deterministic.
+	return fmt.Sprintf("%s%s%v", level, msg, e.fields)
+}
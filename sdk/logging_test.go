@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_logDeduplicator tests that repeated identical log messages are coalesced.
+func Test_logDeduplicator(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	dedup := newLogDeduplicator(time.Minute)
+	for i := 0; i < 10; i++ {
+		dedup.Errorf("failed to read from device %s", "foo")
+		c.Advance(time.Second)
+	}
+
+	// Only the first message should be logged within the window.
+	assert.Equal(t, 1, len(hook.AllEntries()))
+	assert.Equal(t, "failed to read from device foo", hook.LastEntry().Message)
+	assert.Equal(t, log.ErrorLevel, hook.LastEntry().Level)
+
+	// Once the window has passed, the message is logged again, noting how many
+	// times it was suppressed.
+	c.Advance(time.Minute)
+	dedup.Errorf("failed to read from device %s", "foo")
+	assert.Equal(t, 2, len(hook.AllEntries()))
+	assert.Equal(t, "failed to read from device foo (logged 9 more times in the last 1m0s)", hook.LastEntry().Message)
+	assert.Equal(t, 9, hook.LastEntry().Data["suppressed"])
+}
+
+// Test_logDeduplicator2 tests that different messages are not coalesced.
+func Test_logDeduplicator2(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	useFakeClock(t, time.Unix(0, 0))
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	dedup := newLogDeduplicator(time.Minute)
+	dedup.Errorf("failed to read from device %s", "foo")
+	dedup.Errorf("failed to read from device %s", "bar")
+	dedup.Warnf("failed to read from device %s", "foo")
+	dedup.Errorf("failed to read from device %s", "foo")
+
+	entries := hook.AllEntries()
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "failed to read from device foo", entries[0].Message)
+	assert.Equal(t, "failed to read from device bar", entries[1].Message)
+	assert.Equal(t, log.WarnLevel, entries[2].Level)
+	assert.Nil(t, entries[0].Data["suppressed"])
+}
+
+// Test_logDeduplicator3 tests that messages which have not been seen within the
+// window are no longer tracked.
+func Test_logDeduplicator3(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	dedup := newLogDeduplicator(time.Minute)
+	dedup.Errorf("foo")
+	dedup.Errorf("bar")
+	assert.Equal(t, 2, len(dedup.messages))
+
+	c.Advance(2 * time.Minute)
+	dedup.Errorf("baz")
+	assert.Equal(t, 1, len(dedup.messages))
+}
+
+// Test_logDeduplicator_WithFields tests that repeated identical log messages with
+// fields are coalesced, and that messages with different fields are not.
+func Test_logDeduplicator_WithFields(t *testing.T) {
+	defer func() { clock = realClock{} }()
+	c := useFakeClock(t, time.Unix(0, 0))
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	dedup := newLogDeduplicator(time.Minute)
+	for i := 0; i < 3; i++ {
+		dedup.WithFields(log.Fields{"kind": "foo", "info": "a"}).Error("unknown location specified")
+		dedup.WithField("kind", "bar").Error("unknown location specified")
+		dedup.WithField("kind", "bar").Warn("unknown location specified")
+	}
+
+	entries := hook.AllEntries()
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "foo", entries[0].Data["kind"])
+	assert.Equal(t, "a", entries[0].Data["info"])
+	assert.Equal(t, "bar", entries[1].Data["kind"])
+	assert.Equal(t, log.WarnLevel, entries[2].Level)
+
+	c.Advance(time.Minute)
+	dedup.WithField("kind", "bar").Error("unknown location specified")
+	assert.Equal(t, 4, len(hook.AllEntries()))
+	assert.Equal(t, "unknown location specified (logged 2 more times in the last 1m0s)", hook.LastEntry().Message)
+	assert.Equal(t, "bar", hook.LastEntry().Data["kind"])
+	assert.Equal(t, 2, hook.LastEntry().Data["suppressed"])
+}
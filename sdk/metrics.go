@@ -0,0 +1,226 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// metrics holds the plugin's read, write, and error counters.
+var metrics = newPluginMetrics()
+
+// pluginMetrics holds counters for the reads and writes performed by the plugin.
+// The counters are cumulative from the time the metrics were created.
+type pluginMetrics struct {
+	start time.Time
+
+	reads       int64
+	readErrors  int64
+	writes      int64
+	writeErrors int64
+}
+
+// newPluginMetrics creates a new set of plugin metrics.
+func newPluginMetrics() *pluginMetrics {
+	return &pluginMetrics{
+		start: clock.Now(),
+	}
+}
+
+// recordRead records a device read, and whether or not it failed.
+func (m *pluginMetrics) recordRead(err error) {
+	atomic.AddInt64(&m.reads, 1)
+	if err != nil {
+		atomic.AddInt64(&m.readErrors, 1)
+	}
+}
+
+// recordWrite records a device write, and whether or not it failed.
+func (m *pluginMetrics) recordWrite(err error) {
+	atomic.AddInt64(&m.writes, 1)
+	if err != nil {
+		atomic.AddInt64(&m.writeErrors, 1)
+	}
+}
+
+// counters gets a snapshot of the current values of the plugin's counters.
+func (m *pluginMetrics) counters() []metricCounter {
+	return []metricCounter{
+		{"synse.plugin.reads", "The number of device read operations performed.", "{read}", atomic.LoadInt64(&m.reads)},
+		{"synse.plugin.read.errors", "The number of device read operations which failed.", "{error}", atomic.LoadInt64(&m.readErrors)},
+		{"synse.plugin.writes", "The number of device writes performed.", "{write}", atomic.LoadInt64(&m.writes)},
+		{"synse.plugin.write.errors", "The number of device writes which failed.", "{error}", atomic.LoadInt64(&m.writeErrors)},
+	}
+}
+
+// metricCounter is a snapshot of a single cumulative counter.
+type metricCounter struct {
+	name        string
+	description string
+	unit        string
+	value       int64
+}
+
+// runMetricsExporter exports the plugin metrics to the configured OTLP endpoint
+// once per export interval. Failed exports are logged and retried on the next
+// interval. This blocks, so it should be run in a goroutine.
+func runMetricsExporter(settings *MetricsSettings) {
+	interval, err := settings.GetInterval()
+	if err != nil {
+		log.WithField("error", err).Error("[metrics] misconfiguration: failed to get export interval")
+		return
+	}
+
+	exporter := newOTLPExporter(settings.Endpoint)
+	log.WithFields(log.Fields{
+		"endpoint": settings.Endpoint,
+		"interval": interval,
+	}).Info("[metrics] starting otlp metrics export")
+	for {
+		clock.Sleep(interval)
+		if err := exporter.export(metrics); err != nil {
+			repeatedLog.Warnf("[metrics] failed to export metrics: %v", err)
+		}
+	}
+}
+
+// otlpExporter exports plugin metrics to an OpenTelemetry collector using
+// the OTLP/HTTP protocol with JSON encoding.
+type otlpExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+// newOTLPExporter creates a new exporter for the given OTLP/HTTP metrics endpoint.
+func newOTLPExporter(endpoint string) *otlpExporter {
+	return &otlpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// export sends the current values of the plugin metrics to the collector.
+func (exporter *otlpExporter) export(m *pluginMetrics) error {
+	body, err := json.Marshal(newOTLPMetricsRequest(m, clock.Now()))
+	if err != nil {
+		return err
+	}
+
+	resp, err := exporter.client.Post(exporter.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp collector responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// The types below are the JSON encoding of the OTLP ExportMetricsServiceRequest
+// message, and only include the fields needed to export cumulative counters.
+
+type otlpMetricsRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string         `json:"key"`
+	Value otlpAttrString `json:"value"`
+}
+
+type otlpAttrString struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type otlpMetric struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Unit        string  `json:"unit"`
+	Sum         otlpSum `json:"sum"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+// otlpDataPoint is a NumberDataPoint. In the OTLP JSON encoding, 64-bit integers
+// (including the timestamps) are encoded as strings.
+type otlpDataPoint struct {
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	TimeUnixNano      string `json:"timeUnixNano"`
+	AsInt             string `json:"asInt"`
+}
+
+// otlpAggregationCumulative is the OTLP AGGREGATION_TEMPORALITY_CUMULATIVE value.
+const otlpAggregationCumulative = 2
+
+// newOTLPMetricsRequest creates the OTLP export request for the plugin's metrics
+// at the given time. The plugin name and instance ID identify the resource.
+func newOTLPMetricsRequest(m *pluginMetrics, now time.Time) *otlpMetricsRequest {
+	attributes := []otlpAttribute{
+		{Key: "service.name", Value: otlpAttrString{StringValue: metainfo.Name}},
+	}
+	if ctx.instanceID != "" {
+		attributes = append(attributes, otlpAttribute{
+			Key:   "service.instance.id",
+			Value: otlpAttrString{StringValue: ctx.instanceID},
+		})
+	}
+
+	var otlpMetrics []otlpMetric
+	for _, counter := range m.counters() {
+		otlpMetrics = append(otlpMetrics, otlpMetric{
+			Name:        counter.name,
+			Description: counter.description,
+			Unit:        counter.unit,
+			Sum: otlpSum{
+				DataPoints: []otlpDataPoint{{
+					StartTimeUnixNano: strconv.FormatInt(m.start.UnixNano(), 10),
+					TimeUnixNano:      strconv.FormatInt(now.UnixNano(), 10),
+					AsInt:             strconv.FormatInt(counter.value, 10),
+				}},
+				AggregationTemporality: otlpAggregationCumulative,
+				IsMonotonic:            true,
+			},
+		})
+	}
+
+	return &otlpMetricsRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{Attributes: attributes},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "github.com/vapor-ware/synse-sdk", Version: Version},
+				Metrics: otlpMetrics,
+			}},
+		}},
+	}
+}
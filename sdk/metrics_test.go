@@ -0,0 +1,238 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"github.com/vapor-ware/synse-server-grpc/go"
+)
+
+// fakeCollector is a fake OTLP collector for testing, which records the
+// metrics export requests it receives.
+type fakeCollector struct {
+	*httptest.Server
+
+	requests []*otlpMetricsRequest
+	paths    []string
+	types    []string
+}
+
+// newFakeCollector creates a new fakeCollector which responds with the given status.
+func newFakeCollector(t *testing.T, status int) *fakeCollector {
+	collector := &fakeCollector{}
+	collector.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		req := &otlpMetricsRequest{}
+		assert.NoError(t, json.Unmarshal(body, req))
+
+		collector.requests = append(collector.requests, req)
+		collector.paths = append(collector.paths, r.URL.Path)
+		collector.types = append(collector.types, r.Header.Get("Content-Type"))
+		w.WriteHeader(status)
+	}))
+	return collector
+}
+
+// exportedValues gets the exported value of each metric in an export request.
+func exportedValues(req *otlpMetricsRequest) map[string]string {
+	values := map[string]string{}
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		values[m.Name] = m.Sum.DataPoints[0].AsInt
+	}
+	return values
+}
+
+// TestPluginMetrics tests recording plugin metrics.
+func TestPluginMetrics(t *testing.T) {
+	m := newPluginMetrics()
+	m.recordRead(nil)
+	m.recordRead(fmt.Errorf("test error"))
+	m.recordRead(nil)
+	m.recordWrite(fmt.Errorf("test error"))
+
+	assert.Equal(t, []metricCounter{
+		{"synse.plugin.reads", "The number of device read operations performed.", "{read}", 3},
+		{"synse.plugin.read.errors", "The number of device read operations which failed.", "{error}", 1},
+		{"synse.plugin.writes", "The number of device writes performed.", "{write}", 1},
+		{"synse.plugin.write.errors", "The number of device writes which failed.", "{error}", 1},
+	}, m.counters())
+}
+
+// TestDataManager_readOne_Metrics tests that device reads are recorded in the plugin
+// metrics. Reads of devices which do not support reading are not recorded.
+func TestDataManager_readOne_Metrics(t *testing.T) {
+	defer func() {
+		metrics = newPluginMetrics()
+	}()
+	metrics = newPluginMetrics()
+
+	d := dataManager{readChannel: make(chan *ReadContext, 10)}
+	location := &Location{Rack: "rack", Board: "board"}
+
+	d.readOne(context.Background(), &Device{id: "1", Location: location, Handler: &DeviceHandler{
+		Read: func(_ *Device) ([]*Reading, error) { return []*Reading{}, nil },
+	}})
+	d.readOne(context.Background(), &Device{id: "2", Location: location, Handler: &DeviceHandler{
+		Read: func(_ *Device) ([]*Reading, error) { return nil, fmt.Errorf("test error") },
+	}})
+	d.readOne(context.Background(), &Device{id: "3", Location: location, Handler: &DeviceHandler{}})
+
+	assert.Equal(t, int64(2), metrics.reads)
+	assert.Equal(t, int64(1), metrics.readErrors)
+}
+
+// TestDataManager_write_Metrics tests that device writes are recorded in the plugin metrics.
+func TestDataManager_write_Metrics(t *testing.T) {
+	defer func() {
+		metrics = newPluginMetrics()
+		resetContext()
+	}()
+	metrics = newPluginMetrics()
+
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Write: func(_ *Device, _ *WriteData) error { return fmt.Errorf("test error") },
+		},
+	}
+
+	d := dataManager{}
+	d.write(&WriteContext{
+		transaction: &transaction{id: "test"},
+		device:      "1",
+		board:       "board",
+		rack:        "rack",
+		data:        &synse.WriteData{Action: "test"},
+	})
+
+	assert.Equal(t, int64(1), metrics.writes)
+	assert.Equal(t, int64(1), metrics.writeErrors)
+}
+
+// TestOTLPExporter_export tests exporting the plugin metrics to an OTLP collector.
+func TestOTLPExporter_export(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Unix(100, 0))
+	ctx.instanceID = "replica-1"
+
+	collector := newFakeCollector(t, http.StatusOK)
+	defer collector.Close()
+
+	m := newPluginMetrics()
+	m.recordRead(nil)
+	m.recordRead(fmt.Errorf("test error"))
+	m.recordWrite(nil)
+	c.Advance(30 * time.Second)
+
+	exporter := newOTLPExporter(collector.URL + "/v1/metrics")
+	assert.NoError(t, exporter.export(m))
+
+	assert.Equal(t, 1, len(collector.requests))
+	assert.Equal(t, []string{"/v1/metrics"}, collector.paths)
+	assert.Equal(t, []string{"application/json"}, collector.types)
+
+	req := collector.requests[0]
+	assert.Equal(t, 1, len(req.ResourceMetrics))
+	assert.Contains(t, req.ResourceMetrics[0].Resource.Attributes, otlpAttribute{
+		Key:   "service.instance.id",
+		Value: otlpAttrString{StringValue: "replica-1"},
+	})
+	assert.Equal(t, "github.com/vapor-ware/synse-sdk", req.ResourceMetrics[0].ScopeMetrics[0].Scope.Name)
+	assert.Equal(t, map[string]string{
+		"synse.plugin.reads":        "2",
+		"synse.plugin.read.errors":  "1",
+		"synse.plugin.writes":       "1",
+		"synse.plugin.write.errors": "0",
+	}, exportedValues(req))
+
+	sum := req.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Sum
+	assert.Equal(t, otlpAggregationCumulative, sum.AggregationTemporality)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, "100000000000", sum.DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, "130000000000", sum.DataPoints[0].TimeUnixNano)
+
+	// Metrics are cumulative across exports.
+	m.recordRead(nil)
+	assert.NoError(t, exporter.export(m))
+	assert.Equal(t, 2, len(collector.requests))
+	assert.Equal(t, "3", exportedValues(collector.requests[1])["synse.plugin.reads"])
+}
+
+// TestOTLPExporter_exportError tests exporting the plugin metrics when the collector
+// responds with an error.
+func TestOTLPExporter_exportError(t *testing.T) {
+	collector := newFakeCollector(t, http.StatusInternalServerError)
+	defer collector.Close()
+
+	exporter := newOTLPExporter(collector.URL + "/v1/metrics")
+	err := exporter.export(newPluginMetrics())
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(collector.requests))
+}
+
+// TestMetricsSettings_Validate tests validating MetricsSettings.
+func TestMetricsSettings_Validate(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		errCount int
+		settings MetricsSettings
+	}{
+		{
+			desc:     "valid endpoint",
+			settings: MetricsSettings{Endpoint: "http://localhost:4318/v1/metrics"},
+		},
+		{
+			desc:     "valid endpoint and interval",
+			settings: MetricsSettings{Endpoint: "http://localhost:4318/v1/metrics", Interval: "10s"},
+		},
+		{
+			desc:     "no endpoint",
+			errCount: 1,
+			settings: MetricsSettings{},
+		},
+		{
+			desc:     "bad endpoint",
+			errCount: 1,
+			settings: MetricsSettings{Endpoint: "localhost"},
+		},
+		{
+			desc:     "bad interval",
+			errCount: 1,
+			settings: MetricsSettings{Endpoint: "http://localhost:4318/v1/metrics", Interval: "foo"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.settings.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
+
+// TestMetricsSettings_GetInterval tests getting the metrics export interval.
+func TestMetricsSettings_GetInterval(t *testing.T) {
+	settings := MetricsSettings{}
+	interval, err := settings.GetInterval()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, interval)
+
+	settings.Interval = "5s"
+	interval, err = settings.GetInterval()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, interval)
+}
@@ -0,0 +1,248 @@
+package sdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigMigration is a function that upgrades a configuration from one major
+// scheme version to the next. The config passed to the migration will be the
+// root config struct for the config type (e.g. *DeviceConfig, *PluginConfig).
+//
+// The migration only needs to transform the config data; the SDK will update
+// the scheme version of the config once the migration completes successfully.
+type ConfigMigration func(ConfigBase) error
+
+// versionSetter is implemented by config structs which embed a SchemeVersion,
+// allowing the scheme version to be updated after a migration is applied.
+type versionSetter interface {
+	setVersion(*ConfigVersion)
+}
+
+// RegisterDeviceConfigMigration registers a migration which upgrades device
+// configs with the given major scheme version to the next major version.
+// Migrations are applied automatically when an older device config is loaded.
+func (plugin *Plugin) RegisterDeviceConfigMigration(fromMajor int, migration ConfigMigration) {
+	ctx.deviceConfigMigrations[fromMajor] = migration
+}
+
+// RegisterPluginConfigMigration registers a migration which upgrades plugin
+// configs with the given major scheme version to the next major version.
+// Migrations are applied automatically when an older plugin config is loaded.
+func (plugin *Plugin) RegisterPluginConfigMigration(fromMajor int, migration ConfigMigration) {
+	ctx.pluginConfigMigrations[fromMajor] = migration
+}
+
+// deviceConfigAliasScopes are the paths of the mappings in a device config whose
+// fields can be aliased, and the config struct that each is unmarshaled into.
+var deviceConfigAliasScopes = map[string]reflect.Type{
+	"locations":         reflect.TypeOf(LocationConfig{}),
+	"devices":           reflect.TypeOf(DeviceKind{}),
+	"devices.instances": reflect.TypeOf(DeviceInstance{}),
+}
+
+// RegisterDeviceConfigFieldAlias registers a deprecated alias for a device config
+// field which has been renamed, so that configs using the old name continue to
+// work. The alias is given as its path in the device config and the field by its
+// current name, e.g. "devices.instances.handler" and "handlerName". Aliases may be
+// registered for the fields of locations, device kinds, and device instances.
+//
+// When a config is loaded, the value of an aliased field is used for its current
+// field, and a deprecation warning is logged. If a config sets both the alias and
+// the current field, the current field takes precedence.
+func (plugin *Plugin) RegisterDeviceConfigFieldAlias(alias, field string) error {
+	i := strings.LastIndex(alias, ".")
+	if i == -1 {
+		return fmt.Errorf("device config field alias %q must be a path, e.g. devices.%s", alias, alias)
+	}
+	scope, name := alias[:i], alias[i+1:]
+
+	t, ok := deviceConfigAliasScopes[scope]
+	if !ok {
+		return fmt.Errorf("device config fields under %q can not be aliased", scope)
+	}
+	if yamlFieldIndex(t, field) == -1 {
+		return fmt.Errorf("unknown device config field: %s.%s", scope, field)
+	}
+	if yamlFieldIndex(t, name) != -1 {
+		return fmt.Errorf("device config field alias %q conflicts with an existing field", alias)
+	}
+	if existing, ok := ctx.deviceConfigFieldAliases[scope][name]; ok {
+		return fmt.Errorf("device config field alias %q is already registered for %s", alias, existing)
+	}
+
+	if ctx.deviceConfigFieldAliases[scope] == nil {
+		ctx.deviceConfigFieldAliases[scope] = map[string]string{}
+	}
+	ctx.deviceConfigFieldAliases[scope][name] = field
+	return nil
+}
+
+// yamlFieldIndex gets the index of the field of the given struct type which has
+// the given YAML name, or -1 if there is no such field.
+func yamlFieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyFieldAliases sets the fields of a device config struct (out) from the values
+// of any aliased fields in the YAML mapping being unmarshaled. It is called from
+// the UnmarshalYAML methods of the config structs, after the mapping has been
+// unmarshaled into the struct as-is. The scope is the path of the mapping in the
+// device config, e.g. "devices.instances".
+func applyFieldAliases(unmarshal func(interface{}) error, scope string, out interface{}) error {
+	aliases := ctx.deviceConfigFieldAliases[scope]
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	var names []string
+	for alias := range aliases {
+		if _, ok := raw[alias]; ok {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names)
+
+	v := reflect.ValueOf(out).Elem()
+	for _, alias := range names {
+		field := aliases[alias]
+		fieldLog := log.WithFields(log.Fields{
+			"alias": scope + "." + alias,
+			"field": scope + "." + field,
+		})
+		if _, ok := raw[field]; ok {
+			fieldLog.Warn("[sdk] deprecated config field alias ignored, since its field is also set")
+			continue
+		}
+
+		// Unmarshal the aliased value into a struct with a single field of the
+		// target field's type, so it is decoded exactly as the field would be.
+		target := v.Field(yamlFieldIndex(v.Type(), field))
+		holder := reflect.New(reflect.StructOf([]reflect.StructField{{
+			Name: "Value",
+			Type: target.Type(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:"%s"`, alias)),
+		}}))
+		if err := unmarshal(holder.Interface()); err != nil {
+			return err
+		}
+		target.Set(holder.Elem().Field(0))
+		fieldLog.Warn("[sdk] config field is deprecated, use its new name instead")
+	}
+	return nil
+}
+
+// migrateConfig applies the registered migrations to the given config until it
+// reaches the current scheme version, or until there is no migration registered
+// for its version. It returns whether or not any migrations were applied.
+//
+// If the config does not specify a valid version, no migrations are applied;
+// the version will be reported as an error during config validation.
+func migrateConfig(config ConfigBase, migrations map[int]ConfigMigration, current string) (bool, error) {
+	if len(migrations) == 0 {
+		return false, nil
+	}
+
+	target, err := NewVersion(current)
+	if err != nil {
+		return false, err
+	}
+
+	setter, ok := config.(versionSetter)
+	if !ok {
+		return false, fmt.Errorf("config %T does not support scheme migration", config)
+	}
+
+	migrated := false
+	for {
+		version, err := config.GetVersion()
+		if err != nil {
+			return migrated, nil
+		}
+		if !version.IsLessThan(target) {
+			break
+		}
+
+		migration, ok := migrations[version.Major]
+		if !ok {
+			log.WithField("version", version.String()).Warn("[sdk] no config migration registered for scheme version")
+			break
+		}
+
+		if err := migration(config); err != nil {
+			return migrated, fmt.Errorf("failed to migrate config from scheme v%s: %v", version.String(), err)
+		}
+		next := &ConfigVersion{Major: version.Major + 1, Minor: 0}
+		setter.setVersion(next)
+		migrated = true
+
+		log.WithFields(log.Fields{
+			"from": version.String(),
+			"to":   next.String(),
+		}).Info("[sdk] migrated config scheme")
+	}
+	return migrated, nil
+}
+
+// migrateConfigFile applies the registered migrations to a config that was loaded
+// from the given file. If any migrations were applied and the plugin was run with
+// the --persist-migrations flag, the migrated config is written back to the file.
+func migrateConfigFile(file string, config ConfigBase, migrations map[int]ConfigMigration, current string) error {
+	migrated, err := migrateConfig(config, migrations, current)
+	if err != nil {
+		return err
+	}
+
+	if migrated && flagPersistMigrations {
+		return persistConfig(file, config)
+	}
+	return nil
+}
+
+// persistConfig writes the config to the given file as YAML, preserving the
+// file's existing permissions.
+//
+// A file which references environment variables is not persisted, since the
+// migrated config holds the expanded values, e.g. credentials, rather than
+// the references.
+func persistConfig(file string, config ConfigBase) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	contents, err := ioutil.ReadFile(file) // #nosec
+	if err != nil {
+		return err
+	}
+	if hasEnvReferences(contents) {
+		log.WithField("file", file).Warn("[sdk] not persisting migrated config: file references environment variables")
+		return nil
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("file", file).Info("[sdk] persisting migrated config")
+	return ioutil.WriteFile(file, out, info.Mode())
+}
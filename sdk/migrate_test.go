@@ -0,0 +1,346 @@
+package sdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+	"gopkg.in/yaml.v2"
+)
+
+// TestPlugin_RegisterDeviceConfigMigration tests registering a device config migration.
+func TestPlugin_RegisterDeviceConfigMigration(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	assert.Equal(t, 0, len(ctx.deviceConfigMigrations))
+
+	plugin.RegisterDeviceConfigMigration(1, func(_ ConfigBase) error { return nil })
+	assert.Equal(t, 1, len(ctx.deviceConfigMigrations))
+}
+
+// TestPlugin_RegisterPluginConfigMigration tests registering a plugin config migration.
+func TestPlugin_RegisterPluginConfigMigration(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	assert.Equal(t, 0, len(ctx.pluginConfigMigrations))
+
+	plugin.RegisterPluginConfigMigration(1, func(_ ConfigBase) error { return nil })
+	assert.Equal(t, 1, len(ctx.pluginConfigMigrations))
+}
+
+// Test_migrateConfig tests migrating a v1 config through a registered migration.
+func Test_migrateConfig(t *testing.T) {
+	migrations := map[int]ConfigMigration{
+		1: func(config ConfigBase) error {
+			cfg := config.(*DeviceConfig)
+			for _, kind := range cfg.Devices {
+				kind.HandlerName = kind.Name
+			}
+			return nil
+		},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1"},
+		Devices: []*DeviceKind{
+			{Name: "foo"},
+		},
+	}
+
+	migrated, err := migrateConfig(cfg, migrations, "2.0")
+	assert.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, "2.0", cfg.Version)
+	assert.Equal(t, "foo", cfg.Devices[0].HandlerName)
+
+	version, err := cfg.GetVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version.Major)
+}
+
+// Test_migrateConfig2 tests migrating a config through multiple registered migrations.
+func Test_migrateConfig2(t *testing.T) {
+	var applied []int
+	migrations := map[int]ConfigMigration{
+		1: func(_ ConfigBase) error { applied = append(applied, 1); return nil },
+		2: func(_ ConfigBase) error { applied = append(applied, 2); return nil },
+	}
+
+	cfg := &PluginConfig{SchemeVersion: SchemeVersion{Version: "1.0"}}
+
+	migrated, err := migrateConfig(cfg, migrations, "3.0")
+	assert.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, "3.0", cfg.Version)
+	assert.Equal(t, []int{1, 2}, applied)
+}
+
+// Test_migrateConfig3 tests migrating a config which is already at the current version.
+func Test_migrateConfig3(t *testing.T) {
+	migrations := map[int]ConfigMigration{
+		1: func(_ ConfigBase) error { return fmt.Errorf("should not be called") },
+	}
+
+	cfg := &DeviceConfig{SchemeVersion: SchemeVersion{Version: "1.0"}}
+
+	migrated, err := migrateConfig(cfg, migrations, "1.0")
+	assert.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, "1.0", cfg.Version)
+}
+
+// Test_migrateConfig4 tests migrating a config when there is no migration registered
+// for its version.
+func Test_migrateConfig4(t *testing.T) {
+	migrations := map[int]ConfigMigration{
+		2: func(_ ConfigBase) error { return nil },
+	}
+
+	cfg := &DeviceConfig{SchemeVersion: SchemeVersion{Version: "1.0"}}
+
+	migrated, err := migrateConfig(cfg, migrations, "3.0")
+	assert.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, "1.0", cfg.Version)
+}
+
+// Test_migrateConfig5 tests migrating a config when the migration fails.
+func Test_migrateConfig5(t *testing.T) {
+	migrations := map[int]ConfigMigration{
+		1: func(_ ConfigBase) error { return fmt.Errorf("test error") },
+	}
+
+	cfg := &DeviceConfig{SchemeVersion: SchemeVersion{Version: "1.0"}}
+
+	migrated, err := migrateConfig(cfg, migrations, "2.0")
+	assert.Error(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, "1.0", cfg.Version)
+}
+
+// TestGetDeviceConfigsFromFile_Migrated tests that an older device config is migrated
+// when loaded from file, and that the migrated form is persisted when enabled.
+func TestGetDeviceConfigsFromFile_Migrated(t *testing.T) {
+	defer resetContext()
+	defer func(v string) { currentDeviceSchemeVersion = v }(currentDeviceSchemeVersion)
+	currentDeviceSchemeVersion = "2.0"
+
+	flagPersistMigrations = true
+	defer func() { flagPersistMigrations = false }()
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	data := `
+version: "1.0"
+devices:
+- name: foo
+`
+	foo := test.WriteTempFile(t, "foo.yaml", data, os.ModePerm)
+
+	test.SetEnv(t, EnvDeviceConfig, foo)
+	defer test.RemoveEnv(t, EnvDeviceConfig)
+
+	plugin := NewPlugin()
+	plugin.RegisterDeviceConfigMigration(1, func(config ConfigBase) error {
+		cfg := config.(*DeviceConfig)
+		for _, kind := range cfg.Devices {
+			kind.HandlerName = "bar"
+		}
+		return nil
+	})
+
+	ctxs, err := getDeviceConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ctxs))
+
+	cfg := ctxs[0].Config.(*DeviceConfig)
+	assert.Equal(t, "2.0", cfg.Version)
+	assert.Equal(t, "bar", cfg.Devices[0].HandlerName)
+
+	// The migrated config should have been written back to file.
+	persisted := &DeviceConfig{}
+	err = unmarshalConfigFile(foo, persisted)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0", persisted.Version)
+	assert.Equal(t, "bar", persisted.Devices[0].HandlerName)
+
+	contents, err := ioutil.ReadFile(foo)
+	assert.NoError(t, err)
+	assert.Contains(t, string(contents), "handlerName: bar")
+}
+
+// TestPlugin_RegisterDeviceConfigFieldAlias tests registering device config field aliases.
+func TestPlugin_RegisterDeviceConfigFieldAlias(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("devices.instances.handler", "handlerName"))
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("devices.kind", "name"))
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("locations.id", "name"))
+	assert.Equal(t, map[string]map[string]string{
+		"devices.instances": {"handler": "handlerName"},
+		"devices":           {"kind": "name"},
+		"locations":         {"id": "name"},
+	}, ctx.deviceConfigFieldAliases)
+}
+
+// TestPlugin_RegisterDeviceConfigFieldAlias_Error tests registering invalid device
+// config field aliases.
+func TestPlugin_RegisterDeviceConfigFieldAlias_Error(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("devices.instances.handler", "handlerName"))
+
+	var testTable = []struct {
+		desc  string
+		alias string
+		field string
+	}{
+		{"alias is not a path", "handler", "handlerName"},
+		{"alias for unsupported mapping", "devices.outputs.kind", "type"},
+		{"unknown field", "devices.instances.foo", "bar"},
+		{"alias is an existing field", "devices.instances.info", "handlerName"},
+		{"alias is already registered", "devices.instances.handler", "info"},
+	}
+
+	for _, testCase := range testTable {
+		err := plugin.RegisterDeviceConfigFieldAlias(testCase.alias, testCase.field)
+		assert.Error(t, err, testCase.desc)
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"devices.instances": {"handler": "handlerName"},
+	}, ctx.deviceConfigFieldAliases)
+}
+
+// TestGetDeviceConfigsFromFile_FieldAlias tests loading a device config which uses
+// the deprecated names of aliased fields.
+func TestGetDeviceConfigsFromFile_FieldAlias(t *testing.T) {
+	defer resetContext()
+
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	data := `
+version: 1.0
+locations:
+- id: r1b1
+  rack:
+    name: rack-1
+  board:
+    name: board-1
+devices:
+- name: temperature
+  instances:
+  - info: temp 1
+    location: r1b1
+    handler: foo
+    data:
+      handler: not-aliased
+  - info: temp 2
+    location: r1b1
+    handler: foo
+    handlerName: bar
+`
+	foo := test.WriteTempFile(t, "foo.yaml", data, os.ModePerm)
+
+	test.SetEnv(t, EnvDeviceConfig, foo)
+	defer test.RemoveEnv(t, EnvDeviceConfig)
+
+	plugin := NewPlugin()
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("devices.instances.handler", "handlerName"))
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("locations.id", "name"))
+
+	ctxs, err := getDeviceConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ctxs))
+
+	cfg := ctxs[0].Config.(*DeviceConfig)
+	assert.Equal(t, "r1b1", cfg.Locations[0].Name)
+	assert.Equal(t, "foo", cfg.Devices[0].Instances[0].HandlerName)
+	assert.Equal(t, "not-aliased", cfg.Devices[0].Instances[0].Data["handler"])
+
+	// When both the alias and the field are set, the field takes precedence.
+	assert.Equal(t, "bar", cfg.Devices[0].Instances[1].HandlerName)
+
+	var warnings []string
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == log.WarnLevel {
+			warnings = append(warnings, fmt.Sprintf("%s %s", entry.Data["alias"], entry.Message))
+		}
+	}
+	assert.Equal(t, []string{
+		"locations.id [sdk] config field is deprecated, use its new name instead",
+		"devices.instances.handler [sdk] config field is deprecated, use its new name instead",
+		"devices.instances.handler [sdk] deprecated config field alias ignored, since its field is also set",
+	}, warnings)
+}
+
+// TestDeviceKind_UnmarshalYAML_FieldAlias tests unmarshaling an aliased field of
+// a non-string type.
+func TestDeviceKind_UnmarshalYAML_FieldAlias(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	assert.NoError(t, plugin.RegisterDeviceConfigFieldAlias("devices.meta", "metadata"))
+
+	kind := &DeviceKind{}
+	err := yaml.Unmarshal([]byte("name: foo\nmeta:\n  model: x1\n"), kind)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", kind.Name)
+	assert.Equal(t, map[string]string{"model": "x1"}, kind.Metadata)
+}
+
+// TestGetDeviceConfigsFromFile_MigratedEnv tests that a migrated device config is
+// not persisted when the file references environment variables, so the expanded
+// values are not written back to file.
+func TestGetDeviceConfigsFromFile_MigratedEnv(t *testing.T) {
+	defer resetContext()
+	defer func(v string) { currentDeviceSchemeVersion = v }(currentDeviceSchemeVersion)
+	currentDeviceSchemeVersion = "2.0"
+
+	flagPersistMigrations = true
+	defer func() { flagPersistMigrations = false }()
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	test.SetEnv(t, "SDK_TEST_KIND", "foo")
+	defer test.RemoveEnv(t, "SDK_TEST_KIND")
+
+	data := `
+version: "1.0"
+devices:
+- name: ${SDK_TEST_KIND}
+`
+	foo := test.WriteTempFile(t, "foo.yaml", data, os.ModePerm)
+
+	test.SetEnv(t, EnvDeviceConfig, foo)
+	defer test.RemoveEnv(t, EnvDeviceConfig)
+
+	plugin := NewPlugin()
+	plugin.RegisterDeviceConfigMigration(1, func(config ConfigBase) error { return nil })
+
+	ctxs, err := getDeviceConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(ctxs))
+
+	cfg := ctxs[0].Config.(*DeviceConfig)
+	assert.Equal(t, "2.0", cfg.Version)
+	assert.Equal(t, "foo", cfg.Devices[0].Name)
+
+	contents, err := ioutil.ReadFile(foo)
+	assert.NoError(t, err)
+	assert.Equal(t, data, string(contents))
+}
@@ -1,11 +1,133 @@
 package sdk
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/vapor-ware/synse-server-grpc/go"
 )
 
+// EnumEncoding specifies how values of a plugin-defined enum type are encoded
+// into a reading value.
+type EnumEncoding uint8
+
+const (
+	// EnumAsString encodes the enum value as its string label. The enum type
+	// must implement fmt.Stringer to use this encoding.
+	EnumAsString EnumEncoding = iota
+
+	// EnumAsInt encodes the enum value as its underlying integer value.
+	EnumAsInt
+)
+
+// RegisterEnumEncoding registers how values of the given enum type should be
+// encoded into reading values. The value passed in should be any value of the
+// enum type; it is only used to determine the type being registered.
+//
+// The enum type must have an integer base type. If EnumAsString is specified,
+// it must also implement fmt.Stringer.
+func RegisterEnumEncoding(enum interface{}, encoding EnumEncoding) error {
+	t := reflect.TypeOf(enum)
+	if t == nil {
+		return fmt.Errorf("cannot register enum encoding for nil value")
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("enum type %s must have an integer base type, but has %s", t, t.Kind())
+	}
+
+	switch encoding {
+	case EnumAsString:
+		if _, ok := enum.(fmt.Stringer); !ok {
+			return fmt.Errorf("enum type %s must implement fmt.Stringer to be encoded as a string", t)
+		}
+	case EnumAsInt:
+	default:
+		return fmt.Errorf("unsupported enum encoding: %d", encoding)
+	}
+
+	ctx.enumEncodings[t] = encoding
+	return nil
+}
+
+// encodeEnum encodes the given value using its registered EnumEncoding. If the
+// value's type has no registered encoding, the value is returned unchanged.
+func encodeEnum(value interface{}) interface{} {
+	encoding, ok := ctx.enumEncodings[reflect.TypeOf(value)]
+	if !ok {
+		return value
+	}
+
+	switch encoding {
+	case EnumAsString:
+		return value.(fmt.Stringer).String()
+	case EnumAsInt:
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Uint()
+		default:
+			return v.Int()
+		}
+	}
+	return value
+}
+
+// The reading context keys set by the SDK. Like all reading context, these are
+// only sent with the readings streamed by the synse.ReadingBatches service (see
+// Reading.Context).
+const (
+	// ContextKeyEpoch is the reading context key for the reading timestamp as
+	// nanoseconds since the Unix epoch. It is only set if enabled via the
+	// plugin's read settings.
+	ContextKeyEpoch = "epoch"
+
+	// ContextKeySourceUnit is the reading context key for the unit (symbol) that
+	// the reading value was originally reported in, before it was normalized to
+	// the unit of its output type.
+	ContextKeySourceUnit = "source_unit"
+
+	// ContextKeyPluginInstance is the reading context key for the ID of the
+	// plugin instance (replica) which produced the reading.
+	ContextKeyPluginInstance = "plugin_instance"
+
+	// ContextKeyEncoding is the reading context key for the compression used to
+	// encode a []byte reading value, e.g. "gzip". It is only set if the value was
+	// compressed, as configured by its output type. Consumers of the
+	// synse.ReadingBatches service use it to decompress the value.
+	ContextKeyEncoding = "encoding"
+
+	// ContextKeyRawValue is the reading context key for the raw value of a reading
+	// whose value was smoothed, as configured by its output type. It is only set
+	// if the output type keeps the raw value.
+	ContextKeyRawValue = "raw_value"
+
+	// ContextKeyError is the reading context key for the error from a failed
+	// device read. It is only set for the error readings which are emitted in
+	// place of a device's readings when error readings are enabled via the
+	// plugin's read settings.
+	ContextKeyError = "error"
+
+	// ContextKeyStale is the reading context key which marks the stale-marker
+	// readings emitted for a device which has produced no readings within the
+	// watchdog timeout. It is only set if enabled via the plugin's watchdog
+	// settings.
+	ContextKeyStale = "stale"
+
+	// ContextKeyDecimal is the reading context key which marks readings whose
+	// value is a Decimal. Decimal values are encoded as strings. Since the reading
+	// context is only sent with the readings of the synse.ReadingBatches service,
+	// only its consumers can tell these apart from string readings.
+	ContextKeyDecimal = "decimal"
+)
+
 // Reading describes a single device reading with a timestamp. The timestamp
 // should be formatted with the RFC3339Nano layout.
 type Reading struct {
@@ -23,6 +145,24 @@ type Reading struct {
 
 	// Value is the reading value itself.
 	Value interface{}
+
+	// Precision optionally overrides the precision of the reading's output
+	// type, for handlers which know that a particular reading deserves more
+	// or less precision. When set, floating point values are rounded to this
+	// many decimal places when the reading is encoded.
+	//
+	// Note: Synse Server rounds readings to the precision of their output
+	// type, if it has one, so an override can lower, but not raise, the
+	// precision of readings for an output type which sets a precision.
+	Precision *int
+
+	// Context holds any additional contextual information for the reading.
+	//
+	// Note: The reading context is not part of the Synse gRPC Reading message,
+	// so it is not included in the readings of the synse.Plugin service (e.g.
+	// its Read responses). It is only sent with the readings streamed by the
+	// synse.ReadingBatches service.
+	Context map[string]string
 }
 
 // NewReading creates a new instance of a Reading. This is the recommended method
@@ -32,13 +172,96 @@ func NewReading(output *Output, value interface{}) (reading *Reading, err error)
 		return nil, fmt.Errorf("Unable to create reading. output is nil")
 	}
 
-	return &Reading{
-		Timestamp: GetCurrentTime(),
+	// Check the type of the value the handler provided, if enforced for the output.
+	if err := output.checkDataType(value); err != nil {
+		return nil, err
+	}
+
+	// Calibrate the raw value, if configured for the output.
+	if output.Calibration != nil {
+		value, err = output.Calibration.apply(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calibrate reading for %s: %v", output.Name, err)
+		}
+	}
+
+	now := readingTime()
+	reading = &Reading{
+		Timestamp: now.Format(time.RFC3339Nano),
 		Type:      output.Type(),
 		Info:      output.Info,
-		Unit:      output.Unit,
+		Unit:      output.EffectiveUnit(),
 		Value:     output.Apply(value),
-	}, nil
+		Context:   map[string]string{},
+	}
+
+	if epochTimestampEnabled() {
+		reading.Context[ContextKeyEpoch] = strconv.FormatInt(now.UnixNano(), 10)
+	}
+
+	// Compress []byte values, if configured by the output type.
+	if b, ok := reading.Value.([]byte); ok && output.Compression != "" {
+		reading.Value, err = output.compress(b)
+		if err != nil {
+			return nil, err
+		}
+		reading.Context[ContextKeyEncoding] = output.Compression
+	}
+
+	// Encode Decimal values as strings, so they are not made imprecise.
+	if d, ok := reading.Value.(Decimal); ok {
+		reading.Value = d.String()
+		reading.Context[ContextKeyDecimal] = "true"
+	}
+	return reading, nil
+}
+
+// NewReadingWithUnit creates a new instance of a Reading for a value that was
+// measured in the given source unit. The value is normalized to the unit of the
+// output's type, and the source unit is recorded in the reading context. The
+// source unit should be specified by its symbol, e.g. "psi".
+func NewReadingWithUnit(output *Output, value interface{}, sourceUnit string) (reading *Reading, err error) {
+	reading, err = NewReading(output, value)
+	if err != nil {
+		return nil, err
+	}
+
+	reading.Value, err = output.normalizeUnit(reading.Value, sourceUnit)
+	if err != nil {
+		return nil, err
+	}
+	if sourceUnit != "" {
+		reading.Context[ContextKeySourceUnit] = sourceUnit
+	}
+	return reading, nil
+}
+
+// epochTimestampEnabled checks whether the plugin is configured to add the epoch
+// timestamp to the reading context.
+func epochTimestampEnabled() bool {
+	if Config.Plugin == nil || Config.Plugin.Settings == nil || Config.Plugin.Settings.Read == nil {
+		return false
+	}
+	return Config.Plugin.Settings.Read.EpochTimestamp
+}
+
+// readingTime gets the current time to timestamp new readings with. If the plugin
+// is configured with a timestamp resolution, the time is truncated or rounded to
+// that resolution.
+func readingTime() time.Time {
+	now := clock.Now().UTC()
+	if Config.Plugin == nil || Config.Plugin.Settings == nil || Config.Plugin.Settings.Read == nil {
+		return now
+	}
+	settings := Config.Plugin.Settings.Read
+	resolution, err := settings.GetTimestampResolution()
+	if err != nil || resolution <= 0 {
+		return now
+	}
+	if settings.TimestampRounding == timestampRound {
+		return now.Round(resolution)
+	}
+	return now.Truncate(resolution)
 }
 
 // encode translates the Reading type to the corresponding gRPC Reading message.
@@ -50,7 +273,7 @@ func (reading *Reading) encode() *synse.Reading { // nolint: gocyclo
 		Unit:      reading.Unit.encode(),
 	}
 
-	switch t := reading.Value.(type) {
+	switch t := reading.round(encodeEnum(reading.Value)).(type) {
 	case string:
 		r.Value = &synse.Reading_StringValue{StringValue: t}
 	case bool:
@@ -91,6 +314,68 @@ func (reading *Reading) encode() *synse.Reading { // nolint: gocyclo
 	return &r
 }
 
+// isEncodableValue checks whether a value is of a type that a Reading can be
+// encoded with (see encode), so it can be used as a reading value without
+// causing a panic when it is encoded.
+func isEncodableValue(value interface{}) bool {
+	switch encodeEnum(value).(type) {
+	case string, bool, float64, float32, int64, int32, int16, int8, int, []byte,
+		uint64, uint32, uint16, uint8, uint, Decimal, nil:
+		return true
+	}
+	return false
+}
+
+// encodeFor translates the Reading for the given Device to the corresponding gRPC
+// Reading message, namespacing its Type with the Device's reading type prefix,
+// if it has one. The Device may be nil (e.g. for a cached reading of a device which
+// is no longer registered), in which case only the plugin's prefix is applied.
+func (reading *Reading) encodeFor(device *Device) *synse.Reading {
+	r := reading.encode()
+	if prefix := device.readingTypePrefix(); prefix != "" {
+		r.Type = prefix + "." + r.Type
+	}
+	return r
+}
+
+// SetPrecision sets the precision override for the reading, which is used instead
+// of the precision of its output type.
+func (reading *Reading) SetPrecision(precision int) *Reading {
+	reading.Precision = &precision
+	return reading
+}
+
+// round rounds a floating point reading value to the reading's precision
+// override. Values are returned unchanged if the reading has no override, the
+// override is negative, or the value is not a finite floating point number.
+func (reading *Reading) round(value interface{}) interface{} {
+	if reading.Precision == nil || *reading.Precision < 0 {
+		return value
+	}
+
+	switch t := value.(type) {
+	case float64:
+		return roundFloat(t, *reading.Precision, 64)
+	case float32:
+		return float32(roundFloat(float64(t), *reading.Precision, 32))
+	}
+	return value
+}
+
+// roundFloat rounds the value to the given number of decimal places. It is
+// rounded via its decimal representation, so that the result is the closest
+// float to the rounded decimal value.
+func roundFloat(value float64, precision, bitSize int) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'f', precision, bitSize), bitSize)
+	if err != nil {
+		return value
+	}
+	return rounded
+}
+
 // ReadContext provides the context for a device reading. This context
 // identifies the device being read and associates it with a set of readings
 // at a given time.
@@ -118,6 +403,41 @@ func NewReadContext(device *Device, readings []*Reading) *ReadContext {
 	}
 }
 
+// errorReadingInfo is the prefix of the info of error readings. The reading
+// context is not sent in the readings of the synse.Plugin service, so error
+// readings are marked by their info, where it is encoded, instead.
+const errorReadingInfo = "error: "
+
+// newErrorReadContext creates a ReadContext holding error readings for a device
+// whose read failed: one for each of the device's outputs, with a nil value and
+// the read error in its context. The read error is also set as the info of the
+// readings, prefixed with "error: ". If the device has no outputs, a single
+// untyped error reading is used.
+func newErrorReadContext(device *Device, err error) *ReadContext {
+	timestamp := readingTime().Format(time.RFC3339Nano)
+	errorReading := func(output *Output) *Reading {
+		reading := &Reading{
+			Timestamp: timestamp,
+			Info:      errorReadingInfo + err.Error(),
+			Context:   map[string]string{ContextKeyError: err.Error()},
+		}
+		if output != nil {
+			reading.Type = output.Type()
+			reading.Unit = output.EffectiveUnit()
+		}
+		return reading
+	}
+
+	var readings []*Reading
+	for _, output := range device.Outputs {
+		readings = append(readings, errorReading(output))
+	}
+	if len(readings) == 0 {
+		readings = append(readings, errorReading(nil))
+	}
+	return NewReadContext(device, readings)
+}
+
 // ID returns a compound string that can identify the resource by its
 // rack, board, and device. This ID should be globally unique. It simply follows
 // the pattern {rack}-{board}-{device}.
@@ -132,6 +452,13 @@ type WriteContext struct {
 	board       string
 	rack        string
 	data        *synse.WriteData
+
+	// traceCtx carries the trace span of the request for the write, if any.
+	traceCtx context.Context
+
+	// barrier is set for a write to a transaction group which is queued in the
+	// write queue of each of the group's members (see groupBarrier).
+	barrier *groupBarrier
 }
 
 // ID returns a compound string that can identify the resource by its
@@ -1,7 +1,13 @@
 package sdk
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-server-grpc/go"
@@ -97,6 +103,318 @@ func TestNewReading(t *testing.T) {
 	assert.Equal(t, 42, reading.Value)
 }
 
+// TestNewReading_Scale tests creating a new Reading for an output with an SI
+// prefix scale.
+func TestNewReading_Scale(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name:  "test",
+			Scale: "k",
+			Unit: Unit{
+				Name:   "pascal",
+				Symbol: "Pa",
+			},
+		},
+	}
+
+	reading, err := NewReading(output, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, "kilopascal", reading.Unit.Name)
+	assert.Equal(t, "kPa", reading.Unit.Symbol)
+	assert.Equal(t, float64(2), reading.Value)
+}
+
+// TestNewReading_Compression tests creating a new Reading with a []byte value for
+// an output which compresses its values.
+func TestNewReading_Compression(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name:        "test",
+			Compression: "gzip",
+		},
+	}
+
+	value := bytes.Repeat([]byte("frame data "), 100)
+	reading, err := NewReading(output, value)
+	assert.NoError(t, err)
+	assert.Equal(t, "gzip", reading.Context[ContextKeyEncoding])
+
+	compressed, ok := reading.Value.([]byte)
+	assert.True(t, ok)
+	assert.True(t, len(compressed) < len(value))
+
+	// The compressed value should round-trip to the original value.
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	decompressed, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decompressed)
+}
+
+// TestNewReading_Compression2 tests creating a new Reading with a non-[]byte value
+// for an output which compresses its values.
+func TestNewReading_Compression2(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name:        "test",
+			Compression: "gzip",
+		},
+	}
+
+	reading, err := NewReading(output, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, reading.Value)
+	assert.NotContains(t, reading.Context, ContextKeyEncoding)
+}
+
+// TestNewReading_Compression3 tests creating a new Reading with a []byte value for
+// an output which does not compress its values.
+func TestNewReading_Compression3(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name: "test",
+		},
+	}
+
+	reading, err := NewReading(output, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), reading.Value)
+	assert.NotContains(t, reading.Context, ContextKeyEncoding)
+}
+
+// TestNewReading_EpochTimestamp tests creating a new Reading when the epoch
+// timestamp is enabled in the plugin config.
+func TestNewReading_EpochTimestamp(t *testing.T) {
+	defer Config.reset()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{
+				EpochTimestamp: true,
+			},
+		},
+	}
+
+	output := &Output{OutputType: OutputType{Name: "test"}}
+
+	reading, err := NewReading(output, 42)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reading.Timestamp)
+	assert.Contains(t, reading.Context, ContextKeyEpoch)
+
+	// The epoch timestamp and the RFC3339 timestamp should agree.
+	ts, err := ParseRFC3339Nano(reading.Timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(ts.UnixNano(), 10), reading.Context[ContextKeyEpoch])
+}
+
+// TestNewReading_EpochTimestampDisabled tests creating a new Reading when the
+// epoch timestamp is disabled in the plugin config.
+func TestNewReading_EpochTimestampDisabled(t *testing.T) {
+	defer Config.reset()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{
+				EpochTimestamp: false,
+			},
+		},
+	}
+
+	output := &Output{OutputType: OutputType{Name: "test"}}
+
+	reading, err := NewReading(output, 42)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reading.Timestamp)
+	assert.NotContains(t, reading.Context, ContextKeyEpoch)
+}
+
+// TestNewReading_TimestampResolution tests creating a new Reading when the plugin
+// is configured to adjust reading timestamps to a resolution.
+func TestNewReading_TimestampResolution(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 678900000, time.UTC))
+
+	var testTable = []struct {
+		desc       string
+		resolution string
+		rounding   string
+		expected   string
+	}{
+		{
+			desc:     "no resolution",
+			expected: "2019-01-02T03:04:05.6789Z",
+		},
+		{
+			desc:       "truncate to the second",
+			resolution: "1s",
+			rounding:   "truncate",
+			expected:   "2019-01-02T03:04:05Z",
+		},
+		{
+			desc:       "truncate by default",
+			resolution: "1s",
+			expected:   "2019-01-02T03:04:05Z",
+		},
+		{
+			desc:       "truncate to the millisecond",
+			resolution: "1ms",
+			expected:   "2019-01-02T03:04:05.678Z",
+		},
+		{
+			desc:       "truncate to the read interval",
+			resolution: "30s",
+			expected:   "2019-01-02T03:04:00Z",
+		},
+		{
+			desc:       "round to the second",
+			resolution: "1s",
+			rounding:   "round",
+			expected:   "2019-01-02T03:04:06Z",
+		},
+	}
+
+	output := &Output{OutputType: OutputType{Name: "test"}}
+	for _, testCase := range testTable {
+		Config.Plugin = &PluginConfig{
+			Settings: &PluginSettings{
+				Read: &ReadSettings{
+					EpochTimestamp:      true,
+					TimestampResolution: testCase.resolution,
+					TimestampRounding:   testCase.rounding,
+				},
+			},
+		}
+
+		reading, err := NewReading(output, 42)
+		assert.NoError(t, err, testCase.desc)
+		assert.Equal(t, testCase.expected, reading.Timestamp, testCase.desc)
+
+		// The epoch timestamp is adjusted as well.
+		ts, err := ParseRFC3339Nano(reading.Timestamp)
+		assert.NoError(t, err, testCase.desc)
+		assert.Equal(t, strconv.FormatInt(ts.UnixNano(), 10), reading.Context[ContextKeyEpoch], testCase.desc)
+	}
+}
+
+// TestNewErrorReadContext_TimestampResolution tests that error reading timestamps
+// are adjusted to the configured resolution.
+func TestNewErrorReadContext_TimestampResolution(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 678900000, time.UTC))
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{TimestampResolution: "1s"},
+		},
+	}
+
+	device := &Device{id: "device", Location: &Location{Rack: "rack", Board: "board"}}
+	readCtx := newErrorReadContext(device, fmt.Errorf("read failed"))
+	assert.Equal(t, 1, len(readCtx.Reading))
+	assert.Equal(t, "2019-01-02T03:04:05Z", readCtx.Reading[0].Timestamp)
+}
+
+// TestNewErrorReadContext_Encode tests that error readings are marked as such when
+// they are encoded, since their context is not encoded.
+func TestNewErrorReadContext_Encode(t *testing.T) {
+	device := &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs:  []*Output{{OutputType: OutputType{Name: "temperature"}, Info: "temp"}},
+	}
+	readCtx := newErrorReadContext(device, fmt.Errorf("read failed"))
+	assert.Equal(t, 1, len(readCtx.Reading))
+
+	encoded := readCtx.Reading[0].encode()
+	assert.Equal(t, "temperature", encoded.Type)
+	assert.Equal(t, "error: read failed", encoded.Info)
+	assert.Nil(t, encoded.Value)
+}
+
+// TestNewReadingWithUnit tests creating a new Reading from a value in a source
+// unit, which gets normalized to the output type's unit.
+func TestNewReadingWithUnit(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name: "pressure",
+			Unit: Unit{Name: "kilopascal", Symbol: "kPa"},
+		},
+	}
+
+	reading, err := NewReadingWithUnit(output, 10, "psi")
+	assert.NoError(t, err)
+	assert.Equal(t, "kPa", reading.Unit.Symbol)
+	assert.InDelta(t, 68.94757, reading.Value, 0.00001)
+	assert.Equal(t, "psi", reading.Context[ContextKeySourceUnit])
+}
+
+// TestNewReadingWithUnit2 tests creating a new Reading from a value which is
+// already in the output type's unit.
+func TestNewReadingWithUnit2(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name: "pressure",
+			Unit: Unit{Name: "kilopascal", Symbol: "kPa"},
+		},
+	}
+
+	reading, err := NewReadingWithUnit(output, 10, "kPa")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, reading.Value)
+	assert.Equal(t, "kPa", reading.Context[ContextKeySourceUnit])
+
+	reading, err = NewReadingWithUnit(output, 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, reading.Value)
+	assert.NotContains(t, reading.Context, ContextKeySourceUnit)
+}
+
+// TestNewReadingWithUnit3 tests creating a new Reading from a value in a source
+// unit which has no conversion to the output type's unit.
+func TestNewReadingWithUnit3(t *testing.T) {
+	output := &Output{
+		OutputType: OutputType{
+			Name: "pressure",
+			Unit: Unit{Name: "kilopascal", Symbol: "kPa"},
+		},
+	}
+
+	reading, err := NewReadingWithUnit(output, 10, "bar")
+	assert.Error(t, err)
+	assert.Nil(t, reading)
+}
+
+// TestNewReadingWithUnit4 tests creating a new Reading using a unit conversion
+// registered by the plugin.
+func TestNewReadingWithUnit4(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterUnitConversion("bar", "kPa", func(f float64) float64 { return f * 100 })
+	assert.NoError(t, err)
+
+	err = plugin.RegisterUnitConversion("bar", "kPa", func(f float64) float64 { return f * 100 })
+	assert.Error(t, err)
+
+	output := &Output{
+		OutputType: OutputType{
+			Name: "pressure",
+			Unit: Unit{Name: "kilopascal", Symbol: "kPa"},
+		},
+	}
+
+	reading, err := output.MakeReadingWithUnit(1.5, "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(150), reading.Value)
+	assert.Equal(t, "bar", reading.Context[ContextKeySourceUnit])
+}
+
 // TestNewReadContext tests creating a new ReadContext.
 func TestNewReadContext(t *testing.T) {
 	device := &Device{
@@ -157,6 +475,96 @@ func TestReading_encode_float32(t *testing.T) {
 	assert.Equal(t, float32(7), out.GetFloat32Value())
 }
 
+// TestReading_encode_precision tests encoding a Reading with a precision override,
+// which is used instead of its output type's precision.
+func TestReading_encode_precision(t *testing.T) {
+	output := &Output{OutputType: OutputType{Name: "test", Precision: 2}}
+
+	reading, err := output.MakeReading(3.14159)
+	assert.NoError(t, err)
+
+	// Without an override, the value is not rounded by the SDK.
+	assert.Equal(t, 3.14159, reading.encode().GetFloat64Value())
+
+	reading.SetPrecision(4)
+	assert.Equal(t, 3.1416, reading.encode().GetFloat64Value())
+
+	reading.SetPrecision(0)
+	assert.Equal(t, float64(3), reading.encode().GetFloat64Value())
+
+	// The reading value itself is not modified.
+	assert.Equal(t, 3.14159, reading.Value)
+}
+
+// TestReading_encode_precision2 tests encoding Readings with a precision override
+// for different value types.
+func TestReading_encode_precision2(t *testing.T) {
+	var testTable = []struct {
+		desc      string
+		value     interface{}
+		precision int
+		expected  interface{}
+	}{
+		{"float64 rounded up", float64(2.675), 1, float64(2.7)},
+		{"float64 rounded down", float64(-1.2345), 2, float64(-1.23)},
+		{"float32", float32(1.23456), 3, float32(1.235)},
+		{"negative precision", float64(1.23456), -1, float64(1.23456)},
+		{"int", int64(12345), 2, int64(12345)},
+		{"string", "1.23456", 2, "1.23456"},
+	}
+
+	for _, testCase := range testTable {
+		reading := &Reading{Type: "test", Value: testCase.value}
+		reading.SetPrecision(testCase.precision)
+		out := reading.encode()
+
+		var actual interface{}
+		switch testCase.expected.(type) {
+		case float64:
+			actual = out.GetFloat64Value()
+		case float32:
+			actual = out.GetFloat32Value()
+		case int64:
+			actual = out.GetInt64Value()
+		case string:
+			actual = out.GetStringValue()
+		}
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestReading_encodeFor tests encoding a Reading for a device with a reading
+// type prefix.
+func TestReading_encodeFor(t *testing.T) {
+	defer Config.reset()
+
+	var testTable = []struct {
+		desc     string
+		plugin   string
+		device   *Device
+		expected string
+	}{
+		{"no prefix", "", &Device{}, "temperature"},
+		{"no prefix, no device", "", nil, "temperature"},
+		{"plugin prefix", "siteA", &Device{}, "siteA.temperature"},
+		{"plugin prefix, no device", "siteA", nil, "siteA.temperature"},
+		{"device prefix", "", &Device{TypePrefix: "siteB"}, "siteB.temperature"},
+		{"device prefix overrides plugin", "siteA", &Device{TypePrefix: "tenant.siteB"}, "tenant.siteB.temperature"},
+	}
+
+	for _, testCase := range testTable {
+		Config.Plugin = &PluginConfig{
+			Settings: &PluginSettings{
+				Read: &ReadSettings{TypePrefix: testCase.plugin},
+			},
+		}
+		reading := &Reading{Type: "temperature", Value: 1}
+		out := reading.encodeFor(testCase.device)
+		assert.Equal(t, testCase.expected, out.Type, testCase.desc)
+		assert.Equal(t, "temperature", reading.Type, testCase.desc)
+	}
+}
+
 // TestReading_encode_int64 tests encoding a Reading when the value is an int64.
 func TestReading_encode_int64(t *testing.T) {
 	reading := Reading{
@@ -288,3 +696,133 @@ func TestReading_encode_nil(t *testing.T) {
 	assert.Equal(t, "test", out.Type)
 	assert.Equal(t, nil, out.GetValue())
 }
+
+// testEnum is an enum type used to test enum reading value encoding.
+type testEnum int
+
+const (
+	testEnumOff testEnum = iota
+	testEnumOn
+)
+
+func (e testEnum) String() string {
+	if e == testEnumOn {
+		return "on"
+	}
+	return "off"
+}
+
+// testUintEnum is an unsigned enum type which does not implement fmt.Stringer.
+type testUintEnum uint8
+
+// TestRegisterEnumEncoding tests registering enum encodings.
+func TestRegisterEnumEncoding(t *testing.T) {
+	defer resetContext()
+
+	var testTable = []struct {
+		desc     string
+		enum     interface{}
+		encoding EnumEncoding
+		isError  bool
+	}{
+		{
+			desc:     "stringer enum as string",
+			enum:     testEnumOn,
+			encoding: EnumAsString,
+			isError:  false,
+		},
+		{
+			desc:     "unsigned enum as int",
+			enum:     testUintEnum(1),
+			encoding: EnumAsInt,
+			isError:  false,
+		},
+		{
+			desc:     "non-stringer enum as string",
+			enum:     testUintEnum(1),
+			encoding: EnumAsString,
+			isError:  true,
+		},
+		{
+			desc:     "non-integer type",
+			enum:     "foo",
+			encoding: EnumAsInt,
+			isError:  true,
+		},
+		{
+			desc:     "nil value",
+			enum:     nil,
+			encoding: EnumAsInt,
+			isError:  true,
+		},
+		{
+			desc:     "unsupported encoding",
+			enum:     testEnumOn,
+			encoding: EnumEncoding(99),
+			isError:  true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		err := RegisterEnumEncoding(testCase.enum, testCase.encoding)
+		if testCase.isError {
+			assert.Error(t, err, testCase.desc)
+		} else {
+			assert.NoError(t, err, testCase.desc)
+		}
+	}
+}
+
+// TestReading_encode_enumString tests encoding a Reading when the value is an
+// enum registered to encode as a string.
+func TestReading_encode_enumString(t *testing.T) {
+	defer resetContext()
+
+	err := RegisterEnumEncoding(testEnumOff, EnumAsString)
+	assert.NoError(t, err)
+
+	reading := Reading{
+		Type:  "test",
+		Value: testEnumOn,
+	}
+	out := reading.encode()
+	assert.Equal(t, "test", out.Type)
+	assert.Equal(t, "on", out.GetStringValue())
+}
+
+// TestReading_encode_enumInt tests encoding a Reading when the value is an
+// enum registered to encode as an integer.
+func TestReading_encode_enumInt(t *testing.T) {
+	defer resetContext()
+
+	err := RegisterEnumEncoding(testEnumOff, EnumAsInt)
+	assert.NoError(t, err)
+	err = RegisterEnumEncoding(testUintEnum(0), EnumAsInt)
+	assert.NoError(t, err)
+
+	reading := Reading{
+		Type:  "test",
+		Value: testEnumOn,
+	}
+	out := reading.encode()
+	assert.Equal(t, int64(1), out.GetInt64Value())
+
+	reading = Reading{
+		Type:  "test",
+		Value: testUintEnum(3),
+	}
+	out = reading.encode()
+	assert.Equal(t, uint64(3), out.GetUint64Value())
+}
+
+// TestReading_encode_enumUnregistered tests encoding a Reading when the value
+// is an enum with no registered encoding.
+func TestReading_encode_enumUnregistered(t *testing.T) {
+	defer resetContext()
+
+	reading := Reading{
+		Type:  "test",
+		Value: testEnumOn,
+	}
+	assert.Panics(t, func() { reading.encode() })
+}
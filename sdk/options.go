@@ -38,3 +38,14 @@ func CustomDeviceDataValidator(validator DeviceDataValidator) PluginOption {
 		ctx.deviceDataValidator = validator
 	}
 }
+
+// CustomAtmosphericPressureSource lets you set a custom function for getting the local
+// atmospheric pressure used to convert between absolute and gauge pressure (see the
+// "absoluteToGaugePressure" and "gaugeToAbsolutePressure" conversions). By default, the
+// atmospheric pressure set in the Plugin config is used. The pressure from the source
+// is cached for a minute, so the source is not called for every converted reading.
+func CustomAtmosphericPressureSource(source AtmosphericPressureSource) PluginOption {
+	return func(ctx *PluginContext) {
+		setAtmosphericPressureSource(source)
+	}
+}
@@ -3,8 +3,13 @@ package sdk
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"reflect"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -13,12 +18,14 @@ import (
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 	"github.com/vapor-ware/synse-sdk/sdk/health"
 	"github.com/vapor-ware/synse-sdk/sdk/policies"
+	"google.golang.org/grpc/keepalive"
 )
 
 // A Plugin represents an instance of a Synse Plugin. Synse Plugins are used
 // as data providers and device controllers for Synse server.
 type Plugin struct {
 	server *server
+	admin  *http.Server
 	quit   chan os.Signal
 }
 
@@ -55,6 +62,137 @@ func (plugin *Plugin) RegisterOutputTypes(types ...*OutputType) error {
 	return multiErr.Err()
 }
 
+// RegisterOutputTypeWithConversion registers an OutputType along with the named
+// Conversion it references. If a conversion is given, it is registered under the
+// OutputType's Conversion name, which must be set and must not already be in use.
+// If no conversion is given, every conversion the OutputType references must
+// already be registered.
+//
+// The OutputType is validated before it is registered. If there are any errors,
+// neither the OutputType nor the conversion is registered.
+func (plugin *Plugin) RegisterOutputTypeWithConversion(outputType *OutputType, conversion Conversion) error {
+	if outputType == nil {
+		return fmt.Errorf("output type is nil")
+	}
+	if _, hasType := ctx.outputTypes[outputType.Name]; hasType {
+		log.WithField("type", outputType.Name).Error("[sdk] output type already exists")
+		return fmt.Errorf("output type with name '%s' already exists", outputType.Name)
+	}
+
+	if conversion != nil {
+		if outputType.Conversion == "" {
+			return fmt.Errorf("output type '%s' does not name the conversion to register", outputType.Name)
+		}
+		if err := plugin.RegisterConversion(outputType.Conversion, conversion); err != nil {
+			return err
+		}
+	}
+
+	multiErr := errors.NewMultiError("registering output type")
+	multiErr.Context["source"] = outputType.Name
+	outputType.Validate(multiErr)
+	if multiErr.HasErrors() {
+		// Unregister the conversion, so that nothing is registered.
+		if conversion != nil {
+			delete(ctx.conversions, outputType.Conversion)
+		}
+		return multiErr
+	}
+
+	log.WithField("type", outputType.Name).Debug("[sdk] adding new output type")
+	ctx.outputTypes[outputType.Name] = outputType
+	return nil
+}
+
+// SetOutputTypes replaces the set of output types used by the SDK with the given
+// output types. Unlike RegisterOutputTypes, it does not add to the output types
+// which are already registered, so it can be used to inject a known set of output
+// types (e.g. in tests or tooling) without output type config files.
+//
+// Each output type is validated. An output type which is given more than once is
+// only added once, but different output types with the same name are an error. If
+// there are any errors, the current output types are left unchanged.
+func SetOutputTypes(types []*OutputType) error {
+	multiErr := errors.NewMultiError("setting output types")
+	log.Debug("[sdk] setting output types")
+
+	outputTypes := map[string]*OutputType{}
+	for i, outputType := range types {
+		if outputType == nil {
+			multiErr.Add(fmt.Errorf("output type at index %d is nil", i))
+			continue
+		}
+
+		multiErr.Context["source"] = outputType.Name
+		outputType.Validate(multiErr)
+
+		existing, hasType := outputTypes[outputType.Name]
+		if hasType {
+			if !reflect.DeepEqual(existing, outputType) {
+				log.WithField("type", outputType.Name).Error("[sdk] conflicting output types with the same name")
+				multiErr.Add(fmt.Errorf("conflicting output types with name '%s'", outputType.Name))
+			}
+			continue
+		}
+		outputTypes[outputType.Name] = outputType
+	}
+
+	if multiErr.HasErrors() {
+		return multiErr
+	}
+	ctx.outputTypes = outputTypes
+	return nil
+}
+
+// RegisterConversion registers a named Conversion with the Plugin. Once registered,
+// OutputTypes can reference the conversion by name in their conversion chain.
+func (plugin *Plugin) RegisterConversion(name string, conversion Conversion) error {
+	if _, exists := ctx.conversions[name]; exists {
+		log.WithField("conversion", name).Error("[sdk] conversion already exists")
+		return fmt.Errorf("conversion with name '%s' already exists", name)
+	}
+	log.WithField("conversion", name).Debug("[sdk] adding new conversion")
+	ctx.conversions[name] = conversion
+	return nil
+}
+
+// RegisterConversionUnit declares the symbol of the unit that a registered named
+// Conversion converts to. OutputTypes whose conversion chain ends with the
+// conversion must then declare the same unit, so that readings are not reported
+// with the wrong unit.
+func (plugin *Plugin) RegisterConversionUnit(name, unit string) error {
+	if _, exists := ctx.conversions[name]; !exists {
+		log.WithField("conversion", name).Error("[sdk] conversion does not exist")
+		return fmt.Errorf("conversion with name '%s' does not exist", name)
+	}
+	log.WithFields(log.Fields{
+		"conversion": name,
+		"unit":       unit,
+	}).Debug("[sdk] declaring conversion unit")
+	ctx.conversionUnits[name] = unit
+	return nil
+}
+
+// RegisterUnitConversion registers a Conversion between two units of measure,
+// identified by their unit symbols. Readings made with a source unit are normalized
+// to the unit of their output type using the registered unit conversions.
+func (plugin *Plugin) RegisterUnitConversion(from, to string, conversion Conversion) error {
+	units := unitConversion{from: from, to: to}
+	if _, exists := ctx.unitConversions[units]; exists {
+		log.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).Error("[sdk] unit conversion already exists")
+		return fmt.Errorf("unit conversion from '%s' to '%s' already exists", from, to)
+	}
+	log.WithFields(log.Fields{
+		"from": from,
+		"to":   to,
+	}).Debug("[sdk] adding new unit conversion")
+	ctx.unitConversions[units] = conversion
+	return nil
+}
+
 // RegisterPreRunActions registers functions with the plugin that will be called
 // before the gRPC server and dataManager are started. The functions here can be
 // used for plugin-wide setup actions.
@@ -96,6 +234,254 @@ func (plugin *Plugin) RegisterDeviceHandlers(handlers ...*DeviceHandler) {
 	ctx.deviceHandlers = append(ctx.deviceHandlers, handlers...)
 }
 
+// HandlerInfo describes a DeviceHandler which is registered with the plugin.
+type HandlerInfo struct {
+	// Name is the name of the handler.
+	Name string
+
+	// Read is true if the handler supports individual device reads.
+	Read bool
+
+	// BulkRead is true if devices using the handler are read in bulk. This
+	// is only the case if the handler defines BulkRead, but not Read.
+	BulkRead bool
+
+	// Write is true if the handler supports writes.
+	Write bool
+
+	// Listen is true if the handler listens for push-based data.
+	Listen bool
+
+	// Devices is the number of registered devices which use the handler.
+	Devices int
+}
+
+// ListHandlers gets info for each of the DeviceHandlers registered with the
+// plugin, in the order that they were registered. This can be used to inspect
+// the handlers available at runtime and the devices matched to them.
+func (plugin *Plugin) ListHandlers() []*HandlerInfo {
+	var handlers []*HandlerInfo
+	for _, handler := range ctx.deviceHandlers {
+		handlers = append(handlers, &HandlerInfo{
+			Name:     handler.Name,
+			Read:     handler.supportsRead(),
+			BulkRead: handler.supportsBulkRead(),
+			Write:    handler.supportsWrite(),
+			Listen:   handler.Listen != nil,
+			Devices:  len(handler.getDevicesForHandler()),
+		})
+	}
+	return handlers
+}
+
+// PauseReads pauses the plugin's read loop. While reads are paused, devices are
+// not polled and the last readings for them are kept. Listeners are not
+// affected. Reads are resumed with ResumeReads.
+func (plugin *Plugin) PauseReads() {
+	DataManager.pauseReads(true)
+	log.Info("[sdk] reads paused")
+}
+
+// ResumeReads resumes the plugin's read loop after it was paused with PauseReads.
+func (plugin *Plugin) ResumeReads() {
+	DataManager.pauseReads(false)
+	log.Info("[sdk] reads resumed")
+}
+
+// ReadsPaused checks whether the plugin's read loop is paused.
+func (plugin *Plugin) ReadsPaused() bool {
+	return DataManager.readsArePaused()
+}
+
+// ReadDevice reads the device with the given ID (its GUID, e.g. "rack-board-device")
+// immediately, outside of the read loop, and updates the readings state with the
+// result. This works even while reads are paused.
+func (plugin *Plugin) ReadDevice(id string) ([]*Reading, error) {
+	device := ctx.getDevice(id)
+	if device == nil {
+		return nil, errors.NotFoundErr("no device found with id: %s", id)
+	}
+	if err := validateForRead(id); err != nil {
+		return nil, err
+	}
+
+	readings, err := DataManager.readDevice(device)
+	if err != nil {
+		return nil, err
+	}
+	DataManager.updateReadings(NewReadContext(device, readings))
+	return readings, nil
+}
+
+// ReloadDevice re-reads and re-validates the device configuration from its
+// sources and replaces the device with the given ID (its GUID, e.g.
+// "rack-board-device") with the newly configured one. All other devices are
+// left untouched, even if their configuration has also changed.
+//
+// The device is swapped in by atomically replacing the device map as a whole, so
+// anything iterating the existing map is unaffected by the reload. The device's
+// instance in the plugin's device config (Config.Device) is replaced along with it,
+// so the config continues to describe the running devices. If the device no
+// longer exists in the configuration, or if the configuration is invalid, the
+// existing device is kept and an error is returned. If the plugin is configured
+// with a maximum number of reload failures, the plugin exits with an error once
+// that many consecutive reloads have failed due to an invalid configuration.
+//
+// The device setup actions whose filters match the reloaded device are run for it
+// before it is swapped in. If any of them fails, the existing device is kept and
+// the errors are returned.
+//
+// Listeners can not be stopped, so if the device's handler has a listener function,
+// the listener started for the device keeps running with the device as it was
+// before the reload, and no listener is started for the reloaded device. A change
+// to the config of a listened-to device takes effect once the plugin is restarted.
+func (plugin *Plugin) ReloadDevice(id string) error {
+	return reloadDevice(plugin, id)
+}
+
+// reloadDevice reloads the config for the device with the given ID. See
+// Plugin.ReloadDevice.
+func reloadDevice(plugin *Plugin, id string) error {
+	if ctx.getDevice(id) == nil {
+		return errors.NotFoundErr("no device found with id: %s", id)
+	}
+
+	cfg, err := loadDeviceConfigs()
+	if err != nil {
+		return reloadFailed(err)
+	}
+	devices, err := makeDevices(cfg)
+	if err != nil {
+		return reloadFailed(err)
+	}
+	atomic.StoreInt32(&reloadFailures, 0)
+
+	var device *Device
+	for _, d := range devices {
+		if d.GUID() == id {
+			device = d
+			break
+		}
+	}
+	if device == nil {
+		return errors.NotFoundErr("device %s not found in reloaded config", id)
+	}
+
+	// Run the device setup actions for the reloaded device before it is swapped
+	// in, just as they are run for the devices registered at startup.
+	if multiErr := execDeviceSetupFor(plugin, []*Device{device}); multiErr.HasErrors() {
+		return multiErr
+	}
+
+	err = ctx.updateDevices(func(devices map[string]*Device) error {
+		existing, exists := devices[id]
+		if !exists {
+			return errors.NotFoundErr("no device found with id: %s", id)
+		}
+		device.order = existing.order
+		devices[id] = device
+		Config.Device = reloadedDeviceConfig(Config.Device, cfg, id)
+		DataManager.resetDeviceState(id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithField("id", id).Info("[sdk] reloaded device config")
+	return nil
+}
+
+// reloadedDeviceConfig gets the device config which describes the plugin's devices
+// once the device with the given ID is reloaded from the reloaded config. It is a
+// copy of the current config, with the device's instance, and the location which
+// it references, replaced by those in the reloaded config. The current config is
+// not modified.
+func reloadedDeviceConfig(current, reloaded *DeviceConfig, id string) *DeviceConfig {
+	kindIdx, instanceIdx := findDeviceInstance(reloaded, id)
+	if kindIdx == -1 {
+		return current
+	}
+	if current == nil {
+		return reloaded
+	}
+	kind := reloaded.Devices[kindIdx]
+	instance := kind.Instances[instanceIdx]
+
+	cfg := copyDeviceConfig(current)
+	if k, i := findDeviceInstance(current, id); k != -1 {
+		cfg.Devices[k].Instances[i] = instance
+	} else {
+		kindCopy := *kind
+		kindCopy.Instances = []*DeviceInstance{instance}
+		cfg.Devices = append(cfg.Devices, &kindCopy)
+	}
+
+	if location, err := reloaded.GetLocation(instance.Location); err == nil {
+		replaced := false
+		for i, l := range cfg.Locations {
+			if l.Name == location.Name {
+				cfg.Locations[i] = location
+				replaced = true
+			}
+		}
+		if !replaced {
+			cfg.Locations = append(cfg.Locations, location)
+		}
+	}
+	return cfg
+}
+
+// findDeviceInstance finds the device kind and instance in the device config which
+// the device with the given ID is made from, returning their indices. If no instance
+// makes the device, both indices are -1.
+func findDeviceInstance(cfg *DeviceConfig, id string) (int, int) {
+	if cfg == nil {
+		return -1, -1
+	}
+	for k, kind := range cfg.Devices {
+		for i, instance := range kind.Instances {
+			single := *kind
+			single.Instances = []*DeviceInstance{instance}
+			devices, err := makeDevices(&DeviceConfig{
+				SchemeVersion: cfg.SchemeVersion,
+				Locations:     cfg.Locations,
+				Devices:       []*DeviceKind{&single},
+			})
+			if err == nil && len(devices) == 1 && devices[0].GUID() == id {
+				return k, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+// reloadFailures is the number of consecutive device config reloads which have
+// failed due to an invalid configuration. It is accessed atomically.
+var reloadFailures int32
+
+// reloadFailed records a device config reload which failed with the given error,
+// and returns the error. If the plugin is configured with a maximum number of
+// reload failures and it has been reached, the failure is escalated by exiting
+// the plugin with an error, so that the broken config does not go unnoticed.
+func reloadFailed(err error) error {
+	failures := atomic.AddInt32(&reloadFailures, 1)
+	rlog := log.WithFields(log.Fields{
+		"error":    err,
+		"failures": failures,
+	})
+
+	var max int
+	if Config.Plugin != nil && Config.Plugin.Settings != nil {
+		max = Config.Plugin.Settings.MaxReloadFailures
+	}
+	if max > 0 && int(failures) >= max {
+		rlog.Fatal("[sdk] exiting after repeated failed device config reloads")
+	}
+	rlog.Warn("[sdk] failed to reload device config, keeping existing config")
+	return err
+}
+
 // Run starts the Plugin.
 //
 // Before the gRPC server is started, and before the read and write goroutines
@@ -143,36 +529,114 @@ func (plugin *Plugin) Run() error {
 		health.RegisterPeriodicCheck("write buffer health", 30*time.Second, writeBufferHealthCheck)
 	}
 
+	// If device quarantine is enabled, register a health check for it
+	if deviceQuarantine != nil {
+		health.RegisterPeriodicCheck("device quarantine", 30*time.Second, quarantineHealthCheck)
+	}
+
+	// If the device staleness watchdog is enabled, register a health check for it
+	if deviceWatchdog != nil {
+		health.RegisterPeriodicCheck("device staleness", 30*time.Second, watchdogHealthCheck)
+	}
+
 	// Start the data manager
 	err = DataManager.run()
 	if err != nil {
 		return err
 	}
 
+	// If metrics export is configured, start exporting metrics
+	if Config.Plugin.Metrics != nil {
+		go runMetricsExporter(Config.Plugin.Metrics)
+	}
+
+	// If the admin server is configured, start serving it
+	if Config.Plugin.Admin != nil {
+		plugin.admin = newAdminServer(plugin, Config.Plugin.Admin)
+		go runAdminServer(plugin.admin)
+	}
+
 	// Start the gRPC server
-	return plugin.server.Serve()
+	return plugin.serve()
+}
+
+// serve starts the plugin's gRPC server. If the server fails to bind to its
+// configured address, the plugin will not be able to run, so the post-run
+// actions are executed to release any resources acquired by the pre-run and
+// device setup actions before the bind error is returned.
+func (plugin *Plugin) serve() error {
+	err := plugin.server.Serve()
+	if _, ok := err.(*errors.ServerBindError); ok {
+		log.WithField("error", err).Error("[sdk] failed to start grpc server, running post-run actions")
+		if multiErr := execPostRun(plugin); multiErr.HasErrors() {
+			log.Error(multiErr)
+		}
+	}
+	return err
 }
 
 // onQuit is a function that waits for a signal to terminate the plugin's run
 // and run cleanup/post-run actions prior to terminating.
 func (plugin *Plugin) onQuit() {
 	sig := <-plugin.quit
+	os.Exit(plugin.shutdown(sig))
+}
+
+// shutdown stops the plugin's gRPC server and executes the post-run actions,
+// returning the exit code for the plugin. If a shutdown timeout is configured
+// and the post-run actions do not complete within it, the plugin does not wait
+// for them and exits with an error.
+func (plugin *Plugin) shutdown(sig os.Signal) int {
 	log.Infof("[sdk] stopping plugin (%s)...", sig.String())
 
 	// TODO: any other stop/cleanup actions should go here (closing channels, etc)
 
-	// Immediately stop the gRPC server.
-	plugin.server.Stop()
+	// Stop the gRPC server, letting in-flight RPCs complete.
+	drainTimeout := defaultDrainTimeout
+	if Config.Plugin != nil && Config.Plugin.Network != nil {
+		t, err := Config.Plugin.Network.GetDrainTimeout()
+		if err != nil {
+			log.WithField("error", err).Error("[sdk] invalid drain timeout, using default")
+		} else {
+			drainTimeout = t
+		}
+	}
+	plugin.server.GracefulStop(drainTimeout)
+
+	// Stop the admin server, if it is running, in the same way.
+	stopAdminServer(plugin.admin, drainTimeout)
+
+	var timeout time.Duration
+	if Config.Plugin != nil && Config.Plugin.Settings != nil {
+		t, err := Config.Plugin.Settings.GetShutdownTimeout()
+		if err != nil {
+			log.WithField("error", err).Error("[sdk] invalid shutdown timeout, waiting for post-run actions")
+		}
+		timeout = t
+	}
+
+	// Wait for the writes already queued for devices to be fulfilled. No new
+	// writes can be queued, since the gRPC server is stopped.
+	if !DataManager.waitForWriteQueues(timeout) {
+		log.Warn("[sdk] timed out waiting for queued writes, exiting with writes pending")
+	}
+
+	// Stop recording readings, so the recording file is closed before exit.
+	DataManager.stopRecording()
 
 	// Execute post-run actions.
-	multiErr := execPostRun(plugin)
+	multiErr, err := execPostRunWithTimeout(plugin, timeout)
+	if err != nil {
+		log.WithField("error", err).Error("[sdk] forcing plugin exit")
+		return 1
+	}
 	if multiErr.HasErrors() {
 		log.Error(multiErr)
-		os.Exit(1)
+		return 1
 	}
 
 	log.Info("[done]")
-	os.Exit(0)
+	return 0
 }
 
 // setupLogger sets up the logger. Currently this just gives us sub second time
@@ -208,6 +672,11 @@ func (plugin *Plugin) setup() error {
 	// action, that action will be resolved here.
 	parseFlags()
 
+	// --snapshot saves a snapshot of the latest readings to file on SIGUSR1.
+	if flagSnapshot != "" {
+		go watchSnapshotSignal(flagSnapshot)
+	}
+
 	// Check that the registered device handlers do not have any conflicting names.
 	err = ctx.checkDeviceHandlers()
 	if err != nil {
@@ -232,6 +701,9 @@ func (plugin *Plugin) setup() error {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	// Resolve the ID of this plugin instance
+	ctx.instanceID = resolveInstanceID()
+
 	// Initialize Device instances for each of the devices configured with
 	// the plugin.
 	err = registerDevices()
@@ -239,6 +711,24 @@ func (plugin *Plugin) setup() error {
 		return err
 	}
 
+	// Make sure the plugin has devices, if it requires them.
+	err = checkDevicesRegistered()
+	if err != nil {
+		return err
+	}
+
+	// Check that the handlers, devices, and output types are wired correctly.
+	err = plugin.runSelfTest()
+	if err != nil {
+		return err
+	}
+
+	// Make sure every output type is used by a device, if the plugin requires it.
+	err = checkOutputTypesUsed()
+	if err != nil {
+		return err
+	}
+
 	// Set up the transaction cache
 	ttl, err := Config.Plugin.Settings.Transaction.GetTTL()
 	if err != nil {
@@ -249,14 +739,45 @@ func (plugin *Plugin) setup() error {
 	// Set up the readings cache, if its configured
 	setupReadingsCache()
 
+	// Set up the readings history, if its configured
+	setupReadingsHistory()
+
+	// Set up the device quarantine, if its configured
+	setupDeviceQuarantine()
+
+	// Set up the device staleness watchdog, if its configured
+	err = setupDeviceWatchdog()
+	if err != nil {
+		return err
+	}
+
 	// Initialize a gRPC server for the Plugin to use.
 	plugin.server = newServer(
 		Config.Plugin.Network.Type,
 		Config.Plugin.Network.Address,
 	)
+	plugin.server.plugin = plugin
 	return nil
 }
 
+// resolveInstanceID gets the ID of the plugin instance. The ID set in the plugin
+// config takes precedence, followed by the ID set via environment variable. If
+// neither is set, the hostname is used.
+func resolveInstanceID() string {
+	if Config.Plugin.InstanceID != "" {
+		return Config.Plugin.InstanceID
+	}
+	if id := os.Getenv(EnvPluginInstanceID); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.WithField("error", err).Warn("[sdk] unable to get hostname for plugin instance id")
+		return ""
+	}
+	return hostname
+}
+
 // processConfig handles plugin configuration in a number of steps. The behavior
 // of config handling is dependent on the config policy that is set. If no config
 // policies are set, the plugin will terminate in error.
@@ -273,6 +794,13 @@ func (plugin *Plugin) processConfig() error {
 		return err
 	}
 
+	// Register any conversions defined in the plugin config, so they are
+	// available to the output types.
+	err = registerConfiguredConversions(plugin)
+	if err != nil {
+		return err
+	}
+
 	// Resolve the output type config(s).
 	log.Debug("[sdk] resolving output type config(s)")
 	outputTypes, err := processOutputTypeConfig()
@@ -335,6 +863,12 @@ type PluginConfig struct {
 	// with debug logging or not.
 	Debug bool `default:"false" yaml:"debug,omitempty" addedIn:"1.0"`
 
+	// InstanceID is the ID of this plugin instance. This is useful for
+	// identifying which replica produced a reading when multiple replicas
+	// of a plugin are deployed. If this is not set, the PLUGIN_INSTANCE_ID
+	// environment variable is used, falling back to the hostname.
+	InstanceID string `yaml:"instanceId,omitempty" addedIn:"1.3"`
+
 	// Settings provide specifications for how the plugin should run.
 	Settings *PluginSettings `default:"{}" yaml:"settings,omitempty" addedIn:"1.0"`
 
@@ -351,6 +885,29 @@ type PluginConfig struct {
 	// Health specifies the settings for health checking in the plugin.
 	Health *HealthSettings `default:"{}" yaml:"health,omitempty" addedIn:"1.0"`
 
+	// Metrics specifies the configuration for exporting plugin metrics. If
+	// this is not set, metrics are not exported.
+	Metrics *MetricsSettings `yaml:"metrics,omitempty" addedIn:"1.3"`
+
+	// SelfMonitoring specifies the configuration for the plugin's self-monitoring
+	// devices. If this is not set, the devices are not registered.
+	SelfMonitoring *SelfMonitoringSettings `yaml:"selfMonitoring,omitempty" addedIn:"1.3"`
+
+	// Admin specifies the configuration for the plugin's HTTP admin server. If
+	// this is not set, the admin server is not run.
+	Admin *AdminSettings `yaml:"admin,omitempty" addedIn:"1.3"`
+
+	// Conversions is a table of linear unit conversions to register with the
+	// plugin, in addition to any registered in code.
+	Conversions []*ConversionConfig `yaml:"conversions,omitempty" addedIn:"1.3"`
+
+	// AtmosphericPressure is the local atmospheric pressure used as the baseline
+	// for converting between absolute and gauge pressure. It should be in the same
+	// unit as the pressure readings being converted. If this is not set, the
+	// standard atmospheric pressure in the unit of the output type is used, e.g.
+	// 101.325 for "kPa"; it must be set for units with no known standard pressure.
+	AtmosphericPressure float64 `yaml:"atmosphericPressure,omitempty" addedIn:"1.3"`
+
 	// Context is a map that allows the plugin to specify any arbitrary
 	// data it may need.
 	Context map[string]interface{} `default:"{}" yaml:"context,omitempty" addedIn:"1.0"`
@@ -380,6 +937,10 @@ func (config PluginConfig) Validate(multiErr *errors.MultiError) {
 		log.WithField("config", config).Error("[validation] no network")
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "network"))
 	}
+
+	if config.AtmosphericPressure < 0 {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "atmosphericPressure", "a positive number"))
+	}
 }
 
 // PluginSettings specifies the configuration options that determine the
@@ -405,6 +966,68 @@ type PluginSettings struct {
 	// Cache contains the settings to configure local data caching
 	// by the plugin.
 	Cache *CacheSettings `default:"{}" yaml:"cache,omitempty" addedIn:"1.2"`
+
+	// History contains the settings to configure the rolling history
+	// of recent readings kept by the plugin.
+	History *HistorySettings `default:"{}" yaml:"history,omitempty" addedIn:"1.3"`
+
+	// RequireDevices specifies whether the plugin requires at least one
+	// device to be configured. If it does and no devices are configured,
+	// plugin startup will fail. This is false by default, allowing the
+	// plugin to run with no devices.
+	RequireDevices bool `default:"false" yaml:"requireDevices,omitempty" addedIn:"1.3"`
+
+	// RequireSelfTest specifies whether the plugin self-test must pass for the
+	// plugin to start (see Plugin.SelfTest). If it does and the self-test finds
+	// any problems, plugin startup will fail. This is false by default, in which
+	// case any problems are logged as a warning.
+	RequireSelfTest bool `default:"false" yaml:"requireSelfTest,omitempty" addedIn:"1.3"`
+
+	// RequireOutputTypesUsed specifies whether every registered output type must
+	// be used by at least one device for the plugin to start. This catches output
+	// types which are unused because of config errors, e.g. a misspelled type in
+	// a copy-pasted device output. This is false by default, in which case unused
+	// output types are only logged.
+	RequireOutputTypesUsed bool `default:"false" yaml:"requireOutputTypesUsed,omitempty" addedIn:"1.3"`
+
+	// Quarantine contains the settings to configure the quarantine of
+	// devices which repeatedly return bad readings.
+	Quarantine *QuarantineSettings `default:"{}" yaml:"quarantine,omitempty" addedIn:"1.3"`
+
+	// Watchdog contains the settings to configure the watchdog which alerts
+	// when devices stop producing readings.
+	Watchdog *WatchdogSettings `default:"{}" yaml:"watchdog,omitempty" addedIn:"1.3"`
+
+	// ShutdownTimeout is the maximum amount of time to wait for the writes in
+	// the per-device write queues to be fulfilled, and then for the post-run
+	// actions to complete, when the plugin is stopped. If either has not
+	// completed by then, the plugin does not wait for it. The default of 0s
+	// waits indefinitely.
+	ShutdownTimeout string `default:"0s" yaml:"shutdownTimeout,omitempty" addedIn:"1.3"`
+
+	// Stateless specifies whether the plugin retains readings. A stateless
+	// plugin keeps no current reading state, readings cache, or readings
+	// history; devices are read on demand when their readings are requested
+	// and cached reading requests return no readings. Devices are still polled,
+	// so the readings are streamed by the synse.ReadingBatches service as they
+	// are read. This takes precedence over the cache and history settings. By
+	// default, this is false.
+	Stateless bool `default:"false" yaml:"stateless,omitempty" addedIn:"1.3"`
+
+	// DeviceOrder is the order in which devices are returned by the Devices RPC.
+	// This can be one of "id" or "config". With "id", devices are sorted by their
+	// ID (rack, board, and device ID). With "config", devices are returned in the
+	// order they were registered, i.e. the order of their config, followed by any
+	// dynamically registered devices. This is "id" by default.
+	DeviceOrder string `default:"id" yaml:"deviceOrder,omitempty" addedIn:"1.3"`
+
+	// MaxReloadFailures is the number of consecutive device config reloads
+	// which can fail due to an invalid configuration before the plugin exits
+	// with an error. Otherwise, a plugin keeps its existing config when a reload
+	// fails, which can mask a broken config; exiting lets orchestration notice.
+	// A value of 0 means the plugin never exits due to failed reloads. This is
+	// 0 by default.
+	MaxReloadFailures int `default:"0" yaml:"maxReloadFailures,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the PluginSettings has no configuration errors.
@@ -417,6 +1040,39 @@ func (settings PluginSettings) Validate(multiErr *errors.MultiError) {
 			"one of: serial, parallel",
 		))
 	}
+
+	// Try parsing the shutdown timeout to validate it is a correctly specified duration string.
+	_, err := settings.GetShutdownTimeout()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	if settings.DeviceOrder != "" && settings.DeviceOrder != deviceOrderID && settings.DeviceOrder != deviceOrderConfig {
+		log.WithField("config", settings).Error("[validation] bad device order")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.deviceOrder",
+			"one of: id, config",
+		))
+	}
+
+	if settings.MaxReloadFailures < 0 {
+		log.WithField("config", settings).Error("[validation] bad max reload failures")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.maxReloadFailures",
+			"a value greater than or equal to 0",
+		))
+	}
+}
+
+// GetShutdownTimeout gets the timeout for the post-run actions to complete when
+// the plugin is stopped. An empty timeout is treated as 0s (no timeout).
+func (settings *PluginSettings) GetShutdownTimeout() (time.Duration, error) {
+	if settings.ShutdownTimeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.ShutdownTimeout)
 }
 
 // IsSerial checks if the PluginSettings is configured with mode "serial".
@@ -445,6 +1101,42 @@ type NetworkSettings struct {
 	// connection between Synse Server and the plugin. If this is not set,
 	// insecure transport will be used.
 	TLS *TLSNetworkSettings `yaml:"tls,omitempty" addedIn:"1.1"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams (RPCs)
+	// allowed for each client connection to the gRPC server. If this is 0,
+	// the gRPC default is used.
+	MaxConcurrentStreams uint32 `yaml:"maxConcurrentStreams,omitempty" addedIn:"1.3"`
+
+	// DrainTimeout is the maximum amount of time to wait for in-flight RPCs to
+	// complete when the gRPC server is stopped. While the server is draining,
+	// new RPCs are refused. If the in-flight RPCs have not completed by then,
+	// the server is stopped immediately. If this is not set, it defaults to
+	// 10s. A timeout of 0s stops the server immediately.
+	DrainTimeout string `yaml:"drainTimeout,omitempty" addedIn:"1.3"`
+
+	// BindTimeout is the maximum amount of time to keep retrying to bind the
+	// gRPC server if its address is in use, e.g. because it is still held by a
+	// previous instance of the plugin which is terminating. Attempts are
+	// retried with an exponential backoff. If this is not set, the plugin
+	// fails immediately if it can not bind.
+	BindTimeout string `yaml:"bindTimeout,omitempty" addedIn:"1.3"`
+
+	// MaxConnectionIdle is the amount of time a client connection may be idle
+	// (have no in-flight RPCs) before the server closes it. If this is not set,
+	// idle connections are not closed.
+	MaxConnectionIdle string `yaml:"maxConnectionIdle,omitempty" addedIn:"1.3"`
+
+	// MaxConnectionAge is the maximum amount of time a client connection may
+	// exist before the server closes it, so that clients behind a load balancer
+	// periodically reconnect and are rebalanced. If this is not set, connections
+	// are not closed because of their age.
+	MaxConnectionAge string `yaml:"maxConnectionAge,omitempty" addedIn:"1.3"`
+
+	// MaxConnectionAgeGrace is the amount of time in-flight RPCs are given to
+	// complete once a connection has reached its maximum age, before the
+	// connection is forcibly closed. If this is not set, in-flight RPCs are
+	// given as long as they need.
+	MaxConnectionAgeGrace string `yaml:"maxConnectionAgeGrace,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the NetworkSettings has no configuration errors.
@@ -466,6 +1158,68 @@ func (settings NetworkSettings) Validate(multiErr *errors.MultiError) {
 		log.WithField("config", settings).Error("[validation] empty address")
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "network.address"))
 	}
+
+	// Try parsing the drain timeout to validate it is a correctly specified duration string.
+	_, err := settings.GetDrainTimeout()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	// Try parsing the bind timeout to validate it is a correctly specified duration string.
+	_, err = settings.GetBindTimeout()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	// Try getting the keepalive parameters to validate the connection idle/age
+	// durations are correctly specified duration strings.
+	_, err = settings.GetKeepaliveParameters()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+}
+
+// GetDrainTimeout gets the timeout for in-flight RPCs to complete when the gRPC
+// server is stopped. An empty timeout is treated as the default of 10s.
+func (settings *NetworkSettings) GetDrainTimeout() (time.Duration, error) {
+	if settings.DrainTimeout == "" {
+		return defaultDrainTimeout, nil
+	}
+	return time.ParseDuration(settings.DrainTimeout)
+}
+
+// GetBindTimeout gets the maximum amount of time to keep retrying to bind the
+// gRPC server. An empty timeout is treated as 0s, i.e. binding is not retried.
+func (settings *NetworkSettings) GetBindTimeout() (time.Duration, error) {
+	if settings.BindTimeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.BindTimeout)
+}
+
+// GetKeepaliveParameters gets the gRPC server keepalive parameters for the
+// configured connection idle and age limits. Limits which are not set are left
+// as their zero value, which gRPC treats as no limit.
+func (settings *NetworkSettings) GetKeepaliveParameters() (keepalive.ServerParameters, error) {
+	var params keepalive.ServerParameters
+	for _, limit := range []struct {
+		value    string
+		duration *time.Duration
+	}{
+		{settings.MaxConnectionIdle, &params.MaxConnectionIdle},
+		{settings.MaxConnectionAge, &params.MaxConnectionAge},
+		{settings.MaxConnectionAgeGrace, &params.MaxConnectionAgeGrace},
+	} {
+		if limit.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(limit.value)
+		if err != nil {
+			return keepalive.ServerParameters{}, err
+		}
+		*limit.duration = d
+	}
+	return params, nil
 }
 
 // TLSNetworkSettings specifies configuration around TLS/SSL for securing the
@@ -493,11 +1247,156 @@ type DynamicRegistrationSettings struct {
 	// As an example, this could hold the information for connecting with a server,
 	// or it could contain a bus address, etc.
 	Config []map[string]interface{} `default:"[]" yaml:"config,omitempty" addedIn:"1.0"`
+
+	// Timeout is the maximum amount of time to wait for the dynamic device
+	// config registrar to complete for each dynamic registration config. If
+	// the timeout is reached, the registration is handled according to the
+	// dynamic device config policy. This is 0s (no timeout) by default.
+	Timeout string `default:"0s" yaml:"timeout,omitempty" addedIn:"1.3"`
+
+	// Collisions is the policy for dynamically registered devices whose ID
+	// collides with that of a device which is already registered. This can be
+	// one of "error" or "suffix". With "error", device registration fails. With
+	// "suffix", a numeric discriminator is appended to the ID of the colliding
+	// device, e.g. "<id>-2", so that both devices are registered. Since dynamic
+	// registration runs in order, the discriminators are deterministic. This is
+	// "error" by default.
+	Collisions string `default:"error" yaml:"collisions,omitempty" addedIn:"1.3"`
+
+	// Merge is the strategy for combining device kinds which are defined in both
+	// the device config file(s) and the dynamically registered device configs.
+	// This can be one of "merge", "dynamic-wins", or "file-wins". With "merge",
+	// the instances of the dynamic device kind are added to those of the file
+	// device kind. With "dynamic-wins" or "file-wins", only the device kind from
+	// that source is used. This is "merge" by default.
+	Merge string `default:"merge" yaml:"merge,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the DynamicRegistrationSettings has no configuration errors.
 func (settings DynamicRegistrationSettings) Validate(multiErr *errors.MultiError) {
-	// nothing to validate here.
+	// Try parsing the timeout to validate it is a correctly specified duration string.
+	_, err := settings.GetTimeout()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	if settings.Collisions != "" && settings.Collisions != collisionsError && settings.Collisions != collisionsSuffix {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"dynamicRegistration.collisions",
+			"one of: error, suffix",
+		))
+	}
+
+	switch settings.Merge {
+	case "", mergeStrategyMerge, mergeStrategyDynamicWins, mergeStrategyFileWins:
+	default:
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"dynamicRegistration.merge",
+			"one of: merge, dynamic-wins, file-wins",
+		))
+	}
+}
+
+// GetTimeout gets the timeout for dynamic device config registration. A
+// timeout of 0 means that there is no timeout.
+func (settings *DynamicRegistrationSettings) GetTimeout() (time.Duration, error) {
+	if settings.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.Timeout)
+}
+
+// MetricsSettings specifies configurations for exporting plugin metrics to an
+// OpenTelemetry collector via the OTLP/HTTP protocol.
+type MetricsSettings struct {
+	// Endpoint is the URL of the collector's OTLP/HTTP metrics endpoint,
+	// e.g. "http://localhost:4318/v1/metrics". This is required.
+	Endpoint string `yaml:"endpoint,omitempty" addedIn:"1.3"`
+
+	// Interval is the interval at which metrics are exported. If this is
+	// not set, metrics are exported every 30s.
+	Interval string `yaml:"interval,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the MetricsSettings has no configuration errors.
+func (settings MetricsSettings) Validate(multiErr *errors.MultiError) {
+	if settings.Endpoint == "" {
+		log.WithField("config", settings).Error("[validation] empty metrics endpoint")
+		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "metrics.endpoint"))
+	} else if u, err := url.Parse(settings.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		log.WithField("config", settings).Error("[validation] bad metrics endpoint")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"metrics.endpoint",
+			"URL (e.g. http://localhost:4318/v1/metrics)",
+		))
+	}
+
+	// Try parsing the interval to validate it is a correctly specified duration string.
+	_, err := settings.GetInterval()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+}
+
+// GetInterval gets the metrics export interval as a duration. If the interval
+// is not set, this defaults to 30s.
+func (settings *MetricsSettings) GetInterval() (time.Duration, error) {
+	if settings.Interval == "" {
+		return 30 * time.Second, nil
+	}
+	return time.ParseDuration(settings.Interval)
+}
+
+// SelfMonitoringSettings specifies configurations for the plugin's self-monitoring
+// devices. When enabled, the plugin registers synthetic devices whose readings are
+// its own internal counters (the number of device reads and read errors, and its
+// uptime), so they can be read like any other device.
+type SelfMonitoringSettings struct {
+	// Enabled sets whether the self-monitoring devices are registered.
+	Enabled bool `yaml:"enabled,omitempty" addedIn:"1.3"`
+
+	// Rack is the rack of the self-monitoring devices' location. If this is
+	// not set, "synse" is used.
+	Rack string `yaml:"rack,omitempty" addedIn:"1.3"`
+
+	// Board is the board of the self-monitoring devices' location. If this is
+	// not set, the plugin name is used.
+	Board string `yaml:"board,omitempty" addedIn:"1.3"`
+}
+
+// GetRack gets the rack of the self-monitoring devices' location. If the rack
+// is not set, this defaults to "synse".
+func (settings *SelfMonitoringSettings) GetRack() string {
+	if settings.Rack == "" {
+		return "synse"
+	}
+	return settings.Rack
+}
+
+// AdminSettings specifies configurations for the plugin's HTTP admin server,
+// which exposes endpoints for inspecting and controlling the plugin at runtime.
+type AdminSettings struct {
+	// Address is the address that the admin server binds to, e.g.
+	// "localhost:5002". This is required.
+	Address string `yaml:"address,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the AdminSettings has no configuration errors.
+func (settings AdminSettings) Validate(multiErr *errors.MultiError) {
+	if settings.Address == "" {
+		log.WithField("config", settings).Error("[validation] empty admin address")
+		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "admin.address"))
+	} else if _, _, err := net.SplitHostPort(settings.Address); err != nil {
+		log.WithField("config", settings).Error("[validation] bad admin address")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"admin.address",
+			"host:port (e.g. localhost:5002)",
+		))
+	}
 }
 
 // LimiterSettings specifies configurations for a rate limiter on reads
@@ -579,6 +1478,88 @@ type ReadSettings struct {
 	// SerialReadInterval specifies the interval to pause between serial reads.
 	// This is here to avoid overwhelming a device. This is 0s by default.
 	SerialReadInterval string `default:"0s" yaml:"serialReadInterval,omitempty" addedIn:"1.3"`
+
+	// EpochTimestamp specifies whether readings should include the time they
+	// were taken as nanoseconds since the Unix epoch, in addition to the RFC3339
+	// timestamp. This is added to the reading context under the "epoch" key (see
+	// Reading.Context for where the reading context is sent).
+	// This is false by default.
+	EpochTimestamp bool `default:"false" yaml:"epochTimestamp,omitempty" addedIn:"1.3"`
+
+	// InitialRead specifies whether all devices should be read once at startup,
+	// before the read loop starts, so that readings are available immediately
+	// rather than after the first read interval. This is true by default.
+	InitialRead bool `default:"true" yaml:"initialRead,omitempty" addedIn:"1.3"`
+
+	// AlignInterval specifies whether reads should be scheduled on wall-clock
+	// boundaries of the read interval (e.g. on the minute for a 1m interval),
+	// rather than relative to the time the plugin started. This allows multiple
+	// plugin replicas to read on the same ticks. This is false by default.
+	AlignInterval bool `default:"false" yaml:"alignInterval,omitempty" addedIn:"1.3"`
+
+	// Deduplicate specifies whether readings which are identical in timestamp,
+	// type, and value to a device's most recent reading should be suppressed,
+	// e.g. when a device is read on-demand and by the read loop in quick
+	// succession. Suppressed readings are not added to the readings cache or
+	// history. This is false by default.
+	Deduplicate bool `default:"false" yaml:"deduplicate,omitempty" addedIn:"1.3"`
+
+	// TypePrefix is a namespace which is prepended to the Type of every reading
+	// when it is sent to Synse Server, e.g. a prefix of "siteA" makes the type
+	// "temperature" into "siteA.temperature". Devices may override this with
+	// their own TypePrefix. By default, reading types are not prefixed.
+	TypePrefix string `yaml:"typePrefix,omitempty" addedIn:"1.3"`
+
+	// Strict specifies whether a bulk read fails if its device handler returns
+	// readings for a device which was not read, e.g. due to a bug in the handler.
+	// Otherwise, the mis-associated readings are logged and dropped, and the
+	// rest of the readings are kept. This is false by default.
+	Strict bool `default:"false" yaml:"strict,omitempty" addedIn:"1.3"`
+
+	// ErrorReadings specifies whether an error reading is emitted for each output
+	// of a device when a read of the device fails, so that gaps in its readings
+	// are visible downstream. Error readings have a nil value, and their info is
+	// the read error prefixed with "error: ", so they can be told apart from other
+	// readings without a value. The read error is also in their context under the
+	// "error" key. This is false by default.
+	ErrorReadings bool `default:"false" yaml:"errorReadings,omitempty" addedIn:"1.3"`
+
+	// BatchSize is the maximum number of readings accumulated before they are
+	// sent as a batch in the synse.ReadingBatches service's Stream RPC response
+	// stream. Sending readings in batches trades latency for throughput. A size
+	// of 0 disables batching, so each reading is sent in a batch of its own as
+	// soon as it is gathered. This is 0 by default.
+	BatchSize int `default:"0" yaml:"batchSize,omitempty" addedIn:"1.3"`
+
+	// BatchInterval is the maximum time readings are accumulated for before
+	// they are sent as a batch, even if the batch is not full. This only applies
+	// when the BatchSize is set. An interval of 0s does not limit the time. This
+	// is 0s by default.
+	BatchInterval string `default:"0s" yaml:"batchInterval,omitempty" addedIn:"1.3"`
+
+	// IdleInterval is the interval at which devices are read while no gRPC
+	// clients (e.g. Synse Server) are connected to the plugin. This can be used
+	// to reduce the resources used and hardware wear from reading devices when
+	// nothing is consuming the readings. The full read interval resumes once a
+	// client connects. By default, reads are not degraded while idle.
+	IdleInterval string `yaml:"idleInterval,omitempty" addedIn:"1.3"`
+
+	// IdlePause specifies whether reads are paused entirely while no gRPC
+	// clients are connected to the plugin. This takes precedence over the
+	// IdleInterval. This is false by default.
+	IdlePause bool `default:"false" yaml:"idlePause,omitempty" addedIn:"1.3"`
+
+	// TimestampResolution is the resolution that reading timestamps are adjusted
+	// to when readings are made, e.g. "1s" to drop sub-second precision, or the
+	// read interval to group the readings from a read cycle. By default, reading
+	// timestamps are not adjusted.
+	TimestampResolution string `yaml:"timestampResolution,omitempty" addedIn:"1.3"`
+
+	// TimestampRounding is how reading timestamps are adjusted to the timestamp
+	// resolution. This can be one of "truncate" or "round". With "truncate", the
+	// timestamp is rounded down to a multiple of the resolution. With "round", it
+	// is rounded to the nearest multiple. This is "truncate" by default.
+	TimestampRounding string `default:"truncate" yaml:"timestampRounding,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the ReadSettings has no configuration errors.
@@ -608,6 +1589,68 @@ func (settings ReadSettings) Validate(multiErr *errors.MultiError) {
 			"a value greater than 0",
 		))
 	}
+
+	if settings.TypePrefix != "" && !validNamespacedName(settings.TypePrefix) {
+		log.WithField("config", settings).Error("[validation] bad reading type prefix")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.read.typePrefix",
+			"a name or dot-separated namespace with no empty segments (e.g. siteA)",
+		))
+	}
+
+	if settings.BatchSize < 0 {
+		log.WithField("config", settings).Error("[validation] bad read batch size")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.read.batchSize",
+			"a value greater than or equal to 0",
+		))
+	}
+
+	// Try parsing the batch interval to validate it is a correctly specified duration string.
+	_, err = settings.GetBatchInterval()
+	if err != nil {
+		log.WithField("config", settings).Error("[validation] bad read batch interval")
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	// Try parsing the idle interval to validate it is a correctly specified duration string.
+	idleInterval, err := settings.GetIdleInterval()
+	if err != nil {
+		log.WithField("config", settings).Error("[validation] bad idle read interval")
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	} else if idleInterval < 0 {
+		log.WithField("config", settings).Error("[validation] bad idle read interval")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.read.idleInterval",
+			"a duration greater than or equal to 0s",
+		))
+	}
+
+	// Try parsing the timestamp resolution to validate it is a correctly specified duration string.
+	resolution, err := settings.GetTimestampResolution()
+	if err != nil {
+		log.WithField("config", settings).Error("[validation] bad reading timestamp resolution")
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	} else if resolution < 0 {
+		log.WithField("config", settings).Error("[validation] bad reading timestamp resolution")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.read.timestampResolution",
+			"a duration greater than or equal to 0s",
+		))
+	}
+
+	if settings.TimestampRounding != "" && settings.TimestampRounding != timestampTruncate && settings.TimestampRounding != timestampRound {
+		log.WithField("config", settings).Error("[validation] bad reading timestamp rounding")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.read.timestampRounding",
+			"one of: truncate, round",
+		))
+	}
 }
 
 // GetInterval gets the read interval as a duration. If the config
@@ -623,6 +1666,33 @@ func (settings *ReadSettings) GetSerialReadInterval() (time.Duration, error) {
 	return time.ParseDuration(settings.SerialReadInterval)
 }
 
+// GetBatchInterval gets the maximum time to accumulate a batch of readings for
+// as a duration. If no interval is set, this is 0.
+func (settings *ReadSettings) GetBatchInterval() (time.Duration, error) {
+	if settings.BatchInterval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.BatchInterval)
+}
+
+// GetIdleInterval gets the interval to read devices at while no gRPC clients
+// are connected as a duration. If no interval is set, this is 0.
+func (settings *ReadSettings) GetIdleInterval() (time.Duration, error) {
+	if settings.IdleInterval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.IdleInterval)
+}
+
+// GetTimestampResolution gets the resolution to adjust reading timestamps to as
+// a duration. If no resolution is set, this is 0.
+func (settings *ReadSettings) GetTimestampResolution() (time.Duration, error) {
+	if settings.TimestampResolution == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(settings.TimestampResolution)
+}
+
 // WriteSettings provides configuration options for write operations.
 type WriteSettings struct {
 	// Enabled globally enables or disables writing for the plugin.
@@ -641,6 +1711,21 @@ type WriteSettings struct {
 	// in a single batch. In general, this can tune performance when
 	// running in serial mode.
 	Max int `default:"100" yaml:"max,omitempty" addedIn:"1.0"`
+
+	// QueueSize is the size of the per-device write queues. When set, writes
+	// to each device are queued separately and fulfilled one at a time, in
+	// order, and a write is rejected if its device's queue is full. A write to
+	// a transaction group is queued for each member of the group, and rejected
+	// if any of their queues is full. When 0
+	// (the default), all writes share the write buffer and are fulfilled in
+	// batches at the write interval.
+	QueueSize int `default:"0" yaml:"queueSize,omitempty" addedIn:"1.3"`
+
+	// Workers is the maximum number of per-device write queues which may
+	// fulfill a write at the same time. Writes to a single device are still
+	// fulfilled one at a time, in order. This only applies when the QueueSize
+	// is set. When 0 (the default), the number of workers is not limited.
+	Workers int `default:"0" yaml:"workers,omitempty" addedIn:"1.3"`
 }
 
 // Validate validates that the WriteSettings has no configuration errors.
@@ -672,6 +1757,24 @@ func (settings WriteSettings) Validate(multiErr *errors.MultiError) {
 			"a value greater than 0",
 		))
 	}
+
+	if settings.QueueSize < 0 {
+		log.WithField("config", settings).Error("[validation] bad write queue size")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.write.queueSize",
+			"a value greater than or equal to 0",
+		))
+	}
+
+	if settings.Workers < 0 {
+		log.WithField("config", settings).Error("[validation] bad write workers")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"settings.write.workers",
+			"a value greater than or equal to 0",
+		))
+	}
 }
 
 // GetInterval gets the write interval as a duration. If the config
@@ -733,3 +1836,102 @@ type CacheSettings struct {
 func (settings CacheSettings) Validate(multiErr *errors.MultiError) {
 	// Nothing to validate
 }
+
+// QuarantineSettings provides configuration options for quarantining devices
+// which repeatedly return bad readings, e.g. NaN/Inf values or values outside
+// the valid range for their output type. The readings for quarantined devices
+// are not emitted until the device is released via Plugin.ResetQuarantine.
+type QuarantineSettings struct {
+	// Enabled sets whether devices with bad readings will be quarantined.
+	// By default, this is not enabled.
+	Enabled bool `default:"false" yaml:"enabled,omitempty" addedIn:"1.3"`
+
+	// Threshold is the number of consecutive bad reads after which a
+	// device is quarantined.
+	Threshold int `default:"5" yaml:"threshold,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the QuarantineSettings has no configuration errors.
+func (settings QuarantineSettings) Validate(multiErr *errors.MultiError) {
+	if settings.Enabled && settings.Threshold <= 0 {
+		log.WithField("config", settings).Error("[validation] bad quarantine threshold")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"quarantine.threshold",
+			"greater than 0",
+		))
+	}
+}
+
+// WatchdogSettings provides configuration options for the device staleness
+// watchdog. The watchdog alerts, via the plugin health, when a device produces no
+// readings within the timeout, e.g. because its handler returns no readings
+// without an error.
+type WatchdogSettings struct {
+	// Enabled sets whether devices are watched for staleness. By default,
+	// this is not enabled.
+	Enabled bool `default:"false" yaml:"enabled,omitempty" addedIn:"1.3"`
+
+	// Timeout is the time after which a device which has produced no readings
+	// is stale. This is 5m by default.
+	Timeout string `default:"5m" yaml:"timeout,omitempty" addedIn:"1.3"`
+
+	// StaleReadings sets whether a stale-marker reading is emitted for each
+	// output of a device when it becomes stale. Stale-marker readings are error
+	// readings (see ReadSettings.ErrorReadings) for a "no readings within" error,
+	// which are also marked as stale in their context under the "stale" key. This
+	// is false by default.
+	StaleReadings bool `default:"false" yaml:"staleReadings,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the WatchdogSettings has no configuration errors.
+func (settings WatchdogSettings) Validate(multiErr *errors.MultiError) {
+	if !settings.Enabled {
+		return
+	}
+	timeout, err := settings.GetTimeout()
+	if err != nil {
+		log.WithField("config", settings).Error("[validation] bad watchdog timeout")
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	} else if timeout <= 0 {
+		log.WithField("config", settings).Error("[validation] bad watchdog timeout")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"watchdog.timeout",
+			"a duration greater than 0s",
+		))
+	}
+}
+
+// GetTimeout gets the watchdog timeout as a duration. If the config has
+// been validated successfully, this should never return an error.
+func (settings *WatchdogSettings) GetTimeout() (time.Duration, error) {
+	return time.ParseDuration(settings.Timeout)
+}
+
+// HistorySettings provides configuration options for an in-memory rolling
+// history of device readings. The history is independent of the readings
+// cache and is intended for debugging and live troubleshooting. The history
+// of a device can be read via the synse.ReadingHistory gRPC service.
+type HistorySettings struct {
+	// Enabled sets whether the plugin will keep a history of the most
+	// recent readings for each device. By default, this is not enabled.
+	Enabled bool `default:"false" yaml:"enabled,omitempty" addedIn:"1.3"`
+
+	// Size is the number of readings to retain per device. Once this
+	// number is reached, the oldest reading is evicted as new readings
+	// are added.
+	Size int `default:"10" yaml:"size,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the HistorySettings has no configuration errors.
+func (settings HistorySettings) Validate(multiErr *errors.MultiError) {
+	if settings.Size <= 0 {
+		log.WithField("config", settings).Error("[validation] bad history size")
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"history.size",
+			"greater than 0",
+		))
+	}
+}
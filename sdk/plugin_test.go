@@ -1,10 +1,21 @@
 package sdk
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"os"
 	"testing"
+	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
+	"github.com/vapor-ware/synse-sdk/sdk/policies"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 // TestNewPlugin tests creating a new plugin.
@@ -90,6 +101,132 @@ func TestPlugin_RegisterOutputTypesError(t *testing.T) {
 	assert.True(t, len(ctx.outputTypes) > 0)
 }
 
+// TestPlugin_RegisterOutputTypeWithConversion tests registering an output type
+// along with its conversion.
+func TestPlugin_RegisterOutputTypeWithConversion(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	outputType := &OutputType{Name: "voltage", Conversion: "countsToVolts"}
+	err := plugin.RegisterOutputTypeWithConversion(outputType, func(f float64) float64 { return f * 5 / 1024 })
+	assert.NoError(t, err)
+	assert.Equal(t, outputType, ctx.outputTypes["voltage"])
+	assert.Contains(t, ctx.conversions, "countsToVolts")
+	assert.Equal(t, 2.5, outputType.Apply(512))
+
+	// Output types can also be registered with a conversion that is already registered.
+	other := &OutputType{Name: "voltage.scaled", Conversions: []string{"countsToVolts"}}
+	err = plugin.RegisterOutputTypeWithConversion(other, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, other, ctx.outputTypes["voltage.scaled"])
+}
+
+// TestPlugin_RegisterOutputTypeWithConversion_Error tests registering an output type
+// along with its conversion when there are errors. Nothing should be registered.
+func TestPlugin_RegisterOutputTypeWithConversion_Error(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	double := func(f float64) float64 { return f * 2 }
+	ctx.outputTypes["existing"] = &OutputType{Name: "existing"}
+
+	var testTable = []struct {
+		desc       string
+		outputType *OutputType
+		conversion Conversion
+	}{
+		{"nil output type", nil, double},
+		{"dangling conversion reference", &OutputType{Name: "foo", Conversion: "double"}, nil},
+		{"conversion not named", &OutputType{Name: "foo"}, double},
+		{"conversion already exists", &OutputType{Name: "foo", Conversion: "englishToMetricTemperature"}, double},
+		{"output type already exists", &OutputType{Name: "existing", Conversion: "double"}, double},
+		{"invalid output type", &OutputType{Name: "foo", Conversion: "double", Scale: "x"}, double},
+	}
+
+	for _, testCase := range testTable {
+		err := plugin.RegisterOutputTypeWithConversion(testCase.outputType, testCase.conversion)
+		assert.Error(t, err, testCase.desc)
+		assert.Equal(t, 1, len(ctx.outputTypes), testCase.desc)
+		assert.NotContains(t, ctx.conversions, "double", testCase.desc)
+	}
+}
+
+// TestSetOutputTypes tests setting the output types used by the SDK.
+func TestSetOutputTypes(t *testing.T) {
+	defer resetContext()
+
+	ctx.outputTypes["old"] = &OutputType{Name: "old"}
+
+	err := SetOutputTypes([]*OutputType{
+		{Name: "foo", Precision: 2},
+		{Name: "bar", Unit: Unit{Name: "celsius", Symbol: "C"}},
+		{Name: "foo", Precision: 2}, // duplicate: only added once
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ctx.outputTypes))
+
+	// The output types replace the existing output types.
+	_, err = GetTypeByName("old")
+	assert.Error(t, err)
+
+	foo, err := GetTypeByName("foo")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, foo.Precision)
+
+	// The output types are used when verifying device configs.
+	cfg := &DeviceConfig{
+		Devices: []*DeviceKind{
+			{
+				Name:    "test",
+				Outputs: []*DeviceOutput{{Type: "bar"}},
+				Instances: []*DeviceInstance{
+					{Outputs: []*DeviceOutput{{Type: "foo"}}},
+				},
+			},
+		},
+	}
+	multiErr := errors.NewMultiError("test")
+	verifyDeviceConfigOutputs(cfg, multiErr)
+	assert.NoError(t, multiErr.Err())
+}
+
+// TestSetOutputTypesError tests setting invalid output types, which should leave
+// the existing output types unchanged.
+func TestSetOutputTypesError(t *testing.T) {
+	defer resetContext()
+
+	ctx.outputTypes["old"] = &OutputType{Name: "old"}
+
+	var testTable = []struct {
+		desc  string
+		types []*OutputType
+	}{
+		{
+			desc:  "output type is nil",
+			types: []*OutputType{{Name: "foo"}, nil},
+		},
+		{
+			desc:  "output type fails validation",
+			types: []*OutputType{{Name: "foo", ScalingFactor: "abc"}},
+		},
+		{
+			desc:  "output type has no name",
+			types: []*OutputType{{}},
+		},
+		{
+			desc:  "conflicting output types with the same name",
+			types: []*OutputType{{Name: "foo", Precision: 1}, {Name: "foo", Precision: 2}},
+		},
+	}
+
+	for _, testCase := range testTable {
+		err := SetOutputTypes(testCase.types)
+		assert.Error(t, err, testCase.desc)
+		assert.Equal(t, 1, len(ctx.outputTypes), testCase.desc)
+		assert.Contains(t, ctx.outputTypes, "old", testCase.desc)
+	}
+}
+
 // TestPlugin_RegisterPreRunActions tests registering pre-run actions.
 func TestPlugin_RegisterPreRunActions(t *testing.T) {
 	defer resetContext()
@@ -124,6 +261,105 @@ func TestPlugin_RegisterPostRunActions(t *testing.T) {
 	assert.Equal(t, 3, len(ctx.postRunActions))
 }
 
+// TestPlugin_serveBindError tests that the post-run actions are executed and a
+// bind error is returned when the gRPC server fails to bind to its address.
+func TestPlugin_serveBindError(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{},
+	}
+
+	// Bind to the address first, so the server can not bind to it.
+	lis, err := net.Listen(networkTypeTCP, "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	var cleanedUp bool
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(func(_ *Plugin) error {
+		cleanedUp = true
+		return nil
+	})
+	plugin.server = newServer(networkTypeTCP, lis.Addr().String())
+
+	err = plugin.serve()
+	assert.Error(t, err)
+	assert.IsType(t, &errors.ServerBindError{}, err)
+	assert.Equal(t, lis.Addr().String(), err.(*errors.ServerBindError).Address)
+	assert.True(t, cleanedUp)
+}
+
+// TestPlugin_shutdown tests shutting down the plugin when the post-run actions complete.
+func TestPlugin_shutdown(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{ShutdownTimeout: "1s"},
+	}
+
+	var cleanedUp bool
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(func(_ *Plugin) error {
+		cleanedUp = true
+		return nil
+	})
+	plugin.server = newServer(networkTypeTCP, "localhost:5001")
+
+	assert.Equal(t, 0, plugin.shutdown(os.Interrupt))
+	assert.True(t, cleanedUp)
+}
+
+// TestPlugin_shutdownTimeout tests that shutting down the plugin does not wait for
+// a blocking post-run action past the configured shutdown timeout.
+func TestPlugin_shutdownTimeout(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{ShutdownTimeout: "50ms"},
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+
+	plugin := NewPlugin()
+	plugin.RegisterPostRunActions(func(_ *Plugin) error {
+		<-block
+		return nil
+	})
+	plugin.server = newServer(networkTypeTCP, "localhost:5001")
+
+	start := time.Now()
+	assert.Equal(t, 1, plugin.shutdown(os.Interrupt))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+// TestPluginSettings_GetShutdownTimeout tests getting the shutdown timeout.
+func TestPluginSettings_GetShutdownTimeout(t *testing.T) {
+	settings := PluginSettings{}
+	timeout, err := settings.GetShutdownTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	settings.ShutdownTimeout = "30s"
+	timeout, err = settings.GetShutdownTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	settings.ShutdownTimeout = "foo"
+	_, err = settings.GetShutdownTimeout()
+	assert.Error(t, err)
+}
+
 // TestPlugin_RegisterDeviceSetupActions tests registering device setup actions.
 func TestPlugin_RegisterDeviceSetupActions(t *testing.T) {
 	defer resetContext()
@@ -170,6 +406,38 @@ func TestPlugin_RegisterDeviceHandlers(t *testing.T) {
 	assert.Equal(t, 2, len(ctx.deviceHandlers))
 }
 
+// TestPlugin_ListHandlers tests listing the registered device handlers.
+func TestPlugin_ListHandlers(t *testing.T) {
+	defer resetContext()
+
+	readHandler := &DeviceHandler{
+		Name:  "foo",
+		Read:  func(_ *Device) ([]*Reading, error) { return nil, nil },
+		Write: func(_ *Device, _ *WriteData) error { return nil },
+	}
+	bulkHandler := &DeviceHandler{
+		Name:     "bar",
+		BulkRead: func(_ []*Device) ([]*ReadContext, error) { return nil, nil },
+		Listen:   func(_ *Device, _ chan *ReadContext) error { return nil },
+	}
+	unusedHandler := &DeviceHandler{Name: "baz"}
+
+	plugin := NewPlugin()
+	assert.Empty(t, plugin.ListHandlers())
+
+	plugin.RegisterDeviceHandlers(readHandler, bulkHandler, unusedHandler)
+	ctx.devices["1"] = &Device{Handler: readHandler}
+	ctx.devices["2"] = &Device{Handler: readHandler}
+	ctx.devices["3"] = &Device{Handler: bulkHandler}
+
+	handlers := plugin.ListHandlers()
+	assert.Equal(t, []*HandlerInfo{
+		{Name: "foo", Read: true, Write: true, Devices: 2},
+		{Name: "bar", BulkRead: true, Listen: true, Devices: 1},
+		{Name: "baz"},
+	}, handlers)
+}
+
 // TestNewDefaultPluginConfig tests getting a default plugin config.
 func TestNewDefaultPluginConfig(t *testing.T) {
 	cfg, err := NewDefaultPluginConfig()
@@ -249,6 +517,18 @@ func TestPluginConfig_Validate_Error(t *testing.T) {
 				SchemeVersion: SchemeVersion{Version: "1.0"},
 			},
 		},
+		{
+			desc:     "PluginConfig has a negative atmospheric pressure",
+			errCount: 1,
+			config: PluginConfig{
+				SchemeVersion: SchemeVersion{Version: "1.0"},
+				Network: &NetworkSettings{
+					Type:    "tcp",
+					Address: "10.10.10.10",
+				},
+				AtmosphericPressure: -1,
+			},
+		},
 		{
 			desc:     "PluginConfig is empty",
 			errCount: 2,
@@ -289,6 +569,13 @@ func TestPluginSettings_Validate_Ok(t *testing.T) {
 				Transaction: &TransactionSettings{},
 			},
 		},
+		{
+			desc: "PluginSettings has valid device order",
+			config: PluginSettings{
+				Mode:        "serial",
+				DeviceOrder: "config",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -321,6 +608,30 @@ func TestPluginSettings_Validate_Error(t *testing.T) {
 				Transaction: &TransactionSettings{},
 			},
 		},
+		{
+			desc:     "PluginSettings has invalid shutdown timeout",
+			errCount: 1,
+			config: PluginSettings{
+				Mode:            "serial",
+				ShutdownTimeout: "foo",
+			},
+		},
+		{
+			desc:     "PluginSettings has invalid device order",
+			errCount: 1,
+			config: PluginSettings{
+				Mode:        "serial",
+				DeviceOrder: "name",
+			},
+		},
+		{
+			desc:     "PluginSettings has invalid max reload failures",
+			errCount: 1,
+			config: PluginSettings{
+				Mode:              "serial",
+				MaxReloadFailures: -1,
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -380,6 +691,64 @@ func TestNetworkSettings_Validate_Ok(t *testing.T) {
 	}
 }
 
+// TestNetworkSettings_GetDrainTimeout tests getting the drain timeout for the
+// gRPC server.
+func TestNetworkSettings_GetDrainTimeout(t *testing.T) {
+	settings := NetworkSettings{}
+	timeout, err := settings.GetDrainTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, timeout)
+
+	settings.DrainTimeout = "0s"
+	timeout, err = settings.GetDrainTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	settings.DrainTimeout = "foo"
+	_, err = settings.GetDrainTimeout()
+	assert.Error(t, err)
+}
+
+// TestNetworkSettings_GetBindTimeout tests getting the bind timeout for the
+// gRPC server.
+func TestNetworkSettings_GetBindTimeout(t *testing.T) {
+	settings := NetworkSettings{}
+	timeout, err := settings.GetBindTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), timeout)
+
+	settings.BindTimeout = "30s"
+	timeout, err = settings.GetBindTimeout()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	settings.BindTimeout = "foo"
+	_, err = settings.GetBindTimeout()
+	assert.Error(t, err)
+}
+
+// TestNetworkSettings_GetKeepaliveParameters tests getting the keepalive parameters
+// for the gRPC server.
+func TestNetworkSettings_GetKeepaliveParameters(t *testing.T) {
+	settings := NetworkSettings{}
+	params, err := settings.GetKeepaliveParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, keepalive.ServerParameters{}, params)
+
+	settings.MaxConnectionIdle = "1m"
+	settings.MaxConnectionAge = "10m"
+	settings.MaxConnectionAgeGrace = "5s"
+	params, err = settings.GetKeepaliveParameters()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, params.MaxConnectionIdle)
+	assert.Equal(t, 10*time.Minute, params.MaxConnectionAge)
+	assert.Equal(t, 5*time.Second, params.MaxConnectionAgeGrace)
+
+	settings.MaxConnectionIdle = "foo"
+	_, err = settings.GetKeepaliveParameters()
+	assert.Error(t, err)
+}
+
 // TestNetworkSettings_Validate_Error tests validating a NetworkSettings with errors.
 func TestNetworkSettings_Validate_Error(t *testing.T) {
 	var testTable = []struct {
@@ -414,6 +783,33 @@ func TestNetworkSettings_Validate_Error(t *testing.T) {
 				Address: "foo",
 			},
 		},
+		{
+			desc:     "NetworkSettings has invalid drain timeout",
+			errCount: 1,
+			config: NetworkSettings{
+				Type:         "tcp",
+				Address:      "foo",
+				DrainTimeout: "foo",
+			},
+		},
+		{
+			desc:     "NetworkSettings has invalid bind timeout",
+			errCount: 1,
+			config: NetworkSettings{
+				Type:        "tcp",
+				Address:     "foo",
+				BindTimeout: "foo",
+			},
+		},
+		{
+			desc:     "NetworkSettings has invalid max connection age",
+			errCount: 1,
+			config: NetworkSettings{
+				Type:             "tcp",
+				Address:          "foo",
+				MaxConnectionAge: "foo",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -530,6 +926,35 @@ func TestReadSettings_Validate_Ok(t *testing.T) {
 				SerialReadInterval: "0s",
 			},
 		},
+		{
+			desc: "ReadSettings has valid batch size and batch interval",
+			config: ReadSettings{
+				Interval:           "5s",
+				Buffer:             100,
+				SerialReadInterval: "0s",
+				BatchSize:          10,
+				BatchInterval:      "50ms",
+			},
+		},
+		{
+			desc: "ReadSettings has valid idle interval",
+			config: ReadSettings{
+				Interval:           "5s",
+				Buffer:             100,
+				SerialReadInterval: "0s",
+				IdleInterval:       "1m",
+			},
+		},
+		{
+			desc: "ReadSettings has valid timestamp resolution and rounding",
+			config: ReadSettings{
+				Interval:            "5s",
+				Buffer:              100,
+				SerialReadInterval:  "0s",
+				TimestampResolution: "1s",
+				TimestampRounding:   "round",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -574,6 +999,58 @@ func TestReadSettings_Validate_Error(t *testing.T) {
 				SerialReadInterval: "1s",
 			},
 		},
+		{
+			desc:     "ReadSettings has invalid batch size and batch interval",
+			errCount: 2,
+			config: ReadSettings{
+				Interval:           "1s",
+				Buffer:             100,
+				SerialReadInterval: "1s",
+				BatchSize:          -1,
+				BatchInterval:      "soon",
+			},
+		},
+		{
+			desc:     "ReadSettings has invalid idle interval",
+			errCount: 1,
+			config: ReadSettings{
+				Interval:           "1s",
+				Buffer:             100,
+				SerialReadInterval: "1s",
+				IdleInterval:       "later",
+			},
+		},
+		{
+			desc:     "ReadSettings has negative idle interval",
+			errCount: 1,
+			config: ReadSettings{
+				Interval:           "1s",
+				Buffer:             100,
+				SerialReadInterval: "1s",
+				IdleInterval:       "-1m",
+			},
+		},
+		{
+			desc:     "ReadSettings has invalid timestamp resolution and rounding",
+			errCount: 2,
+			config: ReadSettings{
+				Interval:            "1s",
+				Buffer:              100,
+				SerialReadInterval:  "1s",
+				TimestampResolution: "second",
+				TimestampRounding:   "floor",
+			},
+		},
+		{
+			desc:     "ReadSettings has negative timestamp resolution",
+			errCount: 1,
+			config: ReadSettings{
+				Interval:            "1s",
+				Buffer:              100,
+				SerialReadInterval:  "1s",
+				TimestampResolution: "-1s",
+			},
+		},
 		{
 			desc:     "ReadSettings has invalid interval and invalid buffer size",
 			errCount: 2,
@@ -613,6 +1090,16 @@ func TestWriteSettings_Validate_Ok(t *testing.T) {
 				Max:      100,
 			},
 		},
+		{
+			desc: "WriteSettings has valid queue size and workers",
+			config: WriteSettings{
+				Interval:  "5s",
+				Buffer:    100,
+				Max:       100,
+				QueueSize: 10,
+				Workers:   4,
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -657,6 +1144,27 @@ func TestWriteSettings_Validate_Error(t *testing.T) {
 				Max:      0,
 			},
 		},
+		{
+			desc:     "WriteSettings has invalid queue size",
+			errCount: 1,
+			config: WriteSettings{
+				Interval:  "5s",
+				Buffer:    100,
+				Max:       100,
+				QueueSize: -1,
+			},
+		},
+		{
+			desc:     "WriteSettings has invalid workers",
+			errCount: 1,
+			config: WriteSettings{
+				Interval:  "5s",
+				Buffer:    100,
+				Max:       100,
+				QueueSize: 10,
+				Workers:   -1,
+			},
+		},
 		{
 			desc:     "WriteSettings has invalid interval, buffer, and max",
 			errCount: 3,
@@ -743,6 +1251,50 @@ func TestDynamicRegistrationSettings_Validate(t *testing.T) {
 	assert.NoError(t, merr.Err())
 }
 
+// TestDynamicRegistrationSettings_Validate_Error tests validating a DynamicRegistrationSettings
+// with an invalid timeout.
+func TestDynamicRegistrationSettings_Validate_Error(t *testing.T) {
+	merr := errors.NewMultiError("test")
+	config := DynamicRegistrationSettings{Timeout: "xyz"}
+	config.Validate(merr)
+	assert.Error(t, merr.Err())
+	assert.Equal(t, 1, len(merr.Errors))
+}
+
+// TestDynamicRegistrationSettings_Validate_Collisions tests validating the collision
+// policy of a DynamicRegistrationSettings.
+func TestDynamicRegistrationSettings_Validate_Collisions(t *testing.T) {
+	for _, policy := range []string{"", "error", "suffix"} {
+		merr := errors.NewMultiError("test")
+		config := DynamicRegistrationSettings{Collisions: policy}
+		config.Validate(merr)
+		assert.NoError(t, merr.Err(), policy)
+	}
+
+	merr := errors.NewMultiError("test")
+	config := DynamicRegistrationSettings{Collisions: "ignore"}
+	config.Validate(merr)
+	assert.Error(t, merr.Err())
+	assert.Equal(t, 1, len(merr.Errors))
+}
+
+// TestDynamicRegistrationSettings_Validate_Merge tests validating the device config
+// merge strategy of a DynamicRegistrationSettings.
+func TestDynamicRegistrationSettings_Validate_Merge(t *testing.T) {
+	for _, strategy := range []string{"", "merge", "dynamic-wins", "file-wins"} {
+		merr := errors.NewMultiError("test")
+		config := DynamicRegistrationSettings{Merge: strategy}
+		config.Validate(merr)
+		assert.NoError(t, merr.Err(), strategy)
+	}
+
+	merr := errors.NewMultiError("test")
+	config := DynamicRegistrationSettings{Merge: "replace"}
+	config.Validate(merr)
+	assert.Error(t, merr.Err())
+	assert.Equal(t, 1, len(merr.Errors))
+}
+
 // TestHealthSettings_Validate tests validating a HealthSettings. Validation should always pass.
 func TestHealthSettings_Validate(t *testing.T) {
 	merr := errors.NewMultiError("test")
@@ -844,3 +1396,349 @@ func TestListenSettings_Validate_Error(t *testing.T) {
 		assert.Equal(t, testCase.errCount, len(merr.Errors), merr.Error())
 	}
 }
+
+// reloadDeviceConfig is a device config template used for testing device reloads.
+const reloadDeviceConfig = `
+version: 1.0
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: test
+    instances:
+      - info: device 1
+        location: r1b1
+        data:
+          id: 1
+          value: %s
+      - info: device 2
+        location: r1b1
+        data:
+          id: 2
+          value: %s
+`
+
+// setupReloadDeviceTest is a test helper which sets up the plugin with the
+// devices from the reloadDeviceConfig and returns the path to the device config.
+func setupReloadDeviceTest(t *testing.T) string {
+	test.SetupTestDir(t)
+	path := test.WriteTempFile(t, "devices.yml", fmt.Sprintf(reloadDeviceConfig, "a", "b"), os.ModePerm)
+	test.SetEnv(t, EnvDeviceConfig, path)
+
+	Config.Plugin = &PluginConfig{
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config: []map[string]interface{}{},
+		},
+	}
+	policies.Add(policies.DeviceConfigFileRequired)
+	policies.Add(policies.DeviceConfigDynamicOptional)
+
+	// Identify devices only by their "id", so changes to other data do not
+	// change the device ID.
+	ctx.deviceIdentifier = func(data map[string]interface{}) string {
+		return fmt.Sprint(data["id"])
+	}
+	ctx.deviceHandlers = []*DeviceHandler{{Name: "test"}}
+
+	err := processDeviceConfigs()
+	assert.NoError(t, err)
+	err = registerDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ctx.devices))
+	return path
+}
+
+// getDeviceByInfo is a test helper to get a registered device by its info.
+func getDeviceByInfo(t *testing.T, info string) *Device {
+	for _, d := range ctx.devices {
+		if d.Info == info {
+			return d
+		}
+	}
+	t.Fatalf("no device found with info: %s", info)
+	return nil
+}
+
+// TestPlugin_ReloadDevice tests reloading the config for a single device.
+func TestPlugin_ReloadDevice(t *testing.T) {
+	defer func() {
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	path := setupReloadDeviceTest(t)
+
+	device1 := getDeviceByInfo(t, "device 1")
+	device2 := getDeviceByInfo(t, "device 2")
+
+	// Change the config data for both devices, but only reload the first.
+	test.WriteTempFile(t, "devices.yml", fmt.Sprintf(reloadDeviceConfig, "changed", "changed"), os.ModePerm)
+	assert.FileExists(t, path)
+
+	// The moving averages of the reloaded device are not kept.
+	DataManager = newDataManager()
+	defer func() { DataManager = newDataManager() }()
+	DataManager.averages[device1.GUID()+"/test"] = 1
+	DataManager.averages[device2.GUID()+"/test"] = 2
+
+	plugin := NewPlugin()
+	err := plugin.ReloadDevice(device1.GUID())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{device2.GUID() + "/test": 2}, DataManager.averages)
+
+	assert.Equal(t, 2, len(ctx.devices))
+	reloaded := ctx.devices[device1.GUID()]
+	assert.NotEqual(t, device1, reloaded)
+	assert.Equal(t, "changed", reloaded.Data["value"])
+	assert.Equal(t, "a", device1.Data["value"])
+
+	assert.Equal(t, device2, ctx.devices[device2.GUID()])
+	assert.Equal(t, "b", ctx.devices[device2.GUID()].Data["value"])
+
+	// The reloaded device keeps its place in the device order.
+	assert.Equal(t, device1.order, reloaded.order)
+
+	// The device config describes the running devices: only the reloaded
+	// device's instance is changed.
+	assert.Equal(t, 1, len(Config.Device.Devices))
+	instances := Config.Device.Devices[0].Instances
+	assert.Equal(t, 2, len(instances))
+	assert.Equal(t, "changed", instances[0].Data["value"])
+	assert.Equal(t, "b", instances[1].Data["value"])
+}
+
+// TestPlugin_ReloadDevice2 tests reloading the config for a device which does
+// not exist.
+func TestPlugin_ReloadDevice2(t *testing.T) {
+	defer func() {
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+
+	plugin := NewPlugin()
+	err := plugin.ReloadDevice("rack-1-board-1-unknown")
+	assert.Error(t, err)
+}
+
+// TestPlugin_ReloadDevice3 tests reloading the config for a device when the
+// reloaded config is invalid. The existing device should be kept.
+func TestPlugin_ReloadDevice3(t *testing.T) {
+	defer func() {
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+
+	device1 := getDeviceByInfo(t, "device 1")
+	test.WriteTempFile(t, "devices.yml", "version: 1.0\ndevices: [{name: test, instances: [{info: no location}]}]", os.ModePerm)
+
+	plugin := NewPlugin()
+	err := plugin.ReloadDevice(device1.GUID())
+	assert.Error(t, err)
+	assert.Equal(t, device1, ctx.devices[device1.GUID()])
+}
+
+// TestPlugin_ReloadDevice_SetupActions tests that the device setup actions are run
+// for a reloaded device. If an action fails, the existing device should be kept.
+func TestPlugin_ReloadDevice_SetupActions(t *testing.T) {
+	defer func() {
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+
+	plugin := NewPlugin()
+	var setup []string
+	var setupErr error
+	plugin.RegisterDeviceSetupActions("kind=test", func(p *Plugin, d *Device) error {
+		assert.Equal(t, plugin, p)
+		setup = append(setup, fmt.Sprint(d.Data["value"]))
+		return setupErr
+	})
+
+	device1 := getDeviceByInfo(t, "device 1")
+	test.WriteTempFile(t, "devices.yml", fmt.Sprintf(reloadDeviceConfig, "changed", "changed"), os.ModePerm)
+
+	setupErr = fmt.Errorf("setup failed")
+	err := plugin.ReloadDevice(device1.GUID())
+	assert.Error(t, err)
+	assert.Equal(t, device1, ctx.devices[device1.GUID()])
+	assert.Equal(t, []string{"changed"}, setup)
+
+	setupErr = nil
+	err = plugin.ReloadDevice(device1.GUID())
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", ctx.devices[device1.GUID()].Data["value"])
+	assert.Equal(t, []string{"changed", "changed"}, setup)
+}
+
+// TestServer_ReloadDevice tests the ReloadDevice RPC of the synse.DeviceInventory
+// service over a running gRPC server.
+func TestServer_ReloadDevice(t *testing.T) {
+	defer func() {
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+	device1 := getDeviceByInfo(t, "device 1")
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+	Config.Plugin.Network = &NetworkSettings{Type: "tcp", Address: address}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	test.WriteTempFile(t, "devices.yml", fmt.Sprintf(reloadDeviceConfig, "changed", "changed"), os.ModePerm)
+	filter := &synse.DeviceFilter{
+		Rack:   device1.Location.Rack,
+		Board:  device1.Location.Board,
+		Device: device1.ID(),
+	}
+	resp := &synse.Status{}
+	err = conn.Invoke(context.Background(), "/synse.DeviceInventory/ReloadDevice", filter, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	assert.True(t, resp.Ok)
+	assert.Equal(t, "changed", ctx.getDevice(device1.GUID()).Data["value"])
+
+	// Unknown devices are not found.
+	filter.Device = "unknown"
+	err = conn.Invoke(context.Background(), "/synse.DeviceInventory/ReloadDevice", filter, &synse.Status{}, grpc.FailFast(false))
+	assert.Error(t, err)
+}
+
+// TestPlugin_ReloadDevice_MaxFailures tests that the plugin exits with an error
+// after the configured number of consecutive failed reloads.
+func TestPlugin_ReloadDevice_MaxFailures(t *testing.T) {
+	var exitCode *int
+	log.StandardLogger().ExitFunc = func(code int) {
+		exitCode = &code
+	}
+	defer func() {
+		log.StandardLogger().ExitFunc = os.Exit
+		reloadFailures = 0
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+	Config.Plugin.Settings = &PluginSettings{MaxReloadFailures: 3}
+	reloadFailures = 0
+
+	device1 := getDeviceByInfo(t, "device 1")
+	invalid := "version: 1.0\ndevices: [{name: test, instances: [{info: no location}]}]"
+	plugin := NewPlugin()
+
+	// Failed reloads below the threshold keep the existing config.
+	test.WriteTempFile(t, "devices.yml", invalid, os.ModePerm)
+	for i := 0; i < 2; i++ {
+		assert.Error(t, plugin.ReloadDevice(device1.GUID()))
+		assert.Nil(t, exitCode)
+	}
+
+	// A successful reload resets the count of consecutive failures.
+	test.WriteTempFile(t, "devices.yml", fmt.Sprintf(reloadDeviceConfig, "a", "b"), os.ModePerm)
+	assert.NoError(t, plugin.ReloadDevice(device1.GUID()))
+	assert.Equal(t, int32(0), reloadFailures)
+
+	test.WriteTempFile(t, "devices.yml", invalid, os.ModePerm)
+	for i := 0; i < 2; i++ {
+		assert.Error(t, plugin.ReloadDevice(device1.GUID()))
+		assert.Nil(t, exitCode)
+	}
+
+	// The failure which reaches the threshold escalates.
+	assert.Error(t, plugin.ReloadDevice(device1.GUID()))
+	if assert.NotNil(t, exitCode) {
+		assert.Equal(t, 1, *exitCode)
+	}
+}
+
+// TestPlugin_ReloadDevice_NoMaxFailures tests that the plugin does not exit due
+// to failed reloads if no maximum is configured.
+func TestPlugin_ReloadDevice_NoMaxFailures(t *testing.T) {
+	exited := false
+	log.StandardLogger().ExitFunc = func(int) {
+		exited = true
+	}
+	defer func() {
+		log.StandardLogger().ExitFunc = os.Exit
+		reloadFailures = 0
+		test.RemoveEnv(t, EnvDeviceConfig)
+		test.ClearTestDir(t)
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	setupReloadDeviceTest(t)
+	reloadFailures = 0
+
+	device1 := getDeviceByInfo(t, "device 1")
+	test.WriteTempFile(t, "devices.yml", "version: 1.0\ndevices: [{name: test, instances: [{info: no location}]}]", os.ModePerm)
+
+	plugin := NewPlugin()
+	for i := 0; i < 10; i++ {
+		assert.Error(t, plugin.ReloadDevice(device1.GUID()))
+	}
+	assert.False(t, exited)
+	assert.Equal(t, int32(10), reloadFailures)
+}
+
+// Test_resolveInstanceID tests resolving the plugin instance ID from the plugin config.
+func Test_resolveInstanceID(t *testing.T) {
+	defer Config.reset()
+	test.SetEnv(t, EnvPluginInstanceID, "from-env")
+	defer test.RemoveEnv(t, EnvPluginInstanceID)
+
+	Config.Plugin = &PluginConfig{InstanceID: "from-config"}
+	assert.Equal(t, "from-config", resolveInstanceID())
+}
+
+// Test_resolveInstanceID2 tests resolving the plugin instance ID from the environment.
+func Test_resolveInstanceID2(t *testing.T) {
+	defer Config.reset()
+	test.SetEnv(t, EnvPluginInstanceID, "from-env")
+	defer test.RemoveEnv(t, EnvPluginInstanceID)
+
+	Config.Plugin = &PluginConfig{}
+	assert.Equal(t, "from-env", resolveInstanceID())
+}
+
+// Test_resolveInstanceID3 tests resolving the plugin instance ID from the hostname
+// when it is not otherwise specified.
+func Test_resolveInstanceID3(t *testing.T) {
+	defer Config.reset()
+
+	hostname, err := os.Hostname()
+	assert.NoError(t, err)
+
+	Config.Plugin = &PluginConfig{}
+	assert.Equal(t, hostname, resolveInstanceID())
+}
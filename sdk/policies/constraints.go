@@ -14,6 +14,7 @@ var constraints = []constraint{
 	oneOrNoneOf(DeviceConfigFileOptional, DeviceConfigFileRequired, DeviceConfigFileProhibited),
 	oneOrNoneOf(DeviceConfigDynamicOptional, DeviceConfigDynamicRequired, DeviceConfigDynamicProhibited),
 	oneOrNoneOf(TypeConfigFileOptional, TypeConfigFileRequired, TypeConfigFileProhibited),
+	oneOrNoneOf(TypeConfigDuplicateError, TypeConfigDuplicateLastWins, TypeConfigDuplicateMerge),
 }
 
 // checkConstraints checks the given slice of ConfigPolicies for constraint
@@ -115,6 +115,11 @@ func TestCheckConstraints(t *testing.T) {
 			policies: []ConfigPolicy{TypeConfigFileProhibited, TypeConfigFileOptional, PluginConfigFileOptional},
 			errCount: 1,
 		},
+		{
+			desc:     "conflicting duplicate TypeConfig policies - should fail",
+			policies: []ConfigPolicy{TypeConfigDuplicateMerge, TypeConfigDuplicateLastWins, TypeConfigFileOptional},
+			errCount: 1,
+		},
 		{
 			desc:     "conflicting PluginConfig policies - should fail",
 			policies: []ConfigPolicy{PluginConfigFileOptional, PluginConfigFileRequired, DeviceConfigFileOptional},
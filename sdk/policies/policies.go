@@ -71,6 +71,23 @@ const (
 	// type configurations from config file(s). This can be used if a plugin
 	// needs to restrict its configuration paths.
 	TypeConfigFileProhibited
+
+	// TypeConfigDuplicateError is a policy that fails config processing if more
+	// than one output type config file defines an output type with the same name,
+	// and the definitions differ. Identical definitions are not a conflict. This
+	// is the default policy for duplicate output type configs.
+	TypeConfigDuplicateError
+
+	// TypeConfigDuplicateLastWins is a policy that, when more than one output type
+	// config file defines an output type with the same name, uses the output type
+	// from the file which was found last.
+	TypeConfigDuplicateLastWins
+
+	// TypeConfigDuplicateMerge is a policy that, when more than one output type
+	// config file defines an output type with the same name, merges the output types
+	// in the order their files were found. Fields set by a later file override the
+	// same fields set by an earlier one.
+	TypeConfigDuplicateMerge
 )
 
 // policyStrings maps ConfigPolicies to their name.
@@ -92,6 +109,10 @@ var policyStrings = map[ConfigPolicy]string{
 	TypeConfigFileOptional:   "TypeConfigFileOptional",
 	TypeConfigFileRequired:   "TypeConfigFileRequired",
 	TypeConfigFileProhibited: "TypeConfigFileProhibited",
+
+	TypeConfigDuplicateError:    "TypeConfigDuplicateError",
+	TypeConfigDuplicateLastWins: "TypeConfigDuplicateLastWins",
+	TypeConfigDuplicateMerge:    "TypeConfigDuplicateMerge",
 }
 
 // String returns the name of the ConfigPolicy.
@@ -114,6 +135,7 @@ type manager struct {
 	deviceConfigFilePolicy    ConfigPolicy
 	deviceConfigDynamicPolicy ConfigPolicy
 	typeConfigFilePolicy      ConfigPolicy
+	typeConfigDuplicatePolicy ConfigPolicy
 }
 
 // Add adds a ConfigPolicy to the policies tracked by the manager.
@@ -133,6 +155,7 @@ func (m *manager) Clear() {
 	m.deviceConfigFilePolicy = NoPolicy
 	m.deviceConfigDynamicPolicy = NoPolicy
 	m.typeConfigFilePolicy = NoPolicy
+	m.typeConfigDuplicatePolicy = NoPolicy
 }
 
 // Clear clears the SDK's policy manager of all policies and settings.
@@ -247,6 +270,31 @@ func GetTypeConfigFilePolicy() ConfigPolicy {
 	return defaultManager.GetTypeConfigFilePolicy()
 }
 
+// GetTypeConfigDuplicatePolicy gets the policy for handling output types with the
+// same name in multiple config files for the manager. If no policy was explicitly
+// set, this will return the default policy.
+func (m *manager) GetTypeConfigDuplicatePolicy() ConfigPolicy {
+	if m.typeConfigDuplicatePolicy == NoPolicy {
+		for _, p := range m.policies {
+			switch p {
+			case TypeConfigDuplicateError, TypeConfigDuplicateLastWins, TypeConfigDuplicateMerge:
+				m.typeConfigDuplicatePolicy = p
+			}
+		}
+		if m.typeConfigDuplicatePolicy == NoPolicy {
+			m.typeConfigDuplicatePolicy = TypeConfigDuplicateError
+		}
+	}
+	return m.typeConfigDuplicatePolicy
+}
+
+// GetTypeConfigDuplicatePolicy gets the policy for handling output types with the
+// same name in multiple config files that was registered with the SDK's policy
+// manager. If no policy was explicitly set, the default policy is returned.
+func GetTypeConfigDuplicatePolicy() ConfigPolicy {
+	return defaultManager.GetTypeConfigDuplicatePolicy()
+}
+
 // Check checks the policy constraint functions against the manager's set of
 // tracked policies. This should be done prior to getting any policies to ensure
 // that the policy set is valid to begin with.
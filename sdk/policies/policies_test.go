@@ -81,6 +81,21 @@ func TestConfigPolicy_String(t *testing.T) {
 			policy:   TypeConfigFileProhibited,
 			expected: "TypeConfigFileProhibited",
 		},
+		{
+			desc:     "String for TypeConfigDuplicateError",
+			policy:   TypeConfigDuplicateError,
+			expected: "TypeConfigDuplicateError",
+		},
+		{
+			desc:     "String for TypeConfigDuplicateLastWins",
+			policy:   TypeConfigDuplicateLastWins,
+			expected: "TypeConfigDuplicateLastWins",
+		},
+		{
+			desc:     "String for TypeConfigDuplicateMerge",
+			policy:   TypeConfigDuplicateMerge,
+			expected: "TypeConfigDuplicateMerge",
+		},
 		{
 			desc:     "String for custom policy",
 			policy:   ConfigPolicy(17),
@@ -226,6 +241,29 @@ func TestGetTypeConfigFilePolicy(t *testing.T) {
 	assert.Equal(t, TypeConfigFileOptional, policy)
 }
 
+// TestGetTypeConfigDuplicatePolicy tests getting the duplicate output type
+// config policy from the global policy manager.
+func TestGetTypeConfigDuplicatePolicy(t *testing.T) {
+	defer resetPolicyManager()
+
+	// Get the duplicate output type policy when none is set - this should give the default.
+	assert.Empty(t, defaultManager.typeConfigDuplicatePolicy)
+	policy := GetTypeConfigDuplicatePolicy()
+	assert.Equal(t, TypeConfigDuplicateError, policy)
+
+	// Get the duplicate output type policy when LastWins is set.
+	defaultManager.typeConfigDuplicatePolicy = TypeConfigDuplicateLastWins
+	policy = GetTypeConfigDuplicatePolicy()
+	assert.Equal(t, TypeConfigDuplicateLastWins, policy)
+
+	// Reset the duplicate output type policy and add the policy to the
+	// tracked policies. It should now find it from there.
+	defaultManager.typeConfigDuplicatePolicy = NoPolicy
+	defaultManager.policies = []ConfigPolicy{TypeConfigDuplicateMerge}
+	policy = GetTypeConfigDuplicatePolicy()
+	assert.Equal(t, TypeConfigDuplicateMerge, policy)
+}
+
 // TestSet tests adding multiple policies to the manager.
 func TestSet(t *testing.T) {
 	defer resetPolicyManager()
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// standardAtmosphericPressures are the standard atmospheric pressure at sea level
+// in each of the known pressure units, by unit symbol. The standard pressure in
+// the unit of an output type is the atmospheric baseline used to convert between
+// absolute and gauge pressure if none is configured.
+var standardAtmosphericPressures = map[string]float64{
+	"Pa":   101325,
+	"hPa":  1013.25,
+	"kPa":  101.325,
+	"MPa":  0.101325,
+	"mbar": 1013.25,
+	"bar":  1.01325,
+	"atm":  1,
+	"psi":  14.6959488,
+	"mmHg": 760,
+	"inHg": 29.9212598,
+	"Torr": 760,
+}
+
+// pressureConversions are the built-in conversions between absolute and gauge
+// pressure, by name, with the sign with which the atmospheric baseline is added
+// to the pressure. The baseline depends on the unit of the output type, so output
+// types bind these conversions to their unit (see getConversion).
+var pressureConversions = map[string]float64{
+	"absoluteToGaugePressure": -1,
+	"gaugeToAbsolutePressure": 1,
+}
+
+// atmosphericPressureTTL is how long a pressure read from the atmospheric pressure
+// source is used for before the source is read again.
+const atmosphericPressureTTL = time.Minute
+
+// atmosphericPressureSource is the source of the current local atmospheric
+// pressure, if one is set with the CustomAtmosphericPressureSource option. It is
+// kept outside of the plugin context, since the built-in conversions which use it
+// are used to initialize the context.
+var atmosphericPressureSource AtmosphericPressureSource
+
+// atmosphericPressureCache holds the last result of reading the atmospheric
+// pressure source, so that the source is not read for every converted reading.
+var atmosphericPressureCache struct {
+	sync.Mutex
+
+	pressure float64
+	err      error
+	expires  time.Time
+}
+
+// setAtmosphericPressureSource sets the atmospheric pressure source, clearing any
+// pressure cached from the previous source.
+func setAtmosphericPressureSource(source AtmosphericPressureSource) {
+	atmosphericPressureCache.Lock()
+	defer atmosphericPressureCache.Unlock()
+
+	atmosphericPressureSource = source
+	atmosphericPressureCache.pressure = 0
+	atmosphericPressureCache.err = nil
+	atmosphericPressureCache.expires = time.Time{}
+}
+
+// sourcedAtmosphericPressure gets the pressure from the atmospheric pressure
+// source. The result, including a failure, is cached for atmosphericPressureTTL.
+func sourcedAtmosphericPressure() (float64, error) {
+	atmosphericPressureCache.Lock()
+	defer atmosphericPressureCache.Unlock()
+
+	now := clock.Now()
+	if now.Before(atmosphericPressureCache.expires) {
+		return atmosphericPressureCache.pressure, atmosphericPressureCache.err
+	}
+	pressure, err := atmosphericPressureSource()
+	atmosphericPressureCache.pressure = pressure
+	atmosphericPressureCache.err = err
+	atmosphericPressureCache.expires = now.Add(atmosphericPressureTTL)
+	return pressure, err
+}
+
+// atmosphericPressure gets the atmospheric baseline for converting between
+// absolute and gauge pressure in the given unit. If a source is set, the pressure
+// is read from it. Otherwise, or if reading from the source fails, the pressure
+// configured in the plugin config is used. If neither is available, the standard
+// atmospheric pressure in the unit is used. An error is returned if the unit has
+// no known standard pressure, since the baseline must be configured for it.
+func atmosphericPressure(unit string) (float64, error) {
+	if atmosphericPressureSource != nil {
+		pressure, err := sourcedAtmosphericPressure()
+		if err == nil {
+			return pressure, nil
+		}
+		repeatedLog.Warnf("[sdk] failed to get atmospheric pressure from source, using configured baseline: %v", err)
+	}
+	if Config.Plugin != nil && Config.Plugin.AtmosphericPressure != 0 {
+		return Config.Plugin.AtmosphericPressure, nil
+	}
+	if pressure, ok := standardAtmosphericPressures[unit]; ok {
+		return pressure, nil
+	}
+	return 0, fmt.Errorf(
+		"no standard atmospheric pressure for unit %q, the atmosphericPressure must be configured", unit,
+	)
+}
+
+// pressureConversion gets the named conversion between absolute and gauge
+// pressure (see pressureConversions) for pressures in the given unit.
+func pressureConversion(name, unit string) (Conversion, error) {
+	baseline, err := atmosphericPressure(unit)
+	if err != nil {
+		return nil, err
+	}
+	sign := pressureConversions[name]
+	return func(pressure float64) float64 {
+		return pressure + sign*baseline
+	}, nil
+}
+
+// absoluteToGaugePressure converts an absolute pressure in kPa to a gauge
+// pressure, i.e. the pressure relative to the atmospheric baseline. This is the
+// registered "absoluteToGaugePressure" conversion; output types apply it in
+// their own unit instead (see pressureConversion).
+func absoluteToGaugePressure(pressure float64) float64 {
+	conversion, err := pressureConversion("absoluteToGaugePressure", "kPa")
+	if err != nil {
+		return pressure
+	}
+	return conversion(pressure)
+}
+
+// gaugeToAbsolutePressure converts a gauge pressure in kPa, relative to the
+// atmospheric baseline, to an absolute pressure. This is the registered
+// "gaugeToAbsolutePressure" conversion; output types apply it in their own unit
+// instead (see pressureConversion).
+func gaugeToAbsolutePressure(pressure float64) float64 {
+	conversion, err := pressureConversion("gaugeToAbsolutePressure", "kPa")
+	if err != nil {
+		return pressure
+	}
+	return conversion(pressure)
+}
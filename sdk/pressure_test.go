@@ -0,0 +1,203 @@
+package sdk
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAtmosphericPressure tests getting the atmospheric baseline for pressure
+// conversions.
+func TestAtmosphericPressure(t *testing.T) {
+	defer func() {
+		setAtmosphericPressureSource(nil)
+		Config.reset()
+	}()
+
+	// No config, and no source.
+	pressure, err := atmosphericPressure("kPa")
+	assert.NoError(t, err)
+	assert.Equal(t, 101.325, pressure)
+
+	// A configured baseline.
+	Config.Plugin = &PluginConfig{AtmosphericPressure: 14.7}
+	pressure, err = atmosphericPressure("psi")
+	assert.NoError(t, err)
+	assert.Equal(t, 14.7, pressure)
+
+	// A dynamic source takes precedence over the configured baseline.
+	setAtmosphericPressureSource(func() (float64, error) {
+		return 99.5, nil
+	})
+	pressure, err = atmosphericPressure("kPa")
+	assert.NoError(t, err)
+	assert.Equal(t, 99.5, pressure)
+
+	// If the source fails, the configured baseline is used.
+	setAtmosphericPressureSource(func() (float64, error) {
+		return 0, fmt.Errorf("test error")
+	})
+	pressure, err = atmosphericPressure("kPa")
+	assert.NoError(t, err)
+	assert.Equal(t, 14.7, pressure)
+}
+
+// TestAtmosphericPressure_StandardUnits tests that the default atmospheric
+// baseline is the standard atmospheric pressure in the unit being converted.
+func TestAtmosphericPressure_StandardUnits(t *testing.T) {
+	var testTable = []struct {
+		unit     string
+		expected float64
+	}{
+		{"Pa", 101325},
+		{"hPa", 1013.25},
+		{"kPa", 101.325},
+		{"bar", 1.01325},
+		{"psi", 14.6959488},
+		{"atm", 1},
+	}
+
+	for _, testCase := range testTable {
+		pressure, err := atmosphericPressure(testCase.unit)
+		assert.NoError(t, err, testCase.unit)
+		assert.Equal(t, testCase.expected, pressure, testCase.unit)
+	}
+
+	// A unit with no known standard pressure requires a configured baseline.
+	for _, unit := range []string{"", "furlong"} {
+		_, err := atmosphericPressure(unit)
+		assert.Error(t, err, unit)
+	}
+}
+
+// TestAtmosphericPressure_SourceCached tests that the pressure from the atmospheric
+// pressure source, and any failure to get it, is cached for atmosphericPressureTTL.
+func TestAtmosphericPressure_SourceCached(t *testing.T) {
+	defer func() {
+		setAtmosphericPressureSource(nil)
+		Config.reset()
+		clock = realClock{}
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	Config.Plugin = &PluginConfig{AtmosphericPressure: 100}
+
+	var calls int
+	var sourceErr error
+	setAtmosphericPressureSource(func() (float64, error) {
+		calls++
+		return float64(90 + calls), sourceErr
+	})
+
+	for i := 0; i < 3; i++ {
+		pressure, err := atmosphericPressure("kPa")
+		assert.NoError(t, err)
+		assert.Equal(t, 91.0, pressure)
+	}
+	assert.Equal(t, 1, calls)
+
+	c.Advance(atmosphericPressureTTL)
+	sourceErr = fmt.Errorf("test error")
+	for i := 0; i < 3; i++ {
+		pressure, err := atmosphericPressure("kPa")
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, pressure)
+	}
+	assert.Equal(t, 2, calls)
+
+	c.Advance(atmosphericPressureTTL)
+	sourceErr = nil
+	pressure, err := atmosphericPressure("kPa")
+	assert.NoError(t, err)
+	assert.Equal(t, 93.0, pressure)
+	assert.Equal(t, 3, calls)
+}
+
+// TestPressureConversions tests converting between absolute and gauge pressure
+// with a fixed atmospheric baseline.
+func TestPressureConversions(t *testing.T) {
+	defer Config.reset()
+	Config.Plugin = &PluginConfig{AtmosphericPressure: 100}
+
+	var testTable = []struct {
+		desc       string
+		conversion string
+		value      float64
+		expected   float64
+	}{
+		{"absolute to gauge", "absoluteToGaugePressure", 250, 150},
+		{"absolute to gauge, below baseline", "absoluteToGaugePressure", 80, -20},
+		{"gauge to absolute", "gaugeToAbsolutePressure", 150, 250},
+		{"gauge to absolute, zero", "gaugeToAbsolutePressure", 0, 100},
+	}
+
+	for _, testCase := range testTable {
+		outputType := OutputType{
+			Name:       "pressure",
+			Conversion: testCase.conversion,
+			Unit:       Unit{Name: "kilopascal", Symbol: "kPa"},
+		}
+		actual := outputType.Apply(testCase.value)
+		assert.InDelta(t, testCase.expected, actual, 1e-9, testCase.desc)
+	}
+
+	// Converting to gauge and back gives the original value.
+	assert.InDelta(t, 123.4, gaugeToAbsolutePressure(absoluteToGaugePressure(123.4)), 1e-9)
+}
+
+// TestPressureConversions_Unit tests that converting between absolute and gauge
+// pressure with no configured baseline uses the standard atmospheric pressure in
+// the unit of the output type.
+func TestPressureConversions_Unit(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		value    float64
+		expected float64
+	}{
+		{
+			desc:     "kPa",
+			output:   OutputType{Name: "pressure", Conversion: "absoluteToGaugePressure", Unit: Unit{Symbol: "kPa"}},
+			value:    201.325,
+			expected: 100,
+		},
+		{
+			desc:     "psi",
+			output:   OutputType{Name: "pressure", Conversion: "absoluteToGaugePressure", Unit: Unit{Symbol: "psi"}},
+			value:    20,
+			expected: 5.3040512,
+		},
+		{
+			desc: "Pa, transform",
+			output: OutputType{
+				Name:       "pressure",
+				Transforms: []*Transform{{Conversion: "gaugeToAbsolutePressure"}},
+				Unit:       Unit{Symbol: "Pa"},
+			},
+			value:    500,
+			expected: 101825,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := testCase.output.Apply(testCase.value)
+		assert.InDelta(t, testCase.expected, actual, 1e-9, testCase.desc)
+	}
+
+	// A unit with no known standard pressure can not be converted without a
+	// configured baseline.
+	_, err := (&OutputType{Name: "pressure", Conversion: "absoluteToGaugePressure"}).applyConversion(20.0)
+	assert.Error(t, err)
+}
+
+// TestCustomAtmosphericPressureSource tests setting a custom source for the
+// atmospheric baseline.
+func TestCustomAtmosphericPressureSource(t *testing.T) {
+	defer setAtmosphericPressureSource(nil)
+
+	CustomAtmosphericPressureSource(func() (float64, error) {
+		return 98.6, nil
+	})(ctx)
+	assert.InDelta(t, 1.4, absoluteToGaugePressure(100), 1e-9)
+}
@@ -0,0 +1,189 @@
+package sdk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// deviceQuarantine tracks bad readings for each device, if quarantine is enabled
+// in the plugin configuration. Devices which repeatedly return bad readings
+// (NaN, Inf, or values outside of the valid range for their output type) are
+// quarantined, and their readings are no longer emitted until the quarantine
+// is reset.
+var deviceQuarantine *quarantine
+
+// quarantine tracks the number of consecutive bad readings for devices, keyed by
+// device ID, and the devices which have been quarantined.
+type quarantine struct {
+	sync.Mutex
+
+	threshold   int
+	badCounts   map[string]int
+	quarantined map[string]bool
+}
+
+// newQuarantine creates a new quarantine which quarantines devices after the
+// given number of consecutive bad readings.
+func newQuarantine(threshold int) *quarantine {
+	return &quarantine{
+		threshold:   threshold,
+		badCounts:   map[string]int{},
+		quarantined: map[string]bool{},
+	}
+}
+
+// check checks the readings for a device, updating its bad reading count. It
+// returns whether the device is quarantined, in which case its readings should
+// not be emitted.
+func (q *quarantine) check(device string, bad bool) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.quarantined[device] {
+		return true
+	}
+	if !bad {
+		delete(q.badCounts, device)
+		return false
+	}
+
+	q.badCounts[device]++
+	if q.badCounts[device] >= q.threshold {
+		log.WithFields(log.Fields{
+			"device":       device,
+			"bad readings": q.badCounts[device],
+		}).Error("[quarantine] device quarantined after repeated bad readings")
+		q.quarantined[device] = true
+		delete(q.badCounts, device)
+		return true
+	}
+	return false
+}
+
+// reset releases a device from quarantine.
+func (q *quarantine) reset(device string) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if !q.quarantined[device] {
+		return false
+	}
+	delete(q.quarantined, device)
+	return true
+}
+
+// devices gets the IDs of all quarantined devices, sorted.
+func (q *quarantine) devices() []string {
+	q.Lock()
+	defer q.Unlock()
+
+	var devices []string
+	for device := range q.quarantined {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// setupDeviceQuarantine sets up the device quarantine, if it is enabled in the
+// plugin configuration.
+func setupDeviceQuarantine() {
+	quarantineSettings := Config.Plugin.Settings.Quarantine
+	if quarantineSettings != nil && quarantineSettings.Enabled {
+		log.WithField(
+			"threshold", quarantineSettings.Threshold,
+		).Info("[quarantine] enabling device quarantine")
+		deviceQuarantine = newQuarantine(quarantineSettings.Threshold)
+	} else {
+		log.Debug("[quarantine] device quarantine disabled")
+	}
+}
+
+// quarantineReadings checks the readings from a ReadContext against the device
+// quarantine, if it is enabled. It returns whether the device which the readings
+// are for is quarantined, in which case the readings should be dropped.
+func quarantineReadings(readCtx *ReadContext) bool {
+	if deviceQuarantine == nil {
+		return false
+	}
+
+	device := ctx.getDevice(readCtx.ID())
+	bad := false
+	for _, reading := range readCtx.Reading {
+		if isBadReading(device, reading) {
+			bad = true
+			break
+		}
+	}
+	return deviceQuarantine.check(readCtx.ID(), bad)
+}
+
+// isBadReading checks whether a reading value is bad. A reading is bad if its
+// value is NaN or Inf, or if it is outside of the valid range for the reading's
+// output type on the device, if one is configured.
+func isBadReading(device *Device, reading *Reading) bool {
+	var f float64
+	switch v := reading.Value.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case string, nil:
+		// Only numeric values are checked.
+		return false
+	default:
+		var err error
+		f, err = ConvertToFloat64(v)
+		if err != nil {
+			return false
+		}
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return true
+	}
+	if device == nil {
+		return false
+	}
+	for _, output := range device.Outputs {
+		if output.Type() != reading.Type {
+			continue
+		}
+		if output.ValidMin != nil && f < *output.ValidMin {
+			return true
+		}
+		if output.ValidMax != nil && f > *output.ValidMax {
+			return true
+		}
+	}
+	return false
+}
+
+// getQuarantinedDevices gets the IDs of the quarantined devices. If the device
+// quarantine is not enabled, nil is returned.
+func getQuarantinedDevices() []string {
+	if deviceQuarantine == nil {
+		return nil
+	}
+	return deviceQuarantine.devices()
+}
+
+// ResetQuarantine releases the device with the given ID (its GUID, e.g.
+// "rack-board-device") from quarantine, so its readings are emitted again.
+// An error is returned if device quarantine is not enabled, or if the device
+// is not quarantined.
+func (plugin *Plugin) ResetQuarantine(id string) error {
+	if deviceQuarantine == nil {
+		return fmt.Errorf("device quarantine is not enabled")
+	}
+	if !deviceQuarantine.reset(id) {
+		return errors.NotFoundErr("no quarantined device found with id: %s", id)
+	}
+	log.WithField("device", id).Info("[quarantine] device released from quarantine")
+	return nil
+}
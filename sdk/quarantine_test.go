@@ -0,0 +1,187 @@
+package sdk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// Test setting up the device quarantine when it is enabled in the config.
+func Test_setupDeviceQuarantine_Enabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		deviceQuarantine = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Quarantine: &QuarantineSettings{
+				Enabled:   true,
+				Threshold: 3,
+			},
+		},
+	}
+
+	assert.Nil(t, deviceQuarantine)
+	setupDeviceQuarantine()
+	assert.NotNil(t, deviceQuarantine)
+	assert.Equal(t, 3, deviceQuarantine.threshold)
+}
+
+// Test setting up the device quarantine when it is disabled in the config.
+func Test_setupDeviceQuarantine_Disabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		deviceQuarantine = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Quarantine: &QuarantineSettings{},
+		},
+	}
+
+	setupDeviceQuarantine()
+	assert.Nil(t, deviceQuarantine)
+	assert.Nil(t, getQuarantinedDevices())
+	assert.NoError(t, quarantineHealthCheck())
+}
+
+// Test that NaN readings quarantine a device, after which its readings are no
+// longer emitted and the health check fails until the quarantine is reset.
+func TestDataManager_updateReadingsQuarantine(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		deviceQuarantine = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+	deviceQuarantine = newQuarantine(3)
+
+	d := newDataManager()
+	read := func(value interface{}) {
+		d.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Value: value}},
+		})
+	}
+
+	// Bad readings below the threshold are still emitted.
+	read(math.NaN())
+	read(math.NaN())
+	assert.Equal(t, 1, len(d.getReadings("rack-board-device")))
+	assert.Empty(t, getQuarantinedDevices())
+	assert.NoError(t, quarantineHealthCheck())
+
+	// Reaching the threshold quarantines the device.
+	read(math.NaN())
+	assert.Nil(t, d.getReadings("rack-board-device"))
+	assert.Equal(t, []string{"rack-board-device"}, getQuarantinedDevices())
+	assert.Error(t, quarantineHealthCheck())
+
+	// Good readings are not emitted while quarantined.
+	read(1.0)
+	assert.Nil(t, d.getReadings("rack-board-device"))
+	assert.Error(t, quarantineHealthCheck())
+
+	// Resetting the quarantine resumes emission.
+	plugin := Plugin{}
+	assert.NoError(t, plugin.ResetQuarantine("rack-board-device"))
+	assert.NoError(t, quarantineHealthCheck())
+
+	read(2.0)
+	readings := d.getReadings("rack-board-device")
+	assert.Equal(t, 1, len(readings))
+	assert.Equal(t, 2.0, readings[0].Value)
+}
+
+// Test that a good reading resets the count of consecutive bad readings.
+func Test_quarantine_checkGoodReadingResets(t *testing.T) {
+	q := newQuarantine(2)
+
+	assert.False(t, q.check("dev", true))
+	assert.False(t, q.check("dev", false))
+	assert.False(t, q.check("dev", true))
+	assert.Empty(t, q.devices())
+
+	assert.True(t, q.check("dev", true))
+	assert.Equal(t, []string{"dev"}, q.devices())
+}
+
+// Test resetting the quarantine for a device which is not quarantined.
+func TestPlugin_ResetQuarantine_NotQuarantined(t *testing.T) {
+	defer func() {
+		deviceQuarantine = nil
+	}()
+
+	plugin := Plugin{}
+	assert.Error(t, plugin.ResetQuarantine("rack-board-device"))
+
+	deviceQuarantine = newQuarantine(1)
+	assert.Error(t, plugin.ResetQuarantine("rack-board-device"))
+}
+
+// Test checking whether readings are bad.
+func Test_isBadReading(t *testing.T) {
+	validMin, validMax := 0.0, 100.0
+	device := &Device{
+		Outputs: []*Output{
+			{OutputType: OutputType{Name: "temperature", ValidMin: &validMin, ValidMax: &validMax}},
+		},
+	}
+
+	var tests = []struct {
+		desc     string
+		device   *Device
+		reading  *Reading
+		expected bool
+	}{
+		{"good float", device, &Reading{Type: "temperature", Value: 20.5}, false},
+		{"good int", device, &Reading{Type: "temperature", Value: 20}, false},
+		{"min bound", device, &Reading{Type: "temperature", Value: 0.0}, false},
+		{"max bound", device, &Reading{Type: "temperature", Value: 100}, false},
+		{"NaN", device, &Reading{Type: "temperature", Value: math.NaN()}, true},
+		{"+Inf", device, &Reading{Type: "temperature", Value: math.Inf(1)}, true},
+		{"-Inf float32", device, &Reading{Type: "temperature", Value: float32(math.Inf(-1))}, true},
+		{"below min", device, &Reading{Type: "temperature", Value: -1}, true},
+		{"above max", device, &Reading{Type: "temperature", Value: 100.1}, true},
+		{"other type", device, &Reading{Type: "humidity", Value: 200}, false},
+		{"no device", nil, &Reading{Type: "temperature", Value: 200}, false},
+		{"no device NaN", nil, &Reading{Type: "temperature", Value: math.NaN()}, true},
+		{"string", device, &Reading{Type: "temperature", Value: "foo"}, false},
+		{"nil", device, &Reading{Type: "temperature", Value: nil}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isBadReading(test.device, test.reading), test.desc)
+	}
+}
+
+// Test validating the quarantine settings.
+func TestQuarantineSettings_Validate(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		settings QuarantineSettings
+		errs     int
+	}{
+		{"disabled", QuarantineSettings{}, 0},
+		{"valid", QuarantineSettings{Enabled: true, Threshold: 5}, 0},
+		{"zero threshold", QuarantineSettings{Enabled: true, Threshold: 0}, 1},
+		{"negative threshold", QuarantineSettings{Enabled: true, Threshold: -1}, 1},
+	}
+
+	for _, test := range tests {
+		merr := errors.NewMultiError("test")
+		test.settings.Validate(merr)
+		assert.Equal(t, test.errs, len(merr.Errors), test.desc)
+	}
+}
@@ -0,0 +1,212 @@
+package sdk
+
+import (
+	"math"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// ReadingRange is the running minimum and maximum of the numeric values read for
+// a reading type of a device, since the plugin started or the range was reset.
+type ReadingRange struct {
+	// Min is the minimum value read.
+	Min float64
+
+	// Max is the maximum value read.
+	Max float64
+
+	// Since is the timestamp of the first value read for the range.
+	Since string
+}
+
+// GetReadingRanges gets the running minimum and maximum values of the numeric
+// readings for the device with the given ID (its GUID), keyed by reading type.
+func GetReadingRanges(deviceID string) map[string]ReadingRange {
+	return DataManager.getReadingRanges(deviceID)
+}
+
+// ResetReadingRanges resets the running minimum and maximum values of the readings
+// for the device with the given ID (its GUID). The next value read for each reading
+// type starts a new range.
+func ResetReadingRanges(deviceID string) {
+	DataManager.resetReadingRanges(deviceID)
+}
+
+// trackReadingRanges updates the running minimum and maximum values for the
+// device's numeric readings. The first value read for a reading type seeds both
+// the minimum and the maximum.
+func (manager *dataManager) trackReadingRanges(deviceID string, readings []*Reading) {
+	manager.rangesLock.Lock()
+	defer manager.rangesLock.Unlock()
+
+	for _, reading := range readings {
+		// Only numeric values are tracked. Strings are not converted, since
+		// they are not otherwise treated as numeric reading values.
+		if _, isString := reading.Value.(string); isString || reading.Value == nil {
+			continue
+		}
+		value, err := ConvertToFloat64(reading.Value)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+
+		ranges, exists := manager.ranges[deviceID]
+		if !exists {
+			ranges = map[string]*ReadingRange{}
+			manager.ranges[deviceID] = ranges
+		}
+		bounds, exists := ranges[reading.Type]
+		if !exists {
+			ranges[reading.Type] = &ReadingRange{Min: value, Max: value, Since: reading.Timestamp}
+			continue
+		}
+		bounds.Min = math.Min(bounds.Min, value)
+		bounds.Max = math.Max(bounds.Max, value)
+	}
+}
+
+// getReadingRanges gets a copy of the reading ranges tracked for a device.
+func (manager *dataManager) getReadingRanges(deviceID string) map[string]ReadingRange {
+	manager.rangesLock.Lock()
+	defer manager.rangesLock.Unlock()
+
+	ranges := map[string]ReadingRange{}
+	for readingType, bounds := range manager.ranges[deviceID] {
+		ranges[readingType] = *bounds
+	}
+	return ranges
+}
+
+// resetReadingRanges clears the reading ranges tracked for a device.
+func (manager *dataManager) resetReadingRanges(deviceID string) {
+	manager.rangesLock.Lock()
+	defer manager.rangesLock.Unlock()
+
+	delete(manager.ranges, deviceID)
+}
+
+// encodeReadingRanges translates reading ranges to a protobuf Struct, keyed by
+// reading type, with the "min", "max", and "since" of each range.
+func encodeReadingRanges(ranges map[string]ReadingRange) *structpb.Struct {
+	numberValue := func(f float64) *structpb.Value {
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: f}}
+	}
+	encoded := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for readingType, bounds := range ranges {
+		encoded.Fields[readingType] = &structpb.Value{Kind: &structpb.Value_StructValue{
+			StructValue: &structpb.Struct{
+				Fields: map[string]*structpb.Value{
+					"min":   numberValue(bounds.Min),
+					"max":   numberValue(bounds.Max),
+					"since": {Kind: &structpb.Value_StringValue{StringValue: bounds.Since}},
+				},
+			},
+		}}
+	}
+	return encoded
+}
+
+// validateForReadingRanges validates the request for a device's reading ranges
+// and gets the ID of the device.
+func validateForReadingRanges(req *synse.DeviceFilter) (string, error) {
+	err := validateDeviceFilter(req)
+	if err != nil {
+		return "", err
+	}
+	deviceID := makeIDString(req.Rack, req.Board, req.Device)
+	if err := validateForRead(deviceID); err != nil {
+		return "", err
+	}
+	return deviceID, nil
+}
+
+// readingRangesServer is the server API for the synse.ReadingRanges service.
+type readingRangesServer interface {
+	GetRanges(context.Context, *synse.DeviceFilter) (*structpb.Struct, error)
+	ResetRanges(context.Context, *synse.DeviceFilter) (*synse.Empty, error)
+}
+
+// GetRanges is the handler for the synse.ReadingRanges service's `GetRanges` RPC
+// method. It reports the running minimum and maximum values of a device's readings.
+func (server *server) GetRanges(ctx context.Context, request *synse.DeviceFilter) (*structpb.Struct, error) {
+	log.WithField("request", request).Debug("[grpc] get ranges rpc request")
+	deviceID, err := validateForReadingRanges(request)
+	if err != nil {
+		return nil, err
+	}
+	return encodeReadingRanges(GetReadingRanges(deviceID)), nil
+}
+
+// ResetRanges is the handler for the synse.ReadingRanges service's `ResetRanges`
+// RPC method. It resets the running minimum and maximum values of a device's readings.
+func (server *server) ResetRanges(ctx context.Context, request *synse.DeviceFilter) (*synse.Empty, error) {
+	log.WithField("request", request).Debug("[grpc] reset ranges rpc request")
+	deviceID, err := validateForReadingRanges(request)
+	if err != nil {
+		return nil, err
+	}
+	ResetReadingRanges(deviceID)
+	return &synse.Empty{}, nil
+}
+
+// getRangesHandler decodes and dispatches requests for the `GetRanges` RPC method.
+func getRangesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.DeviceFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(readingRangesServer).GetRanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.ReadingRanges/GetRanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(readingRangesServer).GetRanges(ctx, req.(*synse.DeviceFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// resetRangesHandler decodes and dispatches requests for the `ResetRanges` RPC method.
+func resetRangesHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.DeviceFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(readingRangesServer).ResetRanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.ReadingRanges/ResetRanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(readingRangesServer).ResetRanges(ctx, req.(*synse.DeviceFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// readingRangesServiceDesc describes the synse.ReadingRanges gRPC service, which
+// reports and resets the running minimum and maximum values of the numeric readings
+// of a device. It is defined in proto/ranges.proto.
+var readingRangesServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.ReadingRanges",
+	HandlerType: (*readingRangesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRanges",
+			Handler:    getRangesHandler,
+		},
+		{
+			MethodName: "ResetRanges",
+			Handler:    resetRangesHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/ranges.proto",
+}
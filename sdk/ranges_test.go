@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// TestDataManager_trackReadingRanges tests tracking the running minimum and maximum
+// of a device's numeric readings.
+func TestDataManager_trackReadingRanges(t *testing.T) {
+	manager := newDataManager()
+
+	// The first reading seeds both the minimum and maximum.
+	manager.trackReadingRanges("rack-board-device", []*Reading{
+		{Timestamp: "t1", Type: "temperature", Value: 20},
+	})
+	assert.Equal(t, map[string]ReadingRange{
+		"temperature": {Min: 20, Max: 20, Since: "t1"},
+	}, manager.getReadingRanges("rack-board-device"))
+
+	values := []interface{}{float32(18.5), int64(25), uint8(21), "100", nil, true}
+	for i, value := range values {
+		manager.trackReadingRanges("rack-board-device", []*Reading{
+			{Timestamp: "t2", Type: "temperature", Value: value},
+			{Timestamp: "t2", Type: "humidity", Value: 40 - i},
+		})
+	}
+
+	ranges := manager.getReadingRanges("rack-board-device")
+	assert.Equal(t, ReadingRange{Min: 18.5, Max: 25, Since: "t1"}, ranges["temperature"])
+	assert.Equal(t, ReadingRange{Min: 35, Max: 40, Since: "t2"}, ranges["humidity"])
+
+	// Other devices are tracked separately.
+	assert.Empty(t, manager.getReadingRanges("rack-board-other"))
+}
+
+// TestDataManager_resetReadingRanges tests resetting the reading ranges for a device.
+func TestDataManager_resetReadingRanges(t *testing.T) {
+	manager := newDataManager()
+
+	manager.trackReadingRanges("dev-1", []*Reading{{Timestamp: "t1", Type: "temperature", Value: 10}})
+	manager.trackReadingRanges("dev-1", []*Reading{{Timestamp: "t2", Type: "temperature", Value: 30}})
+	manager.trackReadingRanges("dev-2", []*Reading{{Timestamp: "t1", Type: "temperature", Value: 50}})
+
+	manager.resetReadingRanges("dev-1")
+	assert.Empty(t, manager.getReadingRanges("dev-1"))
+	assert.Equal(t, ReadingRange{Min: 50, Max: 50, Since: "t1"}, manager.getReadingRanges("dev-2")["temperature"])
+
+	// After a reset, the next reading seeds a new range.
+	manager.trackReadingRanges("dev-1", []*Reading{{Timestamp: "t3", Type: "temperature", Value: 20}})
+	assert.Equal(t, ReadingRange{Min: 20, Max: 20, Since: "t3"}, manager.getReadingRanges("dev-1")["temperature"])
+}
+
+// TestDataManager_updateReadings_ranges tests that reading ranges are tracked as
+// readings are updated.
+func TestDataManager_updateReadings_ranges(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache: &CacheSettings{},
+		},
+	}
+
+	for _, value := range []int{5, -3, 12} {
+		DataManager.updateReadings(&ReadContext{
+			Rack:    "rack",
+			Board:   "board",
+			Device:  "device",
+			Reading: []*Reading{{Timestamp: "now", Type: "temperature", Value: value}},
+		})
+	}
+
+	ranges := GetReadingRanges("rack-board-device")
+	assert.Equal(t, ReadingRange{Min: -3, Max: 12, Since: "now"}, ranges["temperature"])
+
+	ResetReadingRanges("rack-board-device")
+	assert.Empty(t, GetReadingRanges("rack-board-device"))
+}
+
+// Test_encodeReadingRanges tests encoding reading ranges.
+func Test_encodeReadingRanges(t *testing.T) {
+	encoded := encodeReadingRanges(map[string]ReadingRange{
+		"temperature": {Min: -1.5, Max: 30, Since: "now"},
+	})
+
+	assert.Equal(t, 1, len(encoded.Fields))
+	bounds := encoded.Fields["temperature"].GetStructValue()
+	assert.Equal(t, -1.5, bounds.Fields["min"].GetNumberValue())
+	assert.Equal(t, float64(30), bounds.Fields["max"].GetNumberValue())
+	assert.Equal(t, "now", bounds.Fields["since"].GetStringValue())
+}
+
+// TestServer_ReadingRanges tests the RPCs of the synse.ReadingRanges service over
+// a running gRPC server.
+func TestServer_ReadingRanges(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{Enabled: true},
+		},
+		Network: &NetworkSettings{Type: "tcp", Address: address},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Kind:     "foo",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) { return nil, nil },
+		},
+	}
+	DataManager.trackReadingRanges("rack-board-device", []*Reading{
+		{Timestamp: "now", Type: "temperature", Value: 10},
+		{Timestamp: "now", Type: "temperature", Value: 20},
+	})
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	filter := &synse.DeviceFilter{Rack: "rack", Board: "board", Device: "device"}
+
+	resp := &structpb.Struct{}
+	err = conn.Invoke(context.Background(), "/synse.ReadingRanges/GetRanges", filter, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	bounds := resp.Fields["temperature"].GetStructValue()
+	assert.Equal(t, float64(10), bounds.Fields["min"].GetNumberValue())
+	assert.Equal(t, float64(20), bounds.Fields["max"].GetNumberValue())
+
+	err = conn.Invoke(context.Background(), "/synse.ReadingRanges/ResetRanges", filter, &synse.Empty{}, grpc.FailFast(false))
+	assert.NoError(t, err)
+
+	resp = &structpb.Struct{}
+	err = conn.Invoke(context.Background(), "/synse.ReadingRanges/GetRanges", filter, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Fields)
+
+	// Requests for unknown devices fail.
+	unknown := &synse.DeviceFilter{Rack: "rack", Board: "board", Device: "unknown"}
+	err = conn.Invoke(context.Background(), "/synse.ReadingRanges/GetRanges", unknown, &structpb.Struct{}, grpc.FailFast(false))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no device found")
+}
@@ -1,7 +1,9 @@
 package sdk
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -31,8 +33,33 @@ var (
 
 	// supportedExts are the extensions supported for configuration files.
 	supportedExts = []string{".yml", ".yaml"}
+
+	// configStdin is the reader that configs are read from when the plugin is
+	// run with the --config-stdin flag.
+	configStdin io.Reader = os.Stdin
+
+	// stdinConfigs holds the configs read from configStdin. Since stdin can only
+	// be read once, the configs are cached after they are first read.
+	stdinConfigs *configStream
+
+	// pluginConfigKeys are the top-level keys which identify a plugin config
+	// document in a config stream.
+	pluginConfigKeys = []string{
+		"debug", "instanceId", "settings", "network", "dynamicRegistration",
+		"limiter", "health", "metrics", "admin", "conversions", "context",
+	}
 )
 
+const stdinConfigSource = "stdin"
+
+// configStream holds the configs read from a stream of YAML documents, grouped
+// by config type.
+type configStream struct {
+	plugin      *ConfigContext
+	devices     []*ConfigContext
+	outputTypes []*ConfigContext
+}
+
 // getOutputTypeConfigsFromFile finds the files containing output type configurations
 // and marshals them into an OutputType struct. These OutputTypes are wrapped in a
 // ConfigContext which provides the source file for the configuration as well.
@@ -40,6 +67,17 @@ var (
 // All ConfigContexts returned by this function will have their IsOutputTypeConfig
 // function return true.
 func getOutputTypeConfigsFromFile() ([]*ConfigContext, error) {
+	if flagConfigStdin {
+		stream, err := getStdinConfigs()
+		if err != nil {
+			return nil, err
+		}
+		if len(stream.outputTypes) == 0 {
+			return nil, errors.NewConfigsNotFoundError([]string{stdinConfigSource})
+		}
+		return stream.outputTypes, nil
+	}
+
 	var cfgs []*ConfigContext
 
 	// Search for output type config files. No name is specified as an arg here because
@@ -67,6 +105,21 @@ func getOutputTypeConfigsFromFile() ([]*ConfigContext, error) {
 // All ConfigContexts returned by this function will have their IsDeviceConfig
 // function return true.
 func getDeviceConfigsFromFile() ([]*ConfigContext, error) {
+	if flagConfigStdin {
+		stream, err := getStdinConfigs()
+		if err != nil {
+			return nil, err
+		}
+		if len(stream.devices) == 0 {
+			return nil, errors.NewConfigsNotFoundError([]string{stdinConfigSource})
+		}
+		return stream.devices, nil
+	}
+
+	if ref := os.Getenv(EnvDeviceConfigMap); ref != "" {
+		return getConfigMapDeviceConfigs(ref)
+	}
+
 	var cfgs []*ConfigContext
 
 	// Search for device config files. No name is specified as an arg here because
@@ -82,6 +135,10 @@ func getDeviceConfigsFromFile() ([]*ConfigContext, error) {
 		if err != nil {
 			return nil, fmt.Errorf("file: %s -> %s", file, err)
 		}
+		err = migrateConfigFile(file, config, ctx.deviceConfigMigrations, currentDeviceSchemeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s -> %s", file, err)
+		}
 		cfgs = append(cfgs, NewConfigContext(file, config))
 	}
 	return cfgs, nil
@@ -95,6 +152,17 @@ func getDeviceConfigsFromFile() ([]*ConfigContext, error) {
 // The ConfigContext returned by this function will have its IsPluginConfig
 // function return true.
 func getPluginConfigFromFile() (*ConfigContext, error) {
+	if flagConfigStdin {
+		stream, err := getStdinConfigs()
+		if err != nil {
+			return nil, err
+		}
+		if stream.plugin == nil {
+			return nil, errors.NewConfigsNotFoundError([]string{stdinConfigSource})
+		}
+		return stream.plugin, nil
+	}
+
 	// Search for the plugin config file. It should have the name "config".
 	files, err := findConfigs(pluginConfigSearchPaths, EnvPluginConfig, pluginConfigFileName)
 	if err != nil {
@@ -114,12 +182,151 @@ func getPluginConfigFromFile() (*ConfigContext, error) {
 	if err != nil {
 		return nil, fmt.Errorf("file: %s -> %s", files[0], err)
 	}
+	err = migrateConfigFile(files[0], config, ctx.pluginConfigMigrations, currentPluginSchemeVersion)
+	if err != nil {
+		return nil, fmt.Errorf("file: %s -> %s", files[0], err)
+	}
 
 	return NewConfigContext(files[0], config), nil
 }
 
+// getStdinConfigs gets the configs read from stdin, reading them on first use.
+func getStdinConfigs() (*configStream, error) {
+	if stdinConfigs == nil {
+		stream, err := readConfigStream(configStdin, stdinConfigSource)
+		if err != nil {
+			return nil, err
+		}
+		stdinConfigs = stream
+	}
+	return stdinConfigs, nil
+}
+
+// readConfigStream reads a stream of YAML documents, separated by "---", and
+// routes each document to the config type it defines based on its top-level
+// keys: documents with "devices" or "locations" are device configs, documents
+// with a "name" are output type configs, and documents with any of the plugin
+// config keys are the plugin config. Empty documents are ignored.
+//
+// Each config's ConfigContext source is the given source name suffixed with the
+// index of its document in the stream, e.g. "stdin[2]". Registered migrations
+// are applied to device and plugin configs as they are read. As with config
+// files, environment variable references are expanded before the stream is
+// parsed (see expandEnv).
+func readConfigStream(r io.Reader, source string) (*configStream, error) {
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err = expandEnv(contents)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", source, err)
+	}
+
+	// First, decode each document generically to determine its config type.
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d] -> %s", source, len(docs), err)
+		}
+		docs = append(docs, doc)
+	}
+
+	// Then, decode each document again directly into its config struct, so
+	// scalars keep their literal form, as they do when read from file.
+	stream := &configStream{}
+	decoder = yaml.NewDecoder(bytes.NewReader(contents))
+	for i, doc := range docs {
+		docSource := fmt.Sprintf("%s[%d]", source, i)
+
+		if len(doc) == 0 {
+			var empty interface{}
+			if err := decoder.Decode(&empty); err != nil {
+				return nil, fmt.Errorf("%s -> %s", docSource, err)
+			}
+			continue
+		}
+
+		config, err := newConfigForDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, fmt.Errorf("%s -> unable to determine config type for document", docSource)
+		}
+		if _, isPlugin := config.(*PluginConfig); isPlugin && stream.plugin != nil {
+			return nil, fmt.Errorf("only one plugin config should be defined, but found: %s, %s", stream.plugin.Source, docSource)
+		}
+
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("%s -> %s", docSource, err)
+		}
+
+		switch c := config.(type) {
+		case *DeviceConfig:
+			if _, err := migrateConfig(c, ctx.deviceConfigMigrations, currentDeviceSchemeVersion); err != nil {
+				return nil, fmt.Errorf("%s -> %s", docSource, err)
+			}
+			stream.devices = append(stream.devices, NewConfigContext(docSource, c))
+		case *OutputType:
+			stream.outputTypes = append(stream.outputTypes, NewConfigContext(docSource, c))
+		case *PluginConfig:
+			if _, err := migrateConfig(c, ctx.pluginConfigMigrations, currentPluginSchemeVersion); err != nil {
+				return nil, fmt.Errorf("%s -> %s", docSource, err)
+			}
+			stream.plugin = NewConfigContext(docSource, c)
+		}
+	}
+	return stream, nil
+}
+
+// newConfigForDocument creates a new config of the type defined by the given
+// generically decoded YAML document, based on its top-level keys: documents with
+// "devices" or "locations" are device configs, documents with a "name" are output
+// type configs, and documents with any of the plugin config keys are plugin configs.
+// Plugin configs have their defaults resolved. If the config type can not be
+// determined, nil is returned.
+func newConfigForDocument(doc map[string]interface{}) (ConfigBase, error) {
+	switch {
+	case hasAnyKey(doc, "devices", "locations"):
+		return &DeviceConfig{}, nil
+	case hasAnyKey(doc, "name"):
+		return &OutputType{}, nil
+	case hasAnyKey(doc, pluginConfigKeys...):
+		// Resolve the defaults for the config first
+		return NewDefaultPluginConfig()
+	}
+	return nil, nil
+}
+
+// hasAnyKey checks whether the given document has any of the given keys.
+func hasAnyKey(doc map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := doc[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // unmarshalConfigFile unmarshals the contents of the specified file into the
 // specified struct.
+//
+// YAML anchors, aliases, and merge keys (<<) are resolved by the parser as the
+// data is unmarshaled, so shared config blocks can be defined once and referenced
+// throughout the file. Scalars are unmarshaled directly into the struct fields,
+// so referenced values keep their literal form (e.g. a version of "1.0"). Keys
+// which override merged values should be defined after the merge key.
+//
+// Environment variable references, ${VAR}, are expanded before the contents are
+// unmarshaled (see expandEnv).
 func unmarshalConfigFile(filepath string, out interface{}) error {
 	// Read the file contents
 	contents, err := ioutil.ReadFile(filepath) // #nosec
@@ -127,12 +334,88 @@ func unmarshalConfigFile(filepath string, out interface{}) error {
 		return err
 	}
 
+	contents, err = expandEnv(contents)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filepath, err)
+	}
+
 	// Unmarshal into the given struct.
 	// Note: Right now, we only support YAML config files. If that changes,
 	// we'll need to update this to support different encodings.
 	return yaml.Unmarshal(contents, out)
 }
 
+// expandEnv expands the environment variable references, ${VAR}, in the given
+// config file contents with the values of the variables in the plugin's
+// environment. This lets any config value, e.g. credentials or host addresses,
+// differ per environment without templating the config files externally.
+//
+// A reference to a variable which is not set is an error, rather than expanding
+// to an empty string; a variable which is set to an empty string expands to it.
+// A literal "$" can be escaped as "$$". Any other "$" is left as is.
+func expandEnv(contents []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var unset []string
+
+	for i := 0; i < len(contents); i++ {
+		if contents[i] != '$' || i+1 == len(contents) {
+			buf.WriteByte(contents[i])
+			continue
+		}
+
+		switch contents[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+		case '{':
+			end := bytes.IndexByte(contents[i+2:], '}')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated environment variable reference at offset %d", i)
+			}
+			name := string(contents[i+2 : i+2+end])
+			if !isEnvName(name) {
+				return nil, fmt.Errorf("invalid environment variable reference: ${%s}", name)
+			}
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				unset = append(unset, name)
+			}
+			buf.WriteString(value)
+			i += end + 2
+		default:
+			buf.WriteByte('$')
+		}
+	}
+
+	if len(unset) != 0 {
+		return nil, fmt.Errorf("environment variable(s) not set: %s", strings.Join(unset, ", "))
+	}
+	return buf.Bytes(), nil
+}
+
+// isEnvName checks whether the given string is a valid environment variable
+// name, i.e. letters, digits, and underscores, not starting with a digit.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// hasEnvReferences checks whether the given config file contents contain any
+// environment variable references or escapes, i.e. whether expanding them with
+// expandEnv would change them.
+func hasEnvReferences(contents []byte) bool {
+	expanded, err := expandEnv(contents)
+	return err != nil || !bytes.Equal(expanded, contents)
+}
+
 // findConfigs gets the paths for configuration file(s) by searching through
 // any environment overrides and through the specified config search paths.
 //
@@ -2,10 +2,12 @@ package sdk
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-sdk/internal/test"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
 )
 
 // TestIsValidConfig tests validating that a file is a potential config file.
@@ -278,6 +280,112 @@ func TestUnmarshalConfigFile6(t *testing.T) {
 	assert.Nil(t, config.Board)
 }
 
+// Test_unmarshalConfigFile_Anchors tests unmarshalling a device config which uses
+// anchors, aliases, and merge keys to share config blocks.
+func Test_unmarshalConfigFile_Anchors(t *testing.T) {
+	// Set up a temporary directory for test data.
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	// Data to write to file
+	data := `
+version: 1.0
+shared:
+  data: &data
+    address: 10.0.0.1
+    port: 5000
+  outputs: &outputs
+    - type: temperature
+      info: shared output
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: temperature
+    instances:
+      - info: temp 1
+        location: r1b1
+        data:
+          <<: *data
+          id: 1
+        outputs: *outputs
+      - info: temp 2
+        location: r1b1
+        data:
+          <<: *data
+          id: 2
+          port: 5001
+`
+
+	// Add data to the temporary test directory
+	filename := test.WriteTempFile(t, "foo.yml", data, 0666)
+
+	config := &DeviceConfig{}
+	err := unmarshalConfigFile(filename, config)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "1.0", config.Version)
+	assert.Equal(t, 1, len(config.Devices))
+
+	instances := config.Devices[0].Instances
+	assert.Equal(t, 2, len(instances))
+	assert.Equal(t, map[string]interface{}{"address": "10.0.0.1", "port": 5000, "id": 1}, instances[0].Data)
+	assert.Equal(t, 1, len(instances[0].Outputs))
+	assert.Equal(t, "temperature", instances[0].Outputs[0].Type)
+	assert.Equal(t, "shared output", instances[0].Outputs[0].Info)
+
+	// The second instance overrides a merged value.
+	assert.Equal(t, map[string]interface{}{"address": "10.0.0.1", "port": 5001, "id": 2}, instances[1].Data)
+	assert.Empty(t, instances[1].Outputs)
+}
+
+// Test_unmarshalConfigFile_MergeList tests unmarshalling a config which merges
+// a list of anchored blocks. Earlier blocks in the list take precedence.
+func Test_unmarshalConfigFile_MergeList(t *testing.T) {
+	// Set up a temporary directory for test data.
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	// Data to write to file
+	data := `
+version: 1.0
+base: &base
+  interval: 2s
+  buffer: 50
+slow: &slow
+  interval: 10s
+  max: 20
+settings:
+  read:
+    <<: [*base, *slow]
+  write:
+    <<: [*slow, *base]
+    buffer: 200
+`
+
+	// Add data to the temporary test directory
+	filename := test.WriteTempFile(t, "config.yml", data, 0666)
+
+	config, err := NewDefaultPluginConfig()
+	assert.NoError(t, err)
+	err = unmarshalConfigFile(filename, config)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "1.0", config.Version)
+	assert.Equal(t, "2s", config.Settings.Read.Interval)
+	assert.Equal(t, 50, config.Settings.Read.Buffer)
+	assert.Equal(t, "10s", config.Settings.Write.Interval)
+	assert.Equal(t, 20, config.Settings.Write.Max)
+	assert.Equal(t, 200, config.Settings.Write.Buffer)
+
+	// Settings which are not merged keep their defaults.
+	assert.Equal(t, "serial", config.Settings.Mode)
+	assert.True(t, config.Settings.Read.Enabled)
+}
+
 // Test_findConfigs_Env1 tests getting the filepaths for config files when the override
 // environment variable is specified, but no configs are found in that path.
 func Test_findConfigs_Env1(t *testing.T) {
@@ -877,3 +985,242 @@ version: "1.0"
 	cfg = fooCtx.Config.(*OutputType)
 	assert.Equal(t, "1.0", cfg.Version)
 }
+
+// Test_readConfigStream tests reading a multi-document config stream and routing
+// each document to the correct config type.
+func Test_readConfigStream(t *testing.T) {
+	data := `
+version: "1.0"
+debug: true
+network:
+  type: tcp
+  address: ":5001"
+---
+version: "1.0"
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: temperature
+    instances:
+      - info: temp 1
+        location: r1b1
+---
+---
+version: "1.0"
+name: temperature
+precision: 2
+`
+	stream, err := readConfigStream(strings.NewReader(data), "test")
+	assert.NoError(t, err)
+
+	assert.NotNil(t, stream.plugin)
+	assert.Equal(t, "test[0]", stream.plugin.Source)
+	assert.True(t, stream.plugin.IsPluginConfig())
+	pluginCfg := stream.plugin.Config.(*PluginConfig)
+	assert.True(t, pluginCfg.Debug)
+	assert.Equal(t, ":5001", pluginCfg.Network.Address)
+	assert.NotNil(t, pluginCfg.Settings)
+
+	assert.Equal(t, 1, len(stream.devices))
+	assert.Equal(t, "test[1]", stream.devices[0].Source)
+	assert.True(t, stream.devices[0].IsDeviceConfig())
+	deviceCfg := stream.devices[0].Config.(*DeviceConfig)
+	assert.Equal(t, "1.0", deviceCfg.Version)
+	assert.Equal(t, "r1b1", deviceCfg.Locations[0].Name)
+	assert.Equal(t, "temperature", deviceCfg.Devices[0].Name)
+
+	assert.Equal(t, 1, len(stream.outputTypes))
+	assert.Equal(t, "test[3]", stream.outputTypes[0].Source)
+	assert.True(t, stream.outputTypes[0].IsOutputTypeConfig())
+	outputCfg := stream.outputTypes[0].Config.(*OutputType)
+	assert.Equal(t, "temperature", outputCfg.Name)
+	assert.Equal(t, 2, outputCfg.Precision)
+}
+
+// Test_readConfigStreamError tests reading config streams which are not valid.
+func Test_readConfigStreamError(t *testing.T) {
+	var testTable = []struct {
+		desc string
+		data string
+	}{
+		{
+			desc: "unknown document type",
+			data: "version: \"1.0\"\nfoo: bar\n",
+		},
+		{
+			desc: "multiple plugin configs",
+			data: "version: \"1.0\"\ndebug: true\n---\nversion: \"1.0\"\ndebug: false\n",
+		},
+		{
+			desc: "invalid yaml",
+			data: "version: \"1.0\"\n---\n[foo\n",
+		},
+		{
+			desc: "document does not match its config type",
+			data: "version: \"1.0\"\ndevices: foo\n",
+		},
+	}
+
+	for _, testCase := range testTable {
+		_, err := readConfigStream(strings.NewReader(testCase.data), "test")
+		assert.Error(t, err, testCase.desc)
+	}
+}
+
+// Test_readConfigStreamEnv tests reading a config stream which references
+// environment variables.
+func Test_readConfigStreamEnv(t *testing.T) {
+	test.SetEnv(t, "SDK_TEST_HOST", "10.1.2.3")
+	defer test.RemoveEnv(t, "SDK_TEST_HOST")
+
+	data := `
+version: "1.0"
+devices:
+  - name: foo
+    instances:
+      - info: ${SDK_TEST_HOST}:5000
+`
+	stream, err := readConfigStream(strings.NewReader(data), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stream.devices))
+	deviceCfg := stream.devices[0].Config.(*DeviceConfig)
+	assert.Equal(t, "10.1.2.3:5000", deviceCfg.Devices[0].Instances[0].Info)
+
+	_, err = readConfigStream(strings.NewReader("version: ${SDK_TEST_UNSET}"), "test")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SDK_TEST_UNSET")
+}
+
+// TestGetConfigsFromStdin tests getting each config type from stdin when the
+// plugin is run with the --config-stdin flag.
+func TestGetConfigsFromStdin(t *testing.T) {
+	flagConfigStdin = true
+	configStdin = strings.NewReader(`
+version: "1.0"
+name: temperature
+---
+version: "1.0"
+devices:
+  - name: temperature
+`)
+	defer func() {
+		flagConfigStdin = false
+		configStdin = os.Stdin
+		stdinConfigs = nil
+	}()
+
+	outputCtxs, err := getOutputTypeConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(outputCtxs))
+	assert.Equal(t, "stdin[0]", outputCtxs[0].Source)
+
+	// The stream should only be read once, so subsequent lookups use the
+	// cached configs.
+	deviceCtxs, err := getDeviceConfigsFromFile()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(deviceCtxs))
+	assert.Equal(t, "stdin[1]", deviceCtxs[0].Source)
+
+	// No plugin config was given, so it should not be found.
+	pluginCtx, err := getPluginConfigFromFile()
+	assert.Nil(t, pluginCtx)
+	assert.IsType(t, &errors.ConfigsNotFound{}, err)
+}
+
+// Test_unmarshalConfigFile_Env tests unmarshalling a config which references
+// environment variables.
+func Test_unmarshalConfigFile_Env(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	test.SetEnv(t, "SDK_TEST_HOST", "10.1.2.3")
+	defer test.RemoveEnv(t, "SDK_TEST_HOST")
+
+	data := `
+version: 1.0
+devices:
+  - name: foo
+    instances:
+      - info: ${SDK_TEST_HOST}:5000
+        data:
+          password: pa$$word
+          price: $5
+`
+	filename := test.WriteTempFile(t, "foo.yml", data, 0666)
+
+	config := &DeviceConfig{}
+	err := unmarshalConfigFile(filename, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.1.2.3:5000", config.Devices[0].Instances[0].Info)
+	assert.Equal(t, "pa$word", config.Devices[0].Instances[0].Data["password"])
+	assert.Equal(t, "$5", config.Devices[0].Instances[0].Data["price"])
+}
+
+// Test_unmarshalConfigFile_EnvUnset tests unmarshalling a config which references
+// an environment variable that is not set.
+func Test_unmarshalConfigFile_EnvUnset(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	filename := test.WriteTempFile(t, "foo.yml", "version: ${SDK_TEST_UNSET}", 0666)
+
+	config := &DeviceConfig{}
+	err := unmarshalConfigFile(filename, config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SDK_TEST_UNSET")
+	assert.Contains(t, err.Error(), filename)
+}
+
+// Test_expandEnv tests expanding environment variable references.
+func Test_expandEnv(t *testing.T) {
+	test.SetEnv(t, "SDK_TEST_A", "a")
+	defer test.RemoveEnv(t, "SDK_TEST_A")
+	test.SetEnv(t, "SDK_TEST_EMPTY", "")
+	defer test.RemoveEnv(t, "SDK_TEST_EMPTY")
+
+	var tests = []struct {
+		contents string
+		expected string
+	}{
+		{"", ""},
+		{"foo", "foo"},
+		{"${SDK_TEST_A}", "a"},
+		{"x${SDK_TEST_A}y${SDK_TEST_A}", "xaya"},
+		{"[${SDK_TEST_EMPTY}]", "[]"},
+		{"$$", "$"},
+		{"$${SDK_TEST_A}", "${SDK_TEST_A}"},
+		{"$$${SDK_TEST_A}", "$a"},
+		{"$SDK_TEST_A", "$SDK_TEST_A"},
+		{"cost: $", "cost: $"},
+	}
+
+	for _, tt := range tests {
+		expanded, err := expandEnv([]byte(tt.contents))
+		assert.NoError(t, err, tt.contents)
+		assert.Equal(t, tt.expected, string(expanded), tt.contents)
+	}
+}
+
+// Test_expandEnv_Error tests expanding invalid or unset environment variable references.
+func Test_expandEnv_Error(t *testing.T) {
+	var tests = []struct {
+		contents string
+		err      string
+	}{
+		{"${SDK_TEST_UNSET}", "environment variable(s) not set: SDK_TEST_UNSET"},
+		{"${SDK_TEST_UNSET} ${SDK_TEST_UNSET2}", "environment variable(s) not set: SDK_TEST_UNSET, SDK_TEST_UNSET2"},
+		{"${}", "invalid environment variable reference: ${}"},
+		{"${1FOO}", "invalid environment variable reference: ${1FOO}"},
+		{"${FOO BAR}", "invalid environment variable reference: ${FOO BAR}"},
+		{"foo: ${FOO", "unterminated environment variable reference at offset 5"},
+	}
+
+	for _, tt := range tests {
+		_, err := expandEnv([]byte(tt.contents))
+		assert.EqualError(t, err, tt.err, tt.contents)
+	}
+}
@@ -0,0 +1,243 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// recordedRead is a ReadContext as it is stored in a recording of readings.
+// Recordings are stored as JSON, one recorded read per line.
+type recordedRead struct {
+	// Offset is the time at which the readings were recorded, relative to the
+	// start of the recording.
+	Offset time.Duration `json:"offset"`
+
+	Rack     string             `json:"rack"`
+	Board    string             `json:"board"`
+	Device   string             `json:"device"`
+	Readings []*recordedReading `json:"readings"`
+}
+
+// recordedReading is a Reading as it is stored in a recording of readings. Along
+// with the reading, the Go type of its value is recorded, so that the value has
+// the same type when it is replayed.
+type recordedReading struct {
+	*Reading
+
+	ValueType string `json:"valueType,omitempty"`
+}
+
+// newRecordedRead creates the recordedRead for a ReadContext.
+func newRecordedRead(offset time.Duration, reading *ReadContext) *recordedRead {
+	record := &recordedRead{
+		Offset: offset,
+		Rack:   reading.Rack,
+		Board:  reading.Board,
+		Device: reading.Device,
+	}
+	for _, r := range reading.Reading {
+		record.Readings = append(record.Readings, &recordedReading{
+			Reading:   r,
+			ValueType: recordedValueType(r.Value),
+		})
+	}
+	return record
+}
+
+// readContext gets the ReadContext for the recordedRead, restoring the types of
+// its reading values.
+func (record *recordedRead) readContext() (*ReadContext, error) {
+	readCtx := &ReadContext{
+		Rack:   record.Rack,
+		Board:  record.Board,
+		Device: record.Device,
+	}
+	for _, r := range record.Readings {
+		reading := *r.Reading
+		value, err := restoreValueType(reading.Value, r.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		reading.Value = value
+		readCtx.Reading = append(readCtx.Reading, &reading)
+	}
+	return readCtx, nil
+}
+
+// recordedValueTypes are the types of reading values which are restored when a
+// recording is replayed, keyed by type name.
+var recordedValueTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, value := range []interface{}{
+		false, "", []byte{},
+		int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		float32(0), float64(0),
+	} {
+		t := reflect.TypeOf(value)
+		recordedValueTypes[t.String()] = t
+	}
+}
+
+// recordedValueType gets the name of the type of a reading value, if it is one
+// of the types that can be restored when a recording is replayed. Otherwise, an
+// empty string is returned, and the value is replayed as it is decoded from JSON.
+func recordedValueType(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	name := reflect.TypeOf(value).String()
+	if _, ok := recordedValueTypes[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// restoreValueType converts a reading value decoded from a recording back to its
+// recorded type. Numbers are decoded as json.Number so that they can be restored
+// without any loss of precision.
+func restoreValueType(value interface{}, valueType string) (interface{}, error) {
+	if value == nil || valueType == "" {
+		return value, nil
+	}
+	t, ok := recordedValueTypes[valueType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported recorded value type: %s", valueType)
+	}
+
+	s := fmt.Sprint(value)
+	var result interface{}
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		result, err = strconv.ParseBool(s)
+	case reflect.String:
+		result = s
+	case reflect.Slice:
+		result, err = base64.StdEncoding.DecodeString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result, err = strconv.ParseInt(s, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result, err = strconv.ParseUint(s, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		result, err = strconv.ParseFloat(s, t.Bits())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore recorded %s value %v: %v", valueType, value, err)
+	}
+	return reflect.ValueOf(result).Convert(t).Interface(), nil
+}
+
+// readingRecorder records the readings made by the plugin to a file, so they can
+// later be replayed (see the --replay flag).
+type readingRecorder struct {
+	lock    sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	start   time.Time
+	closed  bool
+}
+
+// newReadingRecorder creates a readingRecorder which records readings to the file
+// at the given path. If the file exists, it is truncated.
+func newReadingRecorder(path string) (*readingRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &readingRecorder{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		start:   clock.Now(),
+	}, nil
+}
+
+// record records the readings from the given ReadContext. This is safe to call
+// from multiple goroutines. Once the recorder is closed, readings are no longer
+// recorded.
+func (recorder *readingRecorder) record(reading *ReadContext) error {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if recorder.closed {
+		return nil
+	}
+	return recorder.encoder.Encode(newRecordedRead(clock.Now().Sub(recorder.start), reading))
+}
+
+// close closes the recording file. Closing a closed recorder does nothing.
+func (recorder *readingRecorder) close() error {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	if recorder.closed {
+		return nil
+	}
+	recorder.closed = true
+	return recorder.file.Close()
+}
+
+// loadRecording loads the recorded reads from the recording file at the given path.
+func loadRecording(path string) ([]*recordedRead, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []*recordedRead
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+
+		record := &recordedRead{}
+		if err := decoder.Decode(record); err != nil {
+			return nil, fmt.Errorf("%s:%d -> %v", path, line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// replay passes the given recorded reads to the read channel, in place of the
+// readings from device reads. The reads are replayed at the cadence they were
+// recorded at, sped up by the given factor, e.g. a speed of 2 replays them
+// twice as fast.
+func (manager *dataManager) replay(records []*recordedRead, speed float64) {
+	var previous time.Duration
+	for _, record := range records {
+		if wait := record.Offset - previous; wait > 0 {
+			clock.Sleep(time.Duration(float64(wait) / speed))
+		}
+		previous = record.Offset
+
+		readCtx, err := record.readContext()
+		if err != nil {
+			log.WithField("error", err).Error("[replay] failed to replay recorded read")
+			continue
+		}
+		if ctx.getDevice(readCtx.ID()) == nil {
+			log.WithField("device", readCtx.ID()).Warn("[replay] skipping recorded read for unknown device")
+			continue
+		}
+		manager.readChannel <- readCtx
+	}
+	log.WithField("reads", len(records)).Info("[replay] finished replaying recorded reads")
+}
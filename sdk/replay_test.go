@@ -0,0 +1,231 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+)
+
+// recordingReads are the reads used to test recording and replaying readings.
+// They cover each type of reading value that is restored on replay.
+var recordingReads = []*ReadContext{
+	{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+		Reading: []*Reading{
+			{Timestamp: "t1", Type: "temperature", Unit: Unit{Name: "celsius", Symbol: "C"}, Value: 21.5},
+			{Timestamp: "t1", Type: "count", Value: int16(-12)},
+			{Timestamp: "t1", Type: "big", Value: uint64(18446744073709551615)},
+		},
+	},
+	{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+		Reading: []*Reading{
+			{Timestamp: "t2", Type: "state", Value: "on", Context: map[string]string{"source": "test"}},
+			{Timestamp: "t2", Type: "raw", Value: []byte{0x01, 0x02}},
+			{Timestamp: "t2", Type: "ratio", Value: float32(0.25)},
+		},
+	},
+	{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+		Reading: []*Reading{
+			{Timestamp: "t3", Type: "flag", Value: true},
+			{Timestamp: "t3", Type: "missing", Value: nil},
+			{Timestamp: "t3", Type: "id", Value: int64(9007199254740993)},
+		},
+	},
+}
+
+// Test_recordAndReplay tests recording a sequence of readings, then replaying them.
+func Test_recordAndReplay(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		resetContext()
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := filepath.Join(test.TempDir, "readings.jsonl")
+	recorder, err := newReadingRecorder(path)
+	assert.NoError(t, err)
+
+	// Record the reads at offsets of 0s, 1s, and 3s.
+	for i, offset := range []time.Duration{0, time.Second, 2 * time.Second} {
+		c.Advance(offset)
+		assert.NoError(t, recorder.record(recordingReads[i]))
+	}
+	assert.NoError(t, recorder.close())
+
+	records, err := loadRecording(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, 3*time.Second, records[2].Offset)
+
+	// Replay the reads at twice the recorded speed.
+	ctx.devices["rack-board-device"] = &Device{id: "device", Location: &Location{Rack: "rack", Board: "board"}}
+	manager := newDataManager()
+	manager.readChannel = make(chan *ReadContext, 10)
+
+	c = useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	manager.replay(records, 2)
+	close(manager.readChannel)
+
+	var replayed []*ReadContext
+	for readCtx := range manager.readChannel {
+		replayed = append(replayed, readCtx)
+	}
+	assert.Equal(t, recordingReads, replayed)
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, c.Sleeps())
+}
+
+// Test_replay_UnknownDevice tests that recorded reads for unknown devices are not replayed.
+func Test_replay_UnknownDevice(t *testing.T) {
+	defer resetContext()
+
+	manager := newDataManager()
+	manager.readChannel = make(chan *ReadContext, 10)
+	manager.replay([]*recordedRead{newRecordedRead(0, recordingReads[0])}, 1)
+	assert.Empty(t, manager.readChannel)
+}
+
+// Test_dataManager_updateReadings_Record tests that readings are recorded as the
+// data manager's reading state is updated.
+func Test_dataManager_updateReadings_Record(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{Cache: &CacheSettings{}},
+	}
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := filepath.Join(test.TempDir, "readings.jsonl")
+	recorder, err := newReadingRecorder(path)
+	assert.NoError(t, err)
+
+	manager := newDataManager()
+	manager.recorder = recorder
+	manager.updateReadings(recordingReads[0])
+	assert.NoError(t, recorder.close())
+
+	records, err := loadRecording(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(records))
+
+	readCtx, err := records[0].readContext()
+	assert.NoError(t, err)
+	assert.Equal(t, recordingReads[0], readCtx)
+}
+
+// TestPlugin_shutdownRecording tests that the recording is closed when the
+// plugin shuts down, and that no readings are recorded after it is closed.
+func TestPlugin_shutdownRecording(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{Cache: &CacheSettings{}, ShutdownTimeout: "1s"},
+	}
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := filepath.Join(test.TempDir, "readings.jsonl")
+	recorder, err := newReadingRecorder(path)
+	assert.NoError(t, err)
+	DataManager = newDataManager()
+	DataManager.recorder = recorder
+	DataManager.updateReadings(recordingReads[0])
+
+	plugin := NewPlugin()
+	plugin.server = newServer(networkTypeTCP, "localhost:5001")
+	assert.Equal(t, 0, plugin.shutdown(os.Interrupt))
+	assert.True(t, recorder.closed)
+
+	DataManager.updateReadings(recordingReads[1])
+	assert.NoError(t, recorder.close())
+
+	records, err := loadRecording(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(records))
+}
+
+// Test_dataManager_setup_Replay tests setting up the data manager to replay a recording.
+func Test_dataManager_setup_Replay(t *testing.T) {
+	defer func() {
+		Config.reset()
+		flagReplay = ""
+		flagReplaySpeed = 1
+	}()
+
+	cfg, err := NewDefaultPluginConfig()
+	assert.NoError(t, err)
+	Config.Plugin = cfg
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := test.WriteTempFile(t, "readings.jsonl", `{"offset":0,"rack":"r","board":"b","device":"d","readings":[]}`+"\n\n", os.ModePerm)
+	flagReplay = path
+
+	manager := newDataManager()
+	assert.NoError(t, manager.setup())
+	assert.Equal(t, 1, len(manager.recording))
+
+	flagReplaySpeed = 0
+	assert.Error(t, newDataManager().setup())
+
+	flagReplaySpeed = 1
+	flagReplay = filepath.Join(test.TempDir, "nonexistent.jsonl")
+	assert.Error(t, newDataManager().setup())
+}
+
+// Test_loadRecording_Error tests loading a recording which is not valid.
+func Test_loadRecording_Error(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	path := test.WriteTempFile(t, "readings.jsonl", "{\"offset\":0}\nnot json\n", os.ModePerm)
+	_, err := loadRecording(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "readings.jsonl:2")
+}
+
+// Test_restoreValueType_Error tests restoring recorded values which can not be restored.
+func Test_restoreValueType_Error(t *testing.T) {
+	_, err := restoreValueType("1", "complex128")
+	assert.Error(t, err)
+
+	_, err = restoreValueType("300", "uint8")
+	assert.Error(t, err)
+
+	_, err = restoreValueType("!", "[]uint8")
+	assert.Error(t, err)
+}
+
+// Test_newReadingRecorder_Error tests creating a recorder for a file which can not be created.
+func Test_newReadingRecorder_Error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recording")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = newReadingRecorder(filepath.Join(dir, "missing", "readings.jsonl"))
+	assert.Error(t, err)
+}
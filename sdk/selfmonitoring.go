@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// selfMonitoringHandler is the device handler for the plugin's self-monitoring
+// devices. It reads the plugin's own internal counters, so they are surfaced
+// through the same gRPC API as the readings of the plugin's real devices.
+var selfMonitoringHandler = &DeviceHandler{
+	Name: "synse.plugin.self",
+	Read: readSelfMonitoringDevice,
+}
+
+// selfMonitoringOutputTypes are the output types of the self-monitoring devices.
+// The name of each is also the kind of the device which outputs it.
+var selfMonitoringOutputTypes = []*OutputType{
+	{Name: "synse.plugin.reads", Unit: Unit{Name: "reads"}},
+	{Name: "synse.plugin.read.errors", Unit: Unit{Name: "errors"}},
+	{Name: "synse.plugin.uptime", Unit: Unit{Name: "seconds", Symbol: "s"}},
+}
+
+// selfMonitoringDeviceIDs are the device IDs of the self-monitoring devices, by
+// kind. The IDs are fixed, rather than generated from the device data, so they
+// are stable and do not depend on the plugin's device identifier.
+var selfMonitoringDeviceIDs = map[string]string{
+	"synse.plugin.reads":       "reads",
+	"synse.plugin.read.errors": "read-errors",
+	"synse.plugin.uptime":      "uptime",
+}
+
+// makeSelfMonitoringDevices creates the plugin's self-monitoring devices, one for
+// each of the plugin's internal counters, at the configured location.
+func makeSelfMonitoringDevices(settings *SelfMonitoringSettings) []*Device {
+	location := &Location{
+		Rack:  settings.GetRack(),
+		Board: settings.Board,
+	}
+	if location.Board == "" {
+		location.Board = metainfo.Name
+	}
+
+	var devices []*Device
+	for _, outputType := range selfMonitoringOutputTypes {
+		devices = append(devices, &Device{
+			Kind:     outputType.Name,
+			Plugin:   metainfo.Name,
+			Info:     fmt.Sprintf("plugin %s", outputType.Unit.Name),
+			Location: location,
+			Outputs:  []*Output{{OutputType: *outputType}},
+			Handler:  selfMonitoringHandler,
+			id:       selfMonitoringDeviceIDs[outputType.Name],
+		})
+	}
+	return devices
+}
+
+// registerSelfMonitoringDevices registers the plugin's self-monitoring devices and
+// their output types, if self-monitoring is enabled in the plugin configuration.
+func registerSelfMonitoringDevices() {
+	settings := Config.Plugin.SelfMonitoring
+	if settings == nil || !settings.Enabled {
+		return
+	}
+
+	for _, outputType := range selfMonitoringOutputTypes {
+		if _, hasType := ctx.outputTypes[outputType.Name]; !hasType {
+			ctx.outputTypes[outputType.Name] = outputType
+		}
+	}
+	devices := makeSelfMonitoringDevices(settings)
+	log.Debugf("[sdk] adding %d self-monitoring devices", len(devices))
+	updateDeviceMap(devices)
+}
+
+// readSelfMonitoringDevice is the read function for the self-monitoring devices.
+// It reads the current value of the internal counter for the device's kind. Reads
+// of the self-monitoring devices are themselves counted as device reads.
+func readSelfMonitoringDevice(device *Device) ([]*Reading, error) {
+	var value interface{}
+	switch device.Kind {
+	case "synse.plugin.reads":
+		value = atomic.LoadInt64(&metrics.reads)
+	case "synse.plugin.read.errors":
+		value = atomic.LoadInt64(&metrics.readErrors)
+	case "synse.plugin.uptime":
+		value = clock.Now().Sub(metrics.start).Seconds()
+	default:
+		return nil, fmt.Errorf("unknown self-monitoring device kind: %s", device.Kind)
+	}
+
+	reading, err := device.GetOutput(device.Kind).MakeReading(value)
+	if err != nil {
+		return nil, err
+	}
+	return []*Reading{reading}, nil
+}
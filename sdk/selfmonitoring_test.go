@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+	"github.com/vapor-ware/synse-sdk/sdk/policies"
+)
+
+// Test_registerSelfMonitoringDevices_Disabled tests that no self-monitoring devices
+// are registered if self-monitoring is not enabled.
+func Test_registerSelfMonitoringDevices_Disabled(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+	Config.Plugin = &PluginConfig{
+		SelfMonitoring: &SelfMonitoringSettings{Rack: "synse"},
+	}
+
+	registerSelfMonitoringDevices()
+	assert.Empty(t, ctx.devices)
+	assert.Empty(t, ctx.outputTypes)
+}
+
+// Test_registerSelfMonitoringDevices tests registering the self-monitoring devices
+// alongside the devices from config.
+func Test_registerSelfMonitoringDevices(t *testing.T) {
+	defer func() {
+		metainfo = meta{}
+		resetContext()
+		Config.reset()
+	}()
+	metainfo.Name = "test-plugin"
+	Config.Plugin = &PluginConfig{
+		SchemeVersion:       SchemeVersion{Version: "test"},
+		DynamicRegistration: &DynamicRegistrationSettings{},
+		SelfMonitoring:      &SelfMonitoringSettings{Enabled: true, Rack: "synse"},
+	}
+	Config.Device = &DeviceConfig{
+		Devices: []*DeviceKind{},
+	}
+
+	err := registerDevices()
+	assert.NoError(t, err)
+	assert.Len(t, ctx.devices, 3)
+	for _, guid := range []string{
+		"synse-test-plugin-reads",
+		"synse-test-plugin-read-errors",
+		"synse-test-plugin-uptime",
+	} {
+		device, ok := ctx.devices[guid]
+		assert.True(t, ok, guid)
+		assert.True(t, device.IsReadable(), guid)
+	}
+	assert.Len(t, ctx.outputTypes, 3)
+
+	// The self-monitoring devices are wired correctly.
+	var plugin Plugin
+	assert.NoError(t, plugin.SelfTest())
+}
+
+// Test_registerSelfMonitoringDevices_ConfigFile tests registering the self-monitoring
+// devices when they are enabled in a plugin config file which does not set their
+// location, so the default location is used.
+func Test_registerSelfMonitoringDevices_ConfigFile(t *testing.T) {
+	test.SetEnv(t, EnvPluginConfig, "testdata/plugin/selfmonitoring/config.yml")
+	defer func() {
+		test.RemoveEnv(t, EnvPluginConfig)
+		metainfo = meta{}
+		resetContext()
+		policies.Clear()
+		Config.reset()
+	}()
+	metainfo.Name = "test-plugin"
+
+	assert.NoError(t, processPluginConfig())
+	assert.Equal(t, "", Config.Plugin.SelfMonitoring.Rack)
+
+	devices := makeSelfMonitoringDevices(Config.Plugin.SelfMonitoring)
+	assert.Len(t, devices, 3)
+	for _, device := range devices {
+		assert.Equal(t, "synse", device.Location.Rack)
+		assert.Equal(t, "test-plugin", device.Location.Board)
+	}
+}
+
+// TestReadSelfMonitoringDevice tests that the readings of the self-monitoring devices
+// reflect the plugin's internal counters.
+func TestReadSelfMonitoringDevice(t *testing.T) {
+	defer func() {
+		metrics = newPluginMetrics()
+		clock = realClock{}
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	metrics = newPluginMetrics()
+
+	devices := makeSelfMonitoringDevices(&SelfMonitoringSettings{Rack: "synse", Board: "board"})
+	assert.Len(t, devices, 3)
+
+	// Record some reads (through the data manager) and let time pass.
+	d := dataManager{readChannel: make(chan *ReadContext, 10)}
+	location := &Location{Rack: "rack", Board: "board"}
+	for i := 0; i < 3; i++ {
+		d.readOne(context.Background(), &Device{id: "1", Location: location, Handler: &DeviceHandler{
+			Read: func(_ *Device) ([]*Reading, error) { return []*Reading{}, nil },
+		}})
+	}
+	d.readOne(context.Background(), &Device{id: "2", Location: location, Handler: &DeviceHandler{
+		Read: func(_ *Device) ([]*Reading, error) { return nil, fmt.Errorf("test error") },
+	}})
+	c.Advance(90 * time.Second)
+
+	expected := map[string]interface{}{
+		"synse.plugin.reads":       int64(4),
+		"synse.plugin.read.errors": int64(1),
+		"synse.plugin.uptime":      float64(90),
+	}
+	for _, device := range devices {
+		readings, err := readSelfMonitoringDevice(device)
+		assert.NoError(t, err)
+		assert.Len(t, readings, 1)
+		assert.Equal(t, expected[device.Kind], readings[0].Value, device.Kind)
+		assert.Equal(t, device.Outputs[0].Unit, readings[0].Unit)
+	}
+
+	// Reads of the self-monitoring devices are counted too.
+	d.readOne(context.Background(), devices[0])
+	readCtx := <-d.readChannel
+	for len(d.readChannel) > 0 {
+		readCtx = <-d.readChannel
+	}
+	assert.Equal(t, "reads", readCtx.Device)
+	assert.Equal(t, int64(4), readCtx.Reading[0].Value)
+	assert.Equal(t, int64(5), metrics.reads)
+}
+
+// TestReadSelfMonitoringDevice_Unknown tests reading a self-monitoring device of an
+// unknown kind.
+func TestReadSelfMonitoringDevice_Unknown(t *testing.T) {
+	_, err := readSelfMonitoringDevice(&Device{Kind: "foo"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// SelfTest checks that the plugin's device handlers, devices, and output types
+// are wired together correctly. It checks that every registered device handler
+// is used by at least one device, that every device has a device handler, that
+// every device output resolves to a registered output type, and that every
+// registered output type is valid.
+//
+// All of the problems found are returned together in a MultiError. The self-test
+// is run at plugin startup, once devices are registered, so it can also be called
+// from a post-run action, or from a plugin's own tests.
+func (plugin *Plugin) SelfTest() error {
+	multiErr := errors.NewMultiError("plugin self-test")
+
+	for _, handler := range ctx.deviceHandlers {
+		if len(handler.getDevicesForHandler()) == 0 {
+			multiErr.Add(fmt.Errorf("device handler %q is not used by any devices", handler.Name))
+		}
+	}
+
+	var ids []string
+	for id := range ctx.getDevices() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		device := ctx.getDevice(id)
+		if device.Handler == nil {
+			multiErr.Add(fmt.Errorf("device %s (kind %s) has no device handler", id, device.Kind))
+		}
+		for _, output := range device.Outputs {
+			if _, ok := ctx.outputTypes[output.Name]; !ok {
+				multiErr.Add(fmt.Errorf("device %s (kind %s) output %q does not resolve to a registered output type", id, device.Kind, output.Name))
+			}
+		}
+	}
+
+	var names []string
+	for name := range ctx.outputTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		typeErr := errors.NewMultiError("output type")
+		typeErr.Context["source"] = name
+		ctx.outputTypes[name].Validate(typeErr)
+		for _, err := range typeErr.Errors {
+			multiErr.Add(fmt.Errorf("output type %q is invalid: %v", name, err))
+		}
+	}
+
+	return multiErr.Err()
+}
+
+// runSelfTest runs the plugin self-test at startup. If the plugin is configured
+// to require the self-test to pass, a failure is returned as an error; otherwise,
+// it is only logged.
+func (plugin *Plugin) runSelfTest() error {
+	err := plugin.SelfTest()
+	if err == nil {
+		log.Debug("[sdk] plugin self-test passed")
+		return nil
+	}
+	if Config.Plugin.Settings.RequireSelfTest {
+		return err
+	}
+	log.Warnf("[sdk] plugin self-test failed: %v", err)
+	return nil
+}
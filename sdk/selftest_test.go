@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupSelfTestPlugin is a test helper that registers a device handler, a device
+// which uses it, and the output type for the device's output.
+func setupSelfTestPlugin() *DeviceHandler {
+	handler := &DeviceHandler{Name: "temperature"}
+	ctx.deviceHandlers = []*DeviceHandler{handler}
+	ctx.outputTypes["temperature"] = &OutputType{Name: "temperature"}
+	ctx.devices["123"] = &Device{
+		id:      "123",
+		Kind:    "temperature",
+		Handler: handler,
+		Outputs: []*Output{{OutputType: OutputType{Name: "temperature"}}},
+	}
+	return handler
+}
+
+// TestPlugin_SelfTest tests the plugin self-test when everything is wired correctly.
+func TestPlugin_SelfTest(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+
+	err := NewPlugin().SelfTest()
+	assert.NoError(t, err)
+}
+
+// TestPlugin_SelfTest2 tests the plugin self-test when a device handler is not
+// used by any device.
+func TestPlugin_SelfTest2(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+	ctx.deviceHandlers = append(ctx.deviceHandlers, &DeviceHandler{Name: "unused"})
+
+	err := NewPlugin().SelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `device handler "unused" is not used by any devices`)
+}
+
+// TestPlugin_SelfTest3 tests the plugin self-test when a device has no handler.
+func TestPlugin_SelfTest3(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+	ctx.devices["456"] = &Device{id: "456", Kind: "led"}
+
+	err := NewPlugin().SelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "device 456 (kind led) has no device handler")
+}
+
+// TestPlugin_SelfTest4 tests the plugin self-test when a device output does not
+// resolve to a registered output type.
+func TestPlugin_SelfTest4(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+	ctx.devices["123"].Outputs = append(
+		ctx.devices["123"].Outputs,
+		&Output{OutputType: OutputType{Name: "humidity"}},
+	)
+
+	err := NewPlugin().SelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `device 123 (kind temperature) output "humidity" does not resolve to a registered output type`)
+}
+
+// TestPlugin_SelfTest5 tests the plugin self-test when a registered output type
+// is invalid.
+func TestPlugin_SelfTest5(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+	ctx.outputTypes["temperature"].ScalingFactor = "not-a-number"
+
+	err := NewPlugin().SelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `output type "temperature" is invalid`)
+}
+
+// TestPlugin_SelfTest6 tests that the plugin self-test reports all of the problems
+// it finds.
+func TestPlugin_SelfTest6(t *testing.T) {
+	defer resetContext()
+
+	setupSelfTestPlugin()
+	ctx.deviceHandlers = append(ctx.deviceHandlers, &DeviceHandler{Name: "unused"})
+	ctx.devices["456"] = &Device{id: "456", Kind: "led"}
+
+	err := NewPlugin().SelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `device handler "unused" is not used by any devices`)
+	assert.Contains(t, err.Error(), "device 456 (kind led) has no device handler")
+}
+
+// TestPlugin_runSelfTest tests running the self-test at startup when it fails and
+// the plugin does not require it to pass.
+func TestPlugin_runSelfTest(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireSelfTest: false},
+	}
+	ctx.devices["456"] = &Device{id: "456", Kind: "led"}
+
+	err := NewPlugin().runSelfTest()
+	assert.NoError(t, err)
+}
+
+// TestPlugin_runSelfTest2 tests running the self-test at startup when it fails and
+// the plugin requires it to pass.
+func TestPlugin_runSelfTest2(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireSelfTest: true},
+	}
+	ctx.devices["456"] = &Device{id: "456", Kind: "led"}
+
+	err := NewPlugin().runSelfTest()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "device 456 (kind led) has no device handler")
+}
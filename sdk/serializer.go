@@ -0,0 +1,205 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SerializerJSON is the name of the JSON reading serializer.
+	SerializerJSON = "json"
+
+	// SerializerInflux is the name of the InfluxDB line protocol reading serializer.
+	SerializerInflux = "influx"
+)
+
+// ReadingSerializer serializes readings into a format which can be exported
+// to systems other than Synse Server, e.g. time series databases or message
+// queues.
+type ReadingSerializer interface {
+	// Serialize serializes the given readings.
+	Serialize(readings []*Reading) ([]byte, error)
+}
+
+// NewReadingSerializer gets the built-in ReadingSerializer for the given
+// format name. The supported formats are "json" and "influx".
+func NewReadingSerializer(format string) (ReadingSerializer, error) {
+	switch strings.ToLower(format) {
+	case SerializerJSON:
+		return &JSONSerializer{}, nil
+	case SerializerInflux:
+		return &InfluxSerializer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported reading serialization format: %q", format)
+	}
+}
+
+// JSONSerializer is a ReadingSerializer which serializes readings as a JSON
+// array of reading objects.
+type JSONSerializer struct{}
+
+// jsonReading is the JSON representation of a Reading.
+type jsonReading struct {
+	Timestamp string            `json:"timestamp"`
+	Type      string            `json:"type"`
+	Info      string            `json:"info,omitempty"`
+	Unit      jsonUnit          `json:"unit"`
+	Value     interface{}       `json:"value"`
+	Context   map[string]string `json:"context,omitempty"`
+}
+
+// jsonUnit is the JSON representation of a Unit.
+type jsonUnit struct {
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// Serialize serializes the given readings as JSON.
+func (serializer *JSONSerializer) Serialize(readings []*Reading) ([]byte, error) {
+	out := make([]jsonReading, len(readings))
+	for i, reading := range readings {
+		out[i] = jsonReading{
+			Timestamp: reading.Timestamp,
+			Type:      reading.Type,
+			Info:      reading.Info,
+			Unit: jsonUnit{
+				Name:   reading.Unit.Name,
+				Symbol: reading.Unit.Symbol,
+			},
+			Value:   reading.Value,
+			Context: reading.Context,
+		}
+	}
+	return json.Marshal(out)
+}
+
+// InfluxSerializer is a ReadingSerializer which serializes readings using the
+// InfluxDB line protocol, one line per reading. The reading type is used as the
+// measurement, the reading unit, info, and context are used as tags, and the
+// reading value is the "value" field. The reading timestamp is written with
+// nanosecond precision.
+type InfluxSerializer struct{}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxStringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// Serialize serializes the given readings using the InfluxDB line protocol.
+func (serializer *InfluxSerializer) Serialize(readings []*Reading) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, reading := range readings {
+		line, err := influxLine(reading)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// influxLine gets the line protocol representation of a single reading.
+func influxLine(reading *Reading) (string, error) {
+	if reading.Type == "" {
+		return "", fmt.Errorf("reading has no type, can not get line protocol measurement")
+	}
+
+	value, err := influxFieldValue(reading.Value)
+	if err != nil {
+		return "", err
+	}
+
+	tags := map[string]string{}
+	for k, v := range reading.Context {
+		tags[k] = v
+	}
+	if reading.Info != "" {
+		tags["info"] = reading.Info
+	}
+	if reading.Unit.Symbol != "" {
+		tags["unit"] = reading.Unit.Symbol
+	}
+
+	// Tags should be sorted by key for the best write performance.
+	var keys []string
+	for k, v := range tags {
+		// Tags with empty values are not valid line protocol.
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var line strings.Builder
+	line.WriteString(influxMeasurementEscaper.Replace(reading.Type))
+	for _, k := range keys {
+		line.WriteString(",")
+		line.WriteString(influxTagEscaper.Replace(k))
+		line.WriteString("=")
+		line.WriteString(influxTagEscaper.Replace(tags[k]))
+	}
+	line.WriteString(" value=")
+	line.WriteString(value)
+
+	if reading.Timestamp != "" {
+		ts, err := ParseRFC3339Nano(reading.Timestamp)
+		if err != nil {
+			return "", err
+		}
+		line.WriteString(" ")
+		line.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+	}
+	return line.String(), nil
+}
+
+// influxFieldValue gets the line protocol representation of a reading value.
+func influxFieldValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return influxFloat(v)
+	case float32:
+		return influxFloat(float64(v))
+	case int:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10) + "u", nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10) + "u", nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10) + "u", nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10) + "u", nil
+	case uint64:
+		return strconv.FormatUint(v, 10) + "u", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return `"` + influxStringEscaper.Replace(v) + `"`, nil
+	default:
+		return "", fmt.Errorf("unsupported line protocol value type: %T", value)
+	}
+}
+
+// influxFloat gets the line protocol representation of a float value. NaN and
+// Inf are not supported by the line protocol.
+func influxFloat(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("unsupported line protocol value: %v", f)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleReadings are readings used for testing the reading serializers.
+var sampleReadings = []*Reading{
+	{
+		Timestamp: "2019-01-01T00:00:00.5Z",
+		Type:      "temperature",
+		Info:      "cpu temp",
+		Unit:      Unit{Name: "celsius", Symbol: "C"},
+		Value:     20.5,
+		Context:   map[string]string{"rack": "rack 1"},
+	},
+	{
+		Timestamp: "2019-01-01T00:00:01Z",
+		Type:      "state",
+		Value:     "on",
+	},
+}
+
+// TestNewReadingSerializer tests getting the built-in reading serializers.
+func TestNewReadingSerializer(t *testing.T) {
+	s, err := NewReadingSerializer("json")
+	assert.NoError(t, err)
+	assert.IsType(t, &JSONSerializer{}, s)
+
+	s, err = NewReadingSerializer("Influx")
+	assert.NoError(t, err)
+	assert.IsType(t, &InfluxSerializer{}, s)
+
+	s, err = NewReadingSerializer("avro")
+	assert.Error(t, err)
+	assert.Nil(t, s)
+}
+
+// TestJSONSerializer_Serialize tests serializing readings as JSON.
+func TestJSONSerializer_Serialize(t *testing.T) {
+	out, err := (&JSONSerializer{}).Serialize(sampleReadings)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`[{"timestamp":"2019-01-01T00:00:00.5Z","type":"temperature","info":"cpu temp",`+
+			`"unit":{"name":"celsius","symbol":"C"},"value":20.5,"context":{"rack":"rack 1"}},`+
+			`{"timestamp":"2019-01-01T00:00:01Z","type":"state","unit":{"name":"","symbol":""},"value":"on"}]`,
+		string(out),
+	)
+}
+
+// TestJSONSerializer_Serialize2 tests serializing no readings as JSON.
+func TestJSONSerializer_Serialize2(t *testing.T) {
+	out, err := (&JSONSerializer{}).Serialize(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(out))
+}
+
+// TestJSONSerializer_Serialize3 tests serializing readings as JSON when a value
+// can not be encoded.
+func TestJSONSerializer_Serialize3(t *testing.T) {
+	_, err := (&JSONSerializer{}).Serialize([]*Reading{{Type: "temperature", Value: math.NaN()}})
+	assert.Error(t, err)
+}
+
+// TestInfluxSerializer_Serialize tests serializing readings using the InfluxDB line protocol.
+func TestInfluxSerializer_Serialize(t *testing.T) {
+	out, err := (&InfluxSerializer{}).Serialize(sampleReadings)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`temperature,info=cpu\ temp,rack=rack\ 1,unit=C value=20.5 1546300800500000000`+"\n"+
+			`state value="on" 1546300801000000000`+"\n",
+		string(out),
+	)
+}
+
+// TestInfluxSerializer_Serialize2 tests serializing the supported value types using
+// the InfluxDB line protocol.
+func TestInfluxSerializer_Serialize2(t *testing.T) {
+	var tests = []struct {
+		value    interface{}
+		expected string
+	}{
+		{1.0, "t value=1\n"},
+		{float32(0.25), "t value=0.25\n"},
+		{-3, "t value=-3i\n"},
+		{int64(12), "t value=12i\n"},
+		{uint8(7), "t value=7u\n"},
+		{true, "t value=true\n"},
+		{`say "hi" \o/`, `t value="say \"hi\" \\o/"` + "\n"},
+	}
+
+	for _, test := range tests {
+		out, err := (&InfluxSerializer{}).Serialize([]*Reading{{Type: "t", Value: test.value}})
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.expected, string(out), test.value)
+	}
+}
+
+// TestInfluxSerializer_Serialize3 tests escaping the measurement and tags using
+// the InfluxDB line protocol.
+func TestInfluxSerializer_Serialize3(t *testing.T) {
+	out, err := (&InfluxSerializer{}).Serialize([]*Reading{{
+		Type:    "fan speed,rpm",
+		Value:   1,
+		Context: map[string]string{"a=b": "c,d", "empty": ""},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, `fan\ speed\,rpm,a\=b=c\,d value=1i`+"\n", string(out))
+}
+
+// TestInfluxSerializer_Serialize4 tests serializing readings which can not be
+// represented using the InfluxDB line protocol.
+func TestInfluxSerializer_Serialize4(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		reading *Reading
+	}{
+		{"no type", &Reading{Value: 1}},
+		{"nil value", &Reading{Type: "t"}},
+		{"NaN value", &Reading{Type: "t", Value: math.NaN()}},
+		{"Inf value", &Reading{Type: "t", Value: math.Inf(1)}},
+		{"bytes value", &Reading{Type: "t", Value: []byte{1, 2}}},
+		{"bad timestamp", &Reading{Type: "t", Value: 1, Timestamp: "foo"}},
+	}
+
+	for _, test := range tests {
+		_, err := (&InfluxSerializer{}).Serialize([]*Reading{test.reading})
+		assert.Error(t, err, test.desc)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net"
 	"os"
 	"strings"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
@@ -16,6 +18,7 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
 // server implements the Synse Plugin gRPC server. It is used by the
@@ -24,6 +27,10 @@ type server struct {
 	network string
 	address string
 	grpc    *grpc.Server
+
+	// plugin is the plugin that the server is run by. It is passed to the
+	// device setup actions of devices which are reloaded or added over gRPC.
+	plugin *Plugin
 }
 
 // newServer creates a new instance of a server. This should be used
@@ -110,22 +117,88 @@ func (server *server) Serve() error {
 	if err != nil {
 		return err
 	}
+	if streams := Config.Plugin.Network.MaxConcurrentStreams; streams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(streams))
+	}
+	err = setKeepaliveOption(&opts)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, grpc.StatsHandler(&clientTracker{manager: DataManager}))
 
 	// Create the listener over the configured network type and address.
-	lis, err := net.Listen(server.network, server.address)
+	lis, err := server.listen()
 	if err != nil {
-		return err
+		return errors.NewServerBindError(server.network, server.address, err)
 	}
 
 	// Create the grpc server instance, passing in any server options.
 	svr := grpc.NewServer(opts...)
 	synse.RegisterPluginServer(svr, server)
+	svr.RegisterService(&pluginFeaturesServiceDesc, server)
+	svr.RegisterService(&readingRangesServiceDesc, server)
+	svr.RegisterService(&deviceInventoryServiceDesc, server)
+	svr.RegisterService(&readingSnapshotServiceDesc, server)
+	svr.RegisterService(&readingBatchesServiceDesc, server)
+	svr.RegisterService(&readingHistoryServiceDesc, server)
+	svr.RegisterService(&configChecksumServiceDesc, server)
 	server.grpc = svr
 
 	log.Infof("[grpc] listening on %s:%s", server.network, server.address)
 	return svr.Serve(lis)
 }
 
+// listen creates the listener for the server over its network type and address.
+// If the address is in use, binding is retried with an exponential backoff until
+// the configured bind timeout has elapsed.
+func (server *server) listen() (net.Listener, error) {
+	timeout, err := Config.Plugin.Network.GetBindTimeout()
+	if err != nil {
+		return nil, err
+	}
+	deadline := clock.Now().Add(timeout)
+	backoff := bindRetryInitialBackoff
+
+	for {
+		lis, err := net.Listen(server.network, server.address)
+		if err == nil || !isAddrInUse(err) {
+			return lis, err
+		}
+
+		remaining := deadline.Sub(clock.Now())
+		if remaining <= 0 {
+			return nil, err
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+		log.WithFields(log.Fields{
+			"address": server.address,
+			"retry":   backoff,
+		}).Warn("[grpc] server address in use, retrying bind")
+		clock.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > bindRetryMaxBackoff {
+			backoff = bindRetryMaxBackoff
+		}
+	}
+}
+
+// isAddrInUse checks whether an error from creating a listener is because its
+// address is already in use.
+func isAddrInUse(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	sysErr, ok := opErr.Err.(*os.SyscallError)
+	if !ok {
+		return false
+	}
+	return sysErr.Err == syscall.EADDRINUSE
+}
+
 // setCredsOptions will add a credentials option to the server options slice, if the
 // plugin is configured to use TLS/SSL with gRPC.
 func setCredsOption(options *[]grpc.ServerOption) error {
@@ -198,6 +271,28 @@ func setCredsOption(options *[]grpc.ServerOption) error {
 	return nil
 }
 
+// setKeepaliveOption will add a keepalive parameters option to the server options
+// slice, if the plugin is configured to limit the idle time or age of client
+// connections. Closing connections periodically prompts clients to reconnect, so
+// they can be rebalanced across plugin instances behind a load balancer.
+func setKeepaliveOption(options *[]grpc.ServerOption) error {
+	params, err := Config.Plugin.Network.GetKeepaliveParameters()
+	if err != nil {
+		log.Errorf("[server] failed to get keepalive parameters: %v", err)
+		return err
+	}
+	if params == (keepalive.ServerParameters{}) {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"maxConnectionIdle":     params.MaxConnectionIdle,
+		"maxConnectionAge":      params.MaxConnectionAge,
+		"maxConnectionAgeGrace": params.MaxConnectionAgeGrace,
+	}).Debug("[server] configuring grpc server connection limits")
+	*options = append(*options, grpc.KeepaliveParams(params))
+	return nil
+}
+
 // loadCACerts loads the certs from the provided certificate authority/authorities.
 func loadCACerts(cacerts []string) (*x509.CertPool, error) {
 	certPool := x509.NewCertPool()
@@ -225,6 +320,32 @@ func (server *server) Stop() {
 	}
 }
 
+// GracefulStop stops the gRPC server gracefully. The server stops accepting new
+// connections and RPCs, and waits up to the given timeout for in-flight RPCs to
+// complete before it is stopped immediately. A timeout of 0 stops the server
+// immediately.
+func (server *server) GracefulStop(timeout time.Duration) {
+	if server.grpc == nil || timeout <= 0 {
+		server.Stop()
+		return
+	}
+
+	log.WithField("timeout", timeout).Info("[grpc] draining server connections")
+	done := make(chan struct{})
+	go func() {
+		server.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("[grpc] server stopped")
+	case <-time.After(timeout):
+		log.Warn("[grpc] timed out waiting for in-flight rpcs, stopping server")
+		server.grpc.Stop()
+	}
+}
+
 // Test is the handler for the Synse GRPC Plugin service's `Test` RPC method.
 func (server *server) Test(ctx context.Context, request *synse.Empty) (*synse.Status, error) {
 	log.WithField("request", request).Debug("[grpc] test rpc request")
@@ -242,38 +363,41 @@ func (server *server) Health(ctx context.Context, request *synse.Empty) (*synse.
 	log.WithField("request", request).Debug("[grpc] health rpc request")
 	statuses := health.GetStatus()
 
-	// First, we need to determine the overall health of the plugin.
-	// If all statuses are good, we are ok. If some are bad, we are partially
-	// degraded. If all are bad, we are failing.
-	// TODO: do we want partially degraded, or should we just consider it failing
+	var healthChecks []*synse.HealthCheck
+	for _, status := range statuses {
+		healthChecks = append(healthChecks, status.Encode())
+	}
+
+	return &synse.PluginHealth{
+		Timestamp: GetCurrentTime(),
+		Status:    pluginHealthStatus(statuses),
+		Checks:    healthChecks,
+	}, nil
+}
+
+// pluginHealthStatus determines the overall health of the plugin from the
+// statuses of its health checks. If all statuses are good, we are ok. If some
+// are bad, we are partially degraded. If all are bad, we are failing.
+// TODO: do we want partially degraded, or should we just consider it failing
+func pluginHealthStatus(statuses []*health.Status) synse.PluginHealth_Status {
 	total := len(statuses)
 	ok := 0
 	failing := 0
 
-	var healthChecks []*synse.HealthCheck
 	for _, status := range statuses {
 		if status.Ok {
 			ok++
 		} else {
 			failing++
 		}
-		healthChecks = append(healthChecks, status.Encode())
 	}
 
-	var pluginStatus synse.PluginHealth_Status
 	if total == ok {
-		pluginStatus = synse.PluginHealth_OK
+		return synse.PluginHealth_OK
 	} else if total == failing {
-		pluginStatus = synse.PluginHealth_FAILING
-	} else {
-		pluginStatus = synse.PluginHealth_PARTIALLY_DEGRADED
+		return synse.PluginHealth_FAILING
 	}
-
-	return &synse.PluginHealth{
-		Timestamp: GetCurrentTime(),
-		Status:    pluginStatus,
-		Checks:    healthChecks,
-	}, nil
+	return synse.PluginHealth_PARTIALLY_DEGRADED
 }
 
 // Capabilities is the handler for the Synse GRPC Plugin service's `Capabilities` RPC method.
@@ -281,7 +405,7 @@ func (server *server) Capabilities(request *synse.Empty, stream synse.Plugin_Cap
 	log.WithField("request", request).Debug("[grpc] capabilities rpc request")
 	capabilitiesMap := map[string]*synse.DeviceCapability{}
 
-	for _, device := range ctx.devices {
+	for _, device := range ctx.getDevices() {
 		_, hasKind := capabilitiesMap[device.Kind]
 		if !hasKind {
 			var outputs []string
@@ -318,7 +442,8 @@ func (server *server) Devices(request *synse.DeviceFilter, stream synse.Plugin_D
 		return fmt.Errorf("filter specifies board with no rack - must specifiy rack as well")
 	}
 
-	for _, device := range ctx.devices {
+	var devices []*Device
+	for _, device := range ctx.getDevices() {
 		if rack != "" {
 			if device.Location.Rack != rack {
 				continue
@@ -329,6 +454,17 @@ func (server *server) Devices(request *synse.DeviceFilter, stream synse.Plugin_D
 				}
 			}
 		}
+		devices = append(devices, device)
+	}
+
+	// Send the devices in a stable order, so that repeated listings match.
+	var order string
+	if Config.Plugin != nil && Config.Plugin.Settings != nil {
+		order = Config.Plugin.Settings.DeviceOrder
+	}
+	sortDevices(devices, order)
+
+	for _, device := range devices {
 		if err := stream.Send(device.encode()); err != nil {
 			return err
 		}
@@ -372,12 +508,13 @@ func (server *server) ReadCached(bounds *synse.Bounds, stream synse.Plugin_ReadC
 	readings := make(chan *ReadContext, 128)
 	go getReadingsFromCache(bounds.Start, bounds.End, readings)
 	for r := range readings {
+		device := ctx.getDevice(r.ID())
 		for _, data := range r.Reading {
 			deviceReading := &synse.DeviceReading{
 				Rack:    r.Rack,
 				Board:   r.Board,
 				Device:  r.Device,
-				Reading: data.encode(),
+				Reading: data.encodeFor(device),
 			}
 			if err := stream.Send(deviceReading); err != nil {
 				return err
@@ -387,10 +524,25 @@ func (server *server) ReadCached(bounds *synse.Bounds, stream synse.Plugin_ReadC
 	return nil
 }
 
+// ReloadDevice is the handler for the synse.DeviceInventory service's `ReloadDevice`
+// RPC method, which reloads the config of a single device (see Plugin.ReloadDevice).
+// The device is identified by the rack, board, and device of the filter.
+func (server *server) ReloadDevice(ctx context.Context, request *synse.DeviceFilter) (*synse.Status, error) {
+	log.WithField("request", request).Debug("[grpc] reload device rpc request")
+	if err := validateDeviceFilter(request); err != nil {
+		return nil, err
+	}
+	err := reloadDevice(server.plugin, makeIDString(request.Rack, request.Board, request.Device))
+	if err != nil {
+		return nil, err
+	}
+	return &synse.Status{Ok: true}, nil
+}
+
 // Write is the handler for the Synse GRPC Plugin service's `Write` RPC method.
 func (server *server) Write(ctx context.Context, request *synse.WriteInfo) (*synse.Transactions, error) {
 	log.WithField("request", request).Debug("[grpc] write rpc request")
-	transactions, err := DataManager.Write(request)
+	transactions, err := DataManager.Write(traceContextFromRequest(ctx), request)
 	if err != nil {
 		return nil, err
 	}
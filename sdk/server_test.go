@@ -3,6 +3,7 @@ package sdk
 import (
 	"context"
 	"fmt"
+	"net"
 	"testing"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/vapor-ware/synse-sdk/sdk/health"
 	"github.com/vapor-ware/synse-server-grpc/go"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
 )
 
 // TestNewServer tests that a server is returned when the constructor
@@ -110,6 +114,260 @@ func TestServer_Serve2(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestServer_listen_Retry tests that binding the server is retried while its address
+// is in use, and succeeds once the address is released.
+func TestServer_listen_Retry(t *testing.T) {
+	defer Config.reset()
+	held, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := held.Addr().String()
+
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{BindTimeout: "5s"},
+	}
+
+	// Release the address after a short time, like a terminating instance would.
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		held.Close() // nolint: errcheck
+	}()
+
+	s := newServer("tcp", address)
+	lis, err := s.listen()
+	assert.NoError(t, err)
+	if assert.NotNil(t, lis) {
+		assert.Equal(t, address, lis.Addr().String())
+		assert.NoError(t, lis.Close())
+	}
+}
+
+// TestServer_listen_NoRetry tests that binding the server fails immediately if its
+// address is in use and no bind timeout is configured.
+func TestServer_listen_NoRetry(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	held, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	defer held.Close()
+
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{},
+	}
+
+	s := newServer("tcp", held.Addr().String())
+	_, err = s.listen()
+	assert.Error(t, err)
+	assert.True(t, isAddrInUse(err))
+	assert.Empty(t, c.Sleeps())
+}
+
+// TestServer_listen_Timeout tests that binding the server is retried with a backoff
+// until the bind timeout elapses, if its address stays in use.
+func TestServer_listen_Timeout(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	held, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	defer held.Close()
+
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{BindTimeout: "1s"},
+	}
+
+	s := newServer("tcp", held.Addr().String())
+	_, err = s.listen()
+	assert.Error(t, err)
+	assert.True(t, isAddrInUse(err))
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		300 * time.Millisecond,
+	}, c.Sleeps())
+}
+
+// TestServer_listen_InvalidAddress tests that binding the server is not retried if
+// it fails for a reason other than its address being in use.
+func TestServer_listen_InvalidAddress(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{BindTimeout: "1s"},
+	}
+
+	s := newServer("tcp", "bar")
+	_, err := s.listen()
+	assert.Error(t, err)
+	assert.Empty(t, c.Sleeps())
+}
+
+// serveBlockingRead is a test helper which serves the gRPC server for a stateless
+// plugin with a device whose reads block until the returned release channel is
+// closed. The started channel receives a value when a read is in-flight.
+func serveBlockingRead(t *testing.T) (s *server, started chan struct{}, release chan struct{}) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Stateless: true,
+			Read:      &ReadSettings{Enabled: true},
+		},
+		Network: &NetworkSettings{
+			Type:                 "tcp",
+			Address:              address,
+			MaxConcurrentStreams: 10,
+		},
+	}
+
+	started = make(chan struct{}, 1)
+	release = make(chan struct{})
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				started <- struct{}{}
+				<-release
+				return []*Reading{{Timestamp: "now", Type: "temperature", Value: 1}}, nil
+			},
+		},
+	}
+
+	s = newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	return s, started, release
+}
+
+// readDevice is a test helper which reads the test device over a new client
+// connection to the given address, sending the result to the returned channel.
+func readDevice(address string) chan error {
+	result := make(chan error, 1)
+	go func() {
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		if err != nil {
+			result <- err
+			return
+		}
+		defer conn.Close()
+
+		// Wait for the connection to be ready, since the server may not
+		// be listening yet.
+		stream, err := synse.NewPluginClient(conn).Read(context.Background(), &synse.DeviceFilter{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+		}, grpc.FailFast(false))
+		if err != nil {
+			result <- err
+			return
+		}
+		_, err = stream.Recv()
+		result <- err
+	}()
+	return result
+}
+
+// TestServer_GracefulStop tests that in-flight RPCs complete when the server is
+// stopped gracefully, and that new RPCs are refused while it is draining.
+func TestServer_GracefulStop(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	s, started, release := serveBlockingRead(t)
+	inFlight := readDevice(s.address)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for in-flight read")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop(5 * time.Second)
+		close(stopped)
+	}()
+
+	// Once draining, new connections are refused.
+	refused := false
+	for i := 0; i < 50 && !refused; i++ {
+		dialCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		conn, err := grpc.DialContext(dialCtx, s.address, grpc.WithInsecure(), grpc.WithBlock())
+		cancel()
+		if err != nil {
+			refused = true
+		} else {
+			conn.Close()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	assert.True(t, refused, "new connections should be refused while draining")
+
+	// The server waits for the in-flight read to complete.
+	select {
+	case <-stopped:
+		t.Fatal("server stopped before in-flight rpc completed")
+	default:
+	}
+
+	close(release)
+	assert.NoError(t, <-inFlight)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for server to stop")
+	}
+}
+
+// TestServer_GracefulStopTimeout tests that the server is stopped immediately if
+// in-flight RPCs do not complete within the drain timeout.
+func TestServer_GracefulStopTimeout(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	// The blocked read is never released, since the read would otherwise
+	// continue after the server is stopped and race with the test cleanup.
+	s, started, _ := serveBlockingRead(t)
+
+	inFlight := readDevice(s.address)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for in-flight read")
+	}
+
+	start := time.Now()
+	s.GracefulStop(50 * time.Millisecond)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	// The in-flight read is cancelled when the server is stopped.
+	assert.Error(t, <-inFlight)
+}
+
+// TestServer_GracefulStopNotServing tests stopping a server which is not serving.
+func TestServer_GracefulStopNotServing(t *testing.T) {
+	s := newServer("tcp", "localhost:5001")
+	s.GracefulStop(time.Second)
+	assert.Nil(t, s.grpc)
+}
+
 // TestServer_Test tests the Test method of the gRPC plugin service.
 func TestServer_Test(t *testing.T) {
 	s := server{}
@@ -344,6 +602,50 @@ func TestServer_Devices_NoFilter(t *testing.T) {
 	assert.NotNil(t, mock.Results[baz.ID()])
 }
 
+// TestServer_Devices_Order tests that the Devices method of the gRPC plugin service
+// returns devices in a stable order, for each of the device orders.
+func TestServer_Devices_Order(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	// Register the devices in an order which differs from their ID order.
+	updateDeviceMap([]*Device{
+		{id: "3", Kind: "foo", Location: &Location{Rack: "rack-1", Board: "board-1"}},
+		{id: "1", Kind: "foo", Location: &Location{Rack: "rack-2", Board: "board-1"}},
+		{id: "2", Kind: "foo", Location: &Location{Rack: "rack-1", Board: "board-1"}},
+		{id: "4", Kind: "foo", Location: &Location{Rack: "rack-1", Board: "board-2"}},
+	})
+
+	var testTable = []struct {
+		order    string
+		expected []string
+	}{
+		{order: "", expected: []string{"2", "3", "4", "1"}},
+		{order: "id", expected: []string{"2", "3", "4", "1"}},
+		{order: "config", expected: []string{"3", "1", "2", "4"}},
+	}
+
+	s := server{}
+	for _, testCase := range testTable {
+		Config.Plugin = &PluginConfig{Settings: &PluginSettings{DeviceOrder: testCase.order}}
+
+		// Repeated calls should return the devices in the same order.
+		for i := 0; i < 10; i++ {
+			mock := test.NewMockDevicesStream()
+			assert.NoError(t, s.Devices(&synse.DeviceFilter{}, mock))
+			assert.Equal(t, testCase.expected, mock.Order, testCase.order)
+		}
+	}
+
+	// Filtered devices are ordered as well.
+	Config.Plugin = &PluginConfig{Settings: &PluginSettings{DeviceOrder: "config"}}
+	mock := test.NewMockDevicesStream()
+	assert.NoError(t, s.Devices(&synse.DeviceFilter{Rack: "rack-1"}, mock))
+	assert.Equal(t, []string{"3", "2", "4"}, mock.Order)
+}
+
 // TestServer_Devices_FilterRack tests the Devices method of the gRPC plugin service when
 // there are devices to get, and we filter on rack.
 func TestServer_Devices_FilterRack(t *testing.T) {
@@ -671,6 +973,73 @@ func TestServer_Read(t *testing.T) {
 	assert.Equal(t, 2, len(mock.Results))
 }
 
+// TestServer_ReadTypePrefix tests the Read method of the gRPC plugin service when
+// the plugin and device are configured with reading type prefixes.
+func TestServer_ReadTypePrefix(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{
+				Enabled:    true,
+				TypePrefix: "siteA",
+			},
+		},
+	}
+	for _, id := range []string{"device-1", "device-2"} {
+		ctx.devices["rack-board-"+id] = &Device{
+			id:   id,
+			Kind: "foo",
+			Location: &Location{
+				Rack:  "rack",
+				Board: "board",
+			},
+			Outputs: []*Output{
+				{OutputType: OutputType{Name: "foo.temperature"}},
+			},
+			Handler: &DeviceHandler{
+				Read: func(device *Device) ([]*Reading, error) {
+					return nil, nil
+				},
+			},
+		}
+		DataManager.readings["rack-board-"+id] = []*Reading{
+			{Timestamp: "now", Type: "temperature", Value: 3},
+		}
+	}
+	ctx.devices["rack-board-device-2"].TypePrefix = "tenant1.siteB"
+
+	s := server{}
+	var testTable = []struct {
+		device   string
+		expected string
+	}{
+		{"device-1", "siteA.temperature"},
+		{"device-2", "tenant1.siteB.temperature"},
+	}
+
+	for _, testCase := range testTable {
+		mock := test.NewMockReadStream()
+		err := s.Read(&synse.DeviceFilter{Rack: "rack", Board: "board", Device: testCase.device}, mock)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(mock.Results))
+		for _, result := range mock.Results {
+			assert.Equal(t, testCase.expected, result.Type, testCase.device)
+		}
+
+		// The device's output type still resolves to the unprefixed type.
+		output := ctx.devices["rack-board-"+testCase.device].Outputs[0]
+		assert.Equal(t, "temperature", output.Type())
+	}
+
+	// The stored readings are not modified.
+	assert.Equal(t, "temperature", DataManager.readings["rack-board-device-1"][0].Type)
+}
+
 // TestServer_Read2 tests the Read method of the gRPC plugin service when
 // the filter does not match anything.
 func TestServer_Read2(t *testing.T) {
@@ -759,6 +1128,113 @@ func TestServer_Read4(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestServer_Read5 tests the Read method of the gRPC plugin service when the
+// plugin is stateless, so the device is read on demand.
+func TestServer_Read5(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Stateless: true,
+			Read: &ReadSettings{
+				Enabled: true,
+			},
+		},
+	}
+	reads := 0
+	ctx.devices["rack-board-device"] = &Device{
+		id:   "device",
+		Kind: "foo",
+		Location: &Location{
+			Rack:  "rack",
+			Board: "board",
+		},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				reads++
+				return []*Reading{{Timestamp: "now", Type: "temperature", Value: reads}}, nil
+			},
+		},
+	}
+
+	s := server{}
+	req := &synse.DeviceFilter{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+	}
+	for i := 1; i <= 2; i++ {
+		mock := test.NewMockReadStream()
+		err := s.Read(req, mock)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(mock.Results))
+		assert.Equal(t, int64(i), mock.Results[0].GetInt64Value())
+	}
+	assert.Equal(t, 0, len(DataManager.getAllReadings()))
+}
+
+// TestServer_ReadInactive tests the Read method of the gRPC plugin service when the
+// device is outside of its active hours.
+func TestServer_ReadInactive(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+		clock = realClock{}
+	}()
+	c := useFakeClock(t, time.Date(2018, 1, 1, 20, 0, 0, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{
+				Enabled: true,
+			},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:   "device",
+		Kind: "foo",
+		Location: &Location{
+			Rack:  "rack",
+			Board: "board",
+		},
+		ActiveHours: &ActiveHours{Start: "08:00", End: "18:00"},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				return []*Reading{}, nil
+			},
+		},
+	}
+	DataManager.readings["rack-board-device"] = []*Reading{{Timestamp: "now", Type: "temperature", Value: 1}}
+
+	s := server{}
+	req := &synse.DeviceFilter{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+	}
+
+	// Outside of its active hours, the device is reported as inactive.
+	mock := test.NewMockReadStream()
+	err := s.Read(req, mock)
+	assert.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, err.Error(), "inactive")
+	assert.Equal(t, 0, len(mock.Results))
+
+	// Within its active hours, the readings are returned.
+	c.Advance(14 * time.Hour)
+	mock = test.NewMockReadStream()
+	err = s.Read(req, mock)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(mock.Results))
+}
+
 // Test the ReadCached method of the gRPC plugin service.
 func TestServer_ReadCached1(t *testing.T) {
 	defer func() {
@@ -932,6 +1408,44 @@ func TestServer_ReadCached3(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// TestServer_ReadCachedStateless tests the ReadCached method of the gRPC plugin
+// service when the plugin is stateless. No readings are returned, even if the
+// cache is enabled.
+func TestServer_ReadCachedStateless(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+		readingsCache = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Stateless: true,
+			Cache: &CacheSettings{
+				Enabled: true,
+			},
+		},
+	}
+	setupReadingsCache()
+	assert.Nil(t, readingsCache)
+
+	DataManager.updateReadings(&ReadContext{
+		Rack:    "rack",
+		Board:   "board",
+		Device:  "device",
+		Reading: []*Reading{{Timestamp: "now", Type: "temperature", Value: 3}},
+	})
+
+	s := server{}
+	bounds := &synse.Bounds{}
+	mock := test.NewMockReadCachedStream()
+	err := s.ReadCached(bounds, mock)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(mock.Results))
+}
+
 // TestServer_Write tests the Write method of the gRPC plugin service when
 // the specified device isn't found.
 func TestServer_Write(t *testing.T) {
@@ -1078,6 +1592,180 @@ func TestServer_Write4(t *testing.T) {
 	assert.Equal(t, 2, len(resp.Transactions))
 }
 
+// TestServer_WriteReadOnly tests that the Write method of the gRPC plugin service
+// rejects a write to a read-only device, even though its handler supports writes.
+func TestServer_WriteReadOnly(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	DataManager.writeChannel = make(chan *WriteContext, 20)
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Write: &WriteSettings{
+				Enabled: true,
+			},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:     "device",
+		Kind:   "foo",
+		Access: "read-only",
+		Location: &Location{
+			Rack:  "rack",
+			Board: "board",
+		},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				return nil, nil
+			},
+			Write: func(device *Device, data *WriteData) error {
+				return nil
+			},
+		},
+	}
+
+	s := server{}
+	req := &synse.WriteInfo{
+		DeviceFilter: &synse.DeviceFilter{
+			Rack:   "rack",
+			Board:  "board",
+			Device: "device",
+		},
+		Data: []*synse.WriteData{
+			{Action: "test"},
+		},
+	}
+	resp, err := s.Write(context.Background(), req)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "device is read-only")
+	assert.Nil(t, resp)
+	assert.Empty(t, DataManager.writeChannel)
+}
+
+// TestServer_ReadWriteOnly tests that the Read method of the gRPC plugin service
+// rejects a read of a write-only device, even though its handler supports reads.
+func TestServer_ReadWriteOnly(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read: &ReadSettings{
+				Enabled: true,
+			},
+		},
+	}
+	ctx.devices["rack-board-device"] = &Device{
+		id:     "device",
+		Kind:   "foo",
+		Access: "write-only",
+		Location: &Location{
+			Rack:  "rack",
+			Board: "board",
+		},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				return []*Reading{{Type: "temperature", Value: 1}}, nil
+			},
+			Write: func(device *Device, data *WriteData) error {
+				return nil
+			},
+		},
+	}
+
+	s := server{}
+	req := &synse.DeviceFilter{
+		Rack:   "rack",
+		Board:  "board",
+		Device: "device",
+	}
+	mock := test.NewMockReadStream()
+	err := s.Read(req, mock)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "device is write-only")
+	assert.Equal(t, 0, len(mock.Results))
+}
+
+// TestServer_WriteConstraints tests the Write method of the gRPC plugin service when
+// the device has write constraints.
+func TestServer_WriteConstraints(t *testing.T) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	DataManager.writeChannel = make(chan *WriteContext, 20)
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Write: &WriteSettings{
+				Enabled: true,
+			},
+		},
+	}
+
+	min, max := 10.0, 30.0
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Kind:     "foo",
+		Location: &Location{Rack: "rack", Board: "board"},
+		WriteConstraints: map[string]*WriteConstraint{
+			"setpoint": {Action: "setpoint", Min: &min, Max: &max},
+			"mode":     {Action: "mode", Values: []string{"auto", "manual"}},
+		},
+		Handler: &DeviceHandler{
+			Write: func(device *Device, data *WriteData) error {
+				return nil
+			},
+		},
+	}
+
+	var testTable = []struct {
+		desc string
+		data *synse.WriteData
+		ok   bool
+	}{
+		{"setpoint in range", &synse.WriteData{Action: "setpoint", Data: []byte("22.5")}, true},
+		{"setpoint below range", &synse.WriteData{Action: "setpoint", Data: []byte("5")}, false},
+		{"setpoint above range", &synse.WriteData{Action: "setpoint", Data: []byte("35")}, false},
+		{"mode allowed", &synse.WriteData{Action: "mode", Data: []byte("auto")}, true},
+		{"mode not allowed", &synse.WriteData{Action: "mode", Data: []byte("off")}, false},
+		{"unconstrained action", &synse.WriteData{Action: "other", Data: []byte("off")}, true},
+	}
+
+	s := server{}
+	for _, testCase := range testTable {
+		req := &synse.WriteInfo{
+			DeviceFilter: &synse.DeviceFilter{
+				Rack:   "rack",
+				Board:  "board",
+				Device: "device",
+			},
+			Data: []*synse.WriteData{testCase.data},
+		}
+		resp, err := s.Write(context.Background(), req)
+
+		if testCase.ok {
+			assert.NoError(t, err, testCase.desc)
+			assert.Equal(t, 1, len(resp.Transactions), testCase.desc)
+		} else {
+			assert.Error(t, err, testCase.desc)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), testCase.desc)
+			assert.Nil(t, resp, testCase.desc)
+		}
+	}
+}
+
 // TestServer_Transaction tests the Transaction method of the gRPC plugin service.
 func TestServer_Transaction(t *testing.T) {
 	setupTransactionCache(time.Duration(600) * time.Second)
@@ -1266,6 +1954,94 @@ func Test_setCredsOptions_4(t *testing.T) {
 	assert.Empty(t, options)
 }
 
+// Test_setKeepaliveOption_1 tests setting the keepalive option when the plugin is not
+// configured to limit client connections.
+func Test_setKeepaliveOption_1(t *testing.T) {
+	defer Config.reset()
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{},
+	}
+
+	var options []grpc.ServerOption
+	err := setKeepaliveOption(&options)
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+}
+
+// Test_setKeepaliveOption_2 tests setting the keepalive option when the plugin is
+// configured to limit client connections.
+func Test_setKeepaliveOption_2(t *testing.T) {
+	defer Config.reset()
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{
+			MaxConnectionIdle: "5m",
+			MaxConnectionAge:  "30m",
+		},
+	}
+
+	var options []grpc.ServerOption
+	err := setKeepaliveOption(&options)
+	assert.NoError(t, err)
+	assert.Len(t, options, 1)
+}
+
+// Test_setKeepaliveOption_3 tests setting the keepalive option when a connection
+// limit is invalid.
+func Test_setKeepaliveOption_3(t *testing.T) {
+	defer Config.reset()
+	Config.Plugin = &PluginConfig{
+		Network: &NetworkSettings{
+			MaxConnectionAge: "foo",
+		},
+	}
+
+	var options []grpc.ServerOption
+	err := setKeepaliveOption(&options)
+	assert.Error(t, err)
+	assert.Empty(t, options)
+}
+
+// TestServer_MaxConnectionAge tests that the server closes a client connection once
+// it reaches the configured maximum age.
+func TestServer_MaxConnectionAge(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		resetContext()
+		Config.reset()
+	}()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{},
+		Network: &NetworkSettings{
+			Type:                  "tcp",
+			Address:               address,
+			MaxConnectionAge:      "200ms",
+			MaxConnectionAgeGrace: "100ms",
+		},
+	}
+	s := newServer("tcp", address)
+	defer s.Stop()
+	go s.Serve() // nolint: errcheck
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, address, grpc.WithInsecure(), grpc.WithBlock())
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, connectivity.Ready, conn.GetState())
+
+	// The connection should be closed by the server shortly after its max age,
+	// so the client connection leaves the ready state.
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.True(t, conn.WaitForStateChange(waitCtx, connectivity.Ready), "connection was not closed")
+}
+
 // Test_setCredsOptions_5 tests setting credential options when the plugin is configured
 // for TLS/SSL, there is no cacert specified, and skip verify is enabled.
 func Test_setCredsOptions_5(t *testing.T) {
@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// snapshot is a snapshot of the latest readings of all of the plugin's devices,
+// as it is exported for debugging.
+type snapshot struct {
+	// Timestamp is the time at which the snapshot was taken.
+	Timestamp string `json:"timestamp"`
+
+	// Readings are the latest readings of the devices, sorted by device ID.
+	// The readings of a device are in the order they were read.
+	Readings []*snapshotReading `json:"readings"`
+}
+
+// snapshotReading is a reading as it is exported in a snapshot.
+type snapshotReading struct {
+	Device    string      `json:"device"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+	Unit      adminUnit   `json:"unit"`
+	Timestamp string      `json:"timestamp"`
+}
+
+// newSnapshot creates a snapshot of the given readings, keyed by device ID.
+func newSnapshot(readings map[string][]*Reading) *snapshot {
+	ids := make([]string, 0, len(readings))
+	for id := range readings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	s := &snapshot{
+		Timestamp: clock.Now().Format(time.RFC3339Nano),
+		Readings:  []*snapshotReading{},
+	}
+	for _, id := range ids {
+		for _, reading := range readings[id] {
+			s.Readings = append(s.Readings, &snapshotReading{
+				Device:    id,
+				Type:      reading.Type,
+				Value:     reading.Value,
+				Unit:      adminUnit{Name: reading.Unit.Name, Symbol: reading.Unit.Symbol},
+				Timestamp: reading.Timestamp,
+			})
+		}
+	}
+	return s
+}
+
+// WriteSnapshot writes a snapshot of the latest readings of all of the plugin's
+// devices to the given writer, as JSON. The snapshot holds the time at which it
+// was taken, and the ID, type, value, unit, and timestamp of each reading, sorted
+// by device ID. It is meant for debugging, not as a stable API.
+func (plugin *Plugin) WriteSnapshot(w io.Writer) error {
+	return writeSnapshot(w)
+}
+
+// SaveSnapshot writes a snapshot of the latest readings of all of the plugin's
+// devices to the file at the given path (see Plugin.WriteSnapshot). If the file
+// exists, it is replaced.
+func (plugin *Plugin) SaveSnapshot(path string) error {
+	return saveSnapshot(path)
+}
+
+// encodeSnapshot takes a snapshot of the latest readings and encodes it as JSON.
+func encodeSnapshot() ([]byte, error) {
+	data, err := json.MarshalIndent(newSnapshot(DataManager.getAllReadings()), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// writeSnapshot writes a snapshot of the latest readings to the given writer.
+func writeSnapshot(w io.Writer) error {
+	data, err := encodeSnapshot()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// saveSnapshot writes a snapshot of the latest readings to the file at the given path.
+func saveSnapshot(path string) error {
+	data, err := encodeSnapshot()
+	if err != nil {
+		return err
+	}
+	return saveSnapshotData(path, data)
+}
+
+// saveSnapshotData writes an encoded snapshot to the file at the given path. The
+// snapshot is written to a temporary file which then replaces the file, so a
+// reader never sees a partially written snapshot.
+func saveSnapshotData(path string, data []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), ".snapshot-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // nolint: errcheck
+
+	if _, err := file.Write(data); err != nil {
+		file.Close() // nolint: errcheck
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+	log.WithField("file", path).Info("[sdk] saved reading snapshot")
+	return nil
+}
+
+// watchSnapshotSignal saves a snapshot of the latest readings to the given file
+// each time the plugin receives SIGUSR1. This blocks, so it should be run in a
+// goroutine.
+func watchSnapshotSignal(path string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	for range signals {
+		if err := saveSnapshot(path); err != nil {
+			log.WithField("error", err).Error("[sdk] failed to save reading snapshot")
+		}
+	}
+}
+
+// readingSnapshotServer is the server API for the synse.ReadingSnapshot service.
+type readingSnapshotServer interface {
+	Export(context.Context, *synse.Empty) (*wrappers.BytesValue, error)
+}
+
+// Export is the handler for the synse.ReadingSnapshot service's `Export` RPC
+// method. The response holds the snapshot of the latest readings, as JSON (see
+// Plugin.WriteSnapshot). If the plugin was run with the --snapshot flag, the
+// snapshot is also saved to that file.
+func (server *server) Export(ctx context.Context, request *synse.Empty) (*wrappers.BytesValue, error) {
+	log.Debug("[grpc] export snapshot rpc request")
+	data, err := encodeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	if flagSnapshot != "" {
+		if err := saveSnapshotData(flagSnapshot, data); err != nil {
+			return nil, err
+		}
+	}
+	return &wrappers.BytesValue{Value: data}, nil
+}
+
+// exportHandler decodes and dispatches requests for the `Export` RPC method.
+func exportHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(synse.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(readingSnapshotServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/synse.ReadingSnapshot/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(readingSnapshotServer).Export(ctx, req.(*synse.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// readingSnapshotServiceDesc describes the synse.ReadingSnapshot gRPC service, which
+// exports a snapshot of the latest readings of all of the plugin's devices for
+// debugging. It is defined in proto/snapshot.proto.
+var readingSnapshotServiceDesc = grpc.ServiceDesc{
+	ServiceName: "synse.ReadingSnapshot",
+	HandlerType: (*readingSnapshotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    exportHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/snapshot.proto",
+}
@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// expectedSnapshot is the expected snapshot of the readings set up by setupSnapshotTest.
+const expectedSnapshot = `{
+  "timestamp": "2019-01-02T03:04:05Z",
+  "readings": [
+    {
+      "device": "rack-board-a",
+      "type": "temperature",
+      "value": 21.5,
+      "unit": {
+        "name": "celsius",
+        "symbol": "C"
+      },
+      "timestamp": "2019-01-02T03:04:00Z"
+    },
+    {
+      "device": "rack-board-a",
+      "type": "humidity",
+      "value": 40,
+      "unit": {
+        "name": "percent",
+        "symbol": "%"
+      },
+      "timestamp": "2019-01-02T03:04:00Z"
+    },
+    {
+      "device": "rack-board-b",
+      "type": "state",
+      "value": "on",
+      "unit": {
+        "name": "",
+        "symbol": ""
+      },
+      "timestamp": "2019-01-02T03:04:01Z"
+    }
+  ]
+}
+`
+
+// setupSnapshotTest is a test helper which sets up the latest readings of two devices
+// and a fake clock for taking snapshots of them.
+func setupSnapshotTest(t *testing.T) {
+	useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	DataManager.readings["rack-board-b"] = []*Reading{
+		{Timestamp: "2019-01-02T03:04:01Z", Type: "state", Value: "on"},
+	}
+	DataManager.readings["rack-board-a"] = []*Reading{
+		{Timestamp: "2019-01-02T03:04:00Z", Type: "temperature", Unit: Unit{Name: "celsius", Symbol: "C"}, Value: 21.5},
+		{Timestamp: "2019-01-02T03:04:00Z", Type: "humidity", Unit: Unit{Name: "percent", Symbol: "%"}, Value: 40},
+	}
+}
+
+// TestPlugin_WriteSnapshot tests writing a snapshot of the latest readings.
+func TestPlugin_WriteSnapshot(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		clock = realClock{}
+	}()
+	setupSnapshotTest(t)
+
+	var buf bytes.Buffer
+	err := NewPlugin().WriteSnapshot(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSnapshot, buf.String())
+}
+
+// TestPlugin_WriteSnapshot_NoReadings tests writing a snapshot when there are no readings.
+func TestPlugin_WriteSnapshot_NoReadings(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+	}()
+	useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	var buf bytes.Buffer
+	err := NewPlugin().WriteSnapshot(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"timestamp\": \"2019-01-02T03:04:05Z\",\n  \"readings\": []\n}\n", buf.String())
+}
+
+// TestPlugin_SaveSnapshot tests saving a snapshot of the latest readings to file,
+// replacing any existing file.
+func TestPlugin_SaveSnapshot(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		clock = realClock{}
+	}()
+	setupSnapshotTest(t)
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+	path := test.WriteTempFile(t, "snapshot.json", "old", 0644)
+
+	err := NewPlugin().SaveSnapshot(path)
+	assert.NoError(t, err)
+
+	contents, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSnapshot, string(contents))
+
+	// No temporary files are left behind.
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+// TestPlugin_SaveSnapshot_Error tests saving a snapshot to a directory that does not exist.
+func TestPlugin_SaveSnapshot_Error(t *testing.T) {
+	err := NewPlugin().SaveSnapshot("/foo/bar/snapshot.json")
+	assert.Error(t, err)
+}
+
+// TestServer_Export tests the Export RPC of the synse.ReadingSnapshot service over a
+// running gRPC server.
+func TestServer_Export(t *testing.T) {
+	defer func() {
+		DataManager = newDataManager()
+		clock = realClock{}
+		Config.reset()
+		flagSnapshot = ""
+	}()
+	setupSnapshotTest(t)
+
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+	flagSnapshot = filepath.Join(test.TempDir, "snapshot.json")
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	address := lis.Addr().String()
+	assert.NoError(t, lis.Close())
+	Config.Plugin = &PluginConfig{Network: &NetworkSettings{Type: "tcp", Address: address}}
+
+	s := newServer("tcp", address)
+	go s.Serve() // nolint: errcheck
+	defer s.Stop()
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	resp := &wrappers.BytesValue{}
+	err = conn.Invoke(context.Background(), "/synse.ReadingSnapshot/Export", &synse.Empty{}, resp, grpc.FailFast(false))
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSnapshot, string(resp.Value))
+
+	// The snapshot is also saved to the --snapshot file.
+	contents, err := ioutil.ReadFile(flagSnapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSnapshot, string(contents))
+}
@@ -0,0 +1,188 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/metadata"
+)
+
+// traceparentHeader is the W3C Trace Context header (and gRPC metadata key)
+// used to propagate a trace into the plugin.
+const traceparentHeader = "traceparent"
+
+// Span describes a single traced operation, such as a read cycle or a device
+// write. Spans are carried in a context.Context, so the trace ID is accessible
+// to device handlers via SpanFromContext or TraceIDFromContext. Trace and span
+// IDs use the W3C Trace Context (and OpenTelemetry) format.
+type Span struct {
+	// TraceID is the 32 character hex ID of the trace the span is part of.
+	TraceID string
+
+	// SpanID is the 16 character hex ID of the span.
+	SpanID string
+
+	// ParentSpanID is the ID of the span's parent. It is empty for a root span.
+	ParentSpanID string
+
+	// Name is the name of the traced operation.
+	Name string
+
+	// Attributes holds additional information about the traced operation.
+	Attributes map[string]string
+
+	// Start is the time at which the span was started.
+	Start time.Time
+
+	// End is the time at which the span ended. It is zero until the span ends.
+	End time.Time
+
+	lock sync.Mutex
+}
+
+// SpanExporter exports trace spans once they have ended, e.g. to a tracing
+// backend. Exporters are registered with Plugin.RegisterSpanExporter.
+type SpanExporter interface {
+	// ExportSpan exports a span which has ended.
+	ExportSpan(span *Span)
+}
+
+// RegisterSpanExporter registers an exporter for the spans traced by the plugin
+// for its read cycles, device reads, and device writes.
+func (plugin *Plugin) RegisterSpanExporter(exporter SpanExporter) {
+	ctx.spanExporters = append(ctx.spanExporters, exporter)
+}
+
+// spanKey is the context key for the current Span.
+type spanKey struct{}
+
+// StartSpan starts a new Span with the given name. If the parent context carries
+// a span, the new span is its child and is part of the same trace; otherwise, a
+// new trace is started. The returned context carries the new span.
+func StartSpan(parent context.Context, name string) (context.Context, *Span) {
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	span := &Span{
+		SpanID:     newTraceID(8),
+		Name:       name,
+		Attributes: map[string]string{},
+		Start:      clock.Now(),
+	}
+	if p := SpanFromContext(parent); p != nil {
+		span.TraceID = p.TraceID
+		span.ParentSpanID = p.SpanID
+	} else {
+		span.TraceID = newTraceID(16)
+	}
+	return context.WithValue(parent, spanKey{}, span), span
+}
+
+// SetAttribute sets an attribute on the Span.
+func (span *Span) SetAttribute(key, value string) {
+	span.lock.Lock()
+	defer span.lock.Unlock()
+	span.Attributes[key] = value
+}
+
+// Finish ends the Span and passes it to the registered span exporters. Calling
+// Finish more than once has no effect.
+func (span *Span) Finish() {
+	span.lock.Lock()
+	if !span.End.IsZero() {
+		span.lock.Unlock()
+		return
+	}
+	span.End = clock.Now()
+	span.lock.Unlock()
+
+	log.WithFields(log.Fields{
+		"trace_id": span.TraceID,
+		"span_id":  span.SpanID,
+		"duration": span.End.Sub(span.Start),
+	}).Debugf("[trace] finished span %s", span.Name)
+
+	for _, exporter := range ctx.spanExporters {
+		exporter.ExportSpan(span)
+	}
+}
+
+// SpanFromContext gets the Span carried by the context. If the context does not
+// carry a span, nil is returned.
+func SpanFromContext(c context.Context) *Span {
+	if c == nil {
+		return nil
+	}
+	span, _ := c.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext gets the trace ID of the Span carried by the context. If the
+// context does not carry a span, an empty string is returned.
+func TraceIDFromContext(c context.Context) string {
+	if span := SpanFromContext(c); span != nil {
+		return span.TraceID
+	}
+	return ""
+}
+
+// newTraceID generates a random hex-encoded ID from the given number of bytes.
+func newTraceID(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		log.WithField("error", err).Error("[trace] failed to generate trace id")
+	}
+	return hex.EncodeToString(b)
+}
+
+// traceContextFromRequest gets a context for tracing work done on behalf of an
+// incoming gRPC request. If the request metadata has a W3C traceparent value
+// ("00-<trace id>-<parent span id>-<flags>"), the context carries it as the
+// remote parent span, so spans started from it continue the caller's trace.
+//
+// The returned context is not derived from the request context, so it is safe
+// to use for work which outlives the request, such as queued writes.
+func traceContextFromRequest(reqCtx context.Context) context.Context {
+	traceCtx := context.Background()
+
+	md, ok := metadata.FromIncomingContext(reqCtx)
+	if !ok {
+		return traceCtx
+	}
+	values := md.Get(traceparentHeader)
+	if len(values) == 0 {
+		return traceCtx
+	}
+
+	traceID, spanID, err := parseTraceparent(values[0])
+	if err != nil {
+		log.WithField("error", err).Warn("[trace] ignoring invalid traceparent")
+		return traceCtx
+	}
+	return context.WithValue(traceCtx, spanKey{}, &Span{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Name:    "remote",
+	})
+}
+
+// parseTraceparent parses the trace ID and parent span ID from a W3C traceparent value.
+func parseTraceparent(traceparent string) (traceID, spanID string, err error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", fmt.Errorf("malformed traceparent: %q", traceparent)
+	}
+	for _, id := range parts[1:3] {
+		if _, err := hex.DecodeString(id); err != nil || strings.Trim(id, "0") == "" {
+			return "", "", fmt.Errorf("invalid id in traceparent: %q", traceparent)
+		}
+	}
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2]), nil
+}
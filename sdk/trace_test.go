@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc/metadata"
+)
+
+// spanRecorder is a SpanExporter for testing which records the exported spans.
+type spanRecorder struct {
+	sync.Mutex
+	spans []*Span
+}
+
+func (recorder *spanRecorder) ExportSpan(span *Span) {
+	recorder.Lock()
+	defer recorder.Unlock()
+	recorder.spans = append(recorder.spans, span)
+}
+
+// named gets the recorded spans with the given name.
+func (recorder *spanRecorder) named(name string) []*Span {
+	recorder.Lock()
+	defer recorder.Unlock()
+	var spans []*Span
+	for _, span := range recorder.spans {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// TestStartSpan tests starting root and child spans.
+func TestStartSpan(t *testing.T) {
+	rootCtx, root := StartSpan(context.Background(), "root")
+	assert.Len(t, root.TraceID, 32)
+	assert.Len(t, root.SpanID, 16)
+	assert.Empty(t, root.ParentSpanID)
+	assert.Equal(t, root, SpanFromContext(rootCtx))
+	assert.Equal(t, root.TraceID, TraceIDFromContext(rootCtx))
+
+	childCtx, child := StartSpan(rootCtx, "child")
+	assert.Equal(t, root.TraceID, child.TraceID)
+	assert.Equal(t, root.SpanID, child.ParentSpanID)
+	assert.NotEqual(t, root.SpanID, child.SpanID)
+	assert.Equal(t, child, SpanFromContext(childCtx))
+
+	// A new root span starts a new trace.
+	_, other := StartSpan(nil, "other") // nolint: staticcheck
+	assert.NotEqual(t, root.TraceID, other.TraceID)
+}
+
+// TestSpanFromContext_NoSpan tests getting the span from a context which does not
+// carry one.
+func TestSpanFromContext_NoSpan(t *testing.T) {
+	assert.Nil(t, SpanFromContext(context.Background()))
+	assert.Nil(t, SpanFromContext(nil)) // nolint: staticcheck
+	assert.Equal(t, "", TraceIDFromContext(context.Background()))
+}
+
+// TestSpan_Finish tests that a finished span is exported once.
+func TestSpan_Finish(t *testing.T) {
+	defer resetContext()
+
+	recorder := &spanRecorder{}
+	plugin := NewPlugin()
+	plugin.RegisterSpanExporter(recorder)
+
+	_, span := StartSpan(context.Background(), "test")
+	span.SetAttribute("foo", "bar")
+	assert.True(t, span.End.IsZero())
+
+	span.Finish()
+	span.Finish()
+	assert.False(t, span.End.IsZero())
+	assert.Equal(t, []*Span{span}, recorder.spans)
+	assert.Equal(t, map[string]string{"foo": "bar"}, span.Attributes)
+}
+
+// TestDataManager_serialRead_Trace tests that a span is created for a read cycle and
+// for each device read, and that the trace ID is accessible inside the handler.
+func TestDataManager_serialRead_Trace(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Read:   &ReadSettings{Buffer: 10},
+			Write:  &WriteSettings{Buffer: 10},
+			Listen: &ListenSettings{Buffer: 10},
+		},
+	}
+
+	recorder := &spanRecorder{}
+	plugin := NewPlugin()
+	plugin.RegisterSpanExporter(recorder)
+
+	var handlerTraceID string
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			ReadWithContext: func(c context.Context, _ *Device) ([]*Reading, error) {
+				handlerTraceID = TraceIDFromContext(c)
+				return []*Reading{}, nil
+			},
+		},
+	}
+
+	d := newDataManager()
+	assert.NoError(t, d.setup())
+	d.serialRead(0)
+	assert.Equal(t, 1, len(d.readChannel))
+
+	cycles := recorder.named("read cycle")
+	reads := recorder.named("device read")
+	assert.Equal(t, 1, len(cycles))
+	assert.Equal(t, 1, len(reads))
+
+	assert.NotEmpty(t, handlerTraceID)
+	assert.Equal(t, cycles[0].TraceID, handlerTraceID)
+	assert.Equal(t, cycles[0].TraceID, reads[0].TraceID)
+	assert.Equal(t, cycles[0].SpanID, reads[0].ParentSpanID)
+	assert.Equal(t, "rack-board-1", reads[0].Attributes["device"])
+}
+
+// TestDataManager_write_Trace tests that a write continues the trace of the write
+// request, and that the trace ID is accessible inside the handler.
+func TestDataManager_write_Trace(t *testing.T) {
+	defer resetContext()
+
+	recorder := &spanRecorder{}
+	plugin := NewPlugin()
+	plugin.RegisterSpanExporter(recorder)
+
+	var handlerTraceID string
+	ctx.devices["rack-board-1"] = &Device{
+		id:       "1",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler: &DeviceHandler{
+			WriteWithContext: func(c context.Context, _ *Device, _ *WriteData) error {
+				handlerTraceID = TraceIDFromContext(c)
+				return nil
+			},
+		},
+	}
+
+	reqCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+
+	d := dataManager{}
+	d.write(&WriteContext{
+		traceCtx:    traceContextFromRequest(reqCtx),
+		transaction: &transaction{id: "test"},
+		device:      "1",
+		board:       "board",
+		rack:        "rack",
+		data:        &synse.WriteData{Action: "test"},
+	})
+
+	writes := recorder.named("device write")
+	assert.Equal(t, 1, len(writes))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handlerTraceID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", writes[0].TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", writes[0].ParentSpanID)
+	assert.Equal(t, "test", writes[0].Attributes["transaction"])
+}
+
+// Test_traceContextFromRequest tests getting the trace context for a request which
+// does not carry a valid traceparent.
+func Test_traceContextFromRequest(t *testing.T) {
+	assert.Nil(t, SpanFromContext(traceContextFromRequest(context.Background())))
+
+	reqCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", "foo"))
+	assert.Nil(t, SpanFromContext(traceContextFromRequest(reqCtx)))
+}
+
+// Test_parseTraceparent tests parsing W3C traceparent values.
+func Test_parseTraceparent(t *testing.T) {
+	traceID, spanID, err := parseTraceparent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+	} {
+		_, _, err := parseTraceparent(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -1,10 +1,15 @@
 package sdk
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
@@ -26,6 +31,12 @@ type OutputType struct {
 	// This is only used when the type is a float-type.
 	Precision int `yaml:"precision,omitempty" addedIn:"1.0"`
 
+	// SignificantFigures is an optional number of significant figures to round
+	// numeric readings to, e.g. for scientific data where the magnitude of the
+	// values varies. Unlike Precision, this is applied by the SDK, to the scaled
+	// and converted reading value.
+	SignificantFigures int `yaml:"significantFigures,omitempty" addedIn:"1.3"`
+
 	// Unit is the unit of measure for the reading.
 	Unit Unit `yaml:"unit,omitempty" addedIn:"1.0"`
 
@@ -44,6 +55,606 @@ type OutputType struct {
 	// will be supported for temperature sensors.
 	// This field is not in the Output message, therefore the grpc client never sees this.
 	Conversion string `yaml:"conversion,omitempty" addedIn:"1.2"`
+
+	// Conversions is an ordered list of named conversions to apply to the
+	// scaled reading. Each conversion is applied to the result of the previous
+	// one, allowing multi-step conversions (e.g. raw counts -> voltage ->
+	// engineering units) to be composed. If Conversion is also set, it is
+	// applied before the conversions listed here.
+	Conversions []string `yaml:"conversions,omitempty" addedIn:"1.3"`
+
+	// DefaultValue is an optional value to use for a reading when the device
+	// handler provides a nil value, e.g. when the sensor is unavailable. This
+	// could be a sentinel value that consumers know to treat specially. The
+	// default value is used as-is; scaling and conversions are not applied to it.
+	// It must be a scalar value (a string, bool, number, or bytes). If this is not
+	// set, nil reading values are left as nil.
+	DefaultValue interface{} `yaml:"defaultValue,omitempty" addedIn:"1.3"`
+
+	// Scale is an optional SI prefix symbol (e.g. "k", "m", "M") to express the
+	// reading in. The unit of the reading is prefixed accordingly (e.g. "Pa"
+	// becomes "kPa" for a scale of "k"), and the reading is divided by the power
+	// of ten for the prefix, after the scaling factor is applied, so that it is
+	// the same quantity in the prefixed unit (e.g. 2000 Pa is output as 2 kPa).
+	Scale string `yaml:"scale,omitempty" addedIn:"1.3"`
+
+	// Compression is an optional compression algorithm to apply to []byte
+	// reading values, e.g. for large binary payloads. Currently, only "gzip"
+	// is supported. When a reading value is compressed, the compression used
+	// is recorded in the reading context under the "encoding" key. The reading
+	// context is only sent with the readings of the synse.ReadingBatches
+	// service, so only its consumers can detect and decompress the values.
+	Compression string `yaml:"compression,omitempty" addedIn:"1.3"`
+
+	// ValidMin and ValidMax are the optional bounds (inclusive) of the valid
+	// values for a reading. Readings outside of these bounds are considered
+	// bad readings, which can cause the device to be quarantined if device
+	// quarantine is enabled.
+	ValidMin *float64 `yaml:"validMin,omitempty" addedIn:"1.3"`
+	ValidMax *float64 `yaml:"validMax,omitempty" addedIn:"1.3"`
+
+	// Smoothing configures an optional exponentially-weighted moving average
+	// (EWMA) filter for numeric readings of the output type, e.g. for noisy
+	// analog sensors. The moving average is maintained for each device, and
+	// the smoothed value is emitted in place of the raw reading value.
+	Smoothing *SmoothingSettings `yaml:"smoothing,omitempty" addedIn:"1.3"`
+
+	// Counter configures the optional computation of rates from counter readings,
+	// e.g. for registers which hold monotonically increasing totals. The rate of
+	// change per second between successive readings is computed for each device,
+	// and emitted in place of the counter value, with a per-second unit. It can
+	// not be combined with options which change the raw counter value.
+	Counter *CounterSettings `yaml:"counter,omitempty" addedIn:"1.3"`
+
+	// BitField configures the optional extraction of a boolean from an integer
+	// reading value, e.g. for status words which pack multiple flags into one
+	// register. Each flag can be defined as its own output type, so a single
+	// register value can be used to make multiple boolean readings.
+	BitField *BitField `yaml:"bitField,omitempty" addedIn:"1.3"`
+
+	// Transforms is an optional, ordered pipeline of transforms to apply to
+	// reading values, for outputs which need more control over how their values
+	// are transformed than the scaling factor, scale, conversions, and significant
+	// figures give. Each transform is applied to the result of the previous one.
+	// Transforms can not be combined with those fields.
+	Transforms []*Transform `yaml:"transforms,omitempty" addedIn:"1.3"`
+
+	// DataType is the optional type of the values that device handlers are
+	// expected to provide for readings of the output type. This can be one of
+	// "int" (any integer type), "float", "string", "bool", "bytes", or "decimal".
+	// Values are checked against the data type if type enforcement is enabled.
+	DataType string `yaml:"dataType,omitempty" addedIn:"1.3"`
+
+	// TypeEnforcement specifies how values which do not match the DataType are
+	// handled, e.g. to catch handlers which drift from returning floats to
+	// returning ints. With "log", a warning is logged, and the reading is made
+	// as usual. With "error", the reading fails. If this is not set, values are
+	// not checked.
+	TypeEnforcement string `yaml:"typeEnforcement,omitempty" addedIn:"1.3"`
+}
+
+// Transform is a single stage of an OutputType's transform pipeline. Exactly one
+// of its fields should be set, which determines the kind of the transform.
+type Transform struct {
+	// Factor multiplies the value by a factor. Like the scaling factor of an
+	// OutputType, this should resolve to a non-zero numeric, e.g. "0.01" or "1e-2".
+	Factor string `yaml:"factor,omitempty" addedIn:"1.3"`
+
+	// Offset adds an offset to the value.
+	Offset *float64 `yaml:"offset,omitempty" addedIn:"1.3"`
+
+	// Conversion applies the named conversion to the value.
+	Conversion string `yaml:"conversion,omitempty" addedIn:"1.3"`
+
+	// Clamp limits the value to a range.
+	Clamp *ClampRange `yaml:"clamp,omitempty" addedIn:"1.3"`
+
+	// Precision rounds the value to a number of decimal places.
+	Precision *int `yaml:"precision,omitempty" addedIn:"1.3"`
+
+	// SignificantFigures rounds the value to a number of significant figures.
+	SignificantFigures int `yaml:"significantFigures,omitempty" addedIn:"1.3"`
+
+	// Enum maps values to other values, e.g. status codes to their names. The
+	// map keys are the string forms of the values to map. Values with no
+	// mapping are left unchanged.
+	Enum map[string]interface{} `yaml:"enum,omitempty" addedIn:"1.3"`
+}
+
+// ClampRange is the range that a Transform clamps values to. Either bound may
+// be omitted to leave the value unbounded in that direction.
+type ClampRange struct {
+	Min *float64 `yaml:"min,omitempty" addedIn:"1.3"`
+	Max *float64 `yaml:"max,omitempty" addedIn:"1.3"`
+}
+
+// kind gets the kind of the transform, from which of its fields are set. If
+// more or less than one is set, an empty string is returned.
+func (transform *Transform) kind() string {
+	var kinds []string
+	if transform.Factor != "" {
+		kinds = append(kinds, "factor")
+	}
+	if transform.Offset != nil {
+		kinds = append(kinds, "offset")
+	}
+	if transform.Conversion != "" {
+		kinds = append(kinds, "conversion")
+	}
+	if transform.Clamp != nil {
+		kinds = append(kinds, "clamp")
+	}
+	if transform.Precision != nil {
+		kinds = append(kinds, "precision")
+	}
+	if transform.SignificantFigures != 0 {
+		kinds = append(kinds, "significantFigures")
+	}
+	if transform.Enum != nil {
+		kinds = append(kinds, "enum")
+	}
+	if len(kinds) != 1 {
+		return ""
+	}
+	return kinds[0]
+}
+
+// Validate validates that the Transform has no configuration errors.
+func (transform Transform) Validate(multiErr *errors.MultiError) {
+	source := multiErr.Context["source"]
+	switch transform.kind() {
+	case "":
+		multiErr.Add(errors.NewInvalidValueError(
+			source,
+			"outputType.transforms",
+			"exactly one of: factor, offset, conversion, clamp, precision, significantFigures, enum",
+		))
+	case "factor":
+		if f, err := strconv.ParseFloat(transform.Factor, 64); err != nil || f == 0 {
+			multiErr.Add(errors.NewInvalidValueError(source, "outputType.transforms.factor", "a non-zero numeric"))
+		}
+	case "conversion":
+		if _, ok := ctx.conversions[transform.Conversion]; !ok {
+			multiErr.Add(errors.NewValidationError(
+				source,
+				fmt.Sprintf("unknown conversion specified: %s", transform.Conversion),
+			))
+		}
+	case "clamp":
+		clamp := transform.Clamp
+		if (clamp.Min == nil && clamp.Max == nil) || (clamp.Min != nil && clamp.Max != nil && *clamp.Min > *clamp.Max) {
+			multiErr.Add(errors.NewInvalidValueError(source, "outputType.transforms.clamp", "a min and/or max, where min <= max"))
+		}
+	case "precision":
+		if *transform.Precision < 0 {
+			multiErr.Add(errors.NewInvalidValueError(source, "outputType.transforms.precision", "a non-negative number of decimal places"))
+		}
+	case "significantFigures":
+		if transform.SignificantFigures < 0 {
+			multiErr.Add(errors.NewInvalidValueError(source, "outputType.transforms.significantFigures", "a positive number of significant figures"))
+		}
+	case "enum":
+		if len(transform.Enum) == 0 {
+			multiErr.Add(errors.NewInvalidValueError(source, "outputType.transforms.enum", "a non-empty mapping of values"))
+		}
+	}
+}
+
+// apply applies the transform to a reading value in the unit with the given
+// symbol. If the transform can not be applied, the value is returned unchanged
+// along with the error.
+func (transform *Transform) apply(value interface{}, unit string) (interface{}, error) {
+	kind := transform.kind()
+	if kind == "enum" {
+		// Values with no mapping are left unchanged; this is not an error.
+		if mapped, ok := transform.Enum[fmt.Sprint(value)]; ok {
+			return mapped, nil
+		}
+		return value, nil
+	}
+
+	// Decimal values are scaled and rounded exactly.
+	if d, isDecimal := value.(Decimal); isDecimal {
+		switch kind {
+		case "factor":
+			factor, err := NewDecimal(transform.Factor)
+			if err != nil {
+				return value, err
+			}
+			return d.Mul(factor), nil
+		case "significantFigures":
+			return d.RoundSignificant(transform.SignificantFigures), nil
+		}
+	}
+
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		return value, err
+	}
+
+	switch kind {
+	case "factor":
+		factor, err := strconv.ParseFloat(transform.Factor, 64)
+		if err != nil {
+			return value, err
+		}
+		return f * factor, nil
+	case "offset":
+		return f + *transform.Offset, nil
+	case "conversion":
+		conversion, err := getConversion(transform.Conversion, unit)
+		if err != nil {
+			return value, err
+		}
+		return conversion(f), nil
+	case "clamp":
+		if transform.Clamp.Min != nil && f < *transform.Clamp.Min {
+			return *transform.Clamp.Min, nil
+		}
+		if transform.Clamp.Max != nil && f > *transform.Clamp.Max {
+			return *transform.Clamp.Max, nil
+		}
+		return f, nil
+	case "precision":
+		return roundFloat(f, *transform.Precision, 64), nil
+	case "significantFigures":
+		return roundSignificant(f, transform.SignificantFigures), nil
+	}
+	return value, fmt.Errorf("invalid transform: exactly one transform should be specified")
+}
+
+// transformStage is a stage of the pipeline which an OutputType applies to
+// reading values. If a stage fails, its result is still passed on to the next
+// stage.
+type transformStage struct {
+	name  string
+	apply func(interface{}) (interface{}, error)
+}
+
+// pipeline gets the ordered stages which are applied to reading values for the
+// output type. If the output type defines transforms, these are its transforms.
+// Otherwise, the stages are built from its scaling factor, scale, conversions,
+// and significant figures, each of which is a no-op when not set.
+func (outputType *OutputType) pipeline() []transformStage {
+	if len(outputType.Transforms) > 0 {
+		stages := make([]transformStage, len(outputType.Transforms))
+		for i, transform := range outputType.Transforms {
+			transform := transform
+			stages[i] = transformStage{name: transform.kind(), apply: func(value interface{}) (interface{}, error) {
+				return transform.apply(value, outputType.Unit.Symbol)
+			}}
+		}
+		return stages
+	}
+
+	infallible := func(fn func(interface{}) interface{}) func(interface{}) (interface{}, error) {
+		return func(value interface{}) (interface{}, error) {
+			return fn(value), nil
+		}
+	}
+	return []transformStage{
+		{name: "scalingFactor", apply: infallible(outputType.applyScalingFactor)},
+		{name: "scale", apply: infallible(outputType.applyScale)},
+		{name: "conversion", apply: outputType.applyConversion},
+		{name: "significantFigures", apply: infallible(outputType.applySignificantFigures)},
+	}
+}
+
+// BitField specifies the bits to extract from an integer reading value. Either
+// the Bit or the Mask should be set. The extracted reading is true if any of the
+// specified bits are set in the reading value.
+type BitField struct {
+	// Bit is the position of a single bit to extract, where 0 is the least
+	// significant bit.
+	Bit *uint `yaml:"bit,omitempty" addedIn:"1.3"`
+
+	// Mask is the mask of the bits to extract, e.g. 0x0C for bits 2 and 3.
+	Mask uint64 `yaml:"mask,omitempty" addedIn:"1.3"`
+}
+
+// mask gets the mask of the bits to extract.
+func (field *BitField) mask() uint64 {
+	if field.Bit != nil {
+		return 1 << *field.Bit
+	}
+	return field.Mask
+}
+
+// extract gets whether any of the bits of the bit field are set in the given
+// integer value.
+func (field *BitField) extract(value interface{}) (bool, error) {
+	bits, err := convertToUint64(value)
+	if err != nil {
+		return false, err
+	}
+	return bits&field.mask() != 0, nil
+}
+
+// SmoothingSettings are the settings for the exponentially-weighted moving average
+// filter of an OutputType.
+type SmoothingSettings struct {
+	// Alpha is the weight given to each new reading value, between 0 (exclusive)
+	// and 1 (inclusive). Lower values give smoother readings which are slower to
+	// respond to changes. An alpha of 1 disables smoothing.
+	Alpha float64 `yaml:"alpha,omitempty" addedIn:"1.3"`
+
+	// KeepRaw specifies whether the raw reading value is kept in the reading
+	// context, under the "raw_value" key.
+	KeepRaw bool `yaml:"keepRaw,omitempty" addedIn:"1.3"`
+}
+
+// smooth gets the new moving average from the previous moving average and a new
+// reading value.
+func (settings *SmoothingSettings) smooth(average, value float64) float64 {
+	return settings.Alpha*value + (1-settings.Alpha)*average
+}
+
+// CounterSettings are the settings for computing rates from the counter readings
+// of an output type. The first reading of a counter only sets the baseline for
+// the rate, so it is not emitted.
+type CounterSettings struct {
+	// Max is the maximum value of the counter, after which it wraps around to
+	// 0, e.g. 4294967295 for a 32-bit counter. A decrease in the counter value
+	// is treated as a wraparound. If this is not set, a decrease is treated as
+	// a counter reset instead, and the reading only sets a new baseline.
+	Max float64 `yaml:"max,omitempty" addedIn:"1.3"`
+
+	// KeepRaw specifies whether the counter value is kept in the reading
+	// context, under the "raw_value" key.
+	KeepRaw bool `yaml:"keepRaw,omitempty" addedIn:"1.3"`
+}
+
+// delta gets the change between two successive counter values. If the counter
+// decreased and it has a max value, it wrapped around. Otherwise, it was reset,
+// and false is returned since the change can not be known.
+func (settings *CounterSettings) delta(previous, value float64) (float64, bool) {
+	if value >= previous {
+		return value - previous, true
+	}
+	if settings.Max <= 0 || previous > settings.Max {
+		return 0, false
+	}
+	return settings.Max - previous + value + 1, true
+}
+
+// compressionGzip is the name of the gzip compression for output types.
+const compressionGzip = "gzip"
+
+// The data types which can be declared for the values of an output type.
+const (
+	dataTypeInt     = "int"
+	dataTypeFloat   = "float"
+	dataTypeString  = "string"
+	dataTypeBool    = "bool"
+	dataTypeBytes   = "bytes"
+	dataTypeDecimal = "decimal"
+)
+
+// The type enforcement modes for the values of an output type.
+const (
+	typeEnforcementLog   = "log"
+	typeEnforcementError = "error"
+)
+
+// valueDataType gets the data type of a reading value, or an empty string if the
+// value is not of any of the declared data types. Values of named types (e.g.
+// enums) have the data type of their underlying type.
+func valueDataType(value interface{}) string {
+	switch value.(type) {
+	case []byte:
+		return dataTypeBytes
+	case Decimal:
+		return dataTypeDecimal
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return dataTypeInt
+	case reflect.Float32, reflect.Float64:
+		return dataTypeFloat
+	case reflect.String:
+		return dataTypeString
+	case reflect.Bool:
+		return dataTypeBool
+	}
+	return ""
+}
+
+// checkDataType checks the type of a value provided for a reading against the
+// output type's DataType, if type enforcement is enabled. If the type does not
+// match, a warning is logged or an error is returned, depending on the type
+// enforcement. Nil values are not checked, since they take the default value.
+func (outputType *OutputType) checkDataType(value interface{}) error {
+	if outputType.DataType == "" || outputType.TypeEnforcement == "" || value == nil {
+		return nil
+	}
+	if valueDataType(value) == outputType.DataType {
+		return nil
+	}
+
+	err := fmt.Errorf(
+		"value %v of type %T does not match the data type %s of output type %s",
+		value, value, outputType.DataType, outputType.Name,
+	)
+	if outputType.TypeEnforcement == typeEnforcementError {
+		return err
+	}
+	repeatedLog.Warnf("[type] %v", err)
+	return nil
+}
+
+// compress compresses the []byte value using the output type's compression. If
+// the output type does not specify a compression, the value is returned as-is.
+func (outputType *OutputType) compress(value []byte) ([]byte, error) {
+	switch outputType.Compression {
+	case "":
+		return value, nil
+	case compressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(value); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression specified: %s", outputType.Compression)
+	}
+}
+
+// siPrefix describes an SI prefix that can be used as the scale of an output type.
+type siPrefix struct {
+	name     string
+	exponent int
+}
+
+// siPrefixes are the supported SI prefixes, by symbol.
+var siPrefixes = map[string]siPrefix{
+	"p":  {name: "pico", exponent: -12},
+	"n":  {name: "nano", exponent: -9},
+	"u":  {name: "micro", exponent: -6},
+	"m":  {name: "milli", exponent: -3},
+	"c":  {name: "centi", exponent: -2},
+	"d":  {name: "deci", exponent: -1},
+	"da": {name: "deca", exponent: 1},
+	"h":  {name: "hecto", exponent: 2},
+	"k":  {name: "kilo", exponent: 3},
+	"M":  {name: "mega", exponent: 6},
+	"G":  {name: "giga", exponent: 9},
+	"T":  {name: "tera", exponent: 12},
+}
+
+// Conversion is a function which converts a reading value from one form to
+// another, e.g. from one unit of measure to another.
+type Conversion func(float64) float64
+
+// builtinConversions are the named conversions that are always available
+// for output types to reference.
+var builtinConversions = map[string]Conversion{
+	"englishToMetricTemperature": func(f float64) float64 {
+		return (f - 32.0) * 5.0 / 9.0
+	},
+	"absoluteToGaugePressure": absoluteToGaugePressure,
+	"gaugeToAbsolutePressure": gaugeToAbsolutePressure,
+}
+
+// builtinConversionUnits are the symbols of the units that the built-in
+// conversions convert to.
+var builtinConversionUnits = map[string]string{
+	"englishToMetricTemperature": "C",
+}
+
+// unitConversion identifies a conversion between two units of measure, by
+// their unit symbols.
+type unitConversion struct {
+	from string
+	to   string
+}
+
+// builtinUnitConversions are the unit conversions that are always available
+// for normalizing reading values to the unit of their output type.
+var builtinUnitConversions = map[unitConversion]Conversion{
+	{from: "psi", to: "kPa"}: func(f float64) float64 {
+		return f * 6.894757293168
+	},
+	{from: "kPa", to: "psi"}: func(f float64) float64 {
+		return f / 6.894757293168
+	},
+	{from: "F", to: "C"}: builtinConversions["englishToMetricTemperature"],
+	{from: "C", to: "F"}: func(f float64) float64 {
+		return f*9.0/5.0 + 32.0
+	},
+}
+
+// ConversionConfig defines a linear conversion between two units of measure,
+// where value' = value * factor + offset. Configured conversions are registered
+// as unit conversions, and, if named, as named conversions which output types
+// can reference in their conversion chain.
+type ConversionConfig struct {
+	// Name is the name of the conversion. If set, output types can reference
+	// the conversion by this name. This is optional.
+	Name string `yaml:"name,omitempty" addedIn:"1.3"`
+
+	// From is the symbol of the unit being converted from.
+	From string `yaml:"from,omitempty" addedIn:"1.3"`
+
+	// To is the symbol of the unit being converted to.
+	To string `yaml:"to,omitempty" addedIn:"1.3"`
+
+	// Factor is the value by which to multiply the reading value. This must
+	// be non-zero.
+	Factor float64 `yaml:"factor,omitempty" addedIn:"1.3"`
+
+	// Offset is the value to add to the reading value, after it is multiplied
+	// by the factor.
+	Offset float64 `yaml:"offset,omitempty" addedIn:"1.3"`
+}
+
+// Validate validates that the ConversionConfig has no configuration errors.
+func (config ConversionConfig) Validate(multiErr *errors.MultiError) {
+	if config.From == "" {
+		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "conversion.from"))
+	}
+	if config.To == "" {
+		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "conversion.to"))
+	}
+	if config.Factor == 0 {
+		multiErr.Add(errors.NewInvalidValueError(multiErr.Context["source"], "conversion.factor", "a non-zero number"))
+	}
+}
+
+// conversion gets the linear Conversion defined by the ConversionConfig.
+func (config *ConversionConfig) conversion() Conversion {
+	factor, offset := config.Factor, config.Offset
+	return func(f float64) float64 {
+		return f*factor + offset
+	}
+}
+
+// registerConfiguredConversions registers the conversions defined in the
+// plugin config with the plugin.
+func registerConfiguredConversions(plugin *Plugin) error {
+	if Config.Plugin == nil {
+		return nil
+	}
+	for _, config := range Config.Plugin.Conversions {
+		conversion := config.conversion()
+		if err := plugin.RegisterUnitConversion(config.From, config.To, conversion); err != nil {
+			return err
+		}
+		if config.Name != "" {
+			if err := plugin.RegisterConversion(config.Name, conversion); err != nil {
+				return err
+			}
+			if err := plugin.RegisterConversionUnit(config.Name, config.To); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeUnit converts a value measured in the given source unit to the
+// canonical unit of the output type. Units are matched by symbol. If no source
+// unit is given, or it is already the canonical unit, the value is returned
+// unchanged.
+func (outputType *OutputType) normalizeUnit(value interface{}, sourceUnit string) (interface{}, error) {
+	if sourceUnit == "" || sourceUnit == outputType.Unit.Symbol {
+		return value, nil
+	}
+
+	conversion, ok := ctx.unitConversions[unitConversion{from: sourceUnit, to: outputType.Unit.Symbol}]
+	if !ok {
+		return nil, fmt.Errorf("no unit conversion from '%s' to '%s'", sourceUnit, outputType.Unit.Symbol)
+	}
+
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		return nil, err
+	}
+	return conversion(f), nil
 }
 
 // JSON encodes the config as JSON. This can be useful for logging and debugging.
@@ -59,14 +670,204 @@ func (outputType *OutputType) JSON() (string, error) {
 func (outputType OutputType) Validate(multiErr *errors.MultiError) {
 	if outputType.Name == "" {
 		multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "outputType.name"))
+	} else if !validNamespacedName(outputType.Name) {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.name",
+			"a name or dot-separated namespace with no empty segments (e.g. foo.bar)",
+		))
 	}
 
 	// Try parsing the scaling factor to validate it is a correctly specified
 	// duration string.
-	_, err := outputType.GetScalingFactor()
+	scalingFactor, err := outputType.GetScalingFactor()
 	if err != nil {
 		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
 	}
+
+	// The scale, if set, must be a known SI prefix.
+	scale, err := outputType.GetScale()
+	if err != nil {
+		multiErr.Add(errors.NewValidationError(multiErr.Context["source"], err.Error()))
+	}
+
+	// Boolean and string values are not scaled, so warn if the output type
+	// scales values of either data type.
+	outputType.warnUnscalableDataType(scalingFactor, scale)
+
+	// The default value, if set, must be a value which readings can be encoded
+	// with, e.g. not a map or list from the YAML config.
+	if outputType.DefaultValue != nil && !isEncodableValue(outputType.DefaultValue) {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.defaultValue",
+			"a scalar value (string, bool, number, or bytes)",
+		))
+	}
+
+	// The compression, if set, must be supported.
+	if outputType.Compression != "" && outputType.Compression != compressionGzip {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.compression",
+			"one of: gzip",
+		))
+	}
+
+	// All conversions in the conversion chain must be known.
+	for _, name := range outputType.getConversions() {
+		if _, ok := ctx.conversions[name]; !ok {
+			multiErr.Add(errors.NewValidationError(
+				multiErr.Context["source"],
+				fmt.Sprintf("unknown conversion specified: %s", name),
+			))
+		}
+	}
+
+	// If the last conversion applied declares the unit it converts to, the
+	// output type must declare the same unit.
+	if name := outputType.lastConversion(); name != "" && outputType.Unit.Symbol != "" {
+		if unit, ok := ctx.conversionUnits[name]; ok && unit != outputType.Unit.Symbol {
+			multiErr.Add(errors.NewInvalidValueError(
+				multiErr.Context["source"],
+				"outputType.unit.symbol",
+				fmt.Sprintf("'%s', the unit that conversion %s converts to", unit, name),
+			))
+		}
+	}
+
+	// The bit field, if configured, must specify either a bit within a 64-bit
+	// value or a mask, but not both.
+	if outputType.BitField != nil {
+		field := outputType.BitField
+		if (field.Bit == nil) == (field.Mask == 0) {
+			multiErr.Add(errors.NewInvalidValueError(
+				multiErr.Context["source"],
+				"outputType.bitField",
+				"one of: bit, mask",
+			))
+		} else if field.Bit != nil && *field.Bit > 63 {
+			multiErr.Add(errors.NewInvalidValueError(
+				multiErr.Context["source"],
+				"outputType.bitField.bit",
+				"a bit position in the range [0, 63]",
+			))
+		}
+	}
+
+	// The number of significant figures, if set, must be positive.
+	if outputType.SignificantFigures < 0 {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.significantFigures",
+			"a positive number of significant figures",
+		))
+	}
+
+	// Transforms replace the scaling factor, scale, conversions, and significant
+	// figures, so they can not be combined.
+	if len(outputType.Transforms) > 0 && (outputType.ScalingFactor != "" || outputType.Scale != "" ||
+		len(outputType.getConversions()) > 0 || outputType.SignificantFigures != 0) {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.transforms",
+			"transforms without a scalingFactor, scale, conversion, conversions, or significantFigures",
+		))
+	}
+
+	// The data type and type enforcement, if set, must be known. Types can only be
+	// enforced if a data type is declared.
+	switch outputType.DataType {
+	case "", dataTypeInt, dataTypeFloat, dataTypeString, dataTypeBool, dataTypeBytes, dataTypeDecimal:
+	default:
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.dataType",
+			"one of: int, float, string, bool, bytes, decimal",
+		))
+	}
+	switch outputType.TypeEnforcement {
+	case "":
+	case typeEnforcementLog, typeEnforcementError:
+		if outputType.DataType == "" {
+			multiErr.Add(errors.NewFieldRequiredError(multiErr.Context["source"], "outputType.dataType"))
+		}
+	default:
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.typeEnforcement",
+			"one of: log, error",
+		))
+	}
+
+	// The counter max, if rates are computed from counters, must not be negative.
+	if outputType.Counter != nil && outputType.Counter.Max < 0 {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.counter.max",
+			"a value greater than or equal to 0",
+		))
+	}
+
+	// Rates are computed from the reading values after they are applied, but the
+	// counter max is in raw counter units, so counters can not be combined with
+	// options which change the reading value.
+	if outputType.Counter != nil && (outputType.ScalingFactor != "" || outputType.Scale != "" ||
+		len(outputType.getConversions()) > 0 || len(outputType.Transforms) > 0 ||
+		outputType.Precision != 0 || outputType.SignificantFigures != 0) {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.counter",
+			"a counter without a scalingFactor, scale, conversion, conversions, transforms, precision, or significantFigures",
+		))
+	}
+
+	// The smoothing alpha, if smoothing is configured, must be in (0, 1].
+	if outputType.Smoothing != nil && (outputType.Smoothing.Alpha <= 0 || outputType.Smoothing.Alpha > 1) {
+		multiErr.Add(errors.NewInvalidValueError(
+			multiErr.Context["source"],
+			"outputType.smoothing.alpha",
+			"a value in the range (0, 1]",
+		))
+	}
+}
+
+// validNamespacedName checks whether an output type name is well-formed. The name
+// may be namespaced with dots, but none of its segments may be empty (e.g. from a
+// leading, trailing, or repeated dot) or contain whitespace, since the last segment
+// is used as the reading type.
+func validNamespacedName(name string) bool {
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" || strings.IndexFunc(segment, unicode.IsSpace) != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// getConversions gets the names of all conversions to apply for the output type,
+// in the order that they should be applied.
+func (outputType *OutputType) getConversions() []string {
+	if outputType.Conversion == "" {
+		return outputType.Conversions
+	}
+	return append([]string{outputType.Conversion}, outputType.Conversions...)
+}
+
+// lastConversion gets the name of the last conversion applied for the output
+// type, from either its transforms or its conversion chain. If the output type
+// has no conversions, an empty string is returned.
+func (outputType *OutputType) lastConversion() string {
+	for i := len(outputType.Transforms) - 1; i >= 0; i-- {
+		if outputType.Transforms[i].Conversion != "" {
+			return outputType.Transforms[i].Conversion
+		}
+	}
+	conversions := outputType.getConversions()
+	if len(conversions) == 0 {
+		return ""
+	}
+	return conversions[len(conversions)-1]
 }
 
 // Type gets the type of the reading. This is encoded in the OutputType
@@ -88,6 +889,123 @@ func (outputType *OutputType) GetScalingFactor() (float64, error) {
 	return strconv.ParseFloat(outputType.ScalingFactor, 64)
 }
 
+// GetScale gets the multiplier for the SI prefix scale of the reading type. This
+// is the inverse of the power of ten for the prefix, e.g. 0.001 for "k".
+func (outputType *OutputType) GetScale() (float64, error) {
+	if outputType.Scale == "" {
+		return 1, nil
+	}
+	prefix, ok := siPrefixes[outputType.Scale]
+	if !ok {
+		return 0, fmt.Errorf("unknown SI prefix specified for scale: %s", outputType.Scale)
+	}
+	return math.Pow10(-prefix.exponent), nil
+}
+
+// Bounds gets the range of values expected for readings of the output type. The
+// range is that of its ValidMin and ValidMax, narrowed by the range of the last
+// transform in its pipeline if that transform is a clamp, since Apply then limits
+// reading values to that range. Either bound is nil if the values are not bounded
+// in that direction.
+func (outputType *OutputType) Bounds() (min, max *float64) {
+	min, max = outputType.ValidMin, outputType.ValidMax
+
+	if n := len(outputType.Transforms); n > 0 && outputType.Transforms[n-1].kind() == "clamp" {
+		clamp := outputType.Transforms[n-1].Clamp
+		if clamp.Min != nil && (min == nil || *clamp.Min > *min) {
+			min = clamp.Min
+		}
+		if clamp.Max != nil && (max == nil || *clamp.Max < *max) {
+			max = clamp.Max
+		}
+	}
+	return min, max
+}
+
+// EffectiveUnit gets the unit of the readings for the output type. If the
+// output type has a scale, this is its unit with the SI prefix applied.
+// Otherwise, it is the unit of the output type.
+func (outputType *OutputType) EffectiveUnit() Unit {
+	prefix, ok := siPrefixes[outputType.Scale]
+	if !ok {
+		return outputType.Unit
+	}
+
+	unit := Unit{}
+	if outputType.Unit.Name != "" {
+		unit.Name = prefix.name + outputType.Unit.Name
+	}
+	if outputType.Unit.Symbol != "" {
+		unit.Symbol = outputType.Scale + outputType.Unit.Symbol
+	}
+	return unit
+}
+
+// applyScale converts the reading value to the unit with the output type's SI
+// prefix (see EffectiveUnit) and returns the scaled reading.
+func (outputType *OutputType) applyScale(value interface{}) interface{} {
+	scale, err := outputType.GetScale()
+	if err != nil {
+		log.Errorf("[type] Unable to get scale for outputType %+v, error: %v", outputType, err.Error())
+		return value
+	}
+
+	// If the scale is 1, there is nothing to do. Return the value.
+	if scale == 1 {
+		return value
+	}
+
+	if isUnscalable(value) {
+		repeatedLog.Warnf("[type] not applying scale %v to %T value %v", outputType.Scale, value, value)
+		return value
+	}
+
+	// Decimal values are scaled exactly.
+	exponent := siPrefixes[outputType.Scale].exponent
+	if d, isDecimal := value.(Decimal); isDecimal {
+		return d.Mul(decimalPow10(-exponent))
+	}
+
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		log.Errorf("[type] Unable to apply scale %v to value %v of type %T", outputType.Scale, value, value)
+		return value
+	}
+	// Scale by an exact power of ten, since the multiplier is not exact for
+	// positive exponents (e.g. 0.001).
+	if exponent > 0 {
+		return f / math.Pow10(exponent)
+	}
+	return f * math.Pow10(-exponent)
+}
+
+// isUnscalable checks whether a reading value is of a type which is not scaled.
+// Boolean and string values are returned unchanged by the scaling factor and
+// scale, since scaling them is not meaningful (e.g. true * 0.5).
+func isUnscalable(value interface{}) bool {
+	switch value.(type) {
+	case bool, string:
+		return true
+	}
+	return false
+}
+
+// warnUnscalableDataType logs a warning if the output type declares a boolean
+// or string data type, but has a scaling factor or scale which is not the
+// identity. Values of those types are not scaled (see isUnscalable), so this is
+// likely a config mistake. This does not fail validation.
+func (outputType *OutputType) warnUnscalableDataType(scalingFactor, scale float64) {
+	if outputType.DataType != dataTypeBool && outputType.DataType != dataTypeString {
+		return
+	}
+	if (scalingFactor != 0 && scalingFactor != 1) || (scale != 0 && scale != 1) {
+		log.WithFields(log.Fields{
+			"outputType": outputType.Name,
+			"dataType":   outputType.DataType,
+		}).Warn("[type] scaling factor and scale are not applied to values of the output type's data type")
+	}
+}
+
 // applyScalingFactor multiplies the raw reading value (the value parameter) by the output
 // scaling factor and returns the scaled reading.
 func (outputType *OutputType) applyScalingFactor(value interface{}) interface{} {
@@ -112,6 +1030,22 @@ func (outputType *OutputType) applyScalingFactor(value interface{}) interface{}
 		return value
 	}
 
+	if isUnscalable(value) {
+		repeatedLog.Warnf("[type] not applying scaling factor %v to %T value %v", outputType.ScalingFactor, value, value)
+		return value
+	}
+
+	// Decimal values are scaled exactly, using the decimal representation of the
+	// scaling factor rather than its float value.
+	if d, isDecimal := value.(Decimal); isDecimal {
+		factor, err := NewDecimal(outputType.ScalingFactor)
+		if err != nil {
+			log.Errorf("[type] Unable to apply scaling factor %v to decimal value %v: %v", outputType.ScalingFactor, d, err)
+			return value
+		}
+		return d.Mul(factor)
+	}
+
 	// Otherwise, the scaling factor is non-zero and not 1, so it will
 	// need to be applied.
 	f, err := ConvertToFloat64(value)
@@ -122,46 +1056,149 @@ func (outputType *OutputType) applyScalingFactor(value interface{}) interface{}
 	return f * scalingFactor
 }
 
-// applyConversion applies the conversion based on the output conversion string and
-// the scaled reading.
-// For now this is pretty limited, but it's a place to start.
+// applyConversion applies the conversions specified for the output type to the
+// scaled reading, in order. Each conversion is applied to the result of the
+// previous conversion.
 func (outputType *OutputType) applyConversion(value interface{}) (result interface{}, err error) {
-	// Only one supported conversion string for now.
-	switch outputType.Conversion {
-	case "": // Nothing to do.
+	conversions := outputType.getConversions()
+	if len(conversions) == 0 {
+		// Nothing to do.
 		return value, nil
-	case "englishToMetricTemperature":
-		var f float64
-		f, err = ConvertToFloat64(value)
+	}
+
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		return
+	}
+	for _, name := range conversions {
+		conversion, err := getConversion(name, outputType.Unit.Symbol)
 		if err != nil {
-			return
+			return nil, err
 		}
-		c := (f - 32.0) * 5.0 / 9.0
-		return c, nil
-	default:
-		return nil, fmt.Errorf("Unknown conversion %v", outputType.Conversion)
+		f = conversion(f)
+	}
+	return f, nil
+}
+
+// getConversion gets the named conversion, for values in the unit with the given
+// symbol. Most conversions do not depend on the unit, but the conversions between
+// absolute and gauge pressure use the atmospheric baseline for the unit.
+func getConversion(name, unit string) (Conversion, error) {
+	conversion, ok := ctx.conversions[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown conversion %v", name)
+	}
+	if _, isPressure := pressureConversions[name]; isPressure {
+		return pressureConversion(name, unit)
 	}
+	return conversion, nil
+}
+
+// applySignificantFigures rounds a numeric reading value to the significant
+// figures specified for the output type. Non-numeric values are returned as-is.
+func (outputType *OutputType) applySignificantFigures(value interface{}) interface{} {
+	if outputType.SignificantFigures <= 0 {
+		return value
+	}
+	if _, isString := value.(string); isString {
+		return value
+	}
+	if d, isDecimal := value.(Decimal); isDecimal {
+		return d.RoundSignificant(outputType.SignificantFigures)
+	}
+
+	f, err := ConvertToFloat64(value)
+	if err != nil {
+		return value
+	}
+	return roundSignificant(f, outputType.SignificantFigures)
+}
+
+// roundSignificant rounds a floating point value to the given number of
+// significant figures, e.g. 12345 to 12300 for 3 significant figures.
+func roundSignificant(value float64, figures int) float64 {
+	if value == 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', figures, 64), 64)
+	if err != nil {
+		return value
+	}
+	return rounded
 }
 
 // Apply applies the transformations specified by the OutputType to
-// a reading value. These transformations are (in the order that they
-// are applied): multiply scaling factor.
+// a reading value. If the OutputType defines transforms, they are applied
+// in order. Otherwise, these transformations are (in the order that they
+// are applied): multiply scaling factor, multiply scale, apply conversions,
+// round to significant figures.
+//
+// If the value is nil, the OutputType's default value is returned instead
+// and no transformations are applied.
+//
+// If the OutputType specifies a bit field, the value is instead translated to
+// a boolean indicating whether the bits of the bit field are set, and no other
+// transformations are applied.
 //
 // Precision is not applied at this level, but will instead be applied
 // in Synse server before the corresponding reading is returned to the
 // user.
 func (outputType *OutputType) Apply(value interface{}) interface{} {
+	if value == nil {
+		return outputType.DefaultValue
+	}
 
-	value = outputType.applyScalingFactor(value)
+	if outputType.BitField != nil {
+		set, err := outputType.BitField.extract(value)
+		if err != nil {
+			log.Errorf("Unable to extract bit field from value %v: %v", value, err)
+			// TODO: Return the error.
+			return value
+		}
+		return set
+	}
 
-	value, err := outputType.applyConversion(value)
-	if err != nil {
-		log.Errorf("Unable to apply conversion: %v, error %v", outputType.Conversion, err)
-		// TODO: Return the error.
+	for _, stage := range outputType.pipeline() {
+		result, err := stage.apply(value)
+		if err != nil {
+			log.Errorf("Unable to apply %s transform to value %v, error %v", stage.name, value, err)
+			// TODO: Return the error.
+		}
+		value = result
 	}
 	return value
 }
 
+// PreviewReading gets the reading that the OutputType would produce for the given
+// value, without emitting it. The transforms of the OutputType are applied just as
+// they are for device readings, so this can be used to check the type and value
+// of the readings that a handler's values will produce, e.g. when debugging type
+// mismatches. An error is returned if the reading could not be made, or if its
+// value could not be encoded to send to Synse Server.
+func (outputType *OutputType) PreviewReading(value interface{}) (*Reading, error) {
+	reading, err := NewReading(&Output{OutputType: *outputType}, value)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEncodable(reading); err != nil {
+		return nil, err
+	}
+	return reading, nil
+}
+
+// checkEncodable checks that the Reading can be encoded to its gRPC message.
+// Encoding a reading with an unsupported value type panics, since that indicates
+// bad data from the plugin, so the panic is recovered here to report it as an error.
+func checkEncodable(reading *Reading) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unsupported reading value type: %T", reading.Value)
+		}
+	}()
+	reading.encode()
+	return nil
+}
+
 // Unit is the unit of measure for a device reading.
 type Unit struct {
 	// Name is the full name of the unit.
@@ -3,9 +3,12 @@ package sdk
 import (
 	"testing"
 
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 	"github.com/vapor-ware/synse-server-grpc/go"
+	"gopkg.in/yaml.v2"
 )
 
 // TestOutputType_Type tests getting the type of the reading
@@ -47,6 +50,63 @@ func TestOutputType_Validate_Ok(t *testing.T) {
 				Name: "test",
 			},
 		},
+		{
+			desc: "Valid OutputType instance with a bit field",
+			output: OutputType{
+				Name:     "test",
+				BitField: &BitField{Mask: 0x0C},
+			},
+		},
+		{
+			desc: "Valid OutputType instance with a namespaced name",
+			output: OutputType{
+				Name: "vaporio.fan.speed",
+			},
+		},
+		{
+			desc: "Valid OutputType instance with conversions",
+			output: OutputType{
+				Name:        "test",
+				Conversion:  "englishToMetricTemperature",
+				Conversions: []string{"englishToMetricTemperature"},
+			},
+		},
+		{
+			desc: "Valid OutputType instance with a conversion to its unit",
+			output: OutputType{
+				Name:       "temperature",
+				Unit:       Unit{Name: "celsius", Symbol: "C"},
+				Conversion: "englishToMetricTemperature",
+			},
+		},
+		{
+			desc: "Valid OutputType instance with compression",
+			output: OutputType{
+				Name:        "test",
+				Compression: "gzip",
+			},
+		},
+		{
+			desc: "Valid OutputType instance with scale",
+			output: OutputType{
+				Name:  "test",
+				Scale: "k",
+			},
+		},
+		{
+			desc: "Valid OutputType instance with smoothing",
+			output: OutputType{
+				Name:      "test",
+				Smoothing: &SmoothingSettings{Alpha: 1},
+			},
+		},
+		{
+			desc: "Valid OutputType instance with a default value",
+			output: OutputType{
+				Name:         "test",
+				DefaultValue: -1,
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -69,6 +129,58 @@ func TestOutputType_Validate_Error(t *testing.T) {
 			errCount: 1,
 			output:   OutputType{},
 		},
+		{
+			desc:     "OutputType has a bit field with no bit or mask",
+			errCount: 1,
+			output: OutputType{
+				Name:     "test",
+				BitField: &BitField{},
+			},
+		},
+		{
+			desc:     "OutputType has a bit field with both a bit and a mask",
+			errCount: 1,
+			output: OutputType{
+				Name:     "test",
+				BitField: &BitField{Bit: new(uint), Mask: 0x01},
+			},
+		},
+		{
+			desc:     "OutputType has a bit field with an out of range bit",
+			errCount: 1,
+			output: OutputType{
+				Name: "test",
+				BitField: func() *BitField {
+					b := uint(64)
+					return &BitField{Bit: &b}
+				}(),
+			},
+		},
+		{
+			desc:     "OutputType name has an empty namespace segment",
+			errCount: 1,
+			output:   OutputType{Name: "foo..bar"},
+		},
+		{
+			desc:     "OutputType name has a leading dot",
+			errCount: 1,
+			output:   OutputType{Name: ".foo.bar"},
+		},
+		{
+			desc:     "OutputType name has a trailing dot",
+			errCount: 1,
+			output:   OutputType{Name: "foo.bar."},
+		},
+		{
+			desc:     "OutputType name is only a dot",
+			errCount: 1,
+			output:   OutputType{Name: "."},
+		},
+		{
+			desc:     "OutputType name has whitespace in a segment",
+			errCount: 1,
+			output:   OutputType{Name: "foo. bar"},
+		},
 		{
 			desc:     "OutputType has an invalid scaling factor",
 			errCount: 1,
@@ -77,6 +189,113 @@ func TestOutputType_Validate_Error(t *testing.T) {
 				ScalingFactor: "invalid factor",
 			},
 		},
+		{
+			desc:     "OutputType has an unsupported compression",
+			errCount: 1,
+			output: OutputType{
+				Name:        "test",
+				Compression: "zip",
+			},
+		},
+		{
+			desc:     "OutputType has an unknown scale prefix",
+			errCount: 1,
+			output: OutputType{
+				Name:  "test",
+				Scale: "x",
+			},
+		},
+		{
+			desc:     "OutputType has a map default value",
+			errCount: 1,
+			output: OutputType{
+				Name:         "test",
+				DefaultValue: map[interface{}]interface{}{"foo": "bar"},
+			},
+		},
+		{
+			desc:     "OutputType has a list default value",
+			errCount: 1,
+			output: OutputType{
+				Name:         "test",
+				DefaultValue: []interface{}{1, 2},
+			},
+		},
+		{
+			desc:     "OutputType has a smoothing alpha of 0",
+			errCount: 1,
+			output: OutputType{
+				Name:      "test",
+				Smoothing: &SmoothingSettings{},
+			},
+		},
+		{
+			desc:     "OutputType has a smoothing alpha greater than 1",
+			errCount: 1,
+			output: OutputType{
+				Name:      "test",
+				Smoothing: &SmoothingSettings{Alpha: 1.5},
+			},
+		},
+		{
+			desc:     "OutputType has an unknown data type",
+			errCount: 1,
+			output: OutputType{
+				Name:     "test",
+				DataType: "integer",
+			},
+		},
+		{
+			desc:     "OutputType has an unknown type enforcement",
+			errCount: 1,
+			output: OutputType{
+				Name:            "test",
+				DataType:        "int",
+				TypeEnforcement: "strict",
+			},
+		},
+		{
+			desc:     "OutputType has type enforcement without a data type",
+			errCount: 1,
+			output: OutputType{
+				Name:            "test",
+				TypeEnforcement: "error",
+			},
+		},
+		{
+			desc:     "OutputType has a negative counter max",
+			errCount: 1,
+			output: OutputType{
+				Name:    "test",
+				Counter: &CounterSettings{Max: -1},
+			},
+		},
+		{
+			desc:     "OutputType has a counter and a scale",
+			errCount: 1,
+			output: OutputType{
+				Name:    "test",
+				Scale:   "k",
+				Counter: &CounterSettings{Max: 4294967295},
+			},
+		},
+		{
+			desc:     "OutputType has transforms and a scaling factor",
+			errCount: 1,
+			output: OutputType{
+				Name:          "test",
+				ScalingFactor: "2",
+				Transforms:    []*Transform{{Factor: "2"}},
+			},
+		},
+		{
+			desc:     "OutputType has a negative number of significant figures",
+			errCount: 1,
+			output: OutputType{
+				Name:               "test",
+				SignificantFigures: -1,
+			},
+		},
 		{
 			desc:     "OutputType has an invalid scaling factor and no name",
 			errCount: 2,
@@ -84,6 +303,31 @@ func TestOutputType_Validate_Error(t *testing.T) {
 				ScalingFactor: "invalid factor",
 			},
 		},
+		{
+			desc:     "OutputType has an unknown conversion",
+			errCount: 1,
+			output: OutputType{
+				Name:       "test",
+				Conversion: "unknown",
+			},
+		},
+		{
+			desc:     "OutputType has unknown conversions in its conversion chain",
+			errCount: 2,
+			output: OutputType{
+				Name:        "test",
+				Conversions: []string{"unknown-1", "englishToMetricTemperature", "unknown-2"},
+			},
+		},
+		{
+			desc:     "OutputType has a conversion to a different unit than its own",
+			errCount: 1,
+			output: OutputType{
+				Name:       "temperature",
+				Unit:       Unit{Name: "fahrenheit", Symbol: "F"},
+				Conversion: "englishToMetricTemperature",
+			},
+		},
 	}
 
 	for _, testCase := range testTable {
@@ -459,13 +703,12 @@ func TestOutputType_Apply(t *testing.T) {
 			expected: float64(1.5),
 		},
 		{
-			// TODO: This should probably be an error case. Scaling a bool.
 			desc: "value is a bool, factor is < 1",
 			output: OutputType{
 				ScalingFactor: "0.5",
 			},
 			value:    true,
-			expected: float64(0),
+			expected: true,
 		},
 		{
 			desc: "value is a uint, factor is 1",
@@ -569,119 +812,1178 @@ func TestOutputType_Apply(t *testing.T) {
 	}
 }
 
-// TestOutputType_Apply_Error tests applying when the scaling factor is invalid.
-func TestOutputType_Apply_Error(t *testing.T) {
+// TestOutputType_Apply_ConversionChain tests applying a chain of conversions.
+func TestOutputType_Apply_ConversionChain(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("countsToVolts", func(f float64) float64 { return f * 5 / 1024 })
+	assert.NoError(t, err)
+	err = plugin.RegisterConversion("voltsToPsi", func(f float64) float64 { return (f - 0.5) * 25 })
+	assert.NoError(t, err)
+
 	output := OutputType{
-		ScalingFactor: "foobar",
+		Name:        "pressure",
+		Conversions: []string{"countsToVolts", "voltsToPsi"},
 	}
-	actual := output.Apply(2)
-	assert.Equal(t, 2, actual, "on parse failure, nothing changes")
+	actual := output.Apply(512)
+	assert.Equal(t, float64(50), actual)
+
+	// Conversion order matters.
+	output.Conversions = []string{"voltsToPsi", "countsToVolts"}
+	actual = output.Apply(512)
+	assert.Equal(t, float64(62.43896484375), actual)
 }
 
-// TestUnit_Validate tests validating the Unit. There is nothing to validate here,
-// so it should all validate successfully.
-func TestUnit_Validate(t *testing.T) {
-	merr := errors.NewMultiError("test")
-	unit := Unit{}
-	unit.Validate(merr)
-	assert.NoError(t, merr.Err())
+// TestOutputType_Apply_ConversionChain2 tests applying a chain of conversions
+// when the single conversion is also set.
+func TestOutputType_Apply_ConversionChain2(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("double", func(f float64) float64 { return f * 2 })
+	assert.NoError(t, err)
+
+	output := OutputType{
+		Name:        "temperature",
+		Conversion:  "englishToMetricTemperature",
+		Conversions: []string{"double"},
+	}
+	actual := output.Apply(212)
+	assert.Equal(t, float64(200), actual)
 }
 
-// TestUnit_Encode tests encoding the Unit to the gRPC message.
-func TestUnit_Encode(t *testing.T) {
+// TestOutputType_Apply_ConfiguredConversion tests loading a conversion table
+// from the plugin config and applying a configured conversion by name.
+func TestOutputType_Apply_ConfiguredConversion(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	data := `
+version: 1.0
+network:
+  type: tcp
+  address: localhost:5001
+conversions:
+- name: kelvinToCelsius
+  from: K
+  to: C
+  factor: 1
+  offset: -273.15
+- from: inH2O
+  to: Pa
+  factor: 249.08891
+`
+	cfg := &PluginConfig{}
+	err := yaml.Unmarshal([]byte(data), cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cfg.Conversions))
+	Config.Plugin = cfg
+
+	plugin := NewPlugin()
+	err = registerConfiguredConversions(plugin)
+	assert.NoError(t, err)
+
+	output := OutputType{
+		Name:        "temperature",
+		Conversions: []string{"kelvinToCelsius"},
+	}
+	actual := output.Apply(300)
+	assert.InDelta(t, 26.85, actual, 0.0000001)
+
+	// Configured conversions are also registered as unit conversions.
+	_, exists := ctx.unitConversions[unitConversion{from: "inH2O", to: "Pa"}]
+	assert.True(t, exists)
+	_, exists = ctx.conversions["inH2O"]
+	assert.False(t, exists)
+
+	output = OutputType{Name: "pressure", Unit: Unit{Symbol: "Pa"}}
+	actual, err = output.normalizeUnit(2, "inH2O")
+	assert.NoError(t, err)
+	assert.Equal(t, 498.17782, actual)
+}
+
+// Test_registerConfiguredConversions tests registering a configured conversion
+// which conflicts with an existing unit conversion.
+func Test_registerConfiguredConversions(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Conversions: []*ConversionConfig{
+			{From: "F", To: "C", Factor: 1},
+		},
+	}
+
+	err := registerConfiguredConversions(NewPlugin())
+	assert.Error(t, err)
+}
+
+// TestConversionConfig_Validate tests validating conversion configs.
+func TestConversionConfig_Validate(t *testing.T) {
 	var testTable = []struct {
-		desc    string
-		unit    Unit
-		message synse.Unit
+		desc   string
+		config ConversionConfig
+		errors int
 	}{
 		{
-			desc:    "empty unit",
-			unit:    Unit{},
-			message: synse.Unit{},
+			desc:   "valid conversion",
+			config: ConversionConfig{Name: "foo", From: "K", To: "C", Factor: 1, Offset: -273.15},
+			errors: 0,
 		},
 		{
-			desc: "name and symbol specified",
-			unit: Unit{
-				Name:   "foo",
-				Symbol: "bar",
-			},
-			message: synse.Unit{
-				Name:   "foo",
-				Symbol: "bar",
-			},
+			desc:   "valid conversion, no name",
+			config: ConversionConfig{From: "K", To: "C", Factor: 1},
+			errors: 0,
 		},
 		{
-			desc: "only name specified",
-			unit: Unit{
-				Name: "foo",
-			},
-			message: synse.Unit{
-				Name: "foo",
-			},
+			desc:   "missing units",
+			config: ConversionConfig{Factor: 1},
+			errors: 2,
 		},
 		{
-			desc: "only symbol specified",
-			unit: Unit{
-				Symbol: "bar",
-			},
-			message: synse.Unit{
-				Symbol: "bar",
-			},
+			desc:   "zero factor",
+			config: ConversionConfig{From: "K", To: "C", Offset: -273.15},
+			errors: 1,
 		},
 	}
 
 	for _, testCase := range testTable {
-		actual := testCase.unit.encode()
-		assert.Equal(t, testCase.unit.Name, actual.GetName())
-		assert.Equal(t, testCase.unit.Symbol, actual.GetSymbol())
+		merr := errors.NewMultiError("test")
+		testCase.config.Validate(merr)
+		assert.Equal(t, testCase.errors, len(merr.Errors), testCase.desc)
 	}
 }
 
-// TestNilOutput verifies a parameter check for Output.
-func TestNilOutput(t *testing.T) {
-	var output *Output // nolint: megacheck
-	output = nil
-	_, err := output.MakeReading("should fail")
-	if err == nil {
-		t.Error("nil OutputType should fail")
-	}
-}
+// TestOutputType_Bounds tests getting the bounds of the values for an output type.
+func TestOutputType_Bounds(t *testing.T) {
+	lo, hi := -10.0, 10.0
+	validMin, validMax := -5.0, 50.0
 
-// Test dumping an OutputType to a JSON string.
-func TestOutputType_JSON(t *testing.T) {
 	var testTable = []struct {
-		output   OutputType
-		expected string
+		desc   string
+		output OutputType
+		min    *float64
+		max    *float64
 	}{
 		{
-			output:   OutputType{},
-			expected: `{"Version":"","Name":"","Precision":0,"Unit":{"Name":"","Symbol":""},"ScalingFactor":"","Conversion":""}`,
+			desc:   "no bounds",
+			output: OutputType{Name: "test"},
 		},
 		{
-			output: OutputType{
-				Name:      "foo",
-				Precision: 2,
-			},
-			expected: `{"Version":"","Name":"foo","Precision":2,"Unit":{"Name":"","Symbol":""},"ScalingFactor":"","Conversion":""}`,
+			desc:   "valid range",
+			output: OutputType{Name: "test", ValidMin: &validMin, ValidMax: &validMax},
+			min:    &validMin,
+			max:    &validMax,
 		},
 		{
-			output: OutputType{
-				Name:      "test",
-				Precision: 4,
-				Unit: Unit{
-					Name:   "unit",
-					Symbol: "u",
-				},
-				ScalingFactor: "1e6",
-				Conversion:    "englishToMetric",
-			},
-			expected: `{"Version":"","Name":"test","Precision":4,"Unit":{"Name":"unit","Symbol":"u"},"ScalingFactor":"1e6","Conversion":"englishToMetric"}`,
+			desc: "clamp narrows the valid range",
+			output: OutputType{Name: "test", ValidMin: &validMin, ValidMax: &validMax, Transforms: []*Transform{
+				{Clamp: &ClampRange{Min: &lo, Max: &hi}},
+			}},
+			min: &validMin,
+			max: &hi,
+		},
+		{
+			desc: "clamp with one bound",
+			output: OutputType{Name: "test", Transforms: []*Transform{
+				{Clamp: &ClampRange{Max: &hi}},
+			}},
+			max: &hi,
+		},
+		{
+			desc: "clamp is not the last transform",
+			output: OutputType{Name: "test", Transforms: []*Transform{
+				{Clamp: &ClampRange{Min: &lo, Max: &hi}},
+				{Factor: "2"},
+			}},
 		},
 	}
 
 	for _, testCase := range testTable {
-		actual, err := testCase.output.JSON()
-		assert.NoError(t, err)
+		min, max := testCase.output.Bounds()
+		assert.Equal(t, testCase.min, min, testCase.desc)
+		assert.Equal(t, testCase.max, max, testCase.desc)
+	}
+}
+
+// TestOutputType_Bounds_Apply tests that reading values are limited to the bounds
+// of an output type when it is applied.
+func TestOutputType_Bounds_Apply(t *testing.T) {
+	lo, hi := 0.0, 100.0
+	output := OutputType{Name: "humidity", Transforms: []*Transform{
+		{Factor: "0.1"},
+		{Clamp: &ClampRange{Min: &lo, Max: &hi}},
+	}}
+
+	min, max := output.Bounds()
+	for _, value := range []interface{}{-30, 0, 500, 1200} {
+		applied, err := ConvertToFloat64(output.Apply(value))
+		assert.NoError(t, err)
+		assert.True(t, applied >= *min && applied <= *max, "value %v applied as %v", value, applied)
+	}
+	assert.Equal(t, 0.0, output.Apply(-30))
+	assert.Equal(t, 100.0, output.Apply(1200))
+}
+
+// TestPlugin_RegisterConversionUnit tests declaring the unit that a conversion
+// converts to, and validating output types against it.
+func TestPlugin_RegisterConversionUnit(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("countsToVolts", func(f float64) float64 { return f * 5 / 1024 })
+	assert.NoError(t, err)
+	err = plugin.RegisterConversionUnit("countsToVolts", "V")
+	assert.NoError(t, err)
+
+	match := OutputType{
+		Name:       "voltage",
+		Unit:       Unit{Name: "volt", Symbol: "V"},
+		Transforms: []*Transform{{Conversion: "countsToVolts"}, {Factor: "2"}},
+	}
+	merr := errors.NewMultiError("test")
+	match.Validate(merr)
+	assert.NoError(t, merr.Err())
+
+	mismatch := OutputType{
+		Name:        "voltage",
+		Unit:        Unit{Name: "millivolt", Symbol: "mV"},
+		Conversions: []string{"countsToVolts"},
+	}
+	merr = errors.NewMultiError("test")
+	mismatch.Validate(merr)
+	assert.Error(t, merr.Err())
+	assert.Equal(t, 1, len(merr.Errors))
+	assert.Contains(t, merr.Errors[0].Error(), "'V', the unit that conversion countsToVolts converts to")
+}
+
+// TestPlugin_RegisterConversionUnit_NotFound tests declaring the unit of a
+// conversion which is not registered.
+func TestPlugin_RegisterConversionUnit_NotFound(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversionUnit("countsToVolts", "V")
+	assert.Error(t, err)
+	assert.NotContains(t, ctx.conversionUnits, "countsToVolts")
+}
+
+// Test_registerConfiguredConversions2 tests that named configured conversions
+// declare the unit they convert to.
+func Test_registerConfiguredConversions2(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Conversions: []*ConversionConfig{
+			{Name: "kelvinToCelsius", From: "K", To: "C", Factor: 1, Offset: -273.15},
+		},
+	}
+
+	err := registerConfiguredConversions(NewPlugin())
+	assert.NoError(t, err)
+	assert.Equal(t, "C", ctx.conversionUnits["kelvinToCelsius"])
+}
+
+// TestPlugin_RegisterConversion_Duplicate tests registering a conversion with
+// a name that is already registered.
+func TestPlugin_RegisterConversion_Duplicate(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("englishToMetricTemperature", func(f float64) float64 { return f })
+	assert.Error(t, err)
+}
+
+// TestOutputType_Apply_DefaultValue tests that the default value is used in
+// place of a nil reading value.
+func TestOutputType_Apply_DefaultValue(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "nil value, no default",
+			output:   OutputType{},
+			value:    nil,
+			expected: nil,
+		},
+		{
+			desc:     "nil value, no default, with conversion",
+			output:   OutputType{ScalingFactor: "2", Conversion: "englishToMetricTemperature"},
+			value:    nil,
+			expected: nil,
+		},
+		{
+			desc:     "nil value, sentinel default",
+			output:   OutputType{DefaultValue: -1},
+			value:    nil,
+			expected: -1,
+		},
+		{
+			desc:     "nil value, default is not scaled or converted",
+			output:   OutputType{DefaultValue: -1, ScalingFactor: "2", Conversion: "englishToMetricTemperature"},
+			value:    nil,
+			expected: -1,
+		},
+		{
+			desc:     "nil value, string default",
+			output:   OutputType{DefaultValue: "unavailable"},
+			value:    nil,
+			expected: "unavailable",
+		},
+		{
+			desc:     "non-nil value, default is not used",
+			output:   OutputType{DefaultValue: -1},
+			value:    3,
+			expected: 3,
+		},
+		{
+			desc:     "non-nil value, default is not used, with scaling",
+			output:   OutputType{DefaultValue: -1, ScalingFactor: "2"},
+			value:    3,
+			expected: float64(6),
+		},
+		{
+			desc:     "zero value, default is not used",
+			output:   OutputType{DefaultValue: -1},
+			value:    0,
+			expected: 0,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := testCase.output.Apply(testCase.value)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestOutputType_Apply_Scale tests applying the SI prefix scale of an OutputType.
+func TestOutputType_Apply_Scale(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "no scale",
+			output:   OutputType{},
+			value:    3,
+			expected: 3,
+		},
+		{
+			desc:     "kilo scale",
+			output:   OutputType{Scale: "k"},
+			value:    3000,
+			expected: float64(3),
+		},
+		{
+			desc:     "milli scale",
+			output:   OutputType{Scale: "m"},
+			value:    2.5,
+			expected: float64(2500),
+		},
+		{
+			desc:     "kilo scale with scaling factor",
+			output:   OutputType{Scale: "k", ScalingFactor: "2"},
+			value:    3000,
+			expected: float64(6),
+		},
+		{
+			desc:     "unknown scale is not applied",
+			output:   OutputType{Scale: "x"},
+			value:    3,
+			expected: 3,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := testCase.output.Apply(testCase.value)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestOutputType_Apply_SignificantFigures tests applying an OutputType which
+// rounds reading values to significant figures.
+func TestOutputType_Apply_SignificantFigures(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "no significant figures",
+			output:   OutputType{},
+			value:    12345,
+			expected: 12345,
+		},
+		{
+			desc:     "large int value",
+			output:   OutputType{SignificantFigures: 3},
+			value:    12345,
+			expected: float64(12300),
+		},
+		{
+			desc:     "small float value",
+			output:   OutputType{SignificantFigures: 3},
+			value:    0.0012345,
+			expected: 0.00123,
+		},
+		{
+			desc:     "rounds up",
+			output:   OutputType{SignificantFigures: 3},
+			value:    0.0012355,
+			expected: 0.00124,
+		},
+		{
+			desc:     "negative value",
+			output:   OutputType{SignificantFigures: 2},
+			value:    -987.6,
+			expected: float64(-990),
+		},
+		{
+			desc:     "zero value",
+			output:   OutputType{SignificantFigures: 3},
+			value:    0,
+			expected: float64(0),
+		},
+		{
+			desc:     "rounded after scaling",
+			output:   OutputType{SignificantFigures: 3, ScalingFactor: "0.001"},
+			value:    12345,
+			expected: 12.3,
+		},
+		{
+			desc:     "rounded after scale",
+			output:   OutputType{SignificantFigures: 2, Scale: "k"},
+			value:    1234,
+			expected: 1.2,
+		},
+		{
+			desc:     "string value is unchanged",
+			output:   OutputType{SignificantFigures: 3},
+			value:    "12345",
+			expected: "12345",
+		},
+		{
+			desc:     "bool value is unchanged",
+			output:   OutputType{SignificantFigures: 3},
+			value:    true,
+			expected: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := testCase.output.Apply(testCase.value)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestOutputType_Apply_Transforms tests applying an OutputType with a transform
+// pipeline, where the transforms are applied in order.
+func TestOutputType_Apply_Transforms(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("double", func(f float64) float64 { return f * 2 })
+	assert.NoError(t, err)
+
+	offset := func(f float64) *float64 { return &f }
+	precision := func(p int) *int { return &p }
+
+	var testTable = []struct {
+		desc       string
+		transforms []*Transform
+		value      interface{}
+		expected   interface{}
+	}{
+		{
+			desc:       "factor then offset",
+			transforms: []*Transform{{Factor: "2"}, {Offset: offset(1)}},
+			value:      10,
+			expected:   float64(21),
+		},
+		{
+			desc:       "offset then factor",
+			transforms: []*Transform{{Offset: offset(1)}, {Factor: "2"}},
+			value:      10,
+			expected:   float64(22),
+		},
+		{
+			desc:       "conversion then offset",
+			transforms: []*Transform{{Conversion: "double"}, {Offset: offset(-5)}},
+			value:      10,
+			expected:   float64(15),
+		},
+		{
+			desc:       "clamp then factor",
+			transforms: []*Transform{{Clamp: &ClampRange{Max: offset(5)}}, {Factor: "3"}},
+			value:      10,
+			expected:   float64(15),
+		},
+		{
+			desc:       "factor then clamp",
+			transforms: []*Transform{{Factor: "3"}, {Clamp: &ClampRange{Max: offset(5)}}},
+			value:      10,
+			expected:   float64(5),
+		},
+		{
+			desc:       "clamp to min",
+			transforms: []*Transform{{Clamp: &ClampRange{Min: offset(0), Max: offset(5)}}},
+			value:      -3,
+			expected:   float64(0),
+		},
+		{
+			desc:       "factor then precision",
+			transforms: []*Transform{{Factor: "0.001"}, {Precision: precision(2)}},
+			value:      12345,
+			expected:   12.35,
+		},
+		{
+			desc:       "precision then factor",
+			transforms: []*Transform{{Precision: precision(0)}, {Factor: "0.5"}},
+			value:      2.6,
+			expected:   1.5,
+		},
+		{
+			desc:       "significant figures",
+			transforms: []*Transform{{SignificantFigures: 2}},
+			value:      12345,
+			expected:   float64(12000),
+		},
+		{
+			desc:       "clamp then enum",
+			transforms: []*Transform{{Clamp: &ClampRange{Max: offset(2)}}, {Enum: map[string]interface{}{"1": "on", "2": "fault"}}},
+			value:      7,
+			expected:   "fault",
+		},
+		{
+			desc:       "enum with no mapping",
+			transforms: []*Transform{{Enum: map[string]interface{}{"1": "on"}}},
+			value:      0,
+			expected:   0,
+		},
+		{
+			desc:       "failed stage passes its value on",
+			transforms: []*Transform{{Factor: "2"}, {Offset: offset(1)}},
+			value:      "foo",
+			expected:   "foo",
+		},
+	}
+
+	for _, testCase := range testTable {
+		output := OutputType{Transforms: testCase.transforms}
+		actual := output.Apply(testCase.value)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestOutputType_pipeline tests that an OutputType with no transforms builds its
+// pipeline from its scaling factor, scale, conversions, and significant figures,
+// in that order, and that the pipeline preserves their current behavior.
+func TestOutputType_pipeline(t *testing.T) {
+	defer resetContext()
+
+	plugin := NewPlugin()
+	err := plugin.RegisterConversion("plusOne", func(f float64) float64 { return f + 1 })
+	assert.NoError(t, err)
+
+	output := OutputType{}
+	var names []string
+	for _, stage := range output.pipeline() {
+		names = append(names, stage.name)
+	}
+	assert.Equal(t, []string{"scalingFactor", "scale", "conversion", "significantFigures"}, names)
+
+	// With no stages configured, values are unchanged.
+	assert.Equal(t, 3, output.Apply(3))
+	assert.Equal(t, "foo", output.Apply("foo"))
+
+	// The scaling factor and scale are applied before the conversions.
+	output = OutputType{ScalingFactor: "2", Scale: "k", Conversions: []string{"plusOne"}, SignificantFigures: 2}
+	assert.Equal(t, float64(7), output.Apply(3000))
+
+	// Transforms replace the pipeline.
+	output = OutputType{Transforms: []*Transform{{Conversion: "plusOne"}, {Factor: "2"}}}
+	assert.Equal(t, float64(8), output.Apply(3))
+}
+
+// TestTransform_apply_EnumNoMapping tests that an enum transform leaves a value
+// with no mapping unchanged, without an error.
+func TestTransform_apply_EnumNoMapping(t *testing.T) {
+	transform := Transform{Enum: map[string]interface{}{"1": "on"}}
+
+	value, err := transform.apply(0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, value)
+
+	value, err = transform.apply(1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "on", value)
+}
+
+// TestTransform_Validate tests validating transforms.
+func TestTransform_Validate(t *testing.T) {
+	defer resetContext()
+	ctx.conversions["foo"] = func(f float64) float64 { return f }
+
+	offset := func(f float64) *float64 { return &f }
+	precision := func(p int) *int { return &p }
+
+	var testTable = []struct {
+		desc      string
+		errCount  int
+		transform Transform
+	}{
+		{desc: "factor", transform: Transform{Factor: "1e-2"}},
+		{desc: "offset", transform: Transform{Offset: offset(0)}},
+		{desc: "conversion", transform: Transform{Conversion: "foo"}},
+		{desc: "clamp", transform: Transform{Clamp: &ClampRange{Min: offset(0), Max: offset(1)}}},
+		{desc: "clamp min only", transform: Transform{Clamp: &ClampRange{Min: offset(0)}}},
+		{desc: "precision", transform: Transform{Precision: precision(0)}},
+		{desc: "significant figures", transform: Transform{SignificantFigures: 3}},
+		{desc: "enum", transform: Transform{Enum: map[string]interface{}{"0": "off"}}},
+		{desc: "no transform", errCount: 1, transform: Transform{}},
+		{desc: "multiple transforms", errCount: 1, transform: Transform{Factor: "2", Offset: offset(1)}},
+		{desc: "bad factor", errCount: 1, transform: Transform{Factor: "foo"}},
+		{desc: "zero factor", errCount: 1, transform: Transform{Factor: "0"}},
+		{desc: "unknown conversion", errCount: 1, transform: Transform{Conversion: "bar"}},
+		{desc: "clamp with no bounds", errCount: 1, transform: Transform{Clamp: &ClampRange{}}},
+		{desc: "clamp with min above max", errCount: 1, transform: Transform{Clamp: &ClampRange{Min: offset(2), Max: offset(1)}}},
+		{desc: "negative precision", errCount: 1, transform: Transform{Precision: precision(-1)}},
+		{desc: "negative significant figures", errCount: 1, transform: Transform{SignificantFigures: -1}},
+		{desc: "empty enum", errCount: 1, transform: Transform{Enum: map[string]interface{}{}}},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+
+		testCase.transform.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
+
+// TestOutputType_Transforms_YAML tests loading an output type with transforms
+// from YAML.
+func TestOutputType_Transforms_YAML(t *testing.T) {
+	data := `
+version: 1.0
+name: status
+transforms:
+- factor: 1e-1
+- offset: -2
+- clamp:
+    min: 0
+- enum:
+    0: ok
+    1: warning
+`
+	output := &OutputType{}
+	assert.NoError(t, yaml.Unmarshal([]byte(data), output))
+	assert.Len(t, output.Transforms, 4)
+	assert.Equal(t, []string{"factor", "offset", "clamp", "enum"}, []string{
+		output.Transforms[0].kind(),
+		output.Transforms[1].kind(),
+		output.Transforms[2].kind(),
+		output.Transforms[3].kind(),
+	})
+
+	merr := validator.Validate(NewConfigContext("test", output))
+	assert.NoError(t, merr.Err())
+
+	assert.Equal(t, "ok", output.Apply(15))
+	assert.Equal(t, "warning", output.Apply(30))
+	assert.Equal(t, "ok", output.Apply(5))
+
+	// The transforms are validated along with the output type.
+	output.Transforms = append(output.Transforms, &Transform{})
+	merr = validator.Validate(NewConfigContext("test", output))
+	assert.Error(t, merr.Err())
+}
+
+// TestOutputType_PreviewReading tests previewing the readings an OutputType would
+// produce for values of different types.
+func TestOutputType_PreviewReading(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			desc:     "int value, no transforms",
+			output:   OutputType{Name: "foo.count"},
+			value:    3,
+			expected: 3,
+		},
+		{
+			desc:     "uint16 value with scaling factor",
+			output:   OutputType{Name: "foo.voltage", ScalingFactor: "0.5"},
+			value:    uint16(5),
+			expected: 2.5,
+		},
+		{
+			desc:     "float32 value, no transforms",
+			output:   OutputType{Name: "foo.temperature"},
+			value:    float32(20.5),
+			expected: float32(20.5),
+		},
+		{
+			desc:     "string value",
+			output:   OutputType{Name: "foo.state"},
+			value:    "on",
+			expected: "on",
+		},
+		{
+			desc:     "bool value from bit field",
+			output:   OutputType{Name: "foo.alarm", BitField: &BitField{Mask: 0x02}},
+			value:    6,
+			expected: true,
+		},
+		{
+			desc:     "nil value with default",
+			output:   OutputType{Name: "foo.temperature", DefaultValue: -1},
+			value:    nil,
+			expected: -1,
+		},
+		{
+			desc:     "bytes value",
+			output:   OutputType{Name: "foo.raw"},
+			value:    []byte{0x01},
+			expected: []byte{0x01},
+		},
+	}
+
+	for _, testCase := range testTable {
+		reading, err := testCase.output.PreviewReading(testCase.value)
+		assert.NoError(t, err, testCase.desc)
+		assert.Equal(t, testCase.output.Type(), reading.Type, testCase.desc)
+		assert.Equal(t, testCase.expected, reading.Value, testCase.desc)
+		assert.NotEmpty(t, reading.Timestamp, testCase.desc)
+	}
+}
+
+// TestOutputType_PreviewReading2 tests previewing a reading with the unit of an
+// output type which has a scale.
+func TestOutputType_PreviewReading2(t *testing.T) {
+	output := OutputType{Name: "pressure", Scale: "k", Unit: Unit{Name: "pascal", Symbol: "Pa"}}
+
+	reading, err := output.PreviewReading(2000)
+	assert.NoError(t, err)
+	assert.Equal(t, "pressure", reading.Type)
+	assert.Equal(t, float64(2), reading.Value)
+	assert.Equal(t, Unit{Name: "kilopascal", Symbol: "kPa"}, reading.Unit)
+}
+
+// TestOutputType_PreviewReading_Error tests previewing a reading for a value which
+// can not be encoded.
+func TestOutputType_PreviewReading_Error(t *testing.T) {
+	output := OutputType{Name: "foo"}
+
+	reading, err := output.PreviewReading(struct{}{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "struct {}")
+	assert.Nil(t, reading)
+
+	reading, err = output.PreviewReading([]int{1, 2})
+	assert.Error(t, err)
+	assert.Nil(t, reading)
+}
+
+// TestOutputType_Apply_BitField tests applying an OutputType which extracts a
+// bit field from an integer reading value.
+func TestOutputType_Apply_BitField(t *testing.T) {
+	bit := func(b uint) *uint { return &b }
+
+	var testTable = []struct {
+		desc     string
+		field    BitField
+		value    interface{}
+		expected interface{}
+	}{
+		{"bit 0 set", BitField{Bit: bit(0)}, 0x05, true},
+		{"bit 1 not set", BitField{Bit: bit(1)}, 0x05, false},
+		{"bit 2 set", BitField{Bit: bit(2)}, uint16(0x05), true},
+		{"high bit set", BitField{Bit: bit(63)}, uint64(1) << 63, true},
+		{"negative value", BitField{Bit: bit(15)}, int16(-1), true},
+		{"integral float", BitField{Bit: bit(3)}, float64(8), true},
+		{"hex string", BitField{Bit: bit(4)}, "0x10", true},
+		{"mask with a bit set", BitField{Mask: 0x0C}, int32(0x04), true},
+		{"mask with no bits set", BitField{Mask: 0x0C}, int32(0x13), false},
+		{"non-integer float is unchanged", BitField{Bit: bit(0)}, 1.5, 1.5},
+		{"unsupported type is unchanged", BitField{Bit: bit(0)}, []byte{1}, []byte{1}},
+	}
+
+	for _, testCase := range testTable {
+		output := OutputType{BitField: &testCase.field, ScalingFactor: "10"}
+		actual := output.Apply(testCase.value)
+		assert.Equal(t, testCase.expected, actual, testCase.desc)
+	}
+}
+
+// TestOutputType_Apply_BitField2 tests making multiple boolean readings from a
+// single register value, with an output type for each bit.
+func TestOutputType_Apply_BitField2(t *testing.T) {
+	bit := func(b uint) *uint { return &b }
+
+	outputs := []*Output{
+		{OutputType: OutputType{Name: "status.fan", BitField: &BitField{Bit: bit(0)}}},
+		{OutputType: OutputType{Name: "status.pump", BitField: &BitField{Bit: bit(1)}}},
+		{OutputType: OutputType{Name: "status.alarm", BitField: &BitField{Mask: 0xF0}}},
+	}
+
+	register := uint16(0x21)
+	actual := map[string]interface{}{}
+	for _, output := range outputs {
+		reading, err := output.MakeReading(register)
+		assert.NoError(t, err)
+		actual[reading.Type] = reading.Value
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"fan":   true,
+		"pump":  false,
+		"alarm": true,
+	}, actual)
+}
+
+// TestOutputType_GetScale_Error tests getting the scale for an unknown SI prefix.
+func TestOutputType_GetScale_Error(t *testing.T) {
+	output := OutputType{Scale: "x"}
+	_, err := output.GetScale()
+	assert.Error(t, err)
+}
+
+// TestOutputType_EffectiveUnit tests getting the effective unit of an OutputType.
+func TestOutputType_EffectiveUnit(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		output   OutputType
+		expected Unit
+	}{
+		{
+			desc:     "no scale",
+			output:   OutputType{Unit: Unit{Name: "pascal", Symbol: "Pa"}},
+			expected: Unit{Name: "pascal", Symbol: "Pa"},
+		},
+		{
+			desc:     "kilo scale",
+			output:   OutputType{Scale: "k", Unit: Unit{Name: "pascal", Symbol: "Pa"}},
+			expected: Unit{Name: "kilopascal", Symbol: "kPa"},
+		},
+		{
+			desc:     "milli scale",
+			output:   OutputType{Scale: "m", Unit: Unit{Name: "volt", Symbol: "V"}},
+			expected: Unit{Name: "millivolt", Symbol: "mV"},
+		},
+		{
+			desc:     "scale with no unit",
+			output:   OutputType{Scale: "k"},
+			expected: Unit{},
+		},
+		{
+			desc:     "unknown scale",
+			output:   OutputType{Scale: "x", Unit: Unit{Name: "pascal", Symbol: "Pa"}},
+			expected: Unit{Name: "pascal", Symbol: "Pa"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expected, testCase.output.EffectiveUnit(), testCase.desc)
+	}
+}
+
+// TestOutputType_Apply_Error tests applying when the scaling factor is invalid.
+func TestOutputType_Apply_Error(t *testing.T) {
+	output := OutputType{
+		ScalingFactor: "foobar",
+	}
+	actual := output.Apply(2)
+	assert.Equal(t, 2, actual, "on parse failure, nothing changes")
+}
+
+// TestUnit_Validate tests validating the Unit. There is nothing to validate here,
+// so it should all validate successfully.
+func TestUnit_Validate(t *testing.T) {
+	merr := errors.NewMultiError("test")
+	unit := Unit{}
+	unit.Validate(merr)
+	assert.NoError(t, merr.Err())
+}
+
+// TestUnit_Encode tests encoding the Unit to the gRPC message.
+func TestUnit_Encode(t *testing.T) {
+	var testTable = []struct {
+		desc    string
+		unit    Unit
+		message synse.Unit
+	}{
+		{
+			desc:    "empty unit",
+			unit:    Unit{},
+			message: synse.Unit{},
+		},
+		{
+			desc: "name and symbol specified",
+			unit: Unit{
+				Name:   "foo",
+				Symbol: "bar",
+			},
+			message: synse.Unit{
+				Name:   "foo",
+				Symbol: "bar",
+			},
+		},
+		{
+			desc: "only name specified",
+			unit: Unit{
+				Name: "foo",
+			},
+			message: synse.Unit{
+				Name: "foo",
+			},
+		},
+		{
+			desc: "only symbol specified",
+			unit: Unit{
+				Symbol: "bar",
+			},
+			message: synse.Unit{
+				Symbol: "bar",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual := testCase.unit.encode()
+		assert.Equal(t, testCase.unit.Name, actual.GetName())
+		assert.Equal(t, testCase.unit.Symbol, actual.GetSymbol())
+	}
+}
+
+// TestNilOutput verifies a parameter check for Output.
+func TestNilOutput(t *testing.T) {
+	var output *Output // nolint: megacheck
+	output = nil
+	_, err := output.MakeReading("should fail")
+	if err == nil {
+		t.Error("nil OutputType should fail")
+	}
+}
+
+// Test dumping an OutputType to a JSON string.
+func TestOutputType_JSON(t *testing.T) {
+	var testTable = []struct {
+		output   OutputType
+		expected string
+	}{
+		{
+			output:   OutputType{},
+			expected: `{"Version":"","Name":"","Precision":0,"SignificantFigures":0,"Unit":{"Name":"","Symbol":""},"ScalingFactor":"","Conversion":"","Conversions":null,"DefaultValue":null,"Scale":"","Compression":"","ValidMin":null,"ValidMax":null,"Smoothing":null,"Counter":null,"BitField":null,"Transforms":null,"DataType":"","TypeEnforcement":""}`,
+		},
+		{
+			output: OutputType{
+				Name:      "foo",
+				Precision: 2,
+			},
+			expected: `{"Version":"","Name":"foo","Precision":2,"SignificantFigures":0,"Unit":{"Name":"","Symbol":""},"ScalingFactor":"","Conversion":"","Conversions":null,"DefaultValue":null,"Scale":"","Compression":"","ValidMin":null,"ValidMax":null,"Smoothing":null,"Counter":null,"BitField":null,"Transforms":null,"DataType":"","TypeEnforcement":""}`,
+		},
+		{
+			output: OutputType{
+				Name:      "test",
+				Precision: 4,
+				Unit: Unit{
+					Name:   "unit",
+					Symbol: "u",
+				},
+				ScalingFactor: "1e6",
+				Conversion:    "englishToMetric",
+			},
+			expected: `{"Version":"","Name":"test","Precision":4,"SignificantFigures":0,"Unit":{"Name":"unit","Symbol":"u"},"ScalingFactor":"1e6","Conversion":"englishToMetric","Conversions":null,"DefaultValue":null,"Scale":"","Compression":"","ValidMin":null,"ValidMax":null,"Smoothing":null,"Counter":null,"BitField":null,"Transforms":null,"DataType":"","TypeEnforcement":""}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		actual, err := testCase.output.JSON()
+		assert.NoError(t, err)
 		assert.Equal(t, testCase.expected, actual)
 	}
 }
+
+// Test_valueDataType tests getting the data type of reading values.
+func Test_valueDataType(t *testing.T) {
+	var tests = []struct {
+		value    interface{}
+		expected string
+	}{
+		{1, "int"},
+		{int8(1), "int"},
+		{uint64(1), "int"},
+		{EnumAsInt, "int"},
+		{1.5, "float"},
+		{float32(1.5), "float"},
+		{"1", "string"},
+		{true, "bool"},
+		{[]byte("1"), "bytes"},
+		{NewDecimalFromInt(1), "decimal"},
+		{[]int{1}, ""},
+		{struct{}{}, ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, valueDataType(test.value), "%T", test.value)
+	}
+}
+
+// typeDriftTestDevice creates a device whose handler makes a reading from the given
+// value for an output type which declares an "int" data type, with the given type
+// enforcement.
+func typeDriftTestDevice(enforcement string, value interface{}) *Device {
+	return &Device{
+		Location: &Location{Rack: "rack", Board: "board"},
+		Outputs: []*Output{{OutputType: OutputType{
+			Name:            "count",
+			DataType:        "int",
+			TypeEnforcement: enforcement,
+		}}},
+		Handler: &DeviceHandler{
+			Read: func(device *Device) ([]*Reading, error) {
+				reading, err := device.GetOutput("count").MakeReading(value)
+				if err != nil {
+					return nil, err
+				}
+				return []*Reading{reading}, nil
+			},
+		},
+	}
+}
+
+// TestOutputType_TypeEnforcementError tests that with "error" type enforcement, a
+// handler which returns a value of the wrong type fails to read.
+func TestOutputType_TypeEnforcementError(t *testing.T) {
+	ctx, err := typeDriftTestDevice("error", 1.5).Read()
+	assert.Nil(t, ctx)
+	assert.EqualError(t, err, "value 1.5 of type float64 does not match the data type int of output type count")
+
+	// Values of the declared type, and nil values, are read as usual.
+	ctx, err = typeDriftTestDevice("error", int32(2)).Read()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), ctx.Reading[0].Value)
+
+	ctx, err = typeDriftTestDevice("error", nil).Read()
+	assert.NoError(t, err)
+	assert.Nil(t, ctx.Reading[0].Value)
+}
+
+// TestOutputType_TypeEnforcementLog tests that with "log" type enforcement, a handler
+// which returns a value of the wrong type is read as usual, but a warning is logged.
+func TestOutputType_TypeEnforcementLog(t *testing.T) {
+	defer func() {
+		repeatedLog = newLogDeduplicator(repeatedLogWindow)
+	}()
+	repeatedLog = newLogDeduplicator(repeatedLogWindow)
+	hook := logtest.NewGlobal()
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	ctx, err := typeDriftTestDevice("log", 1.5).Read()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, ctx.Reading[0].Value)
+
+	entry := hook.LastEntry()
+	assert.NotNil(t, entry)
+	assert.Equal(t, log.WarnLevel, entry.Level)
+	assert.Contains(t, entry.Message, "value 1.5 of type float64 does not match the data type int of output type count")
+
+	// Values of the declared type are not logged.
+	hook.Reset()
+	_, err = typeDriftTestDevice("log", 2).Read()
+	assert.NoError(t, err)
+	assert.Empty(t, hook.AllEntries())
+}
+
+// TestOutputType_TypeEnforcementDisabled tests that values are not checked against
+// the data type if type enforcement is not enabled.
+func TestOutputType_TypeEnforcementDisabled(t *testing.T) {
+	ctx, err := typeDriftTestDevice("", "not an int").Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "not an int", ctx.Reading[0].Value)
+}
+
+// TestOutputType_Apply_Unscalable tests that boolean and string values are not
+// scaled by the scaling factor or scale of an output type.
+func TestOutputType_Apply_Unscalable(t *testing.T) {
+	defer func() {
+		repeatedLog = newLogDeduplicator(repeatedLogWindow)
+	}()
+
+	var tests = []struct {
+		desc   string
+		output OutputType
+		value  interface{}
+	}{
+		{"bool, scaling factor", OutputType{ScalingFactor: "0.5"}, true},
+		{"bool, negative scaling factor", OutputType{ScalingFactor: "-1"}, false},
+		{"bool, scale", OutputType{Scale: "k"}, true},
+		{"string, scaling factor", OutputType{ScalingFactor: "2"}, "12.5"},
+		{"string, scale", OutputType{Scale: "m"}, "on"},
+	}
+
+	for _, test := range tests {
+		repeatedLog = newLogDeduplicator(repeatedLogWindow)
+		hook := logtest.NewGlobal()
+
+		assert.Equal(t, test.value, test.output.Apply(test.value), test.desc)
+		if assert.Len(t, hook.Entries, 1, test.desc) {
+			assert.Equal(t, log.WarnLevel, hook.LastEntry().Level, test.desc)
+			assert.Contains(t, hook.LastEntry().Message, "not applying", test.desc)
+		}
+	}
+	log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+}
+
+// TestOutputType_Validate_ScaledDataType tests that validating an output type which
+// scales a boolean or string data type logs a warning, without failing validation.
+func TestOutputType_Validate_ScaledDataType(t *testing.T) {
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	var tests = []struct {
+		desc   string
+		output OutputType
+		warned bool
+	}{
+		{"bool, scaling factor", OutputType{Name: "foo", DataType: "bool", ScalingFactor: "0.5"}, true},
+		{"bool, scale", OutputType{Name: "foo", DataType: "bool", Scale: "k"}, true},
+		{"string, scaling factor", OutputType{Name: "foo", DataType: "string", ScalingFactor: "2"}, true},
+		{"bool, identity scaling factor", OutputType{Name: "foo", DataType: "bool", ScalingFactor: "1"}, false},
+		{"bool, no scaling", OutputType{Name: "foo", DataType: "bool"}, false},
+		{"float, scaling factor", OutputType{Name: "foo", DataType: "float", ScalingFactor: "0.5"}, false},
+		{"no data type, scaling factor", OutputType{Name: "foo", ScalingFactor: "0.5"}, false},
+	}
+
+	for _, test := range tests {
+		hook := logtest.NewGlobal()
+
+		merr := errors.NewMultiError("test")
+		test.output.Validate(merr)
+		assert.NoError(t, merr.Err(), test.desc)
+
+		var warned bool
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == log.WarnLevel && entry.Data["outputType"] == "foo" {
+				warned = true
+			}
+		}
+		assert.Equal(t, test.warned, warned, test.desc)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,14 +16,14 @@ import (
 	"github.com/vapor-ware/synse-sdk/sdk/policies"
 )
 
-// GetCurrentTime return the current time (time.Now()), with location set to UTC,
+// GetCurrentTime return the current time (clock.Now()), with location set to UTC,
 // as a string formatted with the RFC3339Nano layout. This should be the format
 // of all timestamps returned by the SDK.
 //
 // The SDK uses this function to generate all of its timestamps. It is highly
 // recommended that plugins use this as well for timestamp generation.
 func GetCurrentTime() string {
-	return time.Now().UTC().Format(time.RFC3339Nano)
+	return clock.Now().UTC().Format(time.RFC3339Nano)
 }
 
 // ParseRFC3339Nano parses a timestamp string in RFC3339Nano format into a Time struct.
@@ -80,12 +82,100 @@ func ConvertToFloat64(value interface{}) (result float64, err error) { // nolint
 		result = float64(t)
 	case string:
 		result, err = strconv.ParseFloat(t, 64)
+	case Decimal:
+		result = t.Float64()
 	default:
 		err = fmt.Errorf("Unable to convert value %v, type %T to float64", value, value)
 	}
 	return result, err
 }
 
+// convertToUint64 converts an integer value to a uint64 or errors out. Signed
+// values are converted to their two's complement bit pattern, floats must have
+// an integer value, and strings may be formatted with a base prefix, e.g. "0x1F".
+func convertToUint64(value interface{}) (result uint64, err error) { // nolint: gocyclo
+	switch t := value.(type) {
+	case uint64:
+		result = t
+	case uint32:
+		result = uint64(t)
+	case uint16:
+		result = uint64(t)
+	case uint8:
+		result = uint64(t)
+	case uint:
+		result = uint64(t)
+	case int64:
+		result = uint64(t)
+	case int32:
+		result = uint64(t)
+	case int16:
+		result = uint64(t)
+	case int8:
+		result = uint64(t)
+	case int:
+		result = uint64(t)
+	case float64:
+		if t != math.Trunc(t) || math.IsInf(t, 0) {
+			err = fmt.Errorf("Unable to convert non-integer value %v to uint64", value)
+		}
+		result = uint64(int64(t))
+	case float32:
+		if float64(t) != math.Trunc(float64(t)) || math.IsInf(float64(t), 0) {
+			err = fmt.Errorf("Unable to convert non-integer value %v to uint64", value)
+		}
+		result = uint64(int64(t))
+	case string:
+		result, err = strconv.ParseUint(t, 0, 64)
+	default:
+		err = fmt.Errorf("Unable to convert value %v, type %T to uint64", value, value)
+	}
+	return result, err
+}
+
+// compareVersions compares two dot-separated numeric version strings, e.g.
+// "1.2.10" and "1.3". A leading "v" is ignored and missing components are
+// treated as zero. It returns -1 if a < b, 0 if a == b, and 1 if a > b.
+func compareVersions(a, b string) (int, error) {
+	parse := func(version string) ([]int, error) {
+		version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+		var components []int
+		for _, c := range strings.Split(version, ".") {
+			i, err := strconv.Atoi(c)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version %q: %v", version, err)
+			}
+			components = append(components, i)
+		}
+		return components, nil
+	}
+
+	va, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for len(va) < len(vb) {
+		va = append(va, 0)
+	}
+	for len(vb) < len(va) {
+		vb = append(vb, 0)
+	}
+	for i := range va {
+		if va[i] < vb[i] {
+			return -1, nil
+		}
+		if va[i] > vb[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
 // makeIDString makes a compound string out of the given rack, board, and
 // device identifier strings. This string should be a globally unique identifier
 // for a given device.
@@ -110,13 +200,20 @@ func newUID(components ...string) string {
 
 // filterDevices returns a list of Devices (a subset of the deviceMap) which
 // match the specified filter(s) in the given filter string.
-func filterDevices(filter string) ([]*Device, error) { // nolint: gocyclo
-	filters := strings.Split(filter, ",")
-
+func filterDevices(filter string) ([]*Device, error) {
 	var devices []*Device
-	for _, d := range ctx.devices {
+	for _, d := range ctx.getDevices() {
 		devices = append(devices, d)
 	}
+	return filterDeviceList(filter, devices)
+}
+
+// filterDeviceList returns the Devices of the given list which match the
+// specified filter(s) in the given filter string. The given list is not
+// modified.
+func filterDeviceList(filter string, list []*Device) ([]*Device, error) { // nolint: gocyclo
+	filters := strings.Split(filter, ",")
+	devices := append([]*Device{}, list...)
 
 	for _, f := range filters {
 		pair := strings.Split(f, "=")
@@ -160,6 +257,12 @@ func registerDevices() error {
 			if err != nil {
 				return err
 			}
+			if err := resolveDeviceCollisions(devices, Config.Plugin.DynamicRegistration.Collisions); err != nil {
+				return err
+			}
+			if err := verifyDeviceData(devices); err != nil {
+				return err
+			}
 			log.Debugf("[sdk] adding %d devices from dynamic registration", len(devices))
 			updateDeviceMap(devices)
 		}
@@ -174,6 +277,89 @@ func registerDevices() error {
 	log.Debugf("[sdk] adding %d devices from config", len(devices))
 	updateDeviceMap(devices)
 
+	// devices for the plugin's own internal counters, if enabled.
+	registerSelfMonitoringDevices()
+
+	return nil
+}
+
+// resolveDeviceCollisions resolves collisions between the IDs of the given
+// dynamically registered devices and those of the devices which are already
+// registered, or which precede them in the given devices, according to the
+// given collision policy (see DynamicRegistrationSettings.Collisions).
+func resolveDeviceCollisions(devices []*Device, policy string) error {
+	seen := map[string]bool{}
+	for _, device := range devices {
+		guid := device.GUID()
+		if ctx.getDevice(guid) == nil && !seen[guid] {
+			seen[guid] = true
+			continue
+		}
+
+		if policy != collisionsSuffix {
+			log.WithField("id", guid).Error("[sdk] dynamically registered device id collision")
+			return fmt.Errorf("dynamically registered device id collides with an existing device: %s", guid)
+		}
+
+		id := device.ID()
+		for n := 2; ; n++ {
+			device.id = fmt.Sprintf("%s-%d", id, n)
+			if ctx.getDevice(device.GUID()) == nil && !seen[device.GUID()] {
+				break
+			}
+		}
+		log.WithFields(log.Fields{
+			"id":    guid,
+			"newID": device.GUID(),
+		}).Warn("[sdk] dynamically registered device id collision, suffixing device id")
+		seen[device.GUID()] = true
+	}
+	return nil
+}
+
+// checkDevicesRegistered checks that at least one device is registered with the
+// plugin, if the plugin is configured to require devices.
+func checkDevicesRegistered() error {
+	if !Config.Plugin.Settings.RequireDevices {
+		return nil
+	}
+	if len(ctx.getDevices()) == 0 {
+		return fmt.Errorf("no devices configured, but the plugin requires at least one device (settings.requireDevices)")
+	}
+	return nil
+}
+
+// checkOutputTypesUsed checks that every registered output type is used by the
+// outputs of at least one registered device. If the plugin is configured to require
+// this, an error listing the unused output types is returned; otherwise, they are
+// only logged.
+func checkOutputTypesUsed() error {
+	used := map[string]bool{}
+	for _, device := range ctx.getDevices() {
+		for _, output := range device.Outputs {
+			used[output.Name] = true
+		}
+	}
+
+	var unused []string
+	for name := range ctx.outputTypes {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+
+	if Config.Plugin.Settings.RequireOutputTypesUsed {
+		return fmt.Errorf(
+			"output types not used by any device, but the plugin requires all output types to be used "+
+				"(settings.requireOutputTypesUsed): %s",
+			strings.Join(unused, ", "),
+		)
+	}
+	log.WithField("types", unused).Debug("[sdk] output types not used by any device")
 	return nil
 }
 
@@ -185,17 +371,25 @@ func logStartupInfo() {
 	// Log plugin version info
 	version.Log()
 
+	// Log the config checksum, so replicas can be compared for config drift
+	sum, err := GetConfigChecksum()
+	if err != nil {
+		log.Errorf("[sdk] failed to compute config checksum: %v", err)
+	} else {
+		log.Infof("Config Checksum: %s", sum.Combined)
+	}
+
 	// Log registered devices
 	log.Info("Registered Devices:")
-	for id, dev := range ctx.devices {
+	for id, dev := range ctx.getDevices() {
 		log.Infof("  %v (%v)", id, dev.Kind)
 	}
 	log.Info("--------------------------------")
 }
 
 // RedactPasswords redacts map fields containing key substring "pass"
-// (case-insensitive) in Marshaled json string s, and returns a string where
-// those field values are emitted as REDACTED.
+// (case-insensitive) and secret references (see Device.GetData) in Marshaled
+// json string s, and returns a string where those values are emitted as REDACTED.
 func RedactPasswords(s string) (output string, err error) {
 
 	// Unmarshal json string to structure.
@@ -245,6 +439,13 @@ func traverseMap(m map[string]interface{}) (err error) {
 			continue
 		}
 
+		// Secret references are redacted too, so that where secrets are kept
+		// is not logged.
+		if isSecretRef(v) {
+			m[k] = "REDACTED"
+			continue
+		}
+
 		// Is this a map of [string]interface{}?
 		vvalue := reflect.ValueOf(v)
 		vkind := vvalue.Kind()
@@ -287,6 +488,11 @@ func traverseSlice(s []interface{}) (err error) {
 	for i := 0; i < len(s); i++ {
 		v := s[i]
 
+		if isSecretRef(v) {
+			s[i] = "REDACTED"
+			continue
+		}
+
 		// Is this a map of [string]interface{}?
 		vvalue := reflect.ValueOf(v)
 		vkind := vvalue.Kind()
@@ -56,6 +56,47 @@ func TestMakeIDString(t *testing.T) {
 	}
 }
 
+// Test_compareVersions tests comparing version strings.
+func Test_compareVersions(t *testing.T) {
+	var testTable = []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "1", b: "1", expected: 0},
+		{a: "1.0", b: "1", expected: 0},
+		{a: "v1.2.0", b: "1.2", expected: 0},
+		{a: "1.2", b: "1.10", expected: -1},
+		{a: "1.2.3", b: "1.3", expected: -1},
+		{a: "2.0", b: "1.9.9", expected: 1},
+		{a: "1.0.1", b: "1", expected: 1},
+	}
+
+	for _, tc := range testTable {
+		actual, err := compareVersions(tc.a, tc.b)
+		assert.NoError(t, err, fmt.Sprintf("%s, %s", tc.a, tc.b))
+		assert.Equal(t, tc.expected, actual, fmt.Sprintf("%s, %s", tc.a, tc.b))
+	}
+}
+
+// Test_compareVersionsErr tests comparing invalid version strings.
+func Test_compareVersionsErr(t *testing.T) {
+	var testTable = []struct {
+		a string
+		b string
+	}{
+		{a: "", b: "1"},
+		{a: "1", b: "abc"},
+		{a: "1.x", b: "1.0"},
+		{a: "1..2", b: "1.0"},
+	}
+
+	for _, tc := range testTable {
+		_, err := compareVersions(tc.a, tc.b)
+		assert.Error(t, err, fmt.Sprintf("%s, %s", tc.a, tc.b))
+	}
+}
+
 // TestNewUID tests creating new device UIDs successfully.
 func TestNewUID(t *testing.T) {
 	var newUIDTestTable = []struct {
@@ -407,6 +448,118 @@ func Test_registerDevices2(t *testing.T) {
 	assert.Equal(t, 0, len(ctx.devices))
 }
 
+// collidingDynamicRegistrar is a dynamic device registrar which registers two
+// devices with the same ID, and a third with a different ID.
+func collidingDynamicRegistrar(_ map[string]interface{}) ([]*Device, error) {
+	location := &Location{Rack: "rack", Board: "board"}
+	return []*Device{
+		{Kind: "foo", Info: "first", Location: location, Data: map[string]interface{}{"id": 1}},
+		{Kind: "foo", Info: "second", Location: location, Data: map[string]interface{}{"id": 1}},
+		{Kind: "foo", Info: "third", Location: location, Data: map[string]interface{}{"id": 2}},
+	}, nil
+}
+
+// Test_registerDevices_CollisionError tests registering dynamic devices whose IDs
+// collide, when collisions are errors.
+func Test_registerDevices_CollisionError(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	ctx.dynamicDeviceRegistrar = collidingDynamicRegistrar
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config:     []map[string]interface{}{{}},
+			Collisions: "error",
+		},
+	}
+	Config.Device = &DeviceConfig{
+		Devices: []*DeviceKind{},
+	}
+
+	err := registerDevices()
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(ctx.devices))
+}
+
+// Test_registerDevices_CollisionSuffix tests registering dynamic devices whose IDs
+// collide, when colliding IDs are suffixed.
+func Test_registerDevices_CollisionSuffix(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	ctx.dynamicDeviceRegistrar = collidingDynamicRegistrar
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		DynamicRegistration: &DynamicRegistrationSettings{
+			// Each config registers the same devices, so they also collide
+			// with the devices registered from the first config.
+			Config:     []map[string]interface{}{{}, {}},
+			Collisions: "suffix",
+		},
+	}
+	Config.Device = &DeviceConfig{
+		Devices: []*DeviceKind{},
+	}
+
+	err := registerDevices()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(ctx.devices))
+
+	first, err := collidingDynamicRegistrar(nil)
+	assert.NoError(t, err)
+	id1, id2 := first[0].GUID(), first[2].GUID()
+
+	infos := map[string]string{}
+	for guid, device := range ctx.devices {
+		infos[guid] = device.Info
+	}
+	assert.Equal(t, map[string]string{
+		id1:        "first",
+		id1 + "-2": "second",
+		id2:        "third",
+		id1 + "-3": "first",
+		id1 + "-4": "second",
+		id2 + "-2": "third",
+	}, infos)
+}
+
+// Test_registerDevices_MissingDataKey tests registering dynamic devices when one of
+// them is missing a data key required by its handler.
+func Test_registerDevices_MissingDataKey(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	handler := &DeviceHandler{Name: "test", RequiredDataKeys: []string{"id"}}
+	ctx.dynamicDeviceRegistrar = func(map[string]interface{}) ([]*Device, error) {
+		location := &Location{Rack: "rack", Board: "board"}
+		return []*Device{
+			{id: "1", Kind: "test", Location: location, Handler: handler, Data: map[string]interface{}{"id": 1}},
+			{id: "2", Kind: "test", Location: location, Handler: handler, Data: map[string]interface{}{}},
+		}, nil
+	}
+	Config.Plugin = &PluginConfig{
+		SchemeVersion: SchemeVersion{Version: "test"},
+		DynamicRegistration: &DynamicRegistrationSettings{
+			Config: []map[string]interface{}{{}},
+		},
+	}
+	Config.Device = &DeviceConfig{
+		Devices: []*DeviceKind{},
+	}
+
+	err := registerDevices()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "device rack-board-2 (kind test) missing data key required by handler test: id")
+	assert.Equal(t, 0, len(ctx.devices))
+}
+
 // Test_registerDevices3 tests registering devices with the plugin when there
 // is a device config, but it is invalid.
 func Test_registerDevices3(t *testing.T) {
@@ -505,3 +658,120 @@ func TestRedactedJsonConfigOutput(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected, output)
 }
+
+// Test_checkDevicesRegistered tests checking for registered devices when there are
+// no devices and the plugin does not require devices.
+func Test_checkDevicesRegistered(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireDevices: false},
+	}
+
+	assert.Equal(t, 0, len(ctx.devices))
+	err := checkDevicesRegistered()
+	assert.NoError(t, err)
+}
+
+// Test_checkDevicesRegistered2 tests checking for registered devices when there are
+// no devices and the plugin requires devices.
+func Test_checkDevicesRegistered2(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireDevices: true},
+	}
+
+	assert.Equal(t, 0, len(ctx.devices))
+	err := checkDevicesRegistered()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires at least one device")
+}
+
+// Test_checkDevicesRegistered3 tests checking for registered devices when there are
+// devices and the plugin requires devices.
+func Test_checkDevicesRegistered3(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireDevices: true},
+	}
+	ctx.devices["rack-board-device"] = &Device{}
+
+	err := checkDevicesRegistered()
+	assert.NoError(t, err)
+}
+
+// Test_checkOutputTypesUsed tests checking for unused output types when every
+// output type is used by a device and the plugin requires it.
+func Test_checkOutputTypesUsed(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireOutputTypesUsed: true},
+	}
+	ctx.outputTypes["temperature"] = &OutputType{Name: "temperature"}
+	ctx.outputTypes["humidity"] = &OutputType{Name: "humidity"}
+	ctx.devices["rack-board-1"] = &Device{Outputs: []*Output{
+		{OutputType: OutputType{Name: "temperature"}},
+	}}
+	ctx.devices["rack-board-2"] = &Device{Outputs: []*Output{
+		{OutputType: OutputType{Name: "temperature"}},
+		{OutputType: OutputType{Name: "humidity"}},
+	}}
+
+	err := checkOutputTypesUsed()
+	assert.NoError(t, err)
+}
+
+// Test_checkOutputTypesUsed2 tests checking for unused output types when an output
+// type is not used by any device and the plugin requires all output types to be used.
+func Test_checkOutputTypesUsed2(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireOutputTypesUsed: true},
+	}
+	ctx.outputTypes["temperature"] = &OutputType{Name: "temperature"}
+	ctx.outputTypes["humidity"] = &OutputType{Name: "humidity"}
+	ctx.outputTypes["pressure"] = &OutputType{Name: "pressure"}
+	ctx.devices["rack-board-1"] = &Device{Outputs: []*Output{
+		{OutputType: OutputType{Name: "temperature"}},
+	}}
+
+	err := checkOutputTypesUsed()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "settings.requireOutputTypesUsed): humidity, pressure")
+}
+
+// Test_checkOutputTypesUsed3 tests checking for unused output types when an output
+// type is not used by any device and the plugin does not require output types to be used.
+func Test_checkOutputTypesUsed3(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{RequireOutputTypesUsed: false},
+	}
+	ctx.outputTypes["temperature"] = &OutputType{Name: "temperature"}
+
+	err := checkOutputTypesUsed()
+	assert.NoError(t, err)
+}
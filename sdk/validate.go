@@ -2,11 +2,14 @@ package sdk
 
 import (
 	"fmt"
+	"io/ioutil"
 	"reflect"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 	"github.com/vapor-ware/synse-server-grpc/go"
+	"gopkg.in/yaml.v2"
 )
 
 // validator is the global schemeValidator that is used to validate plugin
@@ -15,6 +18,11 @@ var validator = &schemeValidator{}
 
 // schemeValidator is used to validate the scheme of a config.
 type schemeValidator struct {
+	// lock serializes validations, since the validator holds the state of the
+	// validation in progress. Configs may be validated concurrently once the
+	// plugin is running, e.g. when devices are hot-added or reloaded.
+	lock sync.Mutex
+
 	// context is the ConfigContext, which references the configuration
 	// currently being validated.
 	context *ConfigContext
@@ -48,6 +56,9 @@ type schemeValidator struct {
 // validate against, and the config to validate. The "source" from the context is
 // used to attribute to the errors in the event that any are found.
 func (validator *schemeValidator) Validate(context *ConfigContext) *errors.MultiError {
+	validator.lock.Lock()
+	defer validator.lock.Unlock()
+
 	// Once we're done validating, we'll want to clear the state from this validation.
 	defer validator.clearState()
 
@@ -273,13 +284,29 @@ func validateWriteInfo(request *synse.WriteInfo) error {
 	return validateDeviceFilter(request.DeviceFilter)
 }
 
+// validateWriteData validates that the WriteData satisfies the write constraints,
+// if any, that the device has for the data's write action.
+func validateWriteData(device *Device, data *synse.WriteData) error {
+	constraint, ok := device.WriteConstraints[data.Action]
+	if !ok {
+		return nil
+	}
+	if err := constraint.check(string(data.Data)); err != nil {
+		return errors.InvalidArgumentErr("invalid write data for action %q: %v", data.Action, err)
+	}
+	return nil
+}
+
 // validateForRead validates that a device with the given device ID is readable.
 func validateForRead(deviceID string) error {
-	device := ctx.devices[deviceID]
+	device := ctx.getDevice(deviceID)
 	if device == nil {
 		return fmt.Errorf("no device found with ID %s", deviceID)
 	}
 
+	if device.Access == accessWriteOnly {
+		return fmt.Errorf("reading not enabled for device %s (device is write-only)", deviceID)
+	}
 	if !device.IsReadable() {
 		return fmt.Errorf("reading not enabled for device %s (no read handler)", deviceID)
 	}
@@ -288,13 +315,90 @@ func validateForRead(deviceID string) error {
 
 // validateForWrite validates that a device with the given device ID is writable.
 func validateForWrite(deviceID string) error {
-	device := ctx.devices[deviceID]
+	device := ctx.getDevice(deviceID)
 	if device == nil {
 		return fmt.Errorf("no device found with ID %s", deviceID)
 	}
 
+	if device.Access == accessReadOnly {
+		return fmt.Errorf("writing not enabled for device %s (device is read-only)", deviceID)
+	}
 	if !device.IsWritable() {
 		return fmt.Errorf("writing not enabled for device %s (no write handler)", deviceID)
 	}
 	return nil
 }
+
+// ValidationResult holds the results of validating a single config file.
+type ValidationResult struct {
+	// Source is the path of the validated config file.
+	Source string
+
+	// ConfigType is the type of config detected for the file. This is one of
+	// "plugin", "device", or "output type".
+	ConfigType string
+
+	// Errors are the validation errors found for the config. If there are no
+	// errors, the config is valid.
+	Errors []error
+}
+
+// Valid checks whether the validated config has no validation errors.
+func (result *ValidationResult) Valid() bool {
+	return len(result.Errors) == 0
+}
+
+// ValidateConfigFile validates the config file at the given path on its own,
+// without searching for or merging it with any other configs. This is useful
+// for checking config files in an editor or CI. The config type is detected from
+// the file's top-level keys, any registered migrations for that type are applied
+// (but not persisted), and the config is then validated. As when the config is
+// loaded, environment variable references in the file are expanded first.
+//
+// An error is returned if the file can not be read or parsed, or if its config
+// type can not be determined. Otherwise, any validation errors are returned in
+// the ValidationResult.
+func ValidateConfigFile(path string) (*ValidationResult, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec
+	if err != nil {
+		return nil, err
+	}
+	contents, err = expandEnv(contents)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("%s -> %s", path, err)
+	}
+	config, err := newConfigForDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("%s -> unable to determine config type for file", path)
+	}
+	if err := yaml.Unmarshal(contents, config); err != nil {
+		return nil, fmt.Errorf("%s -> %s", path, err)
+	}
+
+	result := &ValidationResult{Source: path}
+	switch c := config.(type) {
+	case *DeviceConfig:
+		result.ConfigType = "device"
+		_, err = migrateConfig(c, ctx.deviceConfigMigrations, currentDeviceSchemeVersion)
+	case *PluginConfig:
+		result.ConfigType = "plugin"
+		_, err = migrateConfig(c, ctx.pluginConfigMigrations, currentPluginSchemeVersion)
+	case *OutputType:
+		result.ConfigType = "output type"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s -> %s", path, err)
+	}
+
+	multiErr := validator.Validate(NewConfigContext(path, config))
+	result.Errors = multiErr.Errors
+	return result, nil
+}
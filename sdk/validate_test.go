@@ -1,9 +1,11 @@
 package sdk
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/internal/test"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
 	"github.com/vapor-ware/synse-server-grpc/go"
 )
@@ -641,6 +643,21 @@ func TestValidateForRead_3(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestValidateForRead_WriteOnly tests validating a write-only device for read.
+func TestValidateForRead_WriteOnly(t *testing.T) {
+	defer resetContext()
+
+	ctx.devices["abc"] = &Device{
+		Access: "write-only",
+		Handler: &DeviceHandler{
+			Read: func(d *Device) ([]*Reading, error) { return nil, nil },
+		},
+	}
+
+	err := validateForRead("abc")
+	assert.EqualError(t, err, "reading not enabled for device abc (device is write-only)")
+}
+
 // TestValidateForWrite_1 tests validating a device for write, when the specified
 // device is not in the device map.
 func TestValidateForWrite_1(t *testing.T) {
@@ -677,3 +694,134 @@ func TestValidateForWrite_3(t *testing.T) {
 	err := validateForWrite("abc")
 	assert.NoError(t, err)
 }
+
+// TestValidateForWrite_ReadOnly tests validating a read-only device for write.
+func TestValidateForWrite_ReadOnly(t *testing.T) {
+	defer resetContext()
+
+	ctx.devices["abc"] = &Device{
+		Access: "read-only",
+		Handler: &DeviceHandler{
+			Write: func(d *Device, data *WriteData) error { return nil },
+		},
+	}
+
+	err := validateForWrite("abc")
+	assert.EqualError(t, err, "writing not enabled for device abc (device is read-only)")
+}
+
+// TestValidateConfigFile tests validating a single valid device config file.
+func TestValidateConfigFile(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	data := `
+version: 1.0
+locations:
+  - name: r1b1
+    rack:
+      name: rack-1
+    board:
+      name: board-1
+devices:
+  - name: temperature
+    instances:
+      - info: temp 1
+        location: r1b1
+`
+	path := test.WriteTempFile(t, "device.yml", data, os.ModePerm)
+
+	result, err := ValidateConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, result.Source)
+	assert.Equal(t, "device", result.ConfigType)
+	assert.True(t, result.Valid())
+	assert.Empty(t, result.Errors)
+}
+
+// TestValidateConfigFile2 tests validating a single invalid device config file.
+func TestValidateConfigFile2(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	data := `
+version: 1.0
+locations:
+  - name: r1b1
+    board:
+      name: board-1
+devices:
+  - name: temperature
+    instances:
+      - info: temp 1
+        debounce: -1s
+`
+	path := test.WriteTempFile(t, "device.yml", data, os.ModePerm)
+
+	result, err := ValidateConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "device", result.ConfigType)
+	assert.False(t, result.Valid())
+	assert.Equal(t, 3, len(result.Errors), result.Errors)
+}
+
+// TestValidateConfigFile3 tests validating single plugin and output type config files.
+func TestValidateConfigFile3(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	plugin := test.WriteTempFile(t, "config.yml", "version: 1.0\ndebug: true\n", os.ModePerm)
+	result, err := ValidateConfigFile(plugin)
+	assert.NoError(t, err)
+	assert.Equal(t, "plugin", result.ConfigType)
+	assert.True(t, result.Valid(), result.Errors)
+
+	output := test.WriteTempFile(t, "output.yml", "version: 1.0\nname: foo..bar\n", os.ModePerm)
+	result, err = ValidateConfigFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "output type", result.ConfigType)
+	assert.False(t, result.Valid())
+	assert.Equal(t, 1, len(result.Errors), result.Errors)
+}
+
+// TestValidateConfigFile_Env tests validating a config file which references
+// environment variables.
+func TestValidateConfigFile_Env(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	test.SetEnv(t, "SDK_TEST_DEBUG", "true")
+	defer test.RemoveEnv(t, "SDK_TEST_DEBUG")
+
+	path := test.WriteTempFile(t, "config.yml", "version: 1.0\ndebug: ${SDK_TEST_DEBUG}\n", os.ModePerm)
+	result, err := ValidateConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "plugin", result.ConfigType)
+	assert.True(t, result.Valid(), result.Errors)
+
+	unset := test.WriteTempFile(t, "unset.yml", "version: 1.0\ndebug: ${SDK_TEST_UNSET}\n", os.ModePerm)
+	_, err = ValidateConfigFile(unset)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SDK_TEST_UNSET")
+}
+
+// TestValidateConfigFile4 tests validating a config file which can not be validated.
+func TestValidateConfigFile4(t *testing.T) {
+	test.SetupTestDir(t)
+	defer test.ClearTestDir(t)
+
+	var testTable = []struct {
+		desc string
+		path string
+	}{
+		{"file does not exist", "does-not-exist.yml"},
+		{"file is not yaml", test.WriteTempFile(t, "bad.yml", "{{{", os.ModePerm)},
+		{"unknown config type", test.WriteTempFile(t, "unknown.yml", "version: 1.0\nfoo: bar\n", os.ModePerm)},
+	}
+
+	for _, testCase := range testTable {
+		result, err := ValidateConfigFile(testCase.path)
+		assert.Error(t, err, testCase.desc)
+		assert.Nil(t, result, testCase.desc)
+	}
+}
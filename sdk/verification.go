@@ -2,6 +2,9 @@ package sdk
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-sdk/sdk/errors"
@@ -11,6 +14,11 @@ var (
 	// deviceConfigLocations is a map to track the locations for the unified
 	// DeviceConfig. The key is the name of the Location.
 	deviceConfigLocations map[string]*LocationConfig
+
+	// verificationLock serializes config verifications, since they share the
+	// tracking state. Configs may be verified concurrently once the plugin is
+	// running, e.g. when devices are hot-added or reloaded.
+	verificationLock sync.Mutex
 )
 
 // verifyConfigs verifies that all device configurations that the plugin has
@@ -30,6 +38,9 @@ var (
 // that all the information in a given config is correct until we have the
 // whole picture of what exists.
 func verifyConfigs(unifiedDeviceConfig *DeviceConfig) *errors.MultiError {
+	verificationLock.Lock()
+	defer verificationLock.Unlock()
+
 	log.Debug("[sdk] verifying unified device config")
 
 	var multiErr = errors.NewMultiError("config verification")
@@ -49,6 +60,9 @@ func verifyConfigs(unifiedDeviceConfig *DeviceConfig) *errors.MultiError {
 	// Verify that device kinds/instances reference valid output types.
 	verifyDeviceConfigOutputs(unifiedDeviceConfig, multiErr)
 
+	// Verify that device instances specify the data required by their handlers.
+	verifyDeviceConfigData(unifiedDeviceConfig, multiErr)
+
 	log.Debugf("[sdk] config verification found %d error(s)", len(multiErr.Errors))
 	return multiErr
 }
@@ -70,7 +84,7 @@ func verifyDeviceConfigLocations(deviceConfig *DeviceConfig, multiErr *errors.Mu
 		// If we already have the location cached, make sure that this Location
 		// is the same as the existing one. If not, we have a conflict.
 		if !loc.Equals(location) {
-			log.WithField("name", loc.Name).Error("[sdk] duplicate location name")
+			repeatedLog.WithField("name", loc.Name).Error("[sdk] duplicate location name")
 			multiErr.Add(
 				errors.NewVerificationConflictError(
 					"device",
@@ -89,11 +103,17 @@ func verifyDeviceConfigInstances(deviceConfig *DeviceConfig, multiErr *errors.Mu
 	for _, device := range deviceConfig.Devices {
 		for _, instance := range device.Instances {
 			if instance.Location == "" {
-				log.Error("[sdk] instance config does not specify location")
+				repeatedLog.WithFields(log.Fields{
+					"kind": device.Name,
+					"info": instance.Info,
+				}).Error("[sdk] instance config does not specify location")
 				multiErr.Add(
 					errors.NewVerificationInvalidError(
 						"device",
-						"device instance needs a location specified, but is empty",
+						fmt.Sprintf(
+							"device instance %q (kind %s) needs a location specified, but is empty",
+							instance.Info, device.Name,
+						),
 					),
 				)
 				continue
@@ -101,16 +121,21 @@ func verifyDeviceConfigInstances(deviceConfig *DeviceConfig, multiErr *errors.Mu
 
 			_, hasLocation := deviceConfigLocations[instance.Location]
 			if !hasLocation {
-				log.WithField("name", instance.Location).Error("[sdk] unknown location specified")
+				repeatedLog.WithFields(log.Fields{
+					"name": instance.Location,
+					"kind": device.Name,
+					"info": instance.Info,
+				}).Error("[sdk] unknown location specified")
 				multiErr.Add(
 					errors.NewVerificationInvalidError(
 						"device",
-						fmt.Sprintf("unknown device instance location specified: %s", instance.Location),
+						fmt.Sprintf(
+							"unknown device instance location specified: %s (instance %q, kind %s)",
+							instance.Location, instance.Info, device.Name,
+						),
 					),
 				)
-				continue
 			}
-
 		}
 	}
 }
@@ -124,7 +149,7 @@ func verifyDeviceConfigOutputs(deviceConfig *DeviceConfig, multiErr *errors.Mult
 		for _, output := range device.Outputs {
 			_, hasOutput := ctx.outputTypes[output.Type]
 			if !hasOutput {
-				log.WithField("name", output.Type).Error("[sdk] unknown output type specified")
+				repeatedLog.WithField("name", output.Type).Error("[sdk] unknown output type specified")
 				multiErr.Add(
 					errors.NewVerificationInvalidError(
 						"device",
@@ -140,7 +165,7 @@ func verifyDeviceConfigOutputs(deviceConfig *DeviceConfig, multiErr *errors.Mult
 			for _, output := range instance.Outputs {
 				_, hasOutput := ctx.outputTypes[output.Type]
 				if !hasOutput {
-					log.WithField("name", output.Type).Error("[sdk] unknown output type specified")
+					repeatedLog.WithField("name", output.Type).Error("[sdk] unknown output type specified")
 					multiErr.Add(
 						errors.NewVerificationInvalidError(
 							"device",
@@ -153,3 +178,207 @@ func verifyDeviceConfigOutputs(deviceConfig *DeviceConfig, multiErr *errors.Mult
 		}
 	}
 }
+
+// verifyDeviceConfigData verifies that the Data of each device instance contains
+// the keys required by the DeviceHandler the instance will use, exactly one key
+// from each of the handler's groups of mutually-exclusive keys, and numeric values
+// which satisfy the handler's numeric constraints. Instances which
+// do not match a registered handler are not checked here; that is reported when the
+// devices are created.
+func verifyDeviceConfigData(deviceConfig *DeviceConfig, multiErr *errors.MultiError) {
+	log.Debug("[sdk] verifying device config instance data keys")
+	for _, device := range deviceConfig.Devices {
+		for _, instance := range device.Instances {
+			handler, err := getHandlerForDevice(getHandlerName(device, instance))
+			if err != nil {
+				continue
+			}
+
+			for _, key := range handler.RequiredDataKeys {
+				if _, hasKey := instance.Data[key]; !hasKey {
+					repeatedLog.WithFields(log.Fields{
+						"kind": device.Name,
+						"info": instance.Info,
+						"key":  key,
+					}).Error("[sdk] device instance missing required data key")
+					multiErr.Add(
+						errors.NewVerificationInvalidError(
+							"device",
+							fmt.Sprintf(
+								"device instance %q (kind %s) missing data key required by handler %s: %s",
+								instance.Info, device.Name, handler.Name, key,
+							),
+						),
+					)
+				}
+			}
+
+			for _, group := range handler.ExclusiveDataKeys {
+				var found []string
+				for _, key := range group {
+					if _, hasKey := instance.Data[key]; hasKey {
+						found = append(found, key)
+					}
+				}
+				if len(found) != 1 {
+					repeatedLog.WithFields(log.Fields{
+						"kind":  device.Name,
+						"info":  instance.Info,
+						"keys":  group,
+						"found": found,
+					}).Error("[sdk] device instance must specify exactly one of the exclusive data keys")
+					multiErr.Add(
+						errors.NewVerificationInvalidError(
+							"device",
+							fmt.Sprintf(
+								"device instance %q (kind %s) must specify exactly one of the data keys %v required by handler %s, but found %d",
+								instance.Info, device.Name, group, handler.Name, len(found),
+							),
+						),
+					)
+				}
+			}
+
+			// Check the numeric constraints in a stable order, so errors are
+			// reported consistently.
+			var numericKeys []string
+			for key := range handler.NumericDataKeys {
+				numericKeys = append(numericKeys, key)
+			}
+			sort.Strings(numericKeys)
+
+			for _, key := range numericKeys {
+				value, hasKey := instance.Data[key]
+				if !hasKey {
+					continue
+				}
+				// Secret references are only resolved when they are accessed, so
+				// their values can not be checked here.
+				if isSecretRef(value) {
+					continue
+				}
+				if err := handler.NumericDataKeys[key].check(value); err != nil {
+					repeatedLog.WithFields(log.Fields{
+						"kind":  device.Name,
+						"info":  instance.Info,
+						"key":   key,
+						"error": err,
+					}).Error("[sdk] device instance data value out of range")
+					multiErr.Add(
+						errors.NewVerificationInvalidError(
+							"device",
+							fmt.Sprintf(
+								"device instance %q (kind %s) has invalid data key %s for handler %s: %v",
+								instance.Info, device.Name, key, handler.Name, err,
+							),
+						),
+					)
+				}
+			}
+		}
+	}
+}
+
+// verifyDeviceData verifies that the Data of each Device contains the keys required
+// by the Device's handler. This is used for devices which are registered directly
+// (e.g. by dynamic device registration), since they are not created from device
+// config and so are not checked by verifyDeviceConfigData. Each missing key is
+// reported for each device, so all of the omissions are found at once.
+func verifyDeviceData(devices []*Device) error {
+	log.Debug("[sdk] verifying device data keys")
+	multiErr := errors.NewMultiError("device data verification")
+	for _, device := range devices {
+		if device.Handler == nil {
+			continue
+		}
+		for _, key := range device.Handler.RequiredDataKeys {
+			if _, hasKey := device.Data[key]; !hasKey {
+				repeatedLog.WithFields(log.Fields{
+					"device": device.GUID(),
+					"kind":   device.Kind,
+					"key":    key,
+				}).Error("[sdk] device missing required data key")
+				multiErr.Add(
+					errors.NewVerificationInvalidError(
+						"device",
+						fmt.Sprintf(
+							"device %s (kind %s) missing data key required by handler %s: %s",
+							device.GUID(), device.Kind, device.Handler.Name, key,
+						),
+					),
+				)
+			}
+		}
+	}
+	return multiErr.Err()
+}
+
+// verifyNoCycles verifies that there are no circular references in a graph of
+// config references, e.g. templates or groups which reference other templates
+// or groups. The graph maps the name of each node to the names of the nodes it
+// references. Resolving a circular reference would never terminate, so an error
+// naming the nodes of each cycle found is added to the MultiError.
+//
+// The device config does not currently have any references which can form a
+// cycle: instances reference their kind, and kinds and instances reference
+// output types and locations, none of which reference other configs. Transaction
+// groups are only names shared by instances. So, this is not yet part of config
+// verification. Config which adds such references, e.g.
+// templates which extend other templates, should verify them with this.
+func verifyNoCycles(configType string, graph map[string][]string, multiErr *errors.MultiError) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	var path []string
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, ref := range graph[node] {
+			switch state[ref] {
+			case unvisited:
+				visit(ref)
+			case visiting:
+				// Found a back-reference to a node on the current path; the
+				// cycle is the path from that node to here.
+				var start int
+				for i, n := range path {
+					if n == ref {
+						start = i
+						break
+					}
+				}
+				cycle := append(append([]string{}, path[start:]...), ref)
+				repeatedLog.WithField("cycle", cycle).Error("[sdk] circular config reference")
+				multiErr.Add(
+					errors.NewVerificationInvalidError(
+						configType,
+						fmt.Sprintf("circular reference detected: %s", strings.Join(cycle, " -> ")),
+					),
+				)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = visited
+	}
+
+	// Visit the nodes in sorted order so that the errors are deterministic.
+	var nodes []string
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+}
@@ -176,6 +176,54 @@ func Test_verifyDeviceConfigInstances_Error(t *testing.T) {
 	assert.Equal(t, 3, len(err.Errors), err.Error())
 }
 
+// Test_verifyDeviceConfigInstances_DanglingLocation tests that verifying the unified
+// device config collects an error for each instance which references a location that
+// is not defined, identifying the instance.
+func Test_verifyDeviceConfigInstances_DanglingLocation(t *testing.T) {
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations: []*LocationConfig{
+			{
+				Name:  "r1b1",
+				Rack:  &LocationData{Name: "rack"},
+				Board: &LocationData{Name: "board"},
+			},
+		},
+		Devices: []*DeviceKind{
+			{
+				Name: "temperature",
+				Instances: []*DeviceInstance{
+					{Info: "temp 1", Location: "r1b1"},
+					{Info: "temp 2", Location: "r1b2"},
+				},
+			},
+			{
+				Name: "led",
+				Instances: []*DeviceInstance{
+					{Info: "led 1", Location: "r2b1"},
+				},
+			},
+		},
+	}
+
+	err := verifyConfigs(cfg)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 2, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), `unknown device instance location specified: r1b2 (instance "temp 2", kind temperature)`)
+	assert.Contains(t, err.Errors[1].Error(), `unknown device instance location specified: r2b1 (instance "led 1", kind led)`)
+
+	// Once the location is defined, the reference resolves.
+	cfg.Locations = append(cfg.Locations, &LocationConfig{
+		Name:  "r1b2",
+		Rack:  &LocationData{Name: "rack"},
+		Board: &LocationData{Name: "board 2"},
+	})
+	cfg.Devices = cfg.Devices[:1]
+
+	err = verifyConfigs(cfg)
+	assert.NoError(t, err.Err())
+}
+
 // Test_verifyDeviceConfigOutputs_Ok tests verifying no issues with device outputs.
 func Test_verifyDeviceConfigOutputs_Ok(t *testing.T) {
 	defer resetContext()
@@ -289,3 +337,370 @@ func Test_verifyDeviceConfigOutputs_Error(t *testing.T) {
 	assert.Error(t, err.Err())
 	assert.Equal(t, 3, len(err.Errors), err.Error())
 }
+
+// Test_verifyDeviceConfigData_Ok tests successfully verifying the required data keys
+// of device instances.
+func Test_verifyDeviceConfigData_Ok(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", RequiredDataKeys: []string{"id"}},
+		{Name: "override", RequiredDataKeys: []string{"id", "channel"}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"id": 1},
+					},
+					{
+						Location:    "foo",
+						HandlerName: "override",
+						Data:        map[string]interface{}{"id": 2, "channel": 3},
+					},
+				},
+			},
+			{
+				// no handler is registered for this kind, so it is not checked here.
+				Name: "foo",
+				Instances: []*DeviceInstance{
+					{
+						Location: "bar",
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.NoError(t, err.Err())
+}
+
+// Test_verifyDeviceConfigData_Error tests verification errors when device instances
+// are missing data keys required by their handler.
+func Test_verifyDeviceConfigData_Error(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", RequiredDataKeys: []string{"id", "channel"}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Info:     "temp 1",
+						Location: "foo",
+						Data:     map[string]interface{}{"channel": 1}, // err: no id
+					},
+					{
+						Info:     "temp 2",
+						Location: "foo", // err: no id, no channel
+					},
+					{
+						Info:     "temp 3",
+						Location: "foo",
+						Data:     map[string]interface{}{"id": 1, "channel": 1},
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 3, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), `"temp 1"`)
+	assert.Contains(t, err.Errors[0].Error(), "id")
+}
+
+// Test_verifyDeviceConfigData_Exclusive tests verifying device instances which
+// specify a single key from a group of mutually-exclusive data keys.
+func Test_verifyDeviceConfigData_Exclusive(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", ExclusiveDataKeys: [][]string{{"tcp_host", "serial_port"}}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"tcp_host": "localhost"},
+					},
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"serial_port": "/dev/ttyUSB0", "id": 1},
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.NoError(t, err.Err())
+}
+
+// Test_verifyDeviceConfigData_ExclusiveError tests verification errors when device
+// instances specify none, or more than one, of a group of mutually-exclusive keys.
+func Test_verifyDeviceConfigData_ExclusiveError(t *testing.T) {
+	defer resetContext()
+
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", ExclusiveDataKeys: [][]string{{"tcp_host", "serial_port"}}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Info:     "neither",
+						Location: "foo",
+						Data:     map[string]interface{}{"id": 1}, // err: neither key
+					},
+					{
+						Info:     "both",
+						Location: "foo",
+						Data:     map[string]interface{}{"tcp_host": "localhost", "serial_port": "/dev/ttyUSB0"}, // err: both keys
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 2, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), `"neither"`)
+	assert.Contains(t, err.Errors[0].Error(), "found 0")
+	assert.Contains(t, err.Errors[1].Error(), `"both"`)
+	assert.Contains(t, err.Errors[1].Error(), "found 2")
+}
+
+// Test_verifyNoCycles_Ok tests verifying a reference graph without cycles.
+func Test_verifyNoCycles_Ok(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+		"c": {},
+		"d": {"a", "unknown"},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyNoCycles("device", graph, err)
+	assert.NoError(t, err.Err())
+}
+
+// Test_verifyNoCycles_TwoNodes tests detecting a cycle between two nodes.
+func Test_verifyNoCycles_TwoNodes(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b"},
+		"b": {"a"},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyNoCycles("device", graph, err)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 1, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), "a -> b -> a")
+}
+
+// Test_verifyNoCycles_ThreeNodes tests detecting a cycle between three nodes,
+// which is not reached from the first node visited.
+func Test_verifyNoCycles_ThreeNodes(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"d"},
+		"d": {"b"},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyNoCycles("device", graph, err)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 1, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), "b -> c -> d -> b")
+}
+
+// Test_verifyNoCycles_SelfReference tests detecting a node which references itself.
+func Test_verifyNoCycles_SelfReference(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"a"},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyNoCycles("device", graph, err)
+	assert.Error(t, err.Err())
+	assert.Contains(t, err.Errors[0].Error(), "a -> a")
+}
+
+// Test_verifyDeviceConfigData_Numeric tests verifying device instance data values
+// which satisfy their handler's numeric constraints.
+func Test_verifyDeviceConfigData_Numeric(t *testing.T) {
+	defer resetContext()
+
+	min, max := 1.0, 65535.0
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", NumericDataKeys: map[string]NumericDataConstraint{
+			"port":  {Min: &min, Max: &max, Integer: true},
+			"scale": {},
+		}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"port": 502, "scale": 0.5},
+					},
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"port": "65535", "scale": "-2"},
+					},
+					{
+						Location: "foo",
+						Data:     map[string]interface{}{"port": 1.0},
+					},
+					{
+						// secret references are not checked until they are resolved.
+						Location: "foo",
+						Data:     map[string]interface{}{"port": "secret:env:PORT"},
+					},
+					{
+						// the constrained keys are not required.
+						Location: "foo",
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.NoError(t, err.Err())
+}
+
+// Test_verifyDeviceConfigData_NumericError tests verification errors when device
+// instance data values do not satisfy their handler's numeric constraints.
+func Test_verifyDeviceConfigData_NumericError(t *testing.T) {
+	defer resetContext()
+
+	min, max := 1.0, 65535.0
+	ctx.deviceHandlers = []*DeviceHandler{
+		{Name: "test", NumericDataKeys: map[string]NumericDataConstraint{
+			"port":    {Min: &min, Max: &max, Integer: true},
+			"channel": {Min: &min},
+		}},
+	}
+
+	cfg := &DeviceConfig{
+		SchemeVersion: SchemeVersion{Version: "1.0"},
+		Locations:     []*LocationConfig{},
+		Devices: []*DeviceKind{
+			{
+				Name: "test",
+				Instances: []*DeviceInstance{
+					{
+						Info:     "above",
+						Location: "foo",
+						Data:     map[string]interface{}{"port": "70000"}, // err: above max
+					},
+					{
+						Info:     "below",
+						Location: "foo",
+						Data:     map[string]interface{}{"port": 0, "channel": -1}, // err: below min (x2)
+					},
+					{
+						Info:     "fraction",
+						Location: "foo",
+						Data:     map[string]interface{}{"port": "502.5"}, // err: not an integer
+					},
+					{
+						Info:     "nan",
+						Location: "foo",
+						Data:     map[string]interface{}{"port": "abc"}, // err: not a number
+					},
+				},
+			},
+		},
+	}
+
+	err := errors.NewMultiError("test")
+	verifyDeviceConfigData(cfg, err)
+	assert.Error(t, err.Err())
+	assert.Equal(t, 5, len(err.Errors), err.Error())
+	assert.Contains(t, err.Errors[0].Error(), `"above"`)
+	assert.Contains(t, err.Errors[0].Error(), "value 70000 is above the maximum 65535")
+	assert.Contains(t, err.Errors[1].Error(), "data key channel")
+	assert.Contains(t, err.Errors[1].Error(), "value -1 is below the minimum 1")
+	assert.Contains(t, err.Errors[2].Error(), "data key port")
+	assert.Contains(t, err.Errors[2].Error(), "value 0 is below the minimum 1")
+	assert.Contains(t, err.Errors[3].Error(), "value 502.5 is not an integer")
+	assert.Contains(t, err.Errors[4].Error(), "value abc is not a number")
+}
+
+// Test_verifyDeviceData_Ok tests successfully verifying the required data keys of
+// registered devices.
+func Test_verifyDeviceData_Ok(t *testing.T) {
+	handler := &DeviceHandler{Name: "test", RequiredDataKeys: []string{"id"}}
+	location := &Location{Rack: "rack", Board: "board"}
+
+	err := verifyDeviceData([]*Device{
+		{id: "1", Kind: "test", Location: location, Handler: handler, Data: map[string]interface{}{"id": 1}},
+		{id: "2", Kind: "test", Location: location, Handler: handler, Data: map[string]interface{}{"id": 2, "channel": 3}},
+		// devices with no handler, or whose handler requires no keys, are not checked.
+		{id: "3", Kind: "test", Location: location},
+		{id: "4", Kind: "test", Location: location, Handler: &DeviceHandler{Name: "other"}},
+	})
+	assert.NoError(t, err)
+}
+
+// Test_verifyDeviceData_Error tests verification errors when registered devices are
+// missing data keys required by their handler.
+func Test_verifyDeviceData_Error(t *testing.T) {
+	handler := &DeviceHandler{Name: "test", RequiredDataKeys: []string{"id", "channel"}}
+	location := &Location{Rack: "rack", Board: "board"}
+
+	err := verifyDeviceData([]*Device{
+		{id: "1", Kind: "temp", Location: location, Handler: handler, Data: map[string]interface{}{"id": 1, "channel": 1}},
+		{id: "2", Kind: "temp", Location: location, Handler: handler, Data: map[string]interface{}{"channel": 1}},
+		{id: "3", Kind: "temp", Location: location, Handler: handler},
+	})
+	assert.Error(t, err)
+
+	merr, ok := err.(*errors.MultiError)
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(merr.Errors), merr.Error())
+	assert.EqualError(t, merr.Errors[0], "device config invalid: device rack-board-2 (kind temp) missing data key required by handler test: id")
+	assert.EqualError(t, merr.Errors[1], "device config invalid: device rack-board-3 (kind temp) missing data key required by handler test: id")
+	assert.EqualError(t, merr.Errors[2], "device config invalid: device rack-board-3 (kind temp) missing data key required by handler test: channel")
+}
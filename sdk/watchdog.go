@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// deviceWatchdog tracks when each device last produced readings, if the staleness
+// watchdog is enabled in the plugin configuration. Devices which produce no
+// readings within the watchdog timeout (e.g. because their handler returns no
+// readings, without an error) are marked as stale until they produce readings
+// again.
+var deviceWatchdog *watchdog
+
+// watchdog tracks the time each device last produced readings, keyed by device
+// ID, and the devices which are stale.
+type watchdog struct {
+	sync.Mutex
+
+	timeout  time.Duration
+	lastSeen map[string]time.Time
+	stale    map[string]bool
+}
+
+// newWatchdog creates a new watchdog which marks devices as stale when they
+// produce no readings within the given timeout.
+func newWatchdog(timeout time.Duration) *watchdog {
+	return &watchdog{
+		timeout:  timeout,
+		lastSeen: map[string]time.Time{},
+		stale:    map[string]bool{},
+	}
+}
+
+// observe records that the device produced readings at the given time. If the
+// device was stale, it no longer is.
+func (w *watchdog) observe(device string, now time.Time) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.lastSeen[device] = now
+	if w.stale[device] {
+		log.WithField("device", device).Info("[watchdog] device readings resumed")
+		delete(w.stale, device)
+	}
+}
+
+// forget stops watching the device until it is next checked, e.g. while it is
+// outside of its active hours.
+func (w *watchdog) forget(device string) {
+	w.Lock()
+	defer w.Unlock()
+
+	delete(w.lastSeen, device)
+	delete(w.stale, device)
+}
+
+// check checks whether the given devices are stale at the given time. It returns
+// the devices which have become stale since they were last checked. Devices which
+// have not been seen before are watched from the given time.
+func (w *watchdog) check(devices []string, now time.Time) []string {
+	w.Lock()
+	defer w.Unlock()
+
+	var stale []string
+	for _, device := range devices {
+		last, ok := w.lastSeen[device]
+		if !ok {
+			w.lastSeen[device] = now
+			continue
+		}
+		if !w.stale[device] && now.Sub(last) >= w.timeout {
+			w.stale[device] = true
+			stale = append(stale, device)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// devices gets the IDs of all stale devices, sorted.
+func (w *watchdog) devices() []string {
+	w.Lock()
+	defer w.Unlock()
+
+	var devices []string
+	for device := range w.stale {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// setupDeviceWatchdog sets up the device staleness watchdog, if it is enabled in
+// the plugin configuration.
+func setupDeviceWatchdog() error {
+	watchdogSettings := Config.Plugin.Settings.Watchdog
+	if watchdogSettings == nil || !watchdogSettings.Enabled {
+		log.Debug("[watchdog] device staleness watchdog disabled")
+		return nil
+	}
+	timeout, err := watchdogSettings.GetTimeout()
+	if err != nil {
+		return err
+	}
+	log.WithField("timeout", timeout).Info("[watchdog] enabling device staleness watchdog")
+	deviceWatchdog = newWatchdog(timeout)
+	return nil
+}
+
+// watchReadings records that the device which the readings from a ReadContext
+// are for has produced readings, if the watchdog is enabled. Error readings and
+// stale-marker readings are not counted.
+func watchReadings(readCtx *ReadContext) {
+	if deviceWatchdog == nil {
+		return
+	}
+	for _, reading := range readCtx.Reading {
+		if reading == nil {
+			continue
+		}
+		if _, isErr := reading.Context[ContextKeyError]; isErr {
+			continue
+		}
+		if _, isStale := reading.Context[ContextKeyStale]; isStale {
+			continue
+		}
+		deviceWatchdog.observe(readCtx.ID(), clock.Now())
+		return
+	}
+}
+
+// goWatchdog starts the goroutine which periodically checks for stale devices,
+// if the watchdog is enabled. Devices are checked at half of the watchdog
+// timeout, so a device is found to be stale within 1.5x the timeout.
+func (manager *dataManager) goWatchdog() {
+	if deviceWatchdog == nil {
+		return
+	}
+	go func() {
+		for {
+			clock.Sleep(deviceWatchdog.timeout / 2)
+			manager.checkStaleDevices()
+		}
+	}()
+}
+
+// checkStaleDevices checks the readable and active devices for staleness. If
+// configured, a stale-marker reading is emitted for each device which has become
+// stale.
+func (manager *dataManager) checkStaleDevices() {
+	if deviceWatchdog == nil {
+		return
+	}
+
+	// The device map may be swapped while the devices are checked (e.g. when a
+	// device is reloaded), so all lookups go through the same snapshot of it.
+	devices := ctx.getDevices()
+
+	var ids []string
+	for id, device := range devices {
+		if !device.IsReadable() {
+			continue
+		}
+		if !device.IsActive() {
+			deviceWatchdog.forget(id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range deviceWatchdog.check(ids, clock.Now()) {
+		log.WithFields(log.Fields{
+			"device":  id,
+			"timeout": deviceWatchdog.timeout,
+		}).Warn("[watchdog] no readings from device within timeout")
+
+		if Config.Plugin.Settings.Watchdog.StaleReadings {
+			manager.readChannel <- newStaleReadContext(devices[id], deviceWatchdog.timeout)
+		}
+	}
+}
+
+// newStaleReadContext creates a ReadContext holding stale-marker readings for a
+// device which has produced no readings within the watchdog timeout. These are
+// error readings (see newErrorReadContext) which are also marked as stale in
+// their context.
+func newStaleReadContext(device *Device, timeout time.Duration) *ReadContext {
+	readCtx := newErrorReadContext(device, fmt.Errorf("no readings within %v", timeout))
+	for _, reading := range readCtx.Reading {
+		reading.Context[ContextKeyStale] = "true"
+	}
+	return readCtx
+}
+
+// getStaleDevices gets the IDs of the stale devices. If the watchdog is not
+// enabled, nil is returned.
+func getStaleDevices() []string {
+	if deviceWatchdog == nil {
+		return nil
+	}
+	return deviceWatchdog.devices()
+}
@@ -0,0 +1,285 @@
+package sdk
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// Test setting up the device watchdog when it is enabled in the config.
+func Test_setupDeviceWatchdog_Enabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		deviceWatchdog = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Watchdog: &WatchdogSettings{
+				Enabled: true,
+				Timeout: "30s",
+			},
+		},
+	}
+
+	assert.Nil(t, deviceWatchdog)
+	assert.NoError(t, setupDeviceWatchdog())
+	assert.NotNil(t, deviceWatchdog)
+	assert.Equal(t, 30*time.Second, deviceWatchdog.timeout)
+}
+
+// Test setting up the device watchdog when it is disabled in the config.
+func Test_setupDeviceWatchdog_Disabled(t *testing.T) {
+	defer func() {
+		Config.reset()
+		deviceWatchdog = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Watchdog: &WatchdogSettings{Timeout: "30s"},
+		},
+	}
+
+	assert.NoError(t, setupDeviceWatchdog())
+	assert.Nil(t, deviceWatchdog)
+	assert.Nil(t, getStaleDevices())
+	assert.NoError(t, watchdogHealthCheck())
+}
+
+// Test setting up the device watchdog with a bad timeout.
+func Test_setupDeviceWatchdog_Error(t *testing.T) {
+	defer func() {
+		Config.reset()
+		deviceWatchdog = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Watchdog: &WatchdogSettings{Enabled: true, Timeout: "soon"},
+		},
+	}
+
+	assert.Error(t, setupDeviceWatchdog())
+	assert.Nil(t, deviceWatchdog)
+}
+
+// Test that the watchdog fires for a device which goes silent, after the timeout,
+// and that the device recovers once it produces readings again.
+func TestDataManager_checkStaleDevices(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+		deviceWatchdog = nil
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache:    &CacheSettings{},
+			Watchdog: &WatchdogSettings{Enabled: true, Timeout: "1m"},
+		},
+	}
+	deviceWatchdog = newWatchdog(time.Minute)
+
+	handler := &DeviceHandler{Name: "test", Read: func(*Device) ([]*Reading, error) { return nil, nil }}
+	ctx.devices["rack-board-device"] = &Device{id: "device", Location: &Location{Rack: "rack", Board: "board"}, Handler: handler}
+	ctx.devices["rack-board-other"] = &Device{id: "other", Location: &Location{Rack: "rack", Board: "board"}, Handler: handler}
+
+	d := newDataManager()
+	d.readChannel = make(chan *ReadContext, 10)
+	read := func(device string, readings ...*Reading) {
+		d.updateReadings(&ReadContext{Rack: "rack", Board: "board", Device: device, Reading: readings})
+	}
+
+	// Both devices are watched from the first check.
+	d.checkStaleDevices()
+	read("device", &Reading{Value: 1})
+	read("other", &Reading{Value: 1})
+
+	// The device goes silent: its handler returns no readings, and error
+	// readings do not count as readings. The other device keeps reading.
+	for i := 0; i < 5; i++ {
+		c.Advance(15 * time.Second)
+		read("device")
+		read("device", &Reading{Context: map[string]string{ContextKeyError: "read failed"}})
+		read("other", &Reading{Value: 1})
+		d.checkStaleDevices()
+		if i < 3 {
+			assert.Empty(t, getStaleDevices(), "before timeout: %d", i)
+			assert.NoError(t, watchdogHealthCheck())
+		}
+	}
+
+	// After the timeout, the watchdog fires for the silent device only.
+	assert.Equal(t, []string{"rack-board-device"}, getStaleDevices())
+	assert.Error(t, watchdogHealthCheck())
+
+	// Stale-marker readings are not emitted unless configured.
+	assert.Empty(t, d.readChannel)
+
+	// Once the device produces readings again, it is no longer stale.
+	read("device", &Reading{Value: 2})
+	assert.Empty(t, getStaleDevices())
+	assert.NoError(t, watchdogHealthCheck())
+}
+
+// Test checking for stale devices while the device map is being updated.
+func TestDataManager_checkStaleDevices_Concurrent(t *testing.T) {
+	defer func() {
+		Config.reset()
+		resetContext()
+		deviceWatchdog = nil
+	}()
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache:    &CacheSettings{},
+			Read:     &ReadSettings{},
+			Watchdog: &WatchdogSettings{Enabled: true, Timeout: "1m", StaleReadings: true},
+		},
+	}
+	deviceWatchdog = newWatchdog(0)
+
+	handler := &DeviceHandler{Name: "test", Read: func(*Device) ([]*Reading, error) { return nil, nil }}
+	ctx.devices["rack-board-device"] = &Device{id: "device", Location: &Location{Rack: "rack", Board: "board"}, Handler: handler}
+
+	d := newDataManager()
+	d.readChannel = make(chan *ReadContext, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			d.checkStaleDevices()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		err := ctx.updateDevices(func(devices map[string]*Device) error {
+			if i%2 == 0 {
+				delete(devices, "rack-board-device")
+			} else {
+				devices["rack-board-device"] = &Device{id: "device", Location: &Location{Rack: "rack", Board: "board"}, Handler: handler}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+
+	// Every stale-marker reading is for the device, even if it was removed
+	// from the device map while it was being checked.
+	close(d.readChannel)
+	for readCtx := range d.readChannel {
+		assert.Equal(t, "rack-board-device", readCtx.ID())
+	}
+}
+
+// Test that stale-marker readings are emitted when a device becomes stale, if
+// configured, and that they do not count as readings for the device.
+func TestDataManager_checkStaleDevices_StaleReadings(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+		deviceWatchdog = nil
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Cache:    &CacheSettings{},
+			Read:     &ReadSettings{},
+			Watchdog: &WatchdogSettings{Enabled: true, Timeout: "1m", StaleReadings: true},
+		},
+	}
+	deviceWatchdog = newWatchdog(time.Minute)
+
+	handler := &DeviceHandler{Name: "test", Read: func(*Device) ([]*Reading, error) { return nil, nil }}
+	ctx.devices["rack-board-device"] = &Device{
+		id:       "device",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  handler,
+		Outputs:  []*Output{{OutputType: OutputType{Name: "temperature"}}},
+	}
+
+	d := newDataManager()
+	d.readChannel = make(chan *ReadContext, 10)
+
+	d.checkStaleDevices()
+	c.Advance(time.Minute)
+	d.checkStaleDevices()
+
+	// A stale-marker reading is emitted once, when the device becomes stale.
+	c.Advance(time.Minute)
+	d.checkStaleDevices()
+	assert.Equal(t, 1, len(d.readChannel))
+
+	readCtx := <-d.readChannel
+	assert.Equal(t, "rack-board-device", readCtx.ID())
+	assert.Equal(t, 1, len(readCtx.Reading))
+	assert.Equal(t, "temperature", readCtx.Reading[0].Type)
+	assert.Nil(t, readCtx.Reading[0].Value)
+	assert.Equal(t, "true", readCtx.Reading[0].Context[ContextKeyStale])
+	assert.Equal(t, "error: no readings within 1m0s", readCtx.Reading[0].Info)
+
+	d.updateReadings(readCtx)
+	assert.Equal(t, []string{"rack-board-device"}, getStaleDevices())
+}
+
+// Test that devices outside of their active hours, and devices which are not
+// readable, are not watched.
+func TestDataManager_checkStaleDevices_Inactive(t *testing.T) {
+	defer func() {
+		clock = realClock{}
+		Config.reset()
+		resetContext()
+		deviceWatchdog = nil
+	}()
+	c := useFakeClock(t, time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC))
+	deviceWatchdog = newWatchdog(time.Minute)
+
+	ctx.devices["rack-board-inactive"] = &Device{
+		id:          "inactive",
+		Location:    &Location{Rack: "rack", Board: "board"},
+		Handler:     &DeviceHandler{Name: "test", Read: func(*Device) ([]*Reading, error) { return nil, nil }},
+		ActiveHours: &ActiveHours{Start: "08:00", End: "18:00"},
+	}
+	ctx.devices["rack-board-writeonly"] = &Device{
+		id:       "writeonly",
+		Location: &Location{Rack: "rack", Board: "board"},
+		Handler:  &DeviceHandler{Name: "test", Write: func(*Device, *WriteData) error { return nil }},
+	}
+
+	d := newDataManager()
+	for i := 0; i < 3; i++ {
+		d.checkStaleDevices()
+		c.Advance(time.Minute)
+	}
+	assert.Empty(t, getStaleDevices())
+}
+
+// TestWatchdogSettings_Validate tests validating WatchdogSettings.
+func TestWatchdogSettings_Validate(t *testing.T) {
+	var testTable = []struct {
+		desc     string
+		errCount int
+		config   WatchdogSettings
+	}{
+		{desc: "disabled", config: WatchdogSettings{}},
+		{desc: "enabled", config: WatchdogSettings{Enabled: true, Timeout: "5m"}},
+		{desc: "invalid timeout", errCount: 1, config: WatchdogSettings{Enabled: true, Timeout: "soon"}},
+		{desc: "zero timeout", errCount: 1, config: WatchdogSettings{Enabled: true, Timeout: "0s"}},
+	}
+
+	for _, testCase := range testTable {
+		merr := errors.NewMultiError("test")
+		testCase.config.Validate(merr)
+		assert.Equal(t, testCase.errCount, len(merr.Errors), testCase.desc)
+	}
+}
@@ -0,0 +1,209 @@
+package sdk
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vapor-ware/synse-sdk/sdk/errors"
+)
+
+// deviceWriteQueue is a bounded queue of writes for a single device. The writes
+// in the queue are fulfilled serially, in the order they were queued, so rapid
+// writes to a device do not race with one another. Each device has its own queue,
+// so a slow device does not hold up writes to other devices.
+type deviceWriteQueue struct {
+	// writes is the channel of writes waiting to be fulfilled. Its capacity
+	// is the size of the queue.
+	writes chan *WriteContext
+
+	// Lock around queuing writes, so all of the writes for a request are
+	// either queued together or rejected together.
+	lock *sync.Mutex
+
+	// pending tracks the writes which have been queued but not yet fulfilled.
+	pending *sync.WaitGroup
+
+	// workers limits the number of write queues which may fulfill a write at
+	// the same time. It is shared by all of the queues, and is nil when the
+	// number of write workers is not limited.
+	workers chan struct{}
+
+	// serial is whether the plugin runs in serial mode, in which case writes
+	// are locked against reads.
+	serial bool
+}
+
+// writeQueuesEnabled checks whether writes should be routed through per-device
+// write queues, based on the configuration.
+func writeQueuesEnabled() bool {
+	return Config.Plugin != nil &&
+		Config.Plugin.Settings != nil &&
+		Config.Plugin.Settings.Write != nil &&
+		Config.Plugin.Settings.Write.QueueSize > 0
+}
+
+// getWriteQueue gets the write queue for the device with the given ID, creating
+// it and starting the goroutine which fulfills its writes on first use.
+func (manager *dataManager) getWriteQueue(device string) *deviceWriteQueue {
+	manager.writeQueuesLock.Lock()
+	defer manager.writeQueuesLock.Unlock()
+
+	if manager.writeWorkers == nil && Config.Plugin.Settings.Write.Workers > 0 {
+		manager.writeWorkers = make(chan struct{}, Config.Plugin.Settings.Write.Workers)
+	}
+
+	queue, ok := manager.writeQueues[device]
+	if !ok {
+		queue = &deviceWriteQueue{
+			writes:  make(chan *WriteContext, Config.Plugin.Settings.Write.QueueSize),
+			lock:    &sync.Mutex{},
+			pending: &sync.WaitGroup{},
+			workers: manager.writeWorkers,
+			serial:  Config.Plugin.Settings.Mode == "serial",
+		}
+		manager.writeQueues[device] = queue
+		go manager.processWriteQueue(queue)
+	}
+	return queue
+}
+
+// processWriteQueue fulfills the writes in the given queue, one at a time. If the
+// number of write workers is limited, each write waits for a free worker first.
+// A write to a transaction group is fulfilled by the last of its members' queues
+// to reach it; the others wait for it to complete before moving on.
+func (manager *dataManager) processWriteQueue(queue *deviceWriteQueue) {
+	for w := range queue.writes {
+		if w.barrier != nil && !w.barrier.arrive() {
+			<-w.barrier.done
+			queue.pending.Done()
+			continue
+		}
+		if queue.workers != nil {
+			queue.workers <- struct{}{}
+		}
+		// If the plugin is a serial plugin, we want to lock around reads
+		// and writes so the two operations do not stomp on one another.
+		if queue.serial {
+			manager.rwLock.Lock()
+			manager.write(w)
+			manager.rwLock.Unlock()
+		} else {
+			manager.write(w)
+		}
+		if queue.workers != nil {
+			<-queue.workers
+		}
+		if w.barrier != nil {
+			close(w.barrier.done)
+		}
+		queue.pending.Done()
+	}
+}
+
+// checkRoom checks that the queue has room for the given number of writes. It
+// must be called with the queue's lock held.
+func (queue *deviceWriteQueue) checkRoom(device string, count int) error {
+	queued, size := len(queue.writes), cap(queue.writes)
+	if size-queued < count {
+		return errors.ResourceExhaustedErr(
+			"write queue full for device %s: %d of %d writes queued, cannot queue %d more",
+			device, queued, size, count,
+		)
+	}
+	return nil
+}
+
+// reserveWriteQueues locks the write queues for the devices with the given IDs
+// and checks that each has room for the given number of writes. If so, the
+// writes are marked as pending in each queue, and the writes must then be sent
+// to every one of the queues before they are unlocked with the returned function.
+// Otherwise, no writes are reserved and an error is returned.
+//
+// The queues are locked in order of device ID, so that writes for overlapping sets
+// of devices (e.g. transaction groups which share a member) are queued in the same
+// order in all of the queues they share.
+func (manager *dataManager) reserveWriteQueues(ids []string, count int) ([]*deviceWriteQueue, func(), error) {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+
+	queues := make([]*deviceWriteQueue, 0, len(sorted))
+	for _, id := range sorted {
+		queue := manager.getWriteQueue(id)
+		queue.lock.Lock()
+		queues = append(queues, queue)
+	}
+	unlock := func() {
+		for i := len(queues) - 1; i >= 0; i-- {
+			queues[i].lock.Unlock()
+		}
+	}
+
+	for i, queue := range queues {
+		if err := queue.checkRoom(sorted[i], count); err != nil {
+			unlock()
+			return nil, nil, err
+		}
+	}
+	for _, queue := range queues {
+		queue.pending.Add(count)
+	}
+	return queues, unlock, nil
+}
+
+// groupBarrier synchronizes the write queues of the members of a transaction
+// group for a write to the group. The write is queued in each member's queue,
+// so it is ordered with the writes made to the members directly: it is only
+// fulfilled once all of the queues have reached it, and none of them move on
+// to their next write until it is complete.
+type groupBarrier struct {
+	// waiting is the number of queues which have not yet reached the write.
+	waiting int32
+
+	// done is closed once the write is complete.
+	done chan struct{}
+}
+
+// newGroupBarrier creates a groupBarrier for a write queued in the given number
+// of write queues.
+func newGroupBarrier(queues int) *groupBarrier {
+	return &groupBarrier{waiting: int32(queues), done: make(chan struct{})}
+}
+
+// arrive marks that a queue has reached the write. It returns true for the last
+// queue to reach it, which should then fulfill the write.
+func (barrier *groupBarrier) arrive() bool {
+	return atomic.AddInt32(&barrier.waiting, -1) == 0
+}
+
+// waitForWriteQueues waits for the writes in the per-device write queues to be
+// fulfilled, for up to the given timeout. A timeout of 0 waits indefinitely. It
+// returns false if writes were still pending when the timeout elapsed.
+func (manager *dataManager) waitForWriteQueues(timeout time.Duration) bool {
+	manager.writeQueuesLock.Lock()
+	queues := make([]*deviceWriteQueue, 0, len(manager.writeQueues))
+	for _, queue := range manager.writeQueues {
+		queues = append(queues, queue)
+	}
+	manager.writeQueuesLock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, queue := range queues {
+			queue.pending.Wait()
+		}
+		close(done)
+	}()
+
+	if timeout <= 0 {
+		<-done
+		return true
+	}
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
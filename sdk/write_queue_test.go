@@ -0,0 +1,366 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-server-grpc/go"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// setupWriteQueueTest is a test helper which configures per-device write queues
+// of the given size and adds writable devices with the given names, which are
+// written to with the given write function.
+func setupWriteQueueTest(size int, write func(*Device, *WriteData) error, devices ...string) {
+	setupTransactionCache(time.Duration(600) * time.Second)
+	DataManager.writeChannel = make(chan *WriteContext, 20)
+	Config.Plugin = &PluginConfig{
+		Settings: &PluginSettings{
+			Write: &WriteSettings{
+				Enabled:   true,
+				QueueSize: size,
+			},
+		},
+	}
+	for _, name := range devices {
+		ctx.devices["rack-board-"+name] = &Device{
+			id:       name,
+			Kind:     "foo",
+			Location: &Location{Rack: "rack", Board: "board"},
+			Handler:  &DeviceHandler{Write: write},
+		}
+	}
+}
+
+// writeQueueRequest is a test helper which makes a write request for the given
+// device with the given write actions.
+func writeQueueRequest(device string, actions ...string) *synse.WriteInfo {
+	req := &synse.WriteInfo{
+		DeviceFilter: &synse.DeviceFilter{
+			Rack:   "rack",
+			Board:  "board",
+			Device: device,
+		},
+	}
+	for _, action := range actions {
+		req.Data = append(req.Data, &synse.WriteData{Action: action})
+	}
+	return req
+}
+
+// TestDataManager_WriteQueueOrdering tests that writes queued for a device are
+// fulfilled in the order they were queued.
+func TestDataManager_WriteQueueOrdering(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	written := map[string][]string{}
+
+	setupWriteQueueTest(20, func(device *Device, data *WriteData) error {
+		lock.Lock()
+		written[device.id] = append(written[device.id], data.Action)
+		lock.Unlock()
+		wg.Done()
+		return nil
+	}, "dev-1", "dev-2")
+
+	var expected []string
+	for i := 0; i < 10; i++ {
+		expected = append(expected, fmt.Sprintf("action-%d", i))
+	}
+
+	wg.Add(4 * len(expected))
+	for _, device := range []string{"dev-1", "dev-2"} {
+		for i := 0; i < len(expected); i += 5 {
+			// Queue the writes across multiple requests, with multiple
+			// writes per request.
+			_, err := DataManager.Write(context.Background(), writeQueueRequest(device, expected[i:i+5]...))
+			assert.NoError(t, err)
+		}
+	}
+	// Single-write requests are queued behind the earlier multi-write requests.
+	for _, device := range []string{"dev-1", "dev-2"} {
+		for _, action := range expected {
+			_, err := DataManager.Write(context.Background(), writeQueueRequest(device, action))
+			assert.NoError(t, err)
+		}
+	}
+	wg.Wait()
+	DataManager.getWriteQueue("rack-board-dev-1").pending.Wait()
+	DataManager.getWriteQueue("rack-board-dev-2").pending.Wait()
+
+	for _, device := range []string{"dev-1", "dev-2"} {
+		assert.Equal(t, append(expected, expected...), written[device], device)
+	}
+
+	// Writes should not have gone through the shared write channel.
+	assert.Equal(t, 0, len(DataManager.writeChannel))
+}
+
+// TestDataManager_WriteQueueIndependent tests that a device with a blocked write
+// does not hold up writes to another device.
+func TestDataManager_WriteQueueIndependent(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	release := make(chan struct{})
+	done := make(chan string, 10)
+
+	setupWriteQueueTest(5, func(device *Device, data *WriteData) error {
+		if device.id == "slow" {
+			<-release
+		}
+		done <- device.id
+		return nil
+	}, "slow", "fast")
+
+	_, err := DataManager.Write(context.Background(), writeQueueRequest("slow", "foo"))
+	assert.NoError(t, err)
+	_, err = DataManager.Write(context.Background(), writeQueueRequest("fast", "foo"))
+	assert.NoError(t, err)
+
+	select {
+	case id := <-done:
+		assert.Equal(t, "fast", id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("write to fast device was blocked by slow device")
+	}
+
+	close(release)
+	assert.Equal(t, "slow", <-done)
+	DataManager.getWriteQueue("rack-board-slow").pending.Wait()
+	DataManager.getWriteQueue("rack-board-fast").pending.Wait()
+}
+
+// TestDataManager_WriteQueueFull tests that a write is rejected when its device's
+// write queue is full.
+func TestDataManager_WriteQueueFull(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+
+	setupWriteQueueTest(2, func(device *Device, data *WriteData) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, "device")
+
+	// The first write is taken off the queue and blocks in the handler.
+	_, err := DataManager.Write(context.Background(), writeQueueRequest("device", "first"))
+	assert.NoError(t, err)
+	<-started
+
+	// The next writes fill the queue.
+	resp, err := DataManager.Write(context.Background(), writeQueueRequest("device", "second", "third"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resp))
+
+	// Once it is full, writes are rejected.
+	resp, err = DataManager.Write(context.Background(), writeQueueRequest("device", "fourth"))
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "write queue full for device rack-board-device")
+
+	close(release)
+	DataManager.getWriteQueue("rack-board-device").pending.Wait()
+}
+
+// TestDataManager_WriteQueueRequestTooLarge tests that a request with more writes
+// than there is room for in the queue is rejected without queuing any of them.
+func TestDataManager_WriteQueueRequestTooLarge(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	setupWriteQueueTest(2, func(device *Device, data *WriteData) error {
+		return nil
+	}, "device")
+
+	resp, err := DataManager.Write(context.Background(), writeQueueRequest("device", "a", "b", "c"))
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	queue := DataManager.getWriteQueue("rack-board-device")
+	assert.Equal(t, 0, len(queue.writes))
+}
+
+// TestDataManager_WriteQueueWorkers tests that writes to different devices are
+// fulfilled in parallel up to the write worker limit, while writes to the same
+// device are still fulfilled in order.
+func TestDataManager_WriteQueueWorkers(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	var lock sync.Mutex
+	var active, maxActive int
+	written := map[string][]string{}
+	started := make(chan struct{}, 20)
+	release := make(chan struct{})
+
+	devices := []string{"dev-1", "dev-2", "dev-3", "dev-4"}
+	setupWriteQueueTest(10, func(device *Device, data *WriteData) error {
+		lock.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		written[device.id] = append(written[device.id], data.Action)
+		lock.Unlock()
+
+		started <- struct{}{}
+		<-release
+
+		lock.Lock()
+		active--
+		lock.Unlock()
+		return nil
+	}, devices...)
+	Config.Plugin.Settings.Write.Workers = 2
+
+	for _, device := range devices {
+		_, err := DataManager.Write(context.Background(), writeQueueRequest(device, "action-1", "action-2"))
+		assert.NoError(t, err)
+	}
+
+	// Two writes should start, and no more until one of them finishes.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for writes to start")
+		}
+	}
+	select {
+	case <-started:
+		t.Fatal("more writes started than there are write workers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for _, device := range devices {
+		DataManager.getWriteQueue("rack-board-" + device).pending.Wait()
+	}
+
+	assert.Equal(t, 2, maxActive)
+	for _, device := range devices {
+		assert.Equal(t, []string{"action-1", "action-2"}, written[device], device)
+	}
+}
+
+// TestPlugin_shutdownWriteQueues tests that the plugin waits for queued writes when
+// it shuts down, and that it stops waiting once the shutdown timeout elapses.
+func TestPlugin_shutdownWriteQueues(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	release := make(chan struct{})
+	var written []string
+	var lock sync.Mutex
+	setupWriteQueueTest(2, func(device *Device, data *WriteData) error {
+		<-release
+		lock.Lock()
+		written = append(written, data.Action)
+		lock.Unlock()
+		return nil
+	}, "device")
+	Config.Plugin.Settings.ShutdownTimeout = "50ms"
+
+	_, err := DataManager.Write(context.Background(), writeQueueRequest("device", "first", "second"))
+	assert.NoError(t, err)
+
+	// The writes are blocked, so the wait times out.
+	assert.False(t, DataManager.waitForWriteQueues(10*time.Millisecond))
+
+	plugin := NewPlugin()
+	plugin.server = newServer(networkTypeTCP, "localhost:5001")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	assert.Equal(t, 0, plugin.shutdown(os.Interrupt))
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{"first", "second"}, written)
+}
+
+// TestDataManager_WriteQueueGroup tests that a write to a transaction group is
+// ordered with the writes queued for its members directly.
+func TestDataManager_WriteQueueGroup(t *testing.T) {
+	defer func() {
+		resetContext()
+		Config.reset()
+		DataManager = newDataManager()
+	}()
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	var lock sync.Mutex
+	written := map[string][]string{}
+
+	setupWriteQueueTest(5, func(device *Device, data *WriteData) error {
+		if device.id == "a" && data.Action == "first" {
+			started <- struct{}{}
+			<-release
+		}
+		lock.Lock()
+		written[device.id] = append(written[device.id], data.Action)
+		lock.Unlock()
+		return nil
+	}, "a", "b")
+	Config.Plugin.Settings.Write.Workers = 1
+	for _, id := range []string{"a", "b"} {
+		ctx.devices["rack-board-"+id].Group = "group"
+	}
+
+	// The first write to device a blocks in the handler.
+	_, err := DataManager.Write(context.Background(), writeQueueRequest("a", "first"))
+	assert.NoError(t, err)
+	<-started
+
+	// The group write waits for device a's queue, and the next write to device
+	// b waits for the group write, even though device b's queue was empty.
+	_, err = DataManager.Write(context.Background(), writeQueueRequest("group", "group"))
+	assert.NoError(t, err)
+	_, err = DataManager.Write(context.Background(), writeQueueRequest("b", "second"))
+	assert.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	lock.Lock()
+	assert.Empty(t, written["b"])
+	lock.Unlock()
+
+	close(release)
+	assert.True(t, DataManager.waitForWriteQueues(time.Second))
+	assert.Equal(t, []string{"first", "group"}, written["a"])
+	assert.Equal(t, []string{"group", "second"}, written["b"])
+}